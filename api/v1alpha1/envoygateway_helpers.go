@@ -225,6 +225,14 @@ func DefaultEnvoyGatewayAdmin() *EnvoyGatewayAdmin {
 	}
 }
 
+// GetSnapshotHistorySize returns the number of xDS snapshots kept for every IR key.
+func (a *EnvoyGatewayAdmin) GetSnapshotHistorySize() int {
+	if a == nil || a.SnapshotHistorySize == nil {
+		return DefaultSnapshotHistorySize
+	}
+	return *a.SnapshotHistorySize
+}
+
 // DefaultEnvoyGatewayAdminAddress returns a new EnvoyGatewayAdminAddress with default configuration parameters.
 func DefaultEnvoyGatewayAdminAddress() *EnvoyGatewayAdminAddress {
 	return &EnvoyGatewayAdminAddress{
@@ -287,6 +295,12 @@ func (r *EnvoyGatewayProvider) IsRunningOnHost() bool {
 		r.Custom.Infrastructure.Type == InfrastructureProviderTypeHost
 }
 
+func (r *EnvoyGatewayProvider) IsRunningOnDocker() bool {
+	return r.Type == ProviderTypeCustom &&
+		r.Custom.Infrastructure != nil &&
+		r.Custom.Infrastructure.Type == InfrastructureProviderTypeDocker
+}
+
 // DefaultEnvoyGatewayLoggingLevel returns a new EnvoyGatewayLogging with default configuration parameters.
 // When v1alpha1.LogComponentGatewayDefault specified, all other logging components are ignored.
 func (logging *EnvoyGatewayLogging) DefaultEnvoyGatewayLoggingLevel(level LogLevel) LogLevel {
@@ -20,6 +20,8 @@ const (
 	GatewayAdminPort = 19000
 	// GatewayAdminHost is the host of envoy gateway admin server.
 	GatewayAdminHost = "127.0.0.1"
+	// DefaultSnapshotHistorySize is the default number of xDS snapshots kept for every IR key.
+	DefaultSnapshotHistorySize = 10
 	// GatewayMetricsPort is the port which envoy gateway metrics server is listening on.
 	GatewayMetricsPort = 19001
 	// GatewayMetricsHost is the host of envoy gateway metrics server.
@@ -93,6 +95,24 @@ type EnvoyGatewaySpec struct {
 	//
 	// +optional
 	ExtensionAPIs *ExtensionAPISettings `json:"extensionApis,omitempty"`
+
+	// XdsCanary enables the canary rollout of the xDS configuration of a Gateway to
+	// its Envoy proxies. A new xDS configuration is first pushed to a subset of the
+	// connected Envoy proxies, and only promoted to the other ones once it's accepted
+	// by the subset for the soak duration.
+	// If unspecified, the xDS configuration is pushed to all the Envoy proxies at once.
+	//
+	// +optional
+	XdsCanary *XdsCanaryRollout `json:"xdsCanary,omitempty"`
+
+	// XdsSnapshotPath is the path to a directory the latest xDS configuration of every
+	// Gateway is persisted into. On startup, the persisted xDS configuration is served
+	// to the Envoy proxies right away, until the resources are translated again.
+	// The directory should be on a volume which outlives the Envoy Gateway process.
+	// If unspecified, the xDS configuration isn't persisted.
+	//
+	// +optional
+	XdsSnapshotPath string `json:"xdsSnapshotPath,omitempty"`
 }
 
 type KubernetesClient struct {
@@ -331,17 +351,23 @@ type EnvoyGatewayCustomProvider struct {
 
 // ResourceProviderType defines the types of custom resource providers supported by Envoy Gateway.
 //
-// +kubebuilder:validation:Enum=File
+// +kubebuilder:validation:Enum=File;Git;HTTP
 type ResourceProviderType string
 
 const (
 	// ResourceProviderTypeFile defines the "File" provider.
 	ResourceProviderTypeFile ResourceProviderType = "File"
+
+	// ResourceProviderTypeGit defines the "Git" provider.
+	ResourceProviderTypeGit ResourceProviderType = "Git"
+
+	// ResourceProviderTypeHTTP defines the "HTTP" provider.
+	ResourceProviderTypeHTTP ResourceProviderType = "HTTP"
 )
 
 // EnvoyGatewayResourceProvider defines configuration for the Custom Resource provider.
 type EnvoyGatewayResourceProvider struct {
-	// Type is the type of resource provider to use. Supported types are "File".
+	// Type is the type of resource provider to use. Supported types are "File", "Git" and "HTTP".
 	//
 	// +unionDiscriminator
 	Type ResourceProviderType `json:"type"`
@@ -350,28 +376,136 @@ type EnvoyGatewayResourceProvider struct {
 	//
 	// +optional
 	File *EnvoyGatewayFileResourceProvider `json:"file,omitempty"`
+	// Git defines the configuration of the Git provider. Git provides runtime
+	// configuration defined by the files of a Git repository at a branch or tag.
+	//
+	// +optional
+	Git *EnvoyGatewayGitResourceProvider `json:"git,omitempty"`
+	// HTTP defines the configuration of the HTTP provider. HTTP provides runtime
+	// configuration defined by a bundle of resources fetched from an HTTP(S) endpoint.
+	//
+	// +optional
+	HTTP *EnvoyGatewayHTTPResourceProvider `json:"http,omitempty"`
 }
 
 // EnvoyGatewayFileResourceProvider defines configuration for the File Resource provider.
 type EnvoyGatewayFileResourceProvider struct {
 	// Paths are the paths to a directory or file containing the resource configuration.
-	// Recursive subdirectories are not currently supported.
+	// Subdirectories are only loaded when Recursive is enabled.
 	Paths []string `json:"paths"`
+
+	// Recursive enables loading and watching resources from all the subdirectories
+	// of the given directories, including subdirectories created afterwards.
+	//
+	// +optional
+	Recursive *bool `json:"recursive,omitempty"`
+
+	// Include is a list of glob patterns a file under the given directories must match
+	// to be loaded. Patterns are matched against the file path relative to the directory,
+	// and `**` matches any number of path segments, e.g. `tenants/**/*.yaml`.
+	// If unset, all files are loaded.
+	//
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude is a list of glob patterns, in the same format as Include, for files under
+	// the given directories that must not be loaded. Exclude takes precedence over Include.
+	//
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// StatusPath is the path to a directory the File provider writes the computed status
+	// of every resource to, as `<kind>/[<namespace>/]<name>.yaml` files in the same shape
+	// as the Kubernetes resource with its `.status` field.
+	// The statuses are always served by the admin server on `/api/status`.
+	//
+	// +optional
+	StatusPath string `json:"statusPath,omitempty"`
+}
+
+// EnvoyGatewayGitResourceProvider defines configuration for the Git Resource provider.
+type EnvoyGatewayGitResourceProvider struct {
+	// Repository is the Git repository containing the resource configuration,
+	// either the path to a local (bare) repository or a `file://` URL.
+	Repository string `json:"repository"`
+
+	// Ref is the branch or tag to load the resource configuration from.
+	// Defaults to the HEAD of the repository.
+	//
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// Paths are the paths to directories or files in the repository containing the
+	// resource configuration, relative to the root of the repository. Directories are
+	// loaded recursively. If unset, the whole repository is loaded. Only the `.yaml`,
+	// `.yml` and `.json` files are loaded, hidden files and directories are ignored.
+	//
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+
+	// PollInterval is the interval between two checks of the branch or tag for new commits.
+	// Defaults to 30s.
+	//
+	// +optional
+	PollInterval *gwapiv1.Duration `json:"pollInterval,omitempty"`
+
+	// StatusPath is the path to a directory the Git provider writes the computed status
+	// of every resource to, in the same format as the File provider, along with the SHA
+	// of the applied commit in the `revision` file.
+	// The statuses and the revision are always served by the admin server on `/api/status`.
+	//
+	// +optional
+	StatusPath string `json:"statusPath,omitempty"`
+}
+
+// EnvoyGatewayHTTPResourceProvider defines configuration for the HTTP Resource provider.
+type EnvoyGatewayHTTPResourceProvider struct {
+	// URL is the HTTP(S) URL of the resource bundle. The bundle is either a tar archive,
+	// optionally gzip compressed, of files containing the resource configuration,
+	// or a single YAML file.
+	URL string `json:"url"`
+
+	// PollInterval is the interval between two fetches of the bundle. The bundle is fetched
+	// with the ETag and Last-Modified of the previous response, so an unchanged bundle isn't
+	// downloaded again. Defaults to 30s.
+	//
+	// +optional
+	PollInterval *gwapiv1.Duration `json:"pollInterval,omitempty"`
+
+	// Timeout is the timeout of a fetch of the bundle. Defaults to 10s.
+	//
+	// +optional
+	Timeout *gwapiv1.Duration `json:"timeout,omitempty"`
+
+	// CACertificatePath is the path to a PEM file with the CA certificates used to verify
+	// the certificate of the HTTPS endpoint. If unset, the system CA certificates are used.
+	//
+	// +optional
+	CACertificatePath string `json:"caCertificatePath,omitempty"`
+
+	// StatusPath is the path to a directory the HTTP provider writes the computed status
+	// of every resource to, in the same format as the File provider.
+	// The statuses are always served by the admin server on `/api/status`.
+	//
+	// +optional
+	StatusPath string `json:"statusPath,omitempty"`
 }
 
 // InfrastructureProviderType defines the types of custom infrastructure providers supported by Envoy Gateway.
 //
-// +kubebuilder:validation:Enum=Host
+// +kubebuilder:validation:Enum=Host;Docker
 type InfrastructureProviderType string
 
 const (
 	// InfrastructureProviderTypeHost defines the "Host" provider.
 	InfrastructureProviderTypeHost InfrastructureProviderType = "Host"
+	// InfrastructureProviderTypeDocker defines the "Docker" provider.
+	InfrastructureProviderTypeDocker InfrastructureProviderType = "Docker"
 )
 
 // EnvoyGatewayInfrastructureProvider defines configuration for the Custom Infrastructure provider.
 type EnvoyGatewayInfrastructureProvider struct {
-	// Type is the type of infrastructure providers to use. Supported types are "Host".
+	// Type is the type of infrastructure providers to use. Supported types are "Host" and "Docker".
 	//
 	// +unionDiscriminator
 	Type InfrastructureProviderType `json:"type"`
@@ -380,11 +514,142 @@ type EnvoyGatewayInfrastructureProvider struct {
 	//
 	// +optional
 	Host *EnvoyGatewayHostInfrastructureProvider `json:"host,omitempty"`
+	// Docker defines the configuration of the Docker provider. Docker provides runtime
+	// deployment of the data plane as containers managed through the Docker Engine API.
+	//
+	// +optional
+	Docker *EnvoyGatewayDockerInfrastructureProvider `json:"docker,omitempty"`
 }
 
 // EnvoyGatewayHostInfrastructureProvider defines configuration for the Host Infrastructure provider.
 type EnvoyGatewayHostInfrastructureProvider struct {
-	// TODO: Add config as use cases are better understood.
+	// EnvoyVersion is the version of Envoy downloaded and run with func-e, e.g. `1.34.1`.
+	// Defaults to the latest Envoy version. Mutually exclusive with EnvoyBinaryPath.
+	//
+	// +optional
+	EnvoyVersion string `json:"envoyVersion,omitempty"`
+
+	// EnvoyBinaryPath is the path to an Envoy binary installed on the host, which is run
+	// instead of downloading Envoy. Mutually exclusive with EnvoyVersion.
+	//
+	// +optional
+	EnvoyBinaryPath string `json:"envoyBinaryPath,omitempty"`
+
+	// HomeDir is the working directory of the managed processes, containing the downloaded
+	// Envoy versions and the runtime data of the processes. Instances of Envoy Gateway
+	// running on the same host must use different home directories.
+	// Defaults to `/tmp/envoy-gateway`.
+	//
+	// +optional
+	HomeDir string `json:"homeDir,omitempty"`
+
+	// BaseID defines how the `--base-id` of every Envoy process is allocated, which must be
+	// unique on the host for Envoy processes to run side by side.
+	// If unset, Envoy uses its default base ID.
+	//
+	// +optional
+	BaseID *HostBaseID `json:"baseID,omitempty"`
+
+	// ExtraArgs are extra arguments passed to every Envoy process.
+	//
+	// +optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+
+	// LogDir is the directory the output of every managed process is written to,
+	// as `<name>.log` files. If unset, the output is written to the standard output
+	// of Envoy Gateway.
+	//
+	// +optional
+	LogDir string `json:"logDir,omitempty"`
+
+	// Resources defines the resource limits of every managed process.
+	// Resource limits are only supported on Linux.
+	//
+	// +optional
+	Resources *HostProcessResources `json:"resources,omitempty"`
+}
+
+// EnvoyGatewayDockerInfrastructureProvider defines configuration for the Docker Infrastructure provider.
+type EnvoyGatewayDockerInfrastructureProvider struct {
+	// Host is the address of the Docker daemon, e.g. `unix:///var/run/docker.sock`.
+	// Defaults to the `DOCKER_HOST` environment variable, or the default Docker socket.
+	//
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// EnvoyImage is the image of the Envoy containers. Defaults to the default Envoy Proxy image.
+	//
+	// +optional
+	EnvoyImage string `json:"envoyImage,omitempty"`
+
+	// RateLimitImage is the image of the rate limit service container.
+	// Defaults to the default rate limit image.
+	//
+	// +optional
+	RateLimitImage string `json:"rateLimitImage,omitempty"`
+
+	// Network is the Docker network the containers are attached to, it's created if it doesn't exist.
+	// Defaults to `envoy-gateway`.
+	//
+	// +optional
+	Network string `json:"network,omitempty"`
+
+	// XdsServerHost is the address the containers use to connect to Envoy Gateway.
+	// Defaults to `host.docker.internal`, which resolves to the host running Docker.
+	//
+	// +optional
+	XdsServerHost string `json:"xdsServerHost,omitempty"`
+}
+
+// HostBaseIDType defines the types of base ID allocation supported by the Host Infrastructure provider.
+//
+// +kubebuilder:validation:Enum=Dynamic;Sequential
+type HostBaseIDType string
+
+const (
+	// HostBaseIDTypeDynamic lets Envoy pick an unused base ID with `--use-dynamic-base-id`.
+	HostBaseIDTypeDynamic HostBaseIDType = "Dynamic"
+	// HostBaseIDTypeSequential allocates the lowest base ID, starting from Start,
+	// that isn't used by another Envoy process of the same Envoy Gateway.
+	HostBaseIDTypeSequential HostBaseIDType = "Sequential"
+)
+
+// HostBaseID defines how the `--base-id` of Envoy processes is allocated.
+type HostBaseID struct {
+	// Type is the type of base ID allocation. Supported types are "Dynamic" and "Sequential".
+	//
+	// +unionDiscriminator
+	Type HostBaseIDType `json:"type"`
+
+	// Start is the first base ID allocated with the Sequential type. Envoy Gateway instances
+	// running on the same host must use non-overlapping ranges. Defaults to 0.
+	//
+	// +optional
+	Start *uint32 `json:"start,omitempty"`
+}
+
+// HostProcessResources defines the resource limits of a process managed by the Host Infrastructure provider.
+type HostProcessResources struct {
+	// MaxOpenFiles is the maximum number of open file descriptors of the process (RLIMIT_NOFILE).
+	//
+	// +optional
+	MaxOpenFiles *uint64 `json:"maxOpenFiles,omitempty"`
+
+	// CgroupParent is the path to a cgroup v2 directory, e.g. `/sys/fs/cgroup/envoy-gateway`,
+	// under which a cgroup is created for every process. It's required to limit Memory or CPU.
+	//
+	// +optional
+	CgroupParent string `json:"cgroupParent,omitempty"`
+
+	// Memory is the maximum memory of the process (`memory.max` of its cgroup).
+	//
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+
+	// CPU is the maximum CPU of the process, e.g. `500m` (`cpu.max` of its cgroup).
+	//
+	// +optional
+	CPU *resource.Quantity `json:"cpu,omitempty"`
 }
 
 // RateLimit defines the configuration associated with the Rate Limit Service
@@ -659,8 +924,74 @@ type EnvoyGatewayAdmin struct {
 	//
 	// +optional
 	EnablePprof bool `json:"enablePprof,omitempty"`
+	// EnableDashboard defines if enable the web dashboard of the resource topology
+	// in Envoy Gateway Admin Server, served on the /dashboard/ path.
+	//
+	// +optional
+	EnableDashboard bool `json:"enableDashboard,omitempty"`
+	// SnapshotHistorySize defines the number of xDS snapshots kept for every Gateway,
+	// or GatewayClass when its Gateways are merged. The kept snapshots can be listed,
+	// diffed and rolled back to with the Envoy Gateway Admin Server.
+	// Defaults to 10.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	SnapshotHistorySize *int `json:"snapshotHistorySize,omitempty"`
 }
 
+// XdsCanaryRollout defines the canary rollout of the xDS configuration of a Gateway.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.replicas) && has(self.percentage))",message="only one of replicas or percentage can be specified"
+type XdsCanaryRollout struct {
+	// Replicas defines the number of Envoy proxies of a Gateway which receive a new
+	// xDS configuration first.
+	// Defaults to 1 if Percentage is unspecified.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Percentage defines the percentage of the Envoy proxies of a Gateway which receive
+	// a new xDS configuration first, rounded up to at least one Envoy proxy.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Percentage *int32 `json:"percentage,omitempty"`
+	// SoakDuration defines how long the canary Envoy proxies have to run a new xDS
+	// configuration without rejecting it before it can be promoted.
+	// Defaults to 60s.
+	//
+	// +optional
+	SoakDuration *gwapiv1.Duration `json:"soakDuration,omitempty"`
+	// Promotion defines whether a new xDS configuration is promoted to all the Envoy
+	// proxies once the soak duration has passed, or only through the Envoy Gateway
+	// Admin Server.
+	// Defaults to Automatic.
+	//
+	// +optional
+	Promotion *XdsCanaryAction `json:"promotion,omitempty"`
+	// Abort defines whether the canary Envoy proxies are reverted to the previous xDS
+	// configuration as soon as one of them rejects the new one, or only through the
+	// Envoy Gateway Admin Server.
+	// Defaults to Automatic.
+	//
+	// +optional
+	Abort *XdsCanaryAction `json:"abort,omitempty"`
+}
+
+// XdsCanaryAction defines how an action of a canary rollout is taken.
+//
+// +kubebuilder:validation:Enum=Automatic;Manual
+type XdsCanaryAction string
+
+const (
+	// XdsCanaryActionAutomatic takes the action as soon as its condition is met.
+	XdsCanaryActionAutomatic XdsCanaryAction = "Automatic"
+	// XdsCanaryActionManual only takes the action through the Envoy Gateway Admin Server.
+	XdsCanaryActionManual XdsCanaryAction = "Manual"
+)
+
 // EnvoyGatewayAdminAddress defines the Envoy Gateway Admin Address configuration.
 type EnvoyGatewayAdminAddress struct {
 	// Port defines the port the admin server is exposed on.
@@ -147,6 +147,7 @@ const (
 	//
 	// * "Invalid"
 	// * "ResourceNotFound"
+	// * "Rejected"
 	//
 	PolicyConditionProgrammed gwapiv1a2.PolicyConditionType = "Programmed"
 
@@ -162,6 +163,10 @@ const (
 	// policy cannot find the resource type to patch to.
 	PolicyReasonResourceNotFound gwapiv1a2.PolicyConditionReason = "ResourceNotFound"
 
+	// PolicyReasonRejected is used with the "Programmed" condition when Envoy
+	// rejected the xDS resources patched by the policy.
+	PolicyReasonRejected gwapiv1a2.PolicyConditionReason = "Rejected"
+
 	// PolicyReasonDisabled is used with the "Accepted" condition when the policy
 	// feature is disabled by the configuration.
 	PolicyReasonDisabled gwapiv1a2.PolicyConditionReason = "Disabled"
@@ -8,6 +8,11 @@ package validation
 import (
 	"fmt"
 	"net/url"
+	"path"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
 )
@@ -59,6 +64,10 @@ func ValidateEnvoyGateway(eg *egv1a1.EnvoyGateway) error {
 		return err
 	}
 
+	if err := validateEnvoyGatewayXdsCanary(eg.XdsCanary); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -109,6 +118,52 @@ func validateEnvoyGatewayCustomResourceProvider(resource egv1a1.EnvoyGatewayReso
 		if len(resource.File.Paths) == 0 {
 			return fmt.Errorf("no paths were assigned for file resource provider to watch")
 		}
+
+		for _, pattern := range append(resource.File.Include, resource.File.Exclude...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid glob pattern %q for file resource provider: %w", pattern, err)
+			}
+		}
+	case egv1a1.ResourceProviderTypeGit:
+		if resource.Git == nil {
+			return fmt.Errorf("field 'git' should be specified when resource type is 'Git'")
+		}
+
+		if len(resource.Git.Repository) == 0 {
+			return fmt.Errorf("no repository was assigned for git resource provider to poll")
+		}
+
+		if resource.Git.PollInterval != nil {
+			d, err := time.ParseDuration(string(*resource.Git.PollInterval))
+			if err != nil {
+				return fmt.Errorf("invalid poll interval for git resource provider: %w", err)
+			}
+			if d <= 0 {
+				return fmt.Errorf("poll interval for git resource provider must be positive")
+			}
+		}
+	case egv1a1.ResourceProviderTypeHTTP:
+		if resource.HTTP == nil {
+			return fmt.Errorf("field 'http' should be specified when resource type is 'HTTP'")
+		}
+
+		u, err := url.Parse(resource.HTTP.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid url %q for http resource provider", resource.HTTP.URL)
+		}
+
+		for _, d := range []*gwapiv1.Duration{resource.HTTP.PollInterval, resource.HTTP.Timeout} {
+			if d == nil {
+				continue
+			}
+			parsed, err := time.ParseDuration(string(*d))
+			if err != nil {
+				return fmt.Errorf("invalid duration for http resource provider: %w", err)
+			}
+			if parsed <= 0 {
+				return fmt.Errorf("duration %s for http resource provider must be positive", *d)
+			}
+		}
 	default:
 		return fmt.Errorf("unsupported resource provider: %s", resource.Type)
 	}
@@ -125,12 +180,49 @@ func validateEnvoyGatewayCustomInfrastructureProvider(infra *egv1a1.EnvoyGateway
 		if infra.Host == nil {
 			return fmt.Errorf("field 'host' should be specified when infrastructure type is 'Host'")
 		}
+		if err := validateEnvoyGatewayHostInfrastructureProvider(infra.Host); err != nil {
+			return err
+		}
+	case egv1a1.InfrastructureProviderTypeDocker:
+		if infra.Docker == nil {
+			return fmt.Errorf("field 'docker' should be specified when infrastructure type is 'Docker'")
+		}
 	default:
 		return fmt.Errorf("unsupported infrastructure provdier: %s", infra.Type)
 	}
 	return nil
 }
 
+func validateEnvoyGatewayHostInfrastructureProvider(host *egv1a1.EnvoyGatewayHostInfrastructureProvider) error {
+	if host.EnvoyVersion != "" && host.EnvoyBinaryPath != "" {
+		return fmt.Errorf("envoyVersion and envoyBinaryPath of host infrastructure provider are mutually exclusive")
+	}
+
+	if host.BaseID != nil {
+		switch host.BaseID.Type {
+		case egv1a1.HostBaseIDTypeDynamic:
+			if host.BaseID.Start != nil {
+				return fmt.Errorf("base id start can only be set with the Sequential base id type")
+			}
+		case egv1a1.HostBaseIDTypeSequential:
+		default:
+			return fmt.Errorf("unsupported base id type: %s", host.BaseID.Type)
+		}
+	}
+
+	if resources := host.Resources; resources != nil {
+		if (resources.Memory != nil || resources.CPU != nil) && resources.CgroupParent == "" {
+			return fmt.Errorf("cgroupParent should be specified to limit the memory or cpu of host processes")
+		}
+		for _, q := range []*resource.Quantity{resources.Memory, resources.CPU} {
+			if q != nil && q.Sign() <= 0 {
+				return fmt.Errorf("resource limit %s of host processes must be positive", q)
+			}
+		}
+	}
+	return nil
+}
+
 func validateEnvoyGatewayLogging(logging *egv1a1.EnvoyGatewayLogging) error {
 	if logging == nil || len(logging.Level) == 0 {
 		return nil
@@ -230,3 +322,39 @@ func validateEnvoyGatewayTelemetry(telemetry *egv1a1.EnvoyGatewayTelemetry) erro
 	}
 	return nil
 }
+
+func validateEnvoyGatewayXdsCanary(canary *egv1a1.XdsCanaryRollout) error {
+	if canary == nil {
+		return nil
+	}
+
+	if canary.Replicas != nil && canary.Percentage != nil {
+		return fmt.Errorf("only one of replicas or percentage can be specified for the xds canary rollout")
+	}
+	if canary.Replicas != nil && *canary.Replicas < 1 {
+		return fmt.Errorf("xds canary rollout replicas must be at least 1")
+	}
+	if canary.Percentage != nil && (*canary.Percentage < 1 || *canary.Percentage > 100) {
+		return fmt.Errorf("xds canary rollout percentage must be between 1 and 100")
+	}
+	if canary.SoakDuration != nil {
+		d, err := time.ParseDuration(string(*canary.SoakDuration))
+		if err != nil {
+			return fmt.Errorf("invalid xds canary rollout soak duration: %w", err)
+		}
+		if d < 0 {
+			return fmt.Errorf("xds canary rollout soak duration must not be negative")
+		}
+	}
+	for _, action := range []*egv1a1.XdsCanaryAction{canary.Promotion, canary.Abort} {
+		if action == nil {
+			continue
+		}
+		switch *action {
+		case egv1a1.XdsCanaryActionAutomatic, egv1a1.XdsCanaryActionManual:
+		default:
+			return fmt.Errorf("unsupported xds canary rollout action: %s", *action)
+		}
+	}
+	return nil
+}
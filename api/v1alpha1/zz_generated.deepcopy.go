@@ -1617,6 +1617,11 @@ func (in *EnvoyGatewayAdmin) DeepCopyInto(out *EnvoyGatewayAdmin) {
 		*out = new(EnvoyGatewayAdminAddress)
 		**out = **in
 	}
+	if in.SnapshotHistorySize != nil {
+		in, out := &in.SnapshotHistorySize, &out.SnapshotHistorySize
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyGatewayAdmin.
@@ -1665,6 +1670,21 @@ func (in *EnvoyGatewayCustomProvider) DeepCopy() *EnvoyGatewayCustomProvider {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyGatewayDockerInfrastructureProvider) DeepCopyInto(out *EnvoyGatewayDockerInfrastructureProvider) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyGatewayDockerInfrastructureProvider.
+func (in *EnvoyGatewayDockerInfrastructureProvider) DeepCopy() *EnvoyGatewayDockerInfrastructureProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyGatewayDockerInfrastructureProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvoyGatewayFileResourceProvider) DeepCopyInto(out *EnvoyGatewayFileResourceProvider) {
 	*out = *in
@@ -1673,6 +1693,21 @@ func (in *EnvoyGatewayFileResourceProvider) DeepCopyInto(out *EnvoyGatewayFileRe
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Recursive != nil {
+		in, out := &in.Recursive, &out.Recursive
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyGatewayFileResourceProvider.
@@ -1685,9 +1720,74 @@ func (in *EnvoyGatewayFileResourceProvider) DeepCopy() *EnvoyGatewayFileResource
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyGatewayGitResourceProvider) DeepCopyInto(out *EnvoyGatewayGitResourceProvider) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyGatewayGitResourceProvider.
+func (in *EnvoyGatewayGitResourceProvider) DeepCopy() *EnvoyGatewayGitResourceProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyGatewayGitResourceProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyGatewayHTTPResourceProvider) DeepCopyInto(out *EnvoyGatewayHTTPResourceProvider) {
+	*out = *in
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyGatewayHTTPResourceProvider.
+func (in *EnvoyGatewayHTTPResourceProvider) DeepCopy() *EnvoyGatewayHTTPResourceProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyGatewayHTTPResourceProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvoyGatewayHostInfrastructureProvider) DeepCopyInto(out *EnvoyGatewayHostInfrastructureProvider) {
 	*out = *in
+	if in.BaseID != nil {
+		in, out := &in.BaseID, &out.BaseID
+		*out = new(HostBaseID)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(HostProcessResources)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyGatewayHostInfrastructureProvider.
@@ -1706,6 +1806,11 @@ func (in *EnvoyGatewayInfrastructureProvider) DeepCopyInto(out *EnvoyGatewayInfr
 	if in.Host != nil {
 		in, out := &in.Host, &out.Host
 		*out = new(EnvoyGatewayHostInfrastructureProvider)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Docker != nil {
+		in, out := &in.Docker, &out.Docker
+		*out = new(EnvoyGatewayDockerInfrastructureProvider)
 		**out = **in
 	}
 }
@@ -1917,6 +2022,16 @@ func (in *EnvoyGatewayResourceProvider) DeepCopyInto(out *EnvoyGatewayResourcePr
 		*out = new(EnvoyGatewayFileResourceProvider)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(EnvoyGatewayGitResourceProvider)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(EnvoyGatewayHTTPResourceProvider)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyGatewayResourceProvider.
@@ -1972,6 +2087,11 @@ func (in *EnvoyGatewaySpec) DeepCopyInto(out *EnvoyGatewaySpec) {
 		*out = new(ExtensionAPISettings)
 		**out = **in
 	}
+	if in.XdsCanary != nil {
+		in, out := &in.XdsCanary, &out.XdsCanary
+		*out = new(XdsCanaryRollout)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyGatewaySpec.
@@ -3480,6 +3600,56 @@ func (in *HealthCheckSettings) DeepCopy() *HealthCheckSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostBaseID) DeepCopyInto(out *HostBaseID) {
+	*out = *in
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostBaseID.
+func (in *HostBaseID) DeepCopy() *HostBaseID {
+	if in == nil {
+		return nil
+	}
+	out := new(HostBaseID)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostProcessResources) DeepCopyInto(out *HostProcessResources) {
+	*out = *in
+	if in.MaxOpenFiles != nil {
+		in, out := &in.MaxOpenFiles, &out.MaxOpenFiles
+		*out = new(uint64)
+		**out = **in
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostProcessResources.
+func (in *HostProcessResources) DeepCopy() *HostProcessResources {
+	if in == nil {
+		return nil
+	}
+	out := new(HostProcessResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPEndpoint) DeepCopyInto(out *IPEndpoint) {
 	*out = *in
@@ -6398,6 +6568,46 @@ func (in *XForwardedForSettings) DeepCopy() *XForwardedForSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XdsCanaryRollout) DeepCopyInto(out *XdsCanaryRollout) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Percentage != nil {
+		in, out := &in.Percentage, &out.Percentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SoakDuration != nil {
+		in, out := &in.SoakDuration, &out.SoakDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Promotion != nil {
+		in, out := &in.Promotion, &out.Promotion
+		*out = new(XdsCanaryAction)
+		**out = **in
+	}
+	if in.Abort != nil {
+		in, out := &in.Abort, &out.Abort
+		*out = new(XdsCanaryAction)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XdsCanaryRollout.
+func (in *XdsCanaryRollout) DeepCopy() *XdsCanaryRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(XdsCanaryRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZipkinTracingProvider) DeepCopyInto(out *ZipkinTracingProvider) {
 	*out = *in
@@ -6,6 +6,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -14,6 +15,13 @@ import (
 
 func main() {
 	if err := egctl.GetRootCommand().Execute(); err != nil {
+		var exitErr *egctl.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.Err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, exitErr.Err)
+			}
+			os.Exit(exitErr.Code)
+		}
 		_, _ = fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -0,0 +1,337 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package configdump serves the live state of the control plane on the admin
+// server: the provider resources, the Gateway API translation results, the xDS
+// IR and the xDS resources.
+package configdump
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	envoytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wukongcloud/gateway/internal/admin"
+	"github.com/wukongcloud/gateway/internal/envoygateway"
+	"github.com/wukongcloud/gateway/internal/gatewayapi"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/ir"
+	"github.com/wukongcloud/gateway/internal/message"
+)
+
+const (
+	// ProviderResourcesPath is the admin server path dumping the provider resources of every GatewayClass.
+	ProviderResourcesPath = "/api/config_dump/provider_resources"
+	// TranslationPath is the admin server path dumping the Gateway API translation result of every GatewayClass.
+	TranslationPath = "/api/config_dump/translation"
+	// XdsIRPath is the admin server path dumping the xDS IR of every IR key.
+	XdsIRPath = "/api/config_dump/xds_ir"
+	// XdsPath is the admin server path dumping the xDS resources of every IR key.
+	XdsPath = "/api/config_dump/xds"
+)
+
+// redacted replaces the content of the secrets in the dumps.
+const redacted = "[redacted]"
+
+// Config holds the messages the config dump is read from.
+type Config struct {
+	ProviderResources  *message.ProviderResources
+	TranslationResults *message.TranslationResults
+	XdsIR              *message.XdsIR
+	Xds                *message.Xds
+}
+
+// Register registers the config dump endpoints on the admin server.
+//
+// All the endpoints accept the following query parameters to filter the dump:
+//   - gatewayClass: the name of a GatewayClass.
+//   - gateway: the "<namespace>/<name>" of a Gateway.
+//   - kind, namespace and name: the kind, namespace and name of the resources for
+//     the provider resources and translation dumps, the kind (HTTP, TCP or UDP) and
+//     name of the listeners for the xDS IR dump, and the type (e.g. Cluster) and name
+//     of the resources for the xDS dump.
+func Register(cfg *Config) {
+	admin.Handle(ProviderResourcesPath, get(cfg.providerResources))
+	admin.Handle(TranslationPath, get(cfg.translation))
+	admin.Handle(XdsIRPath, get(cfg.xdsIR))
+	admin.Handle(XdsPath, get(cfg.xds))
+}
+
+// get serves the dump returned by the function as JSON to the GET requests.
+func get(dump func(f *filter) (any, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		v, err := dump(&filter{
+			gatewayClass: q.Get("gatewayClass"),
+			gateway:      q.Get("gateway"),
+			kind:         q.Get("kind"),
+			namespace:    q.Get("namespace"),
+			name:         q.Get("name"),
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (c *Config) providerResources(f *filter) (any, error) {
+	f.gatewayClasses = c.gatewayClasses()
+	dump := make(map[string]*resource.Resources)
+	for _, resources := range c.ProviderResources.GetResources() {
+		if resources == nil || resources.GatewayClass == nil || !f.matchesGatewayClass(resources.GatewayClass.Name) {
+			continue
+		}
+		dump[resources.GatewayClass.Name] = f.resources(resources)
+	}
+	return dump, nil
+}
+
+func (c *Config) translation(f *filter) (any, error) {
+	f.gatewayClasses = c.gatewayClasses()
+	dump := make(map[string]*gatewayapi.TranslateResult)
+	for gatewayClass, result := range c.TranslationResults.LoadAll() {
+		if !f.matchesGatewayClass(gatewayClass) {
+			continue
+		}
+		filtered := &gatewayapi.TranslateResult{
+			Resources: *f.resources(&result.Resources),
+			XdsIR:     make(resource.XdsIRMap),
+			InfraIR:   make(resource.InfraIRMap),
+		}
+		for key, xdsIR := range result.XdsIR {
+			if f.matchesIRKey(key) {
+				filtered.XdsIR[key] = xdsIR
+			}
+		}
+		for key, infraIR := range result.InfraIR {
+			if f.matchesIRKey(key) {
+				filtered.InfraIR[key] = infraIR
+			}
+		}
+		dump[gatewayClass] = filtered
+	}
+	return dump, nil
+}
+
+func (c *Config) xdsIR(f *filter) (any, error) {
+	f.gatewayClasses = c.gatewayClasses()
+	dump := make(map[string]*ir.Xds)
+	for key, xdsIR := range c.XdsIR.LoadAll() {
+		if !f.matchesIRKey(key) {
+			continue
+		}
+		if f.kind == "" && f.name == "" {
+			dump[key] = xdsIR
+			continue
+		}
+
+		filtered := xdsIR.DeepCopy()
+		filtered.HTTP = filterListeners(filtered.HTTP, f, "HTTP", func(l *ir.HTTPListener) string { return l.Name })
+		filtered.TCP = filterListeners(filtered.TCP, f, "TCP", func(l *ir.TCPListener) string { return l.Name })
+		filtered.UDP = filterListeners(filtered.UDP, f, "UDP", func(l *ir.UDPListener) string { return l.Name })
+		dump[key] = filtered
+	}
+	return dump, nil
+}
+
+func (c *Config) xds(f *filter) (any, error) {
+	f.gatewayClasses = c.gatewayClasses()
+	dump := make(map[string]map[string][]json.RawMessage)
+	for key, table := range c.Xds.LoadAll() {
+		if !f.matchesIRKey(key) {
+			continue
+		}
+		resources := make(map[string][]json.RawMessage)
+		for typeURL, items := range table.XdsResources {
+			if f.kind != "" && !strings.EqualFold(f.kind, typeURL[strings.LastIndex(typeURL, ".")+1:]) {
+				continue
+			}
+			for _, item := range items {
+				if f.name != "" && cachev3.GetResourceName(item) != f.name {
+					continue
+				}
+				raw, err := protojson.Marshal(redactXdsSecret(item))
+				if err != nil {
+					return nil, err
+				}
+				resources[typeURL] = append(resources[typeURL], raw)
+			}
+		}
+		dump[key] = resources
+	}
+	return dump, nil
+}
+
+// gatewayClasses returns the name of the GatewayClass of every Gateway, keyed by "<namespace>/<name>".
+func (c *Config) gatewayClasses() map[string]string {
+	gatewayClasses := make(map[string]string)
+	for _, resources := range c.ProviderResources.GetResources() {
+		if resources == nil || resources.GatewayClass == nil {
+			continue
+		}
+		for _, gateway := range resources.Gateways {
+			gatewayClasses[gateway.Namespace+"/"+gateway.Name] = resources.GatewayClass.Name
+		}
+	}
+	return gatewayClasses
+}
+
+// filter selects the parts of the dumps matching the query parameters.
+type filter struct {
+	gatewayClass string
+	gateway      string
+	kind         string
+	namespace    string
+	name         string
+	// gatewayClasses holds the name of the GatewayClass of every Gateway.
+	gatewayClasses map[string]string
+}
+
+func (f *filter) matchesGatewayClass(gatewayClass string) bool {
+	if f.gatewayClass != "" && gatewayClass != f.gatewayClass {
+		return false
+	}
+	return f.gateway == "" || f.gatewayClasses[f.gateway] == gatewayClass
+}
+
+// matchesIRKey returns whether the IR key, either the "<namespace>/<name>" of a Gateway or
+// the name of a GatewayClass whose Gateways are merged, matches the filter.
+func (f *filter) matchesIRKey(key string) bool {
+	if f.gatewayClass != "" && key != f.gatewayClass && f.gatewayClasses[key] != f.gatewayClass {
+		return false
+	}
+	return f.gateway == "" || key == f.gateway || key == f.gatewayClasses[f.gateway]
+}
+
+func (f *filter) matchesObject(obj client.Object) bool {
+	if f.namespace != "" && obj.GetNamespace() != f.namespace {
+		return false
+	}
+	if f.name != "" && obj.GetName() != f.name {
+		return false
+	}
+	if f.kind == "" {
+		return true
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if gvks, _, err := envoygateway.GetScheme().ObjectKinds(obj); err == nil && len(gvks) > 0 {
+		kind = gvks[0].Kind
+	}
+	return strings.EqualFold(kind, f.kind)
+}
+
+// resources returns a copy of the resources holding the objects matching the filter,
+// with the data of the Secrets redacted.
+func (f *filter) resources(resources *resource.Resources) *resource.Resources {
+	filtered := &resource.Resources{}
+	src, dst := reflect.ValueOf(resources).Elem(), reflect.ValueOf(filtered).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		if !src.Type().Field(i).IsExported() {
+			continue
+		}
+		value := src.Field(i)
+		switch value.Kind() {
+		case reflect.Pointer:
+			if obj, ok := value.Interface().(client.Object); ok && !value.IsNil() && f.matchesObject(obj) {
+				dst.Field(i).Set(value)
+			}
+		case reflect.Slice:
+			kept := reflect.MakeSlice(value.Type(), 0, value.Len())
+			for j := 0; j < value.Len(); j++ {
+				item := value.Index(j)
+				obj, ok := item.Interface().(client.Object)
+				if !ok && item.CanAddr() {
+					// The unstructured objects are held by value.
+					obj, ok = item.Addr().Interface().(client.Object)
+				}
+				if ok && f.matchesObject(obj) {
+					kept = reflect.Append(kept, item)
+				}
+			}
+			if kept.Len() > 0 {
+				dst.Field(i).Set(kept)
+			}
+		}
+	}
+
+	for i, secret := range filtered.Secrets {
+		filtered.Secrets[i] = redactSecret(secret)
+	}
+	return filtered
+}
+
+// filterListeners returns the listeners of the kind matching the filter.
+func filterListeners[T any](listeners []T, f *filter, kind string, name func(T) string) []T {
+	if f.kind != "" && !strings.EqualFold(f.kind, kind) {
+		return nil
+	}
+	var filtered []T
+	for _, listener := range listeners {
+		if f.name == "" || name(listener) == f.name {
+			filtered = append(filtered, listener)
+		}
+	}
+	return filtered
+}
+
+// redactSecret returns a copy of the Secret with its data redacted.
+func redactSecret(secret *corev1.Secret) *corev1.Secret {
+	secret = secret.DeepCopy()
+	for key := range secret.Data {
+		secret.Data[key] = []byte(redacted)
+	}
+	for key := range secret.StringData {
+		secret.StringData[key] = redacted
+	}
+	return secret
+}
+
+// redactXdsSecret returns a copy of the xDS secret with its private parts redacted,
+// or the resource as is if it isn't a secret.
+func redactXdsSecret(item envoytypes.Resource) envoytypes.Resource {
+	secret, ok := item.(*tlsv3.Secret)
+	if !ok {
+		return item
+	}
+
+	secret = proto.Clone(secret).(*tlsv3.Secret)
+	redactedSource := &corev3.DataSource{Specifier: &corev3.DataSource_InlineString{InlineString: redacted}}
+	switch t := secret.Type.(type) {
+	case *tlsv3.Secret_TlsCertificate:
+		if t.TlsCertificate.PrivateKey != nil {
+			t.TlsCertificate.PrivateKey = redactedSource
+		}
+	case *tlsv3.Secret_GenericSecret:
+		if t.GenericSecret.Secret != nil {
+			t.GenericSecret.Secret = redactedSource
+		}
+	case *tlsv3.Secret_SessionTicketKeys:
+		for i := range t.SessionTicketKeys.Keys {
+			t.SessionTicketKeys.Keys[i] = redactedSource
+		}
+	}
+	return secret
+}
@@ -0,0 +1,150 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package configdump
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/wukongcloud/gateway/internal/gatewayapi"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/ir"
+	"github.com/wukongcloud/gateway/internal/message"
+	xdstypes "github.com/wukongcloud/gateway/internal/xds/types"
+)
+
+func newTestConfig() *Config {
+	resources := resource.NewResources()
+	resources.GatewayClass = &gwapiv1.GatewayClass{ObjectMeta: metav1.ObjectMeta{Name: "eg"}}
+	resources.Gateways = []*gwapiv1.Gateway{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "eg"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"}},
+	}
+	resources.HTTPRoutes = []*gwapiv1.HTTPRoute{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backend"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "backend"}},
+	}
+	resources.Secrets = []*corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "tls"}, Data: map[string][]byte{"tls.key": []byte("private")}},
+	}
+	pResources := new(message.ProviderResources)
+	pResources.GatewayAPIResources.Store("gateway.envoyproxy.io/gatewayclass-controller", &resource.ControllerResources{resources})
+
+	results := new(message.TranslationResults)
+	results.Store(map[string]*gatewayapi.TranslateResult{
+		"eg": {
+			Resources: *resources,
+			XdsIR:     resource.XdsIRMap{"default/eg": &ir.Xds{}, "default/other": &ir.Xds{}},
+		},
+	})
+
+	xdsIR := new(message.XdsIR)
+	xdsIR.Store("default/eg", &ir.Xds{
+		HTTP: []*ir.HTTPListener{{CoreListenerDetails: ir.CoreListenerDetails{Name: "default/eg/http"}}},
+		TCP:  []*ir.TCPListener{{CoreListenerDetails: ir.CoreListenerDetails{Name: "default/eg/tcp"}}},
+	})
+	xdsIR.Store("default/other", &ir.Xds{})
+
+	xds := new(message.Xds)
+	xds.Store("default/eg", &xdstypes.ResourceVersionTable{XdsResources: xdstypes.XdsResources{
+		resourcev3.ClusterType: {&clusterv3.Cluster{Name: "a"}, &clusterv3.Cluster{Name: "b"}},
+		resourcev3.SecretType: {&tlsv3.Secret{
+			Name: "default/tls",
+			Type: &tlsv3.Secret_TlsCertificate{TlsCertificate: &tlsv3.TlsCertificate{
+				PrivateKey: &corev3.DataSource{Specifier: &corev3.DataSource_InlineBytes{InlineBytes: []byte("private")}},
+			}},
+		}},
+	}})
+
+	return &Config{
+		ProviderResources:  pResources,
+		TranslationResults: results,
+		XdsIR:              xdsIR,
+		Xds:                xds,
+	}
+}
+
+func dump[T any](t *testing.T, handler func(*filter) (any, error), query string) T {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	get(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?"+query, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var out T
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	return out
+}
+
+func TestProviderResources(t *testing.T) {
+	cfg := newTestConfig()
+
+	all := dump[map[string]*resource.Resources](t, cfg.providerResources, "")
+	require.Len(t, all, 1)
+	require.Len(t, all["eg"].HTTPRoutes, 2)
+	require.Equal(t, []byte(redacted), all["eg"].Secrets[0].Data["tls.key"])
+
+	routes := dump[map[string]*resource.Resources](t, cfg.providerResources, "kind=httproute&namespace=default")
+	require.Len(t, routes["eg"].HTTPRoutes, 1)
+	require.Equal(t, "backend", routes["eg"].HTTPRoutes[0].Name)
+	require.Empty(t, routes["eg"].Gateways)
+	require.Nil(t, routes["eg"].GatewayClass)
+
+	require.Empty(t, dump[map[string]*resource.Resources](t, cfg.providerResources, "gatewayClass=other"))
+	require.Empty(t, dump[map[string]*resource.Resources](t, cfg.providerResources, "gateway=default/unknown"))
+
+	// The provider resources aren't modified by the redaction.
+	require.Equal(t, []byte("private"), cfg.ProviderResources.GetResources()[0].Secrets[0].Data["tls.key"])
+}
+
+func TestTranslation(t *testing.T) {
+	cfg := newTestConfig()
+
+	result := dump[map[string]*gatewayapi.TranslateResult](t, cfg.translation, "gateway=default/eg&kind=Gateway&name=eg")
+	require.Len(t, result["eg"].Gateways, 1)
+	require.Empty(t, result["eg"].HTTPRoutes)
+	require.Len(t, result["eg"].XdsIR, 1)
+	require.Contains(t, result["eg"].XdsIR, "default/eg")
+}
+
+func TestXdsIR(t *testing.T) {
+	cfg := newTestConfig()
+
+	require.Len(t, dump[map[string]*ir.Xds](t, cfg.xdsIR, "gatewayClass=eg"), 2)
+
+	xdsIR := dump[map[string]*ir.Xds](t, cfg.xdsIR, "gateway=default/eg&kind=http")
+	require.Len(t, xdsIR, 1)
+	require.Len(t, xdsIR["default/eg"].HTTP, 1)
+	require.Empty(t, xdsIR["default/eg"].TCP)
+}
+
+func TestXds(t *testing.T) {
+	cfg := newTestConfig()
+
+	xds := dump[map[string]map[string][]map[string]any](t, cfg.xds, "kind=cluster&name=b")
+	require.Len(t, xds["default/eg"][resourcev3.ClusterType], 1)
+	require.Equal(t, "b", xds["default/eg"][resourcev3.ClusterType][0]["name"])
+	require.NotContains(t, xds["default/eg"], resourcev3.SecretType)
+
+	xds = dump[map[string]map[string][]map[string]any](t, cfg.xds, "kind=Secret")
+	secret, err := json.Marshal(xds["default/eg"][resourcev3.SecretType][0])
+	require.NoError(t, err)
+	require.Contains(t, string(secret), redacted)
+	require.NotContains(t, string(secret), "cHJpdmF0ZQ==")
+
+	rec := httptest.NewRecorder()
+	get(cfg.xds).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
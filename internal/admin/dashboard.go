@@ -0,0 +1,25 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package admin
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// dashboardPath is the path prefix of the web dashboard.
+const dashboardPath = "/dashboard/"
+
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// dashboardHandler serves the web dashboard, a static page rendering the topology
+// graph served by the topology endpoint.
+func dashboardHandler() http.Handler {
+	files, _ := fs.Sub(dashboardFiles, "dashboard")
+	return http.StripPrefix(dashboardPath, http.FileServer(http.FS(files)))
+}
@@ -0,0 +1,87 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package loglevel serves the logging levels of the Envoy Gateway components on
+// the admin server, and changes them at runtime.
+package loglevel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/admin"
+	"github.com/wukongcloud/gateway/internal/logging"
+)
+
+// Path is the admin server path listing the logging levels of the components, with GET.
+// With POST, it sets the level of the component given by the "component" query parameter
+// to the "level" query parameter, until reverted after the optional "timeout" query
+// parameter, e.g. "10m". With DELETE, it reverts the component given by the "component"
+// query parameter, or all the components if unspecified, to their configured levels.
+const Path = "/api/logging"
+
+// Register registers the logging level endpoint of the logger on the admin server.
+func Register(logger logging.Logger) {
+	admin.Handle(Path, Handler(logger))
+}
+
+// Handler returns the handler of the logging level endpoint of the logger.
+func Handler(logger logging.Logger) http.Handler {
+	return &handler{logger: logger}
+}
+
+type handler struct {
+	logger logging.Logger
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	component := egv1a1.EnvoyGatewayLogComponent(r.URL.Query().Get("component"))
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		level := egv1a1.LogLevel(r.URL.Query().Get("level"))
+		if level == "" {
+			http.Error(w, "missing level query parameter", http.StatusBadRequest)
+			return
+		}
+		var timeout time.Duration
+		if t := r.URL.Query().Get("timeout"); t != "" {
+			var err error
+			if timeout, err = time.ParseDuration(t); err != nil {
+				http.Error(w, fmt.Sprintf("invalid timeout query parameter: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+		if err := h.logger.SetLevel(component, level, timeout); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Info("changed logging level", "component", component, "level", level, "timeout", timeout)
+	case http.MethodDelete:
+		components := logging.Components
+		if component != "" {
+			components = []egv1a1.EnvoyGatewayLogComponent{component}
+		}
+		for _, component := range components {
+			if err := h.logger.SetLevel(component, "", 0); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		h.logger.Info("reverted logging levels", "components", components)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.logger.Levels()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -8,6 +8,7 @@ package admin
 import (
 	"net/http"
 	"net/http/pprof"
+	"sync"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
@@ -15,6 +16,38 @@ import (
 	"github.com/wukongcloud/gateway/internal/envoygateway/config"
 )
 
+// apiPrefix is the path prefix of the endpoints registered via Handle.
+const apiPrefix = "/api/"
+
+var (
+	// apiHandlers holds the endpoints registered by the Envoy Gateway components, keyed by path.
+	apiHandlers   = map[string]http.Handler{}
+	apiHandlersMu sync.RWMutex
+)
+
+// Handle registers the handler for the given path, which must start with "/api/",
+// on the admin server. It replaces the existing handler registered for the path, if any,
+// so components can register their endpoints again after being restarted.
+func Handle(path string, handler http.Handler) {
+	apiHandlersMu.Lock()
+	defer apiHandlersMu.Unlock()
+
+	apiHandlers[path] = handler
+}
+
+// serveAPI dispatches the request to the handler registered for its path.
+func serveAPI(w http.ResponseWriter, r *http.Request) {
+	apiHandlersMu.RLock()
+	handler, ok := apiHandlers[r.URL.Path]
+	apiHandlersMu.RUnlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
 func Init(cfg *config.Server) error {
 	if cfg.EnvoyGateway.GetEnvoyGatewayAdmin().EnableDumpConfig {
 		spewConfig := spew.NewDefaultConfig()
@@ -29,9 +62,11 @@ func start(cfg *config.Server) error {
 	handlers := http.NewServeMux()
 	address := cfg.EnvoyGateway.GetEnvoyGatewayAdminAddress()
 	enablePprof := cfg.EnvoyGateway.GetEnvoyGatewayAdmin().EnablePprof
+	enableDashboard := cfg.EnvoyGateway.GetEnvoyGatewayAdmin().EnableDashboard
 
 	adminLogger := cfg.Logger.WithName("admin")
-	adminLogger.Info("starting admin server", "address", address, "enablePprof", enablePprof)
+	adminLogger.Info("starting admin server", "address", address, "enablePprof", enablePprof,
+		"enableDashboard", enableDashboard)
 
 	if enablePprof {
 		// Serve pprof endpoints to aid in live debugging.
@@ -42,6 +77,14 @@ func start(cfg *config.Server) error {
 		handlers.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
 	}
 
+	if enableDashboard {
+		// Serve the web dashboard of the resource topology.
+		handlers.Handle(dashboardPath, dashboardHandler())
+	}
+
+	// Serve the endpoints registered by the Envoy Gateway components.
+	handlers.HandleFunc(apiPrefix, serveAPI)
+
 	adminServer := &http.Server{
 		Handler:           handlers,
 		Addr:              address,
@@ -6,6 +6,8 @@
 package admin
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -27,3 +29,33 @@ func TestInitAdminServer(t *testing.T) {
 	err := Init(svrConfig)
 	require.NoError(t, err)
 }
+
+func TestHandle(t *testing.T) {
+	Handle("/api/test", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("foo"))
+	}))
+
+	rec := httptest.NewRecorder()
+	serveAPI(rec, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "foo", rec.Body.String())
+
+	// Registering the same path again replaces the handler.
+	Handle("/api/test", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("bar"))
+	}))
+	rec = httptest.NewRecorder()
+	serveAPI(rec, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+	require.Equal(t, "bar", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	serveAPI(rec, httptest.NewRequest(http.MethodGet, "/api/not-found", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestDashboardHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	dashboardHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, dashboardPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "../api/topology")
+}
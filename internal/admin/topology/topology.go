@@ -0,0 +1,511 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package topology builds the attachment graph of the Gateway API resources,
+// from the GatewayClasses down to the backends along with the policies attached
+// on the way, and serves it on the admin server.
+package topology
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/dominikbraun/graph"
+	"github.com/dominikbraun/graph/draw"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/admin"
+	"github.com/wukongcloud/gateway/internal/gatewayapi"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/message"
+)
+
+// Path is the admin server path serving the topology graph.
+const Path = "/api/topology"
+
+// KindListener is the kind of the nodes of the Gateway listeners.
+const KindListener = "Listener"
+
+// Health summarizes the conditions of a node.
+type Health string
+
+const (
+	// HealthOK is the health of a node whose conditions are all true.
+	HealthOK Health = "OK"
+	// HealthError is the health of a node with an Accepted, Programmed or ResolvedRefs condition set to false.
+	HealthError Health = "Error"
+	// HealthUnknown is the health of a node without conditions, such as a Service.
+	HealthUnknown Health = "Unknown"
+)
+
+// EdgeKind is the relationship between two nodes.
+type EdgeKind string
+
+const (
+	// EdgeParent links a parent to a child, e.g. a Gateway to its listeners or a listener to its routes.
+	EdgeParent EdgeKind = "Parent"
+	// EdgeBackend links a route to its backends.
+	EdgeBackend EdgeKind = "Backend"
+	// EdgePolicy links a policy to its targets.
+	EdgePolicy EdgeKind = "Policy"
+)
+
+// Node is a resource of the topology graph.
+type Node struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the resource, or "<gateway>/<listener>" for the listeners.
+	Name       string             `json:"name"`
+	Health     Health             `json:"health"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Edge is a relationship of the topology graph.
+type Edge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Kind EdgeKind `json:"kind"`
+}
+
+// Topology is the attachment graph of the Gateway API resources.
+type Topology struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []Edge  `json:"edges"`
+}
+
+// Config holds the messages the topology is built from.
+type Config struct {
+	ProviderResources  *message.ProviderResources
+	TranslationResults *message.TranslationResults
+}
+
+// Register registers the topology endpoint on the admin server.
+//
+// The endpoint accepts the gatewayClass query parameter to only serve the topology
+// of a GatewayClass, and the format query parameter to serve it as JSON (the default)
+// or as a Graphviz DOT graph with "dot".
+func Register(cfg *Config) {
+	admin.Handle(Path, http.HandlerFunc(cfg.serve))
+}
+
+func (c *Config) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	g, err := c.build(r.URL.Query().Get("gatewayClass"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		if err := draw.DOT(g, w, draw.GraphAttribute("rankdir", "LR")); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	case "", "json":
+		topology, err := newTopology(g)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(topology); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	default:
+		http.Error(w, "unsupported format "+format, http.StatusBadRequest)
+	}
+}
+
+// build builds the topology graph of the GatewayClass, or of all the GatewayClasses
+// if empty, from the latest translation results.
+func (c *Config) build(gatewayClass string) (graph.Graph[string, *Node], error) {
+	b := &builder{
+		graph:   graph.New(func(n *Node) string { return n.ID }, graph.Directed()),
+		objects: make(map[string][]client.Object),
+	}
+
+	for _, resources := range c.ProviderResources.GetResources() {
+		if resources == nil || resources.GatewayClass == nil {
+			continue
+		}
+		if gatewayClass != "" && resources.GatewayClass.Name != gatewayClass {
+			continue
+		}
+		class := resources.GatewayClass
+		if err := b.addNode(resource.KindGatewayClass, class, class.Status.Conditions); err != nil {
+			return nil, err
+		}
+	}
+
+	results := c.TranslationResults.LoadAll()
+	classes := make([]string, 0, len(results))
+	for class := range results {
+		if gatewayClass == "" || class == gatewayClass {
+			classes = append(classes, class)
+		}
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		if err := b.addResult(class, results[class]); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.graph, nil
+}
+
+// newTopology returns the nodes and edges of the graph, sorted by ID.
+func newTopology(g graph.Graph[string, *Node]) (*Topology, error) {
+	adjacency, err := g.AdjacencyMap()
+	if err != nil {
+		return nil, err
+	}
+
+	topology := &Topology{Nodes: []*Node{}, Edges: []Edge{}}
+	for id, targets := range adjacency {
+		node, err := g.Vertex(id)
+		if err != nil {
+			return nil, err
+		}
+		topology.Nodes = append(topology.Nodes, node)
+		for to, edge := range targets {
+			topology.Edges = append(topology.Edges, Edge{
+				From: id,
+				To:   to,
+				Kind: EdgeKind(edge.Properties.Attributes["kind"]),
+			})
+		}
+	}
+	sort.Slice(topology.Nodes, func(i, j int) bool {
+		return topology.Nodes[i].ID < topology.Nodes[j].ID
+	})
+	sort.Slice(topology.Edges, func(i, j int) bool {
+		if topology.Edges[i].From != topology.Edges[j].From {
+			return topology.Edges[i].From < topology.Edges[j].From
+		}
+		return topology.Edges[i].To < topology.Edges[j].To
+	})
+	return topology, nil
+}
+
+// builder adds the resources of the translation results to the graph.
+type builder struct {
+	graph graph.Graph[string, *Node]
+	// objects holds the added objects by kind, to resolve the target selectors of the policies.
+	objects map[string][]client.Object
+}
+
+// nodeID returns the ID of the node of a resource.
+func nodeID(kind, namespace, name string) string {
+	if namespace == "" {
+		return kind + "/" + name
+	}
+	return kind + "/" + namespace + "/" + name
+}
+
+// healthOf returns the health summarizing the conditions.
+func healthOf(conditions []metav1.Condition) Health {
+	health := HealthUnknown
+	for _, condition := range conditions {
+		switch condition.Type {
+		case string(gwapiv1.RouteConditionAccepted), string(gwapiv1.GatewayConditionProgrammed),
+			string(gwapiv1.RouteConditionResolvedRefs):
+		default:
+			continue
+		}
+		switch condition.Status {
+		case metav1.ConditionFalse:
+			return HealthError
+		case metav1.ConditionTrue:
+			health = HealthOK
+		}
+	}
+	return health
+}
+
+// addNode adds the node of the object to the graph, with the graphviz attributes
+// rendering its health.
+func (b *builder) addNode(kind string, obj client.Object, conditions []metav1.Condition) error {
+	b.objects[kind] = append(b.objects[kind], obj)
+	return b.addVertex(&Node{
+		ID:         nodeID(kind, obj.GetNamespace(), obj.GetName()),
+		Kind:       kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Health:     healthOf(conditions),
+		Conditions: conditions,
+	})
+}
+
+func (b *builder) addVertex(node *Node) error {
+	color := map[Health]string{HealthOK: "palegreen", HealthError: "lightcoral", HealthUnknown: "lightgrey"}[node.Health]
+	err := b.graph.AddVertex(node, graph.VertexAttributes(map[string]string{
+		"label":     node.Kind + "\\n" + strings.TrimPrefix(node.ID, node.Kind+"/"),
+		"shape":     "box",
+		"style":     "filled",
+		"fillcolor": color,
+	}))
+	if errors.Is(err, graph.ErrVertexAlreadyExists) {
+		return nil
+	}
+	return err
+}
+
+// addEdge adds the edge between the nodes, if both of them are in the graph.
+func (b *builder) addEdge(from, to string, kind EdgeKind) error {
+	err := b.graph.AddEdge(from, to, graph.EdgeAttribute("kind", string(kind)))
+	if errors.Is(err, graph.ErrVertexNotFound) || errors.Is(err, graph.ErrEdgeAlreadyExists) {
+		return nil
+	}
+	return err
+}
+
+// route holds the fields of the route kinds the graph is built from.
+type route struct {
+	kind        string
+	obj         client.Object
+	parentRefs  []gwapiv1.ParentReference
+	parents     []gwapiv1.RouteParentStatus
+	backendRefs []gwapiv1.BackendRef
+}
+
+func routes(result *gatewayapi.TranslateResult) []route {
+	var routes []route
+	for _, r := range result.HTTPRoutes {
+		var backendRefs []gwapiv1.BackendRef
+		for _, rule := range r.Spec.Rules {
+			for _, ref := range rule.BackendRefs {
+				backendRefs = append(backendRefs, ref.BackendRef)
+			}
+		}
+		routes = append(routes, route{resource.KindHTTPRoute, r, r.Spec.ParentRefs, r.Status.Parents, backendRefs})
+	}
+	for _, r := range result.GRPCRoutes {
+		var backendRefs []gwapiv1.BackendRef
+		for _, rule := range r.Spec.Rules {
+			for _, ref := range rule.BackendRefs {
+				backendRefs = append(backendRefs, ref.BackendRef)
+			}
+		}
+		routes = append(routes, route{resource.KindGRPCRoute, r, r.Spec.ParentRefs, r.Status.Parents, backendRefs})
+	}
+	for _, r := range result.TLSRoutes {
+		var backendRefs []gwapiv1.BackendRef
+		for _, rule := range r.Spec.Rules {
+			backendRefs = append(backendRefs, rule.BackendRefs...)
+		}
+		routes = append(routes, route{resource.KindTLSRoute, r, r.Spec.ParentRefs, r.Status.Parents, backendRefs})
+	}
+	for _, r := range result.TCPRoutes {
+		var backendRefs []gwapiv1.BackendRef
+		for _, rule := range r.Spec.Rules {
+			backendRefs = append(backendRefs, rule.BackendRefs...)
+		}
+		routes = append(routes, route{resource.KindTCPRoute, r, r.Spec.ParentRefs, r.Status.Parents, backendRefs})
+	}
+	for _, r := range result.UDPRoutes {
+		var backendRefs []gwapiv1.BackendRef
+		for _, rule := range r.Spec.Rules {
+			backendRefs = append(backendRefs, rule.BackendRefs...)
+		}
+		routes = append(routes, route{resource.KindUDPRoute, r, r.Spec.ParentRefs, r.Status.Parents, backendRefs})
+	}
+	return routes
+}
+
+// policy holds the fields of the policy kinds the graph is built from.
+type policy struct {
+	kind    string
+	obj     client.Object
+	targets egv1a1.PolicyTargetReferences
+	status  gwapiv1a2.PolicyStatus
+}
+
+func policies(result *gatewayapi.TranslateResult) []policy {
+	var policies []policy
+	for _, p := range result.ClientTrafficPolicies {
+		policies = append(policies, policy{resource.KindClientTrafficPolicy, p, p.Spec.PolicyTargetReferences, p.Status})
+	}
+	for _, p := range result.BackendTrafficPolicies {
+		policies = append(policies, policy{resource.KindBackendTrafficPolicy, p, p.Spec.PolicyTargetReferences, p.Status})
+	}
+	for _, p := range result.SecurityPolicies {
+		policies = append(policies, policy{resource.KindSecurityPolicy, p, p.Spec.PolicyTargetReferences, p.Status})
+	}
+	for _, p := range result.EnvoyExtensionPolicies {
+		policies = append(policies, policy{resource.KindEnvoyExtensionPolicy, p, p.Spec.PolicyTargetReferences, p.Status})
+	}
+	return policies
+}
+
+// addResult adds the resources of the translation result of the GatewayClass to the graph.
+func (b *builder) addResult(gatewayClass string, result *gatewayapi.TranslateResult) error {
+	classID := nodeID(resource.KindGatewayClass, "", gatewayClass)
+	// listeners holds the IDs of the listener nodes of every Gateway node.
+	listeners := make(map[string][]string)
+
+	for _, gateway := range result.Gateways {
+		if err := b.addNode(resource.KindGateway, gateway, gateway.Status.Conditions); err != nil {
+			return err
+		}
+		gatewayID := nodeID(resource.KindGateway, gateway.Namespace, gateway.Name)
+		if err := b.addEdge(classID, gatewayID, EdgeParent); err != nil {
+			return err
+		}
+
+		for _, listener := range gateway.Spec.Listeners {
+			var conditions []metav1.Condition
+			for _, status := range gateway.Status.Listeners {
+				if status.Name == listener.Name {
+					conditions = status.Conditions
+				}
+			}
+			name := gateway.Name + "/" + string(listener.Name)
+			listenerID := nodeID(KindListener, gateway.Namespace, name)
+			if err := b.addVertex(&Node{
+				ID:         listenerID,
+				Kind:       KindListener,
+				Namespace:  gateway.Namespace,
+				Name:       name,
+				Health:     healthOf(conditions),
+				Conditions: conditions,
+			}); err != nil {
+				return err
+			}
+			if err := b.addEdge(gatewayID, listenerID, EdgeParent); err != nil {
+				return err
+			}
+			listeners[gatewayID] = append(listeners[gatewayID], listenerID)
+		}
+	}
+
+	backendConditions := make(map[string][]metav1.Condition)
+	for _, backend := range result.Backends {
+		backendConditions[nodeID(resource.KindBackend, backend.Namespace, backend.Name)] = backend.Status.Conditions
+	}
+
+	for _, r := range routes(result) {
+		var conditions []metav1.Condition
+		for _, parent := range r.parents {
+			conditions = append(conditions, parent.Conditions...)
+		}
+		if err := b.addNode(r.kind, r.obj, conditions); err != nil {
+			return err
+		}
+		routeID := nodeID(r.kind, r.obj.GetNamespace(), r.obj.GetName())
+
+		for _, ref := range r.parentRefs {
+			if ref.Kind != nil && *ref.Kind != resource.KindGateway {
+				continue
+			}
+			namespace := r.obj.GetNamespace()
+			if ref.Namespace != nil {
+				namespace = string(*ref.Namespace)
+			}
+			gatewayID := nodeID(resource.KindGateway, namespace, string(ref.Name))
+			if ref.SectionName != nil {
+				listenerID := nodeID(KindListener, namespace, string(ref.Name)+"/"+string(*ref.SectionName))
+				if err := b.addEdge(listenerID, routeID, EdgeParent); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, listenerID := range listeners[gatewayID] {
+				if err := b.addEdge(listenerID, routeID, EdgeParent); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, ref := range r.backendRefs {
+			kind := resource.KindService
+			if ref.Kind != nil {
+				kind = string(*ref.Kind)
+			}
+			namespace := r.obj.GetNamespace()
+			if ref.Namespace != nil {
+				namespace = string(*ref.Namespace)
+			}
+			backendID := nodeID(kind, namespace, string(ref.Name))
+			conditions := backendConditions[backendID]
+			if err := b.addVertex(&Node{
+				ID:         backendID,
+				Kind:       kind,
+				Namespace:  namespace,
+				Name:       string(ref.Name),
+				Health:     healthOf(conditions),
+				Conditions: conditions,
+			}); err != nil {
+				return err
+			}
+			if err := b.addEdge(routeID, backendID, EdgeBackend); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, p := range policies(result) {
+		var conditions []metav1.Condition
+		for _, ancestor := range p.status.Ancestors {
+			conditions = append(conditions, ancestor.Conditions...)
+		}
+		if err := b.addNode(p.kind, p.obj, conditions); err != nil {
+			return err
+		}
+		policyID := nodeID(p.kind, p.obj.GetNamespace(), p.obj.GetName())
+		for _, target := range b.targets(p) {
+			if err := b.addEdge(policyID, target, EdgePolicy); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// targets returns the IDs of the nodes targeted by the policy, through its target
+// references and selectors. A Gateway target with a section name is resolved to the
+// node of the listener.
+func (b *builder) targets(p policy) []string {
+	namespace := p.obj.GetNamespace()
+	var targets []string
+	for _, ref := range p.targets.GetTargetRefs() {
+		if ref.Kind == resource.KindGateway && ref.SectionName != nil {
+			targets = append(targets, nodeID(KindListener, namespace, string(ref.Name)+"/"+string(*ref.SectionName)))
+			continue
+		}
+		targets = append(targets, nodeID(string(ref.Kind), namespace, string(ref.Name)))
+	}
+
+	for _, selector := range p.targets.TargetSelectors {
+		s, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+			MatchLabels:      selector.MatchLabels,
+			MatchExpressions: selector.MatchExpressions,
+		})
+		if err != nil {
+			continue
+		}
+		for _, obj := range b.objects[string(selector.Kind)] {
+			if obj.GetNamespace() == namespace && s.Matches(labels.Set(obj.GetLabels())) {
+				targets = append(targets, nodeID(string(selector.Kind), namespace, obj.GetName()))
+			}
+		}
+	}
+	return targets
+}
@@ -0,0 +1,150 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package topology
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/gatewayapi"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/message"
+)
+
+func newTestConfig() *Config {
+	pResources := new(message.ProviderResources)
+	pResources.GatewayAPIResources.Store("gateway.envoyproxy.io/gatewayclass-controller", &resource.ControllerResources{{
+		GatewayClass: &gwapiv1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "eg"},
+			Status: gwapiv1.GatewayClassStatus{Conditions: []metav1.Condition{
+				{Type: string(gwapiv1.GatewayClassConditionStatusAccepted), Status: metav1.ConditionTrue},
+			}},
+		},
+	}})
+
+	results := new(message.TranslationResults)
+	results.Store(map[string]*gatewayapi.TranslateResult{"eg": {Resources: resource.Resources{
+		Gateways: []*gwapiv1.Gateway{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "eg"},
+			Spec: gwapiv1.GatewaySpec{Listeners: []gwapiv1.Listener{
+				{Name: "http"},
+				{Name: "https"},
+			}},
+			Status: gwapiv1.GatewayStatus{
+				Conditions: []metav1.Condition{
+					{Type: string(gwapiv1.GatewayConditionProgrammed), Status: metav1.ConditionTrue},
+				},
+				Listeners: []gwapiv1.ListenerStatus{{
+					Name: "https",
+					Conditions: []metav1.Condition{
+						{Type: string(gwapiv1.ListenerConditionResolvedRefs), Status: metav1.ConditionFalse},
+					},
+				}},
+			},
+		}},
+		HTTPRoutes: []*gwapiv1.HTTPRoute{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "backend", Labels: map[string]string{"app": "backend"}},
+			Spec: gwapiv1.HTTPRouteSpec{
+				CommonRouteSpec: gwapiv1.CommonRouteSpec{ParentRefs: []gwapiv1.ParentReference{
+					{Namespace: ptr.To[gwapiv1.Namespace]("infra"), Name: "eg", SectionName: ptr.To[gwapiv1.SectionName]("http")},
+				}},
+				Rules: []gwapiv1.HTTPRouteRule{{BackendRefs: []gwapiv1.HTTPBackendRef{
+					{BackendRef: gwapiv1.BackendRef{BackendObjectReference: gwapiv1.BackendObjectReference{Name: "backend"}}},
+				}}},
+			},
+		}},
+		TCPRoutes: []*gwapiv1a2.TCPRoute{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "tcp"},
+			Spec: gwapiv1a2.TCPRouteSpec{
+				CommonRouteSpec: gwapiv1.CommonRouteSpec{ParentRefs: []gwapiv1.ParentReference{{Name: "eg"}}},
+			},
+		}},
+		ClientTrafficPolicies: []*egv1a1.ClientTrafficPolicy{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "listener"},
+			Spec: egv1a1.ClientTrafficPolicySpec{PolicyTargetReferences: egv1a1.PolicyTargetReferences{
+				TargetRefs: []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{{
+					LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{Kind: "Gateway", Name: "eg"},
+					SectionName:                ptr.To[gwapiv1.SectionName]("https"),
+				}},
+			}},
+		}},
+		SecurityPolicies: []*egv1a1.SecurityPolicy{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "selector"},
+			Spec: egv1a1.SecurityPolicySpec{PolicyTargetReferences: egv1a1.PolicyTargetReferences{
+				TargetSelectors: []egv1a1.TargetSelector{{Kind: "HTTPRoute", MatchLabels: map[string]string{"app": "backend"}}},
+			}},
+			Status: gwapiv1a2.PolicyStatus{Ancestors: []gwapiv1a2.PolicyAncestorStatus{{
+				Conditions: []metav1.Condition{
+					{Type: string(gwapiv1a2.PolicyConditionAccepted), Status: metav1.ConditionFalse, Reason: "Invalid"},
+				},
+			}}},
+		}},
+	}}})
+
+	return &Config{ProviderResources: pResources, TranslationResults: results}
+}
+
+func TestTopology(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestConfig().serve(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	topology := &Topology{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), topology))
+
+	health := make(map[string]Health)
+	for _, node := range topology.Nodes {
+		health[node.ID] = node.Health
+	}
+	require.Equal(t, map[string]Health{
+		"GatewayClass/eg":                    HealthOK,
+		"Gateway/infra/eg":                   HealthOK,
+		"Listener/infra/eg/http":             HealthUnknown,
+		"Listener/infra/eg/https":            HealthError,
+		"HTTPRoute/apps/backend":             HealthUnknown,
+		"TCPRoute/infra/tcp":                 HealthUnknown,
+		"Service/apps/backend":               HealthUnknown,
+		"ClientTrafficPolicy/infra/listener": HealthUnknown,
+		"SecurityPolicy/apps/selector":       HealthError,
+	}, health)
+
+	require.Equal(t, []Edge{
+		{From: "ClientTrafficPolicy/infra/listener", To: "Listener/infra/eg/https", Kind: EdgePolicy},
+		{From: "Gateway/infra/eg", To: "Listener/infra/eg/http", Kind: EdgeParent},
+		{From: "Gateway/infra/eg", To: "Listener/infra/eg/https", Kind: EdgeParent},
+		{From: "GatewayClass/eg", To: "Gateway/infra/eg", Kind: EdgeParent},
+		{From: "HTTPRoute/apps/backend", To: "Service/apps/backend", Kind: EdgeBackend},
+		{From: "Listener/infra/eg/http", To: "HTTPRoute/apps/backend", Kind: EdgeParent},
+		{From: "Listener/infra/eg/http", To: "TCPRoute/infra/tcp", Kind: EdgeParent},
+		{From: "Listener/infra/eg/https", To: "TCPRoute/infra/tcp", Kind: EdgeParent},
+		{From: "SecurityPolicy/apps/selector", To: "HTTPRoute/apps/backend", Kind: EdgePolicy},
+	}, topology.Edges)
+}
+
+func TestTopologyFormat(t *testing.T) {
+	cfg := newTestConfig()
+
+	rec := httptest.NewRecorder()
+	cfg.serve(rec, httptest.NewRequest(http.MethodGet, Path+"?format=dot&gatewayClass=eg", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"GatewayClass/eg" -> "Gateway/infra/eg"`)
+
+	rec = httptest.NewRecorder()
+	cfg.serve(rec, httptest.NewRequest(http.MethodGet, Path+"?gatewayClass=other", nil))
+	require.JSONEq(t, `{"nodes":[],"edges":[]}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	cfg.serve(rec, httptest.NewRequest(http.MethodGet, Path+"?format=svg", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,540 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/testing/protocmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/pkg/offline"
+)
+
+const (
+	diffAdded   = "Added"
+	diffRemoved = "Removed"
+	diffChanged = "Changed"
+)
+
+// diffResourceTypes are the xDS resource types compared by the diff command, in the
+// order they are reported.
+var diffResourceTypes = []struct {
+	name    string
+	typeURL string
+}{
+	{"Listener", resourcev3.ListenerType},
+	{"Route", resourcev3.RouteType},
+	{"Cluster", resourcev3.ClusterType},
+	{"Secret", resourcev3.SecretType},
+}
+
+type diffOptions struct {
+	namespace           string
+	dnsDomain           string
+	output              string
+	addMissingResources bool
+}
+
+// DiffResult holds the differences between the translations of two sets of resources.
+type DiffResult struct {
+	Xds        []XdsResourceDiff `json:"xds,omitempty"`
+	Conditions []ConditionDiff   `json:"conditions,omitempty"`
+}
+
+// XdsResourceDiff is an xDS resource added, removed or changed by the head resources.
+type XdsResourceDiff struct {
+	IRKey  string `json:"irKey"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Change string `json:"change"`
+	// Diff is a human readable report of the differences of a changed resource.
+	// It is omitted for the secrets, so their content isn't leaked.
+	Diff string `json:"diff,omitempty"`
+}
+
+// ConditionDiff is a status condition added, removed or changed by the head resources.
+type ConditionDiff struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Parent is the parent of a route, the ancestor of a policy or the listener of a
+	// Gateway the condition is reported for, if any.
+	Parent string          `json:"parent,omitempty"`
+	Type   string          `json:"type"`
+	Change string          `json:"change"`
+	Base   *ConditionState `json:"base,omitempty"`
+	Head   *ConditionState `json:"head,omitempty"`
+}
+
+// ConditionState is the state of a status condition.
+type ConditionState struct {
+	Status  metav1.ConditionStatus `json:"status"`
+	Reason  string                 `json:"reason"`
+	Message string                 `json:"message,omitempty"`
+}
+
+func (s *ConditionState) String() string {
+	if s == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("%s (%s)", s.Status, s.Reason)
+}
+
+// Empty returns whether there is no difference.
+func (d *DiffResult) Empty() bool {
+	return len(d.Xds) == 0 && len(d.Conditions) == 0
+}
+
+func newDiffCommand() *cobra.Command {
+	opts := &diffOptions{}
+
+	diffCommand := &cobra.Command{
+		Use:   "diff <base> <head>",
+		Short: "Compare the xDS resources and the statuses translated from two sets of Gateway API resources",
+		Long: `Translate two sets of Gateway API resources, like the ones of a base branch and of a pull request, and
+print the differences of the resulting xDS listeners, routes, clusters and secrets, and of the status conditions
+of the resources. Each set is a file, a directory whose YAML and JSON files are read recursively, or - for stdin.
+
+The exit status is 0 if there is no difference, 1 if there are differences and 2 if an error occurred.`,
+		Example: `  # Compare the resources of two directories.
+  egctl experimental diff ./base ./head
+
+  # Compare the resources of two files, in JSON output.
+  egctl x diff base.yaml head.yaml --output json
+
+  # Compare the resources of the main branch with the ones of the working tree in CI.
+  git show main:gateway.yaml | egctl x diff - gateway.yaml
+	  `,
+		Args: cobra.ExactArgs(2),
+		// The differences found are reported through the exit status only.
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := runDiff(args[0], args[1], opts)
+			if err != nil {
+				return &ExitError{Code: 2, Err: err}
+			}
+			if err := printDiff(cmd.OutOrStdout(), result, opts.output); err != nil {
+				return &ExitError{Code: 2, Err: err}
+			}
+			if !result.Empty() {
+				return &ExitError{Code: 1}
+			}
+			return nil
+		},
+	}
+
+	diffCommand.Flags().StringVarP(&opts.output, "output", "o", "", "One of 'yaml' or 'json'. Prints a human readable report if unset.")
+	diffCommand.Flags().BoolVarP(&opts.addMissingResources, "add-missing-resources", "", false, "Provides dummy resources if missed")
+	diffCommand.Flags().StringVarP(&opts.dnsDomain, "dns-domain", "", "cluster.local", "DNS domain used by k8s services, default is cluster.local")
+	diffCommand.Flags().StringVarP(&opts.namespace, "namespace", "n", "envoy-gateway-system", "Namespace where envoy gateway is installed.")
+
+	return diffCommand
+}
+
+func runDiff(base, head string, opts *diffOptions) (*DiffResult, error) {
+	switch opts.output {
+	case "", yamlOutput, jsonOutput:
+	default:
+		return nil, fmt.Errorf("invalid output %q, valid options: yaml/json", opts.output)
+	}
+	if base == "-" && head == "-" {
+		return nil, fmt.Errorf("only one of base and head can be read from stdin")
+	}
+
+	baseResult, err := translateForDiff(base, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate base %s: %w", base, err)
+	}
+	headResult, err := translateForDiff(head, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate head %s: %w", head, err)
+	}
+
+	return &DiffResult{
+		Xds:        diffXds(baseResult.Xds, headResult.Xds),
+		Conditions: diffConditions(collectConditions(baseResult.Resources), collectConditions(headResult.Resources)),
+	}, nil
+}
+
+// translateForDiff translates the resources read from the file, directory or stdin.
+func translateForDiff(path string, opts *diffOptions) (*offline.Result, error) {
+	files, err := readInputFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read input: %w", err)
+	}
+	resources, err := resource.LoadResourcesFromYAMLBytes(joinInputFiles(files), opts.addMissingResources)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal input: %w", err)
+	}
+	return translateGatewayAPI(resources, opts.namespace, opts.dnsDomain)
+}
+
+// inputFile is a file read from the input of a command.
+type inputFile struct {
+	name    string
+	content []byte
+}
+
+// readInputFiles reads the file or stdin, or the YAML and JSON files of the directory,
+// recursively and in lexical order.
+func readInputFiles(path string) ([]inputFile, error) {
+	if path == "-" {
+		content, err := getInputBytes(path)
+		if err != nil {
+			return nil, err
+		}
+		return []inputFile{{name: path, content: content}}, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return []inputFile{{name: path, content: content}}, nil
+	}
+
+	var files []inputFile
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch filepath.Ext(p) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, inputFile{name: p, content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// joinInputFiles returns the YAML documents of all the files.
+func joinInputFiles(files []inputFile) []byte {
+	docs := make([][]byte, 0, len(files))
+	for _, f := range files {
+		docs = append(docs, f.content)
+	}
+	return bytes.Join(docs, []byte("\n---\n"))
+}
+
+// diffXds returns the xDS resources added, removed and changed between the base and
+// the head, sorted by IR key, type and name.
+func diffXds(base, head map[string]*offline.XdsResources) []XdsResourceDiff {
+	irKeys := make(map[string]struct{})
+	for key := range base {
+		irKeys[key] = struct{}{}
+	}
+	for key := range head {
+		irKeys[key] = struct{}{}
+	}
+	keys := make([]string, 0, len(irKeys))
+	for key := range irKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var diffs []XdsResourceDiff
+	for _, key := range keys {
+		for _, rType := range diffResourceTypes {
+			baseResources := xdsResourcesByName(base[key], rType.typeURL)
+			headResources := xdsResourcesByName(head[key], rType.typeURL)
+
+			names := make([]string, 0, len(baseResources)+len(headResources))
+			for name := range baseResources {
+				names = append(names, name)
+			}
+			for name := range headResources {
+				if _, ok := baseResources[name]; !ok {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				diff := XdsResourceDiff{IRKey: key, Type: rType.name, Name: name}
+				baseResource, inBase := baseResources[name]
+				headResource, inHead := headResources[name]
+				switch {
+				case !inBase:
+					diff.Change = diffAdded
+				case !inHead:
+					diff.Change = diffRemoved
+				default:
+					d := cmp.Diff(baseResource, headResource, protocmp.Transform())
+					if d == "" {
+						continue
+					}
+					diff.Change = diffChanged
+					if rType.typeURL != resourcev3.SecretType {
+						diff.Diff = d
+					}
+				}
+				diffs = append(diffs, diff)
+			}
+		}
+	}
+	return diffs
+}
+
+func xdsResourcesByName(resources *offline.XdsResources, typeURL string) map[string]any {
+	byName := make(map[string]any)
+	if resources == nil {
+		return byName
+	}
+	for _, r := range resources.XdsResources[typeURL] {
+		byName[cachev3.GetResourceName(r)] = r
+	}
+	return byName
+}
+
+// conditionKey identifies a status condition of a resource.
+type conditionKey struct {
+	kind, namespace, name, parent, conditionType string
+}
+
+// collectConditions returns the status conditions of the Gateway API and Envoy Gateway
+// resources which have a status.
+func collectConditions(resources *resource.Resources) map[conditionKey]metav1.Condition {
+	conditions := make(map[conditionKey]metav1.Condition)
+	add := func(kind string, obj metav1.Object, parent string, conds []metav1.Condition) {
+		for _, c := range conds {
+			conditions[conditionKey{kind, obj.GetNamespace(), obj.GetName(), parent, c.Type}] = c
+		}
+	}
+	addParents := func(kind string, obj metav1.Object, parents []gwapiv1.RouteParentStatus) {
+		for _, p := range parents {
+			add(kind, obj, parentRefString(p.ParentRef, obj.GetNamespace()), p.Conditions)
+		}
+	}
+	addAncestors := func(kind string, obj metav1.Object, status gwapiv1a2.PolicyStatus) {
+		for _, a := range status.Ancestors {
+			add(kind, obj, parentRefString(a.AncestorRef, obj.GetNamespace()), a.Conditions)
+		}
+	}
+
+	if resources == nil {
+		return conditions
+	}
+	if resources.GatewayClass != nil {
+		add(resource.KindGatewayClass, resources.GatewayClass, "", resources.GatewayClass.Status.Conditions)
+	}
+	for _, gw := range resources.Gateways {
+		add(resource.KindGateway, gw, "", gw.Status.Conditions)
+		for _, l := range gw.Status.Listeners {
+			add(resource.KindGateway, gw, "Listener "+string(l.Name), l.Conditions)
+		}
+	}
+	for _, r := range resources.HTTPRoutes {
+		addParents(resource.KindHTTPRoute, r, r.Status.Parents)
+	}
+	for _, r := range resources.GRPCRoutes {
+		addParents(resource.KindGRPCRoute, r, r.Status.Parents)
+	}
+	for _, r := range resources.TLSRoutes {
+		addParents(resource.KindTLSRoute, r, r.Status.Parents)
+	}
+	for _, r := range resources.TCPRoutes {
+		addParents(resource.KindTCPRoute, r, r.Status.Parents)
+	}
+	for _, r := range resources.UDPRoutes {
+		addParents(resource.KindUDPRoute, r, r.Status.Parents)
+	}
+	for _, p := range resources.ClientTrafficPolicies {
+		addAncestors(resource.KindClientTrafficPolicy, p, p.Status)
+	}
+	for _, p := range resources.BackendTrafficPolicies {
+		addAncestors(resource.KindBackendTrafficPolicy, p, p.Status)
+	}
+	for _, p := range resources.SecurityPolicies {
+		addAncestors(resource.KindSecurityPolicy, p, p.Status)
+	}
+	for _, p := range resources.EnvoyExtensionPolicies {
+		addAncestors(resource.KindEnvoyExtensionPolicy, p, p.Status)
+	}
+	for _, p := range resources.EnvoyPatchPolicies {
+		addAncestors(resource.KindEnvoyPatchPolicy, p, p.Status)
+	}
+	for _, p := range resources.BackendTLSPolicies {
+		addAncestors(resource.KindBackendTLSPolicy, p, p.Status)
+	}
+	for _, b := range resources.Backends {
+		add(resource.KindBackend, b, "", b.Status.Conditions)
+	}
+	return conditions
+}
+
+// parentRefString formats the parent reference as "Kind namespace/name/section".
+func parentRefString(ref gwapiv1.ParentReference, namespace string) string {
+	kind := resource.KindGateway
+	if ref.Kind != nil {
+		kind = string(*ref.Kind)
+	}
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+	s := kind + " " + namespace + "/" + string(ref.Name)
+	if ref.SectionName != nil {
+		s += "/" + string(*ref.SectionName)
+	}
+	return s
+}
+
+// diffConditions returns the status conditions added, removed and changed between the
+// base and the head, ignoring the transition times and observed generations.
+func diffConditions(base, head map[conditionKey]metav1.Condition) []ConditionDiff {
+	keys := make([]conditionKey, 0, len(base)+len(head))
+	for key := range base {
+		keys = append(keys, key)
+	}
+	for key := range head {
+		if _, ok := base[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if a.parent != b.parent {
+			return a.parent < b.parent
+		}
+		return a.conditionType < b.conditionType
+	})
+
+	var diffs []ConditionDiff
+	for _, key := range keys {
+		diff := ConditionDiff{
+			Kind:      key.kind,
+			Namespace: key.namespace,
+			Name:      key.name,
+			Parent:    key.parent,
+			Type:      key.conditionType,
+		}
+		if c, ok := base[key]; ok {
+			diff.Base = &ConditionState{Status: c.Status, Reason: c.Reason, Message: c.Message}
+		}
+		if c, ok := head[key]; ok {
+			diff.Head = &ConditionState{Status: c.Status, Reason: c.Reason, Message: c.Message}
+		}
+		switch {
+		case diff.Base == nil:
+			diff.Change = diffAdded
+		case diff.Head == nil:
+			diff.Change = diffRemoved
+		case *diff.Base == *diff.Head:
+			continue
+		default:
+			diff.Change = diffChanged
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+func printDiff(w io.Writer, result *DiffResult, output string) error {
+	var (
+		out []byte
+		err error
+	)
+	switch output {
+	case yamlOutput:
+		out, err = yaml.Marshal(result)
+	case jsonOutput:
+		out, err = json.MarshalIndent(result, "", "  ")
+	default:
+		out = []byte(formatDiff(result))
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, string(out))
+	return err
+}
+
+// diffMarkers are the markers of the changes in the human readable report.
+var diffMarkers = map[string]string{
+	diffAdded:   "+",
+	diffRemoved: "-",
+	diffChanged: "~",
+}
+
+// formatDiff returns a human readable report of the differences.
+func formatDiff(result *DiffResult) string {
+	if result.Empty() {
+		return "No differences found.\n"
+	}
+
+	var sb strings.Builder
+	if len(result.Xds) > 0 {
+		sb.WriteString("xDS resources:\n")
+		for _, d := range result.Xds {
+			fmt.Fprintf(&sb, "%s %s %s [%s]\n", diffMarkers[d.Change], d.Type, d.Name, d.IRKey)
+			for _, line := range strings.Split(strings.TrimRight(d.Diff, "\n"), "\n") {
+				if line != "" {
+					fmt.Fprintf(&sb, "    %s\n", line)
+				}
+			}
+		}
+	}
+	if len(result.Conditions) > 0 {
+		if len(result.Xds) > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("Status conditions:\n")
+		for _, d := range result.Conditions {
+			name := d.Name
+			if d.Namespace != "" {
+				name = d.Namespace + "/" + d.Name
+			}
+			parent := ""
+			if d.Parent != "" {
+				parent = " [" + d.Parent + "]"
+			}
+			fmt.Fprintf(&sb, "%s %s %s%s %s: %s -> %s\n", diffMarkers[d.Change], d.Kind, name, parent, d.Type, d.Base, d.Head)
+			if d.Head != nil && d.Head.Message != "" && (d.Base == nil || d.Base.Message != d.Head.Message) {
+				fmt.Fprintf(&sb, "    %s\n", d.Head.Message)
+			}
+		}
+	}
+	fmt.Fprintf(&sb, "\n%d xDS resource(s) and %d status condition(s) differ.\n", len(result.Xds), len(result.Conditions))
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDiff(t *testing.T) {
+	base := filepath.Join("testdata", "diff", "base.yaml")
+	head := filepath.Join("testdata", "diff", "head")
+
+	result, err := runDiff(base, head, &diffOptions{namespace: "envoy-gateway-system", dnsDomain: "cluster.local"})
+	require.NoError(t, err)
+
+	require.Len(t, result.Xds, 1)
+	require.Equal(t, "default/eg", result.Xds[0].IRKey)
+	require.Equal(t, "Route", result.Xds[0].Type)
+	require.Equal(t, "default/eg/http", result.Xds[0].Name)
+	require.Equal(t, diffChanged, result.Xds[0].Change)
+	require.Contains(t, result.Xds[0].Diff, "/api")
+
+	var conditions []string
+	for _, c := range result.Conditions {
+		require.Equal(t, diffAdded, c.Change)
+		require.Nil(t, c.Base)
+		conditions = append(conditions, c.Kind+" "+c.Name+" "+c.Parent+" "+c.Type+" "+string(c.Head.Status))
+	}
+	require.Equal(t, []string{
+		"HTTPRoute missing-listener Gateway default/eg/https Accepted False",
+		"HTTPRoute missing-listener Gateway default/eg/https ResolvedRefs True",
+	}, conditions)
+
+	result, err = runDiff(head, head, &diffOptions{})
+	require.NoError(t, err)
+	require.True(t, result.Empty())
+}
+
+func TestDiffCommand(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		exitCode int
+		output   string
+	}{
+		{
+			name:     "same resources",
+			args:     []string{filepath.Join("testdata", "diff", "base.yaml"), filepath.Join("testdata", "diff", "base.yaml")},
+			exitCode: 0,
+			output:   "No differences found.\n",
+		},
+		{
+			name:     "different resources",
+			args:     []string{filepath.Join("testdata", "diff", "base.yaml"), filepath.Join("testdata", "diff", "head")},
+			exitCode: 1,
+			output:   "1 xDS resource(s) and 2 status condition(s) differ.\n",
+		},
+		{
+			name:     "missing input",
+			args:     []string{filepath.Join("testdata", "diff", "missing.yaml"), filepath.Join("testdata", "diff", "head")},
+			exitCode: 2,
+		},
+		{
+			name:     "invalid output",
+			args:     []string{filepath.Join("testdata", "diff", "base.yaml"), filepath.Join("testdata", "diff", "head"), "-o", "table"},
+			exitCode: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := bytes.NewBufferString("")
+			root := newDiffCommand()
+			root.SetOut(b)
+			root.SetErr(b)
+			root.SetArgs(tc.args)
+
+			err := root.ExecuteContext(context.Background())
+			if tc.exitCode == 0 {
+				require.NoError(t, err)
+			} else {
+				var exitErr *ExitError
+				require.True(t, errors.As(err, &exitErr))
+				require.Equal(t, tc.exitCode, exitErr.Code)
+			}
+			if tc.output != "" {
+				require.Contains(t, b.String(), tc.output)
+			}
+		})
+	}
+}
@@ -30,6 +30,12 @@ func newExperimentalCommand() *cobra.Command {
 	experimentalCommand.AddCommand(newUnInstallCommand())
 	experimentalCommand.AddCommand(newCollectCommand())
 	experimentalCommand.AddCommand(newValidateCommand())
+	experimentalCommand.AddCommand(newLogLevelCommand())
+	experimentalCommand.AddCommand(newDiffCommand())
+	experimentalCommand.AddCommand(newLintCommand())
+	experimentalCommand.AddCommand(newRouteCommand())
+	experimentalCommand.AddCommand(newMigrateCommand())
+	experimentalCommand.AddCommand(newOpenAPICommand())
 
 	return experimentalCommand
 }
@@ -0,0 +1,725 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/yaml"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/envoygateway/config"
+	"github.com/wukongcloud/gateway/internal/gatewayapi"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/pkg/offline"
+)
+
+// LintIgnoreAnnotation is the annotation of a resource listing the IDs or names of the
+// lint rules whose findings are suppressed for the resource, separated by commas.
+const LintIgnoreAnnotation = "lint.gateway.envoyproxy.io/ignore"
+
+const sarifOutput = "sarif"
+
+// LintSeverity is the severity of a lint finding.
+type LintSeverity string
+
+const (
+	LintSeverityError   LintSeverity = "error"
+	LintSeverityWarning LintSeverity = "warning"
+	LintSeverityInfo    LintSeverity = "info"
+)
+
+// rank returns the rank of the severity, the most severe first.
+func (s LintSeverity) rank() int {
+	switch s {
+	case LintSeverityError:
+		return 0
+	case LintSeverityWarning:
+		return 1
+	case LintSeverityInfo:
+		return 2
+	}
+	return 3
+}
+
+// LintFinding is a risky pattern found by a lint rule in a resource.
+type LintFinding struct {
+	RuleID    string       `json:"ruleID"`
+	Rule      string       `json:"rule"`
+	Severity  LintSeverity `json:"severity"`
+	Kind      string       `json:"kind"`
+	Namespace string       `json:"namespace,omitempty"`
+	Name      string       `json:"name"`
+	Message   string       `json:"message"`
+	// File and Line locate the resource in the input files, if known.
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+// resource returns the kind and the namespaced name of the resource of the finding.
+func (f *LintFinding) resource() string {
+	if f.Namespace == "" {
+		return f.Kind + " " + f.Name
+	}
+	return f.Kind + " " + f.Namespace + "/" + f.Name
+}
+
+// lintRule checks the translated resources for a risky pattern.
+type lintRule struct {
+	id          string
+	name        string
+	severity    LintSeverity
+	description string
+	check       func(*lintContext, *lintRule) []LintFinding
+}
+
+// lintRules are the rules run by the lint command, in the order they are documented.
+var lintRules = []lintRule{
+	{
+		id:          "EG001",
+		name:        "route-without-timeout",
+		severity:    LintSeverityWarning,
+		description: "An HTTPRoute or GRPCRoute rule has no request timeout, set neither on the rule nor by a BackendTrafficPolicy.",
+		check:       checkRouteTimeouts,
+	},
+	{
+		id:          "EG002",
+		name:        "policy-overridden",
+		severity:    LintSeverityWarning,
+		description: "A policy is overridden by a policy targeting a more specific resource, for some of its targets.",
+		check:       checkOverriddenPolicies,
+	},
+	{
+		id:          "EG003",
+		name:        "policy-target-not-found",
+		severity:    LintSeverityError,
+		description: "A policy targets a resource, or a section of a resource, which doesn't exist.",
+		check:       checkPolicyTargets,
+	},
+	{
+		id:          "EG004",
+		name:        "wildcard-listener-overlap",
+		severity:    LintSeverityWarning,
+		description: "A listener with a wildcard or no hostname overlaps a listener with a more specific hostname on the same port, so the requests for that hostname are only routed by the routes of the specific listener.",
+		check:       checkWildcardListeners,
+	},
+	{
+		id:          "EG005",
+		name:        "insecure-tls-version",
+		severity:    LintSeverityError,
+		description: "A ClientTrafficPolicy allows TLS versions older than 1.2.",
+		check:       checkClientTLSVersions,
+	},
+	{
+		id:          "EG006",
+		name:        "envoy-patch-policy-not-programmed",
+		severity:    LintSeverityError,
+		description: "An EnvoyPatchPolicy can't be applied, e.g. because it patches a resource or a path which doesn't exist.",
+		check:       checkEnvoyPatchPolicies,
+	},
+	{
+		id:          "EG007",
+		name:        "resource-not-accepted",
+		severity:    LintSeverityError,
+		description: "A Gateway, route or policy isn't accepted by Envoy Gateway.",
+		check:       checkNotAccepted,
+	},
+}
+
+type lintOptions struct {
+	output              string
+	failOn              string
+	addMissingResources bool
+}
+
+func newLintCommand() *cobra.Command {
+	opts := &lintOptions{}
+
+	lintCommand := &cobra.Command{
+		Use:   "lint <file|directory|->",
+		Short: "Check Gateway API and Envoy Gateway resources for risky patterns",
+		Long: `Translate the Gateway API and Envoy Gateway resources, and report the risky patterns found with the ID and
+the severity of the rule finding them. The findings of a rule are suppressed for a resource by listing the rule ID or
+name in the ` + LintIgnoreAnnotation + ` annotation of the resource, separated by commas.
+
+The rules are:
+` + lintRulesHelp() + `
+The exit status is 0 if no finding is at least as severe as --fail-on, 1 otherwise and 2 if an error occurred.`,
+		Example: `  # Lint the resources of a directory.
+  egctl experimental lint ./manifests
+
+  # Lint the resources of a file, in SARIF output.
+  egctl x lint gateway.yaml --output sarif > lint.sarif
+
+  # Lint the resources from stdin, failing on the warnings too.
+  cat gateway.yaml | egctl x lint - --fail-on warning
+	  `,
+		Args: cobra.ExactArgs(1),
+		// The findings are reported through the exit status only.
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			findings, err := runLint(args[0], opts)
+			if err != nil {
+				return &ExitError{Code: 2, Err: err}
+			}
+			if err := printLintFindings(cmd.OutOrStdout(), findings, opts.output); err != nil {
+				return &ExitError{Code: 2, Err: err}
+			}
+			if failOn := LintSeverity(opts.failOn); failOn != "none" {
+				for _, f := range findings {
+					if f.Severity.rank() <= failOn.rank() {
+						return &ExitError{Code: 1}
+					}
+				}
+			}
+			return nil
+		},
+	}
+
+	lintCommand.Flags().StringVarP(&opts.output, "output", "o", "", "One of 'sarif', 'yaml' or 'json'. Prints a human readable report if unset.")
+	lintCommand.Flags().StringVarP(&opts.failOn, "fail-on", "", string(LintSeverityError),
+		"Lowest severity of the findings failing the command, one of 'error', 'warning', 'info' or 'none'.")
+	lintCommand.Flags().BoolVarP(&opts.addMissingResources, "add-missing-resources", "", false, "Provides dummy resources if missed")
+
+	return lintCommand
+}
+
+func lintRulesHelp() string {
+	var sb strings.Builder
+	for _, r := range lintRules {
+		fmt.Fprintf(&sb, "  %s %s (%s): %s\n", r.id, r.name, r.severity, r.description)
+	}
+	return sb.String()
+}
+
+func runLint(path string, opts *lintOptions) ([]LintFinding, error) {
+	switch opts.output {
+	case "", sarifOutput, yamlOutput, jsonOutput:
+	default:
+		return nil, fmt.Errorf("invalid output %q, valid options: sarif/yaml/json", opts.output)
+	}
+	switch LintSeverity(opts.failOn) {
+	case LintSeverityError, LintSeverityWarning, LintSeverityInfo, "none":
+	default:
+		return nil, fmt.Errorf("invalid severity %q, valid options: error/warning/info/none", opts.failOn)
+	}
+
+	files, err := readInputFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read input: %w", err)
+	}
+	resources, err := resource.LoadResourcesFromYAMLBytes(joinInputFiles(files), opts.addMissingResources)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal input: %w", err)
+	}
+	// The translation is best-effort, the failures of the xDS translation are reflected
+	// in the statuses.
+	result, _ := translateGatewayAPI(resources, "", "")
+	if result == nil {
+		return nil, fmt.Errorf("unable to translate the resources")
+	}
+
+	return lint(newLintContext(result, files)), nil
+}
+
+// lintObjectKey identifies a resource.
+type lintObjectKey struct {
+	kind, namespace, name string
+}
+
+// lintSource is a resource of the input files.
+type lintSource struct {
+	file string
+	line int
+	// annotations are read from the files, since the loaded resources don't keep them.
+	annotations map[string]string
+}
+
+// lintContext holds the translation result checked by the rules.
+type lintContext struct {
+	result  *offline.Result
+	objects map[lintObjectKey]metav1.Object
+	sources map[lintObjectKey]lintSource
+}
+
+func newLintContext(result *offline.Result, files []inputFile) *lintContext {
+	ctx := &lintContext{
+		result:  result,
+		objects: make(map[lintObjectKey]metav1.Object),
+		sources: make(map[lintObjectKey]lintSource),
+	}
+
+	r := result.Resources
+	add := func(kind string, obj metav1.Object) {
+		ctx.objects[lintObjectKey{kind, obj.GetNamespace(), obj.GetName()}] = obj
+	}
+	for _, o := range r.Gateways {
+		add(resource.KindGateway, o)
+	}
+	for _, o := range r.HTTPRoutes {
+		add(resource.KindHTTPRoute, o)
+	}
+	for _, o := range r.GRPCRoutes {
+		add(resource.KindGRPCRoute, o)
+	}
+	for _, o := range r.TLSRoutes {
+		add(resource.KindTLSRoute, o)
+	}
+	for _, o := range r.TCPRoutes {
+		add(resource.KindTCPRoute, o)
+	}
+	for _, o := range r.UDPRoutes {
+		add(resource.KindUDPRoute, o)
+	}
+	for _, o := range r.ClientTrafficPolicies {
+		add(resource.KindClientTrafficPolicy, o)
+	}
+	for _, o := range r.BackendTrafficPolicies {
+		add(resource.KindBackendTrafficPolicy, o)
+	}
+	for _, o := range r.SecurityPolicies {
+		add(resource.KindSecurityPolicy, o)
+	}
+	for _, o := range r.EnvoyExtensionPolicies {
+		add(resource.KindEnvoyExtensionPolicy, o)
+	}
+	for _, o := range r.EnvoyPatchPolicies {
+		add(resource.KindEnvoyPatchPolicy, o)
+	}
+
+	for _, f := range files {
+		for key, source := range indexYAMLDocuments(f.content) {
+			if _, ok := ctx.sources[key]; !ok {
+				source.file = f.name
+				ctx.sources[key] = source
+			}
+		}
+	}
+	return ctx
+}
+
+// indexYAMLDocuments returns the line and the annotations of every resource of the
+// YAML documents.
+func indexYAMLDocuments(content []byte) map[lintObjectKey]lintSource {
+	index := make(map[lintObjectKey]lintSource)
+	addDoc := func(doc []byte, line int) {
+		var obj struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name        string            `json:"name"`
+				Namespace   string            `json:"namespace"`
+				Annotations map[string]string `json:"annotations"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal(doc, &obj); err != nil || obj.Kind == "" {
+			return
+		}
+		// The resources without a namespace are loaded in the default namespace.
+		namespace := obj.Metadata.Namespace
+		if namespace == "" && obj.Kind != resource.KindGatewayClass {
+			namespace = config.DefaultNamespace
+		}
+		index[lintObjectKey{obj.Kind, namespace, obj.Metadata.Name}] = lintSource{
+			line:        line,
+			annotations: obj.Metadata.Annotations,
+		}
+	}
+
+	var doc bytes.Buffer
+	start, lineNumber := 1, 0
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if strings.TrimRight(line, " \t") == "---" {
+			addDoc(doc.Bytes(), start)
+			doc.Reset()
+			start = lineNumber + 1
+			continue
+		}
+		doc.WriteString(line)
+		doc.WriteByte('\n')
+	}
+	addDoc(doc.Bytes(), start)
+	return index
+}
+
+// finding returns a finding of the rule for the resource.
+func (c *lintContext) finding(rule *lintRule, kind, namespace, name, message string) LintFinding {
+	f := LintFinding{
+		RuleID:    rule.id,
+		Rule:      rule.name,
+		Severity:  rule.severity,
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		// The messages of the conditions may span several lines.
+		Message: strings.Join(strings.Fields(message), " "),
+	}
+	if source, ok := c.sources[lintObjectKey{kind, namespace, name}]; ok {
+		f.File, f.Line = source.file, source.line
+	}
+	return f
+}
+
+// ignored returns whether the rule is suppressed by the annotation of the resource.
+func (c *lintContext) ignored(rule *lintRule, key lintObjectKey) bool {
+	for _, ignore := range strings.Split(c.sources[key].annotations[LintIgnoreAnnotation], ",") {
+		ignore = strings.TrimSpace(ignore)
+		if ignore == rule.id || ignore == rule.name {
+			return true
+		}
+	}
+	return false
+}
+
+// lint runs the rules, and returns their findings which aren't suppressed, sorted by
+// severity, rule and resource.
+func lint(ctx *lintContext) []LintFinding {
+	var findings []LintFinding
+	for i := range lintRules {
+		rule := &lintRules[i]
+		for _, f := range rule.check(ctx, rule) {
+			if !ctx.ignored(rule, lintObjectKey{f.Kind, f.Namespace, f.Name}) {
+				findings = append(findings, f)
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity.rank() < b.Severity.rank()
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return findings
+}
+
+func checkRouteTimeouts(c *lintContext, rule *lintRule) []LintFinding {
+	type routeRule struct {
+		kind, namespace, name string
+		index                 int
+	}
+	withoutTimeout := make(map[routeRule]bool)
+	for _, xdsIR := range c.result.XdsIR {
+		for _, listener := range xdsIR.HTTP {
+			for _, route := range listener.Routes {
+				ref, ok := parseIRRouteName(route.Name)
+				if !ok {
+					continue
+				}
+				key := routeRule{ref.kind, ref.namespace, ref.name, ref.rule}
+
+				hasTimeout := route.Timeout != nil ||
+					(route.Traffic != nil && route.Traffic.Timeout != nil &&
+						route.Traffic.Timeout.HTTP != nil && route.Traffic.Timeout.HTTP.RequestTimeout != nil)
+				// A rule is reported if any of its routes has no timeout.
+				withoutTimeout[key] = withoutTimeout[key] || !hasTimeout
+			}
+		}
+	}
+
+	var findings []LintFinding
+	for key, missing := range withoutTimeout {
+		if missing {
+			findings = append(findings, c.finding(rule, key.kind, key.namespace, key.name,
+				fmt.Sprintf("rule %d has no request timeout, set one in the rule timeouts or with a BackendTrafficPolicy", key.index)))
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Message < findings[j].Message })
+	return findings
+}
+
+// attachedPolicy is an Envoy Gateway policy attached to the resources by target references.
+type attachedPolicy struct {
+	kind       string
+	obj        metav1.Object
+	targetRefs []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName
+	status     *gwapiv1a2.PolicyStatus
+}
+
+// attachedPolicies returns the ClientTrafficPolicies, BackendTrafficPolicies, SecurityPolicies
+// and EnvoyExtensionPolicies of the resources.
+func attachedPolicies(r *resource.Resources) []attachedPolicy {
+	var policies []attachedPolicy
+	for _, p := range r.ClientTrafficPolicies {
+		policies = append(policies, attachedPolicy{resource.KindClientTrafficPolicy, p, p.Spec.GetTargetRefs(), &p.Status})
+	}
+	for _, p := range r.BackendTrafficPolicies {
+		policies = append(policies, attachedPolicy{resource.KindBackendTrafficPolicy, p, p.Spec.GetTargetRefs(), &p.Status})
+	}
+	for _, p := range r.SecurityPolicies {
+		policies = append(policies, attachedPolicy{resource.KindSecurityPolicy, p, p.Spec.GetTargetRefs(), &p.Status})
+	}
+	for _, p := range r.EnvoyExtensionPolicies {
+		policies = append(policies, attachedPolicy{resource.KindEnvoyExtensionPolicy, p, p.Spec.GetTargetRefs(), &p.Status})
+	}
+	return policies
+}
+
+func checkOverriddenPolicies(c *lintContext, rule *lintRule) []LintFinding {
+	var findings []LintFinding
+	for _, p := range attachedPolicies(c.result.Resources) {
+		for _, ancestor := range p.status.Ancestors {
+			cond := meta.FindStatusCondition(ancestor.Conditions, string(egv1a1.PolicyConditionOverridden))
+			if cond == nil || cond.Status != metav1.ConditionTrue {
+				continue
+			}
+			findings = append(findings, c.finding(rule, p.kind, p.obj.GetNamespace(), p.obj.GetName(),
+				fmt.Sprintf("overridden for %s: %s", parentRefString(ancestor.AncestorRef, p.obj.GetNamespace()), cond.Message)))
+		}
+	}
+	return findings
+}
+
+func checkPolicyTargets(c *lintContext, rule *lintRule) []LintFinding {
+	var findings []LintFinding
+	for _, p := range attachedPolicies(c.result.Resources) {
+		for _, ref := range p.targetRefs {
+			target := string(ref.Kind) + " " + p.obj.GetNamespace() + "/" + string(ref.Name)
+			obj, ok := c.objects[lintObjectKey{string(ref.Kind), p.obj.GetNamespace(), string(ref.Name)}]
+			if !ok || !isPolicyTargetKind(string(ref.Kind)) {
+				findings = append(findings, c.finding(rule, p.kind, p.obj.GetNamespace(), p.obj.GetName(),
+					fmt.Sprintf("targets %s, which doesn't exist", target)))
+				continue
+			}
+			if ref.SectionName != nil && !hasSection(obj, *ref.SectionName) {
+				findings = append(findings, c.finding(rule, p.kind, p.obj.GetNamespace(), p.obj.GetName(),
+					fmt.Sprintf("targets section %s of %s, which doesn't exist", *ref.SectionName, target)))
+			}
+		}
+	}
+	return findings
+}
+
+func isPolicyTargetKind(kind string) bool {
+	switch kind {
+	case resource.KindGateway, resource.KindHTTPRoute, resource.KindGRPCRoute,
+		resource.KindTLSRoute, resource.KindTCPRoute, resource.KindUDPRoute:
+		return true
+	}
+	return false
+}
+
+// hasSection returns whether the Gateway has the listener, or the route has the rule
+// named after the section.
+func hasSection(obj metav1.Object, section gwapiv1.SectionName) bool {
+	switch o := obj.(type) {
+	case *gwapiv1.Gateway:
+		return slices.ContainsFunc(o.Spec.Listeners, func(l gwapiv1.Listener) bool { return l.Name == section })
+	case *gwapiv1.HTTPRoute:
+		return slices.ContainsFunc(o.Spec.Rules, func(r gwapiv1.HTTPRouteRule) bool {
+			return r.Name != nil && *r.Name == section
+		})
+	case *gwapiv1.GRPCRoute:
+		return slices.ContainsFunc(o.Spec.Rules, func(r gwapiv1.GRPCRouteRule) bool {
+			return r.Name != nil && *r.Name == section
+		})
+	}
+	// The sections of the other routes can't be checked.
+	return true
+}
+
+func checkWildcardListeners(c *lintContext, rule *lintRule) []LintFinding {
+	merged := gatewayapi.IsMergeGatewaysEnabled(c.result.Resources)
+
+	type listener struct {
+		gateway  *gwapiv1.Gateway
+		listener gwapiv1.Listener
+	}
+	type group struct {
+		gateway  string
+		port     gwapiv1.PortNumber
+		protocol gwapiv1.ProtocolType
+	}
+	groups := make(map[group][]listener)
+	for _, gw := range c.result.Resources.Gateways {
+		// The listeners of the merged Gateways share the Envoy listeners.
+		gateway := gw.Namespace + "/" + gw.Name
+		if merged {
+			gateway = string(gw.Spec.GatewayClassName)
+		}
+		for _, l := range gw.Spec.Listeners {
+			key := group{gateway, l.Port, l.Protocol}
+			groups[key] = append(groups[key], listener{gw, l})
+		}
+	}
+
+	var findings []LintFinding
+	for _, listeners := range groups {
+		for _, wildcard := range listeners {
+			for _, specific := range listeners {
+				if !hostnameCovers(wildcard.listener.Hostname, specific.listener.Hostname) {
+					continue
+				}
+				findings = append(findings, c.finding(rule, resource.KindGateway, wildcard.gateway.Namespace, wildcard.gateway.Name,
+					fmt.Sprintf("listener %s with hostname %q overlaps listener %s of Gateway %s/%s with hostname %q on port %d, "+
+						"the requests for %s are only routed by the routes of listener %s",
+						wildcard.listener.Name, hostnameString(wildcard.listener.Hostname),
+						specific.listener.Name, specific.gateway.Namespace, specific.gateway.Name,
+						hostnameString(specific.listener.Hostname), wildcard.listener.Port,
+						hostnameString(specific.listener.Hostname), specific.listener.Name)))
+			}
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Message < findings[j].Message })
+	return findings
+}
+
+func hostnameString(hostname *gwapiv1.Hostname) string {
+	if hostname == nil || *hostname == "" {
+		return "*"
+	}
+	return string(*hostname)
+}
+
+// hostnameCovers returns whether the wildcard hostname matches the other, more specific
+// hostname.
+func hostnameCovers(wildcard, other *gwapiv1.Hostname) bool {
+	w, o := hostnameString(wildcard), hostnameString(other)
+	if w == o || !strings.HasPrefix(w, "*") {
+		return false
+	}
+	if w == "*" {
+		return true
+	}
+	// "*.example.com" matches "foo.example.com" and "*.foo.example.com".
+	return strings.HasSuffix(o, w[1:]) && len(strings.TrimPrefix(o, "*")) > len(w)-1
+}
+
+func checkClientTLSVersions(c *lintContext, rule *lintRule) []LintFinding {
+	insecure := func(v *egv1a1.TLSVersion) bool {
+		return v != nil && (*v == egv1a1.TLSv10 || *v == egv1a1.TLSv11)
+	}
+	var findings []LintFinding
+	for _, p := range c.result.Resources.ClientTrafficPolicies {
+		if p.Spec.TLS == nil {
+			continue
+		}
+		switch {
+		case insecure(p.Spec.TLS.MinVersion):
+			findings = append(findings, c.finding(rule, resource.KindClientTrafficPolicy, p.Namespace, p.Name,
+				fmt.Sprintf("the minimum TLS version %s is older than 1.2", *p.Spec.TLS.MinVersion)))
+		case insecure(p.Spec.TLS.MaxVersion):
+			findings = append(findings, c.finding(rule, resource.KindClientTrafficPolicy, p.Namespace, p.Name,
+				fmt.Sprintf("the maximum TLS version %s is older than 1.2", *p.Spec.TLS.MaxVersion)))
+		}
+	}
+	return findings
+}
+
+func checkEnvoyPatchPolicies(c *lintContext, rule *lintRule) []LintFinding {
+	var findings []LintFinding
+	for _, p := range c.result.Resources.EnvoyPatchPolicies {
+		for _, ancestor := range p.Status.Ancestors {
+			cond := meta.FindStatusCondition(ancestor.Conditions, string(egv1a1.PolicyConditionProgrammed))
+			if cond == nil || cond.Status != metav1.ConditionFalse {
+				continue
+			}
+			findings = append(findings, c.finding(rule, resource.KindEnvoyPatchPolicy, p.Namespace, p.Name,
+				fmt.Sprintf("not programmed for %s: %s", parentRefString(ancestor.AncestorRef, p.Namespace), cond.Message)))
+		}
+	}
+	return findings
+}
+
+func checkNotAccepted(c *lintContext, rule *lintRule) []LintFinding {
+	conditions := collectConditions(c.result.Resources)
+	keys := make([]conditionKey, 0, len(conditions))
+	for key := range conditions {
+		if key.conditionType != string(gwapiv1.GatewayConditionAccepted) || conditions[key].Status != metav1.ConditionFalse {
+			continue
+		}
+		// Only the Gateways, routes and policies are reported.
+		if _, ok := c.objects[lintObjectKey{key.kind, key.namespace, key.name}]; ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].parent < keys[j].parent })
+
+	findings := make([]LintFinding, 0, len(keys))
+	for _, key := range keys {
+		message := "not accepted: " + conditions[key].Message
+		if key.parent != "" {
+			message = fmt.Sprintf("not accepted by %s: %s", key.parent, conditions[key].Message)
+		}
+		findings = append(findings, c.finding(rule, key.kind, key.namespace, key.name, message))
+	}
+	return findings
+}
+
+func printLintFindings(w io.Writer, findings []LintFinding, output string) error {
+	var (
+		out []byte
+		err error
+	)
+	switch output {
+	case sarifOutput:
+		out, err = marshalSARIF(findings)
+	case yamlOutput:
+		out, err = yaml.Marshal(findings)
+	case jsonOutput:
+		out, err = json.MarshalIndent(findings, "", "  ")
+	default:
+		return writeLintTable(w, findings)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, strings.TrimRight(string(out), "\n"))
+	return err
+}
+
+func writeLintTable(w io.Writer, findings []LintFinding) error {
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "No issues found.")
+		return err
+	}
+
+	table := newStatusTableWriter(w)
+	header := []string{"SEVERITY", "RULE", "RESOURCE", "LOCATION", "MESSAGE"}
+	body := make([][]string, 0, len(findings))
+	counts := make(map[LintSeverity]int)
+	for _, f := range findings {
+		location := ""
+		if f.File != "" {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		body = append(body, []string{
+			string(f.Severity), f.RuleID + " " + f.Rule, f.resource(), location, f.Message,
+		})
+		counts[f.Severity]++
+	}
+	writeStatusTable(table, header, body)
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d error(s), %d warning(s), %d info(s)\n",
+		counts[LintSeverityError], counts[LintSeverityWarning], counts[LintSeverityInfo])
+	return err
+}
@@ -0,0 +1,161 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestRunLint(t *testing.T) {
+	findings, err := runLint(filepath.Join("testdata", "lint", "resources.yaml"), &lintOptions{failOn: "error"})
+	require.NoError(t, err)
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.RuleID+" "+f.resource())
+	}
+	require.Equal(t, []string{
+		"EG003 BackendTrafficPolicy default/missing-target",
+		"EG005 ClientTrafficPolicy default/tls-1-1",
+		"EG006 EnvoyPatchPolicy default/missing-path",
+		"EG007 ClientTrafficPolicy default/tls-1-1",
+		// The findings of the default/ignored HTTPRoute are suppressed by its annotation.
+		"EG001 HTTPRoute default/no-timeout",
+		"EG002 SecurityPolicy default/gateway",
+		"EG004 Gateway default/eg",
+	}, got)
+
+	require.Equal(t, filepath.Join("testdata", "lint", "resources.yaml"), findings[0].File)
+	require.Equal(t, 115, findings[0].Line)
+	require.Equal(t, "targets HTTPRoute default/missing, which doesn't exist", findings[0].Message)
+}
+
+func TestLintCommand(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		exitCode int
+	}{
+		{
+			name:     "fail on errors",
+			args:     []string{filepath.Join("testdata", "lint", "resources.yaml")},
+			exitCode: 1,
+		},
+		{
+			name:     "fail on none",
+			args:     []string{filepath.Join("testdata", "lint", "resources.yaml"), "--fail-on", "none"},
+			exitCode: 0,
+		},
+		{
+			name:     "warnings only",
+			args:     []string{filepath.Join("testdata", "translate", "in", "envoy-config.yaml")},
+			exitCode: 0,
+		},
+		{
+			name:     "fail on warnings",
+			args:     []string{filepath.Join("testdata", "translate", "in", "envoy-config.yaml"), "--fail-on", "warning"},
+			exitCode: 1,
+		},
+		{
+			name:     "invalid severity",
+			args:     []string{filepath.Join("testdata", "lint", "resources.yaml"), "--fail-on", "critical"},
+			exitCode: 2,
+		},
+		{
+			name:     "sarif",
+			args:     []string{filepath.Join("testdata", "lint", "resources.yaml"), "-o", "sarif"},
+			exitCode: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := bytes.NewBufferString("")
+			root := newLintCommand()
+			// The usage is silenced by the egctl root command.
+			root.SilenceUsage = true
+			root.SetOut(b)
+			root.SetErr(b)
+			root.SetArgs(tc.args)
+
+			err := root.ExecuteContext(context.Background())
+			if tc.exitCode == 0 {
+				require.NoError(t, err)
+				return
+			}
+			var exitErr *ExitError
+			require.True(t, errors.As(err, &exitErr))
+			require.Equal(t, tc.exitCode, exitErr.Code)
+
+			if tc.name == "sarif" {
+				log := &sarifLog{}
+				require.NoError(t, json.Unmarshal(b.Bytes(), log))
+				require.Len(t, log.Runs, 1)
+				require.Len(t, log.Runs[0].Tool.Driver.Rules, len(lintRules))
+				require.Len(t, log.Runs[0].Results, 7)
+				result := log.Runs[0].Results[0]
+				require.Equal(t, "EG003", result.RuleID)
+				require.Equal(t, "error", result.Level)
+				require.Equal(t, "testdata/lint/resources.yaml", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+				require.Equal(t, "BackendTrafficPolicy/default/missing-target", result.Locations[0].LogicalLocations[0].FullyQualifiedName)
+			}
+		})
+	}
+}
+
+func TestIndexYAMLDocuments(t *testing.T) {
+	index := indexYAMLDocuments([]byte(`apiVersion: gateway.networking.k8s.io/v1
+kind: GatewayClass
+metadata:
+  name: eg
+---
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: backend
+  annotations:
+    lint.gateway.envoyproxy.io/ignore: EG001
+---
+# A comment.
+`))
+
+	require.Equal(t, map[lintObjectKey]lintSource{
+		{"GatewayClass", "", "eg"}: {line: 1},
+		{"HTTPRoute", "envoy-gateway-system", "backend"}: {
+			line:        6,
+			annotations: map[string]string{LintIgnoreAnnotation: "EG001"},
+		},
+	}, index)
+}
+
+func TestHostnameCovers(t *testing.T) {
+	testCases := []struct {
+		wildcard, other *gwapiv1.Hostname
+		covers          bool
+	}{
+		{nil, ptr.To[gwapiv1.Hostname]("foo.example.com"), true},
+		{ptr.To[gwapiv1.Hostname]("*.example.com"), ptr.To[gwapiv1.Hostname]("foo.example.com"), true},
+		{ptr.To[gwapiv1.Hostname]("*.example.com"), ptr.To[gwapiv1.Hostname]("*.foo.example.com"), true},
+		{ptr.To[gwapiv1.Hostname]("*.example.com"), ptr.To[gwapiv1.Hostname]("example.com"), false},
+		{ptr.To[gwapiv1.Hostname]("*.example.com"), ptr.To[gwapiv1.Hostname]("*.example.com"), false},
+		{ptr.To[gwapiv1.Hostname]("*.example.com"), ptr.To[gwapiv1.Hostname]("foo.example.org"), false},
+		{ptr.To[gwapiv1.Hostname]("foo.example.com"), ptr.To[gwapiv1.Hostname]("foo.example.com"), false},
+		{nil, nil, false},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.covers, hostnameCovers(tc.wildcard, tc.other), "%s covers %s", hostnameString(tc.wildcard), hostnameString(tc.other))
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/admin/loglevel"
+	"github.com/wukongcloud/gateway/internal/cmd/options"
+	"github.com/wukongcloud/gateway/internal/kubernetes"
+	"github.com/wukongcloud/gateway/internal/logging"
+)
+
+// envoyGatewayLabelSelector selects the Envoy Gateway pods.
+const envoyGatewayLabelSelector = "control-plane=envoy-gateway"
+
+type logLevelOptions struct {
+	namespace string
+	timeout   time.Duration
+	reset     bool
+}
+
+func newLogLevelCommand() *cobra.Command {
+	opts := &logLevelOptions{}
+
+	logLevelCommand := &cobra.Command{
+		Use:   "log-level [component=level...]",
+		Short: "Retrieve or change the logging levels of the Envoy Gateway components at runtime",
+		Long: `Retrieve or change the logging levels of the Envoy Gateway components at runtime, without restarting
+Envoy Gateway. The components are: ` + componentNames() + `.`,
+		Example: `  # Retrieve the logging levels of the components.
+  egctl experimental log-level
+
+  # Set the logging level of the xds-server and gateway-api components to debug.
+  egctl experimental log-level xds-server=debug gateway-api=debug
+
+  # Set the logging level of the xds-server component to debug for 10 minutes.
+  egctl x log-level xds-server=debug --timeout 10m
+
+  # Revert the xds-server component to its configured logging level.
+  egctl x log-level --reset xds-server
+
+  # Revert all the components to their configured logging levels.
+  egctl x log-level --reset
+	  `,
+		Run: func(c *cobra.Command, args []string) {
+			cmdutil.CheckErr(runLogLevel(c.OutOrStdout(), opts, args))
+		},
+	}
+
+	options.AddKubeConfigFlags(logLevelCommand.Flags())
+	logLevelCommand.Flags().StringVarP(&opts.namespace, "namespace", "n", "envoy-gateway-system",
+		"Namespace where Envoy Gateway is installed.")
+	logLevelCommand.Flags().DurationVar(&opts.timeout, "timeout", 0,
+		"Revert the changed logging levels to the configured ones after the timeout, e.g. 10m. Zero keeps them until changed again.")
+	logLevelCommand.Flags().BoolVar(&opts.reset, "reset", false,
+		"Revert the given components, or all the components if none is given, to their configured logging levels.")
+
+	return logLevelCommand
+}
+
+func componentNames() string {
+	names := make([]string, 0, len(logging.Components))
+	for _, component := range logging.Components {
+		names = append(names, string(component))
+	}
+	return strings.Join(names, ", ")
+}
+
+// logLevelRequest is a request to the logging level endpoint of the admin server.
+type logLevelRequest struct {
+	method string
+	query  url.Values
+}
+
+// newLogLevelRequests returns the requests listing or changing the logging levels,
+// given the command arguments.
+func newLogLevelRequests(opts *logLevelOptions, args []string) ([]logLevelRequest, error) {
+	if len(args) == 0 {
+		if opts.reset {
+			return []logLevelRequest{{method: http.MethodDelete, query: url.Values{}}}, nil
+		}
+		return []logLevelRequest{{method: http.MethodGet, query: url.Values{}}}, nil
+	}
+
+	var requests []logLevelRequest
+	for _, arg := range args {
+		if opts.reset {
+			if strings.Contains(arg, "=") {
+				return nil, fmt.Errorf("invalid argument %q, --reset takes component names", arg)
+			}
+			requests = append(requests, logLevelRequest{
+				method: http.MethodDelete,
+				query:  url.Values{"component": {arg}},
+			})
+			continue
+		}
+
+		component, level, ok := strings.Cut(arg, "=")
+		if !ok || component == "" || level == "" {
+			return nil, fmt.Errorf("invalid argument %q, expected component=level", arg)
+		}
+		query := url.Values{"component": {component}, "level": {level}}
+		if opts.timeout > 0 {
+			query.Set("timeout", opts.timeout.String())
+		}
+		requests = append(requests, logLevelRequest{method: http.MethodPost, query: query})
+	}
+	return requests, nil
+}
+
+func runLogLevel(w io.Writer, opts *logLevelOptions, args []string) error {
+	requests, err := newLogLevelRequests(opts, args)
+	if err != nil {
+		return err
+	}
+
+	cli, err := getCLIClient()
+	if err != nil {
+		return err
+	}
+	pods, err := fetchRunningEnvoyGatewayPods(cli, opts.namespace)
+	if err != nil {
+		return err
+	}
+
+	// Every Envoy Gateway replica has its own logging levels.
+	table := newStatusTableWriter(w)
+	header := []string{"POD", "COMPONENT", "LEVEL", "CONFIGURED", "REVERT AT"}
+	var body [][]string
+	for _, pod := range pods {
+		levels, err := logLevels(cli, pod, requests)
+		if err != nil {
+			return err
+		}
+		for _, level := range levels {
+			revertAt := ""
+			if level.RevertAt != nil {
+				revertAt = level.RevertAt.Format(time.RFC3339)
+			}
+			body = append(body, []string{
+				pod.Name, string(level.Component), string(level.Level), string(level.ConfiguredLevel), revertAt,
+			})
+		}
+	}
+	writeStatusTable(table, header, body)
+	return table.Flush()
+}
+
+// fetchRunningEnvoyGatewayPods returns the running Envoy Gateway pods of the namespace.
+func fetchRunningEnvoyGatewayPods(cli kubernetes.CLIClient, namespace string) ([]types.NamespacedName, error) {
+	podList, err := cli.PodsForSelector(namespace, envoyGatewayLabelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []types.NamespacedName
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == corev1.PodRunning {
+			pods = append(pods, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name})
+		}
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no running Envoy Gateway pods found in namespace %s", namespace)
+	}
+	return pods, nil
+}
+
+// logLevels sends the requests to the admin server of the pod, and returns the logging
+// levels returned by the last one.
+func logLevels(cli kubernetes.CLIClient, pod types.NamespacedName, requests []logLevelRequest) ([]logging.ComponentLevel, error) {
+	fw, err := portForwarder(cli, pod, egv1a1.GatewayAdminPort)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize pod-forwarding for %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	if err := fw.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start port forwarding for pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	defer fw.Stop()
+
+	var levels []logging.ComponentLevel
+	for _, request := range requests {
+		if levels, err = logLevelAdminRequest(fw.Address(), request); err != nil {
+			return nil, fmt.Errorf("failed to change logging levels of pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+	return levels, nil
+}
+
+func logLevelAdminRequest(address string, request logLevelRequest) ([]logging.ComponentLevel, error) {
+	u := url.URL{Scheme: "http", Host: address, Path: loglevel.Path, RawQuery: request.query.Encode()}
+	req, err := http.NewRequest(request.method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var levels []logging.ComponentLevel
+	if err := json.Unmarshal(body, &levels); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
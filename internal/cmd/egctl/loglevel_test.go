@@ -0,0 +1,101 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/admin/loglevel"
+	"github.com/wukongcloud/gateway/internal/logging"
+)
+
+func TestNewLogLevelRequests(t *testing.T) {
+	testCases := []struct {
+		name      string
+		opts      logLevelOptions
+		args      []string
+		expect    []logLevelRequest
+		expectErr string
+	}{
+		{
+			name:   "list",
+			expect: []logLevelRequest{{method: http.MethodGet, query: url.Values{}}},
+		},
+		{
+			name: "set",
+			opts: logLevelOptions{timeout: 10 * time.Minute},
+			args: []string{"xds-server=debug", "gateway-api=warn"},
+			expect: []logLevelRequest{
+				{method: http.MethodPost, query: url.Values{"component": {"xds-server"}, "level": {"debug"}, "timeout": {"10m0s"}}},
+				{method: http.MethodPost, query: url.Values{"component": {"gateway-api"}, "level": {"warn"}, "timeout": {"10m0s"}}},
+			},
+		},
+		{
+			name:   "reset all",
+			opts:   logLevelOptions{reset: true},
+			expect: []logLevelRequest{{method: http.MethodDelete, query: url.Values{}}},
+		},
+		{
+			name:   "reset component",
+			opts:   logLevelOptions{reset: true},
+			args:   []string{"provider"},
+			expect: []logLevelRequest{{method: http.MethodDelete, query: url.Values{"component": {"provider"}}}},
+		},
+		{
+			name:      "missing level",
+			args:      []string{"provider"},
+			expectErr: "expected component=level",
+		},
+		{
+			name:      "reset with level",
+			opts:      logLevelOptions{reset: true},
+			args:      []string{"provider=debug"},
+			expectErr: "--reset takes component names",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests, err := newLogLevelRequests(&tc.opts, tc.args)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, requests)
+		})
+	}
+}
+
+func TestLogLevelAdminRequest(t *testing.T) {
+	logger := logging.NewLogger(os.Stdout, egv1a1.DefaultEnvoyGatewayLogging())
+	server := httptest.NewServer(loglevel.Handler(logger))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	levels, err := logLevelAdminRequest(address, logLevelRequest{
+		method: http.MethodPost,
+		query:  url.Values{"component": {"xds-server"}, "level": {"debug"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, egv1a1.LogComponentXdsServerRunner, levels[4].Component)
+	require.Equal(t, egv1a1.LogLevelDebug, levels[4].Level)
+
+	_, err = logLevelAdminRequest(address, logLevelRequest{
+		method: http.MethodPost,
+		query:  url.Values{"component": {"unknown"}, "level": {"debug"}},
+	})
+	require.ErrorContains(t, err, "invalid logging component")
+}
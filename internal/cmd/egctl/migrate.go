@@ -0,0 +1,22 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "migrate",
+		Long:  "Migrate the resources of other ingress controllers to Envoy Gateway.",
+		Short: "Migrate the resources of other ingress controllers to Envoy Gateway.",
+	}
+
+	c.AddCommand(newMigrateIngressNginxCommand())
+
+	return c
+}
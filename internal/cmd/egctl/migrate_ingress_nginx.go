@@ -0,0 +1,1282 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gwapiv1b1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	gwresource "github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+)
+
+const (
+	ingressNginxAnnotationPrefix = "nginx.ingress.kubernetes.io/"
+	ingressClassAnnotation       = "kubernetes.io/ingress.class"
+
+	// The defaults of the ingress-nginx annotations.
+	ingressNginxCORSAllowMethods    = "GET, PUT, POST, DELETE, PATCH, OPTIONS"
+	ingressNginxCORSAllowHeaders    = "DNT,Keep-Alive,User-Agent,X-Requested-With,If-Modified-Since,Cache-Control,Content-Type,Range,Authorization"
+	ingressNginxCORSMaxAge          = 1728000
+	ingressNginxSessionCookieName   = "INGRESSCOOKIE"
+	ingressNginxCanaryWeightTotal   = 100
+	ingressNginxAuthTLSVerifyClient = "on"
+
+	migrateHTTPListener  = "http"
+	migrateHTTPSListener = "https"
+)
+
+// ingressNginxTranslatedAnnotations are the ingress-nginx annotations, without their prefix, translated
+// by the migration. The other ones are reported.
+var ingressNginxTranslatedAnnotations = sets.New(
+	"rewrite-target", "use-regex",
+	"ssl-redirect", "force-ssl-redirect",
+	"auth-url", "auth-response-headers",
+	"whitelist-source-range", "allowlist-source-range", "denylist-source-range",
+	"limit-rps", "limit-rpm",
+	"enable-cors", "cors-allow-origin", "cors-allow-methods", "cors-allow-headers", "cors-expose-headers",
+	"cors-allow-credentials", "cors-max-age",
+	"proxy-body-size",
+	"affinity", "session-cookie-name", "session-cookie-max-age",
+	"auth-tls-secret", "auth-tls-verify-client",
+	"canary", "canary-weight", "canary-weight-total", "canary-by-header", "canary-by-header-value",
+	"canary-by-header-pattern", "canary-by-cookie",
+)
+
+var (
+	// ingressNginxCaptureGroup is a group of the path captured by the rewrite-target annotation.
+	ingressNginxCaptureGroup = regexp.MustCompile(`\$(\d)`)
+	// ingressNginxVariable is an NGINX variable of the rewrite-target annotation, which can't be translated.
+	ingressNginxVariable = regexp.MustCompile(`\$[^\d]`)
+	// ingressNginxSize is an NGINX size, e.g. 8m.
+	ingressNginxSize = regexp.MustCompile(`^(\d+)([kKmMgG]?)$`)
+	// corsOrigin is the validation of the CORS origins of the SecurityPolicy.
+	corsOrigin = regexp.MustCompile(`^(\*|https?:\/\/(\*|(\*\.)?(([\w-]+\.?)+)?[\w-]+)(:\d{1,5})?)$`)
+)
+
+type migrateIngressNginxOptions struct {
+	ingressClass string
+	gatewayClass string
+}
+
+func newMigrateIngressNginxCommand() *cobra.Command {
+	opts := &migrateIngressNginxOptions{}
+
+	ingressNginxCommand := &cobra.Command{
+		Use:   "ingress-nginx <file|directory|->",
+		Short: "Convert ingress-nginx Ingresses to Gateway API and Envoy Gateway resources",
+		Long: `Convert the networking.k8s.io/v1 Ingresses of ingress-nginx, along with their annotations, to a GatewayClass,
+a Gateway per namespace, HTTPRoutes, HTTPRouteFilters, SecurityPolicies, BackendTrafficPolicies,
+ClientTrafficPolicies and ReferenceGrants, printed as YAML.
+
+The Ingress settings which aren't translated, or whose translation behaves differently, are listed on the standard
+error. The Services referenced by the Ingresses with a port name are read from the input to resolve their port
+numbers.`,
+		Example: `  # Convert the Ingresses of a directory.
+  egctl x migrate ingress-nginx ./manifests > gateway.yaml
+
+  # Convert the Ingresses of the cluster, and translate the result.
+  kubectl get ingresses,services -A -o yaml | egctl x migrate ingress-nginx - > gateway.yaml
+  egctl x translate --to xds -f gateway.yaml --add-missing-resources
+	  `,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files, err := readInputFiles(args[0])
+			if err != nil {
+				return err
+			}
+			m, err := migrateIngressNginx(files, opts)
+			if err != nil {
+				return err
+			}
+			if err := writeYAMLObjects(cmd.OutOrStdout(), m.objects()); err != nil {
+				return err
+			}
+			printMigrationNotes(cmd.ErrOrStderr(), m.notes)
+			return nil
+		},
+	}
+
+	flags := ingressNginxCommand.Flags()
+	flags.StringVar(&opts.ingressClass, "ingress-class", "nginx", "Class of the Ingresses to convert, the Ingresses without class are converted too. "+
+		"The Gateways are named after it.")
+	flags.StringVar(&opts.gatewayClass, "gateway-class", "eg", "Name of the GatewayClass of the Gateways.")
+
+	return ingressNginxCommand
+}
+
+// migrationNote is an Ingress setting which isn't translated, or whose translation behaves differently.
+type migrationNote struct {
+	ingress    types.NamespacedName
+	annotation string
+	message    string
+}
+
+func (n migrationNote) String() string {
+	ingress := n.ingress.Name
+	if n.ingress.Namespace != "" {
+		ingress = n.ingress.String()
+	}
+	if n.annotation == "" {
+		return fmt.Sprintf("Ingress %s: %s", ingress, n.message)
+	}
+	return fmt.Sprintf("Ingress %s: %s%s: %s", ingress, ingressNginxAnnotationPrefix, n.annotation, n.message)
+}
+
+func printMigrationNotes(w io.Writer, notes []migrationNote) {
+	if len(notes) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Review the following settings, which aren't translated or behave differently:")
+	for _, n := range notes {
+		fmt.Fprintf(w, "  %s\n", n)
+	}
+}
+
+// ingressPathKey identifies a path of the main Ingresses, with which the canary Ingresses are merged.
+type ingressPathKey struct {
+	namespace, host, path string
+}
+
+// migratedRule is the HTTPRoute rule translated from a path of a main Ingress.
+type migratedRule struct {
+	route *gwapiv1.HTTPRoute
+	index int
+}
+
+// ingressNginxMigration holds the resources translated from the Ingresses.
+type ingressNginxMigration struct {
+	ingressClass string
+	gatewayClass string
+
+	services map[types.NamespacedName]*corev1.Service
+	notes    []migrationNote
+
+	gateways               map[string]*gwapiv1.Gateway
+	routes                 []*gwapiv1.HTTPRoute
+	filters                []*egv1a1.HTTPRouteFilter
+	securityPolicies       []*egv1a1.SecurityPolicy
+	backendTrafficPolicies []*egv1a1.BackendTrafficPolicy
+	clientTrafficPolicies  map[types.NamespacedName]*egv1a1.ClientTrafficPolicy
+	referenceGrants        map[types.NamespacedName]*gwapiv1b1.ReferenceGrant
+	mainRules              map[ingressPathKey]migratedRule
+	// defaultBackends are the namespaces whose default backend is set.
+	defaultBackends sets.Set[string]
+}
+
+// migrateIngressNginx translates the Ingresses of the input files.
+func migrateIngressNginx(files []inputFile, opts *migrateIngressNginxOptions) (*ingressNginxMigration, error) {
+	m := &ingressNginxMigration{
+		ingressClass:          opts.ingressClass,
+		gatewayClass:          opts.gatewayClass,
+		services:              make(map[types.NamespacedName]*corev1.Service),
+		gateways:              make(map[string]*gwapiv1.Gateway),
+		clientTrafficPolicies: make(map[types.NamespacedName]*egv1a1.ClientTrafficPolicy),
+		referenceGrants:       make(map[types.NamespacedName]*gwapiv1b1.ReferenceGrant),
+		mainRules:             make(map[ingressPathKey]migratedRule),
+		defaultBackends:       sets.New[string](),
+	}
+	ingresses, err := m.load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var mains, canaries []*networkingv1.Ingress
+	for _, ing := range ingresses {
+		if class := ingressClass(ing); class != "" && class != m.ingressClass {
+			m.note(ing, "", "skipped, its class is %s", class)
+			continue
+		}
+		if ingressNginxAnnotations(ing)["canary"] == "true" {
+			canaries = append(canaries, ing)
+		} else {
+			mains = append(mains, ing)
+		}
+	}
+	// The listeners are added first, for the Ingresses to use the certificates of the others
+	// as ingress-nginx does.
+	for _, ing := range mains {
+		m.addTLSListeners(ing)
+	}
+	for _, ing := range mains {
+		m.migrateIngress(ing)
+	}
+	// The canary Ingresses are merged with the main Ingresses serving the same paths.
+	for _, ing := range canaries {
+		m.migrateCanary(ing)
+	}
+	sort.SliceStable(m.notes, func(i, j int) bool {
+		if m.notes[i].ingress.Namespace != m.notes[j].ingress.Namespace {
+			return m.notes[i].ingress.Namespace < m.notes[j].ingress.Namespace
+		}
+		return m.notes[i].ingress.Name < m.notes[j].ingress.Name
+	})
+	return m, nil
+}
+
+// load returns the Ingresses of the input files, sorted by namespace and name, and indexes
+// their Services.
+func (m *ingressNginxMigration) load(files []inputFile) ([]*networkingv1.Ingress, error) {
+	var ingresses []*networkingv1.Ingress
+	for _, f := range files {
+		err := gwresource.IterYAMLBytes(f.content, func(doc []byte) error {
+			return m.loadObject(doc, &ingresses)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	sort.SliceStable(ingresses, func(i, j int) bool {
+		if ingresses[i].Namespace != ingresses[j].Namespace {
+			return ingresses[i].Namespace < ingresses[j].Namespace
+		}
+		return ingresses[i].Name < ingresses[j].Name
+	})
+	return ingresses, nil
+}
+
+// loadObject reads the Ingresses and the Services of a document, which can be a List as printed by kubectl.
+func (m *ingressNginxMigration) loadObject(doc []byte, ingresses *[]*networkingv1.Ingress) error {
+	var typeMeta metav1.TypeMeta
+	if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+		return err
+	}
+	switch gvk := typeMeta.GroupVersionKind(); {
+	case gvk == corev1.SchemeGroupVersion.WithKind("List"):
+		list := &metav1.List{}
+		if err := yaml.Unmarshal(doc, list); err != nil {
+			return err
+		}
+		for _, item := range list.Items {
+			if err := m.loadObject(item.Raw, ingresses); err != nil {
+				return err
+			}
+		}
+	case gvk == networkingv1.SchemeGroupVersion.WithKind("Ingress"):
+		ing := &networkingv1.Ingress{}
+		if err := yaml.Unmarshal(doc, ing); err != nil {
+			return err
+		}
+		*ingresses = append(*ingresses, ing)
+	case gvk == corev1.SchemeGroupVersion.WithKind("Service"):
+		svc := &corev1.Service{}
+		if err := yaml.Unmarshal(doc, svc); err != nil {
+			return err
+		}
+		m.services[types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}] = svc
+	case gvk.Kind == "Ingress":
+		obj := &metav1.PartialObjectMetadata{}
+		if err := yaml.Unmarshal(doc, obj); err != nil {
+			return err
+		}
+		m.notes = append(m.notes, migrationNote{
+			ingress: types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name},
+			message: fmt.Sprintf("skipped, %s isn't supported, convert it to networking.k8s.io/v1", typeMeta.APIVersion),
+		})
+	}
+	return nil
+}
+
+func (m *ingressNginxMigration) note(ing *networkingv1.Ingress, annotation, format string, args ...any) {
+	m.notes = append(m.notes, migrationNote{
+		ingress:    types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name},
+		annotation: annotation,
+		message:    fmt.Sprintf(format, args...),
+	})
+}
+
+// ingressClass returns the class of the Ingress, set by its spec or by the legacy annotation.
+func ingressClass(ing *networkingv1.Ingress) string {
+	if ing.Spec.IngressClassName != nil {
+		return *ing.Spec.IngressClassName
+	}
+	return ing.Annotations[ingressClassAnnotation]
+}
+
+// ingressNginxAnnotations returns the ingress-nginx annotations of the Ingress, without their prefix.
+func ingressNginxAnnotations(ing *networkingv1.Ingress) map[string]string {
+	annotations := make(map[string]string)
+	for name, value := range ing.Annotations {
+		if name, found := strings.CutPrefix(name, ingressNginxAnnotationPrefix); found {
+			annotations[name] = strings.TrimSpace(value)
+		}
+	}
+	return annotations
+}
+
+// ingressHostPaths returns the hosts of the rules of the Ingress, in their order, and their paths.
+func ingressHostPaths(ing *networkingv1.Ingress) ([]string, map[string][]networkingv1.HTTPIngressPath) {
+	var hosts []string
+	paths := make(map[string][]networkingv1.HTTPIngressPath)
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		if _, found := paths[rule.Host]; !found {
+			hosts = append(hosts, rule.Host)
+		}
+		paths[rule.Host] = append(paths[rule.Host], rule.HTTP.Paths...)
+	}
+	return hosts, paths
+}
+
+// ingressPath returns the path of an Ingress path, which defaults to /.
+func ingressPath(p networkingv1.HTTPIngressPath) string {
+	if p.Path == "" {
+		return "/"
+	}
+	return p.Path
+}
+
+// hostSlug returns the host in a form usable in the names of the resources.
+func hostSlug(host string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(host, "*", "wildcard"), ".", "-")
+}
+
+// tlsListenerName returns the name of the HTTPS listener of the host, the catch-all
+// HTTPS listener if the host is empty.
+func tlsListenerName(host string) string {
+	if host == "" {
+		return migrateHTTPSListener
+	}
+	return migrateHTTPSListener + "-" + hostSlug(host)
+}
+
+// gateway returns the Gateway of the namespace, named after the ingress class.
+func (m *ingressNginxMigration) gateway(namespace string) *gwapiv1.Gateway {
+	if gw, found := m.gateways[namespace]; found {
+		return gw
+	}
+	gw := &gwapiv1.Gateway{
+		TypeMeta:   metav1.TypeMeta{Kind: gwresource.KindGateway, APIVersion: gwapiv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: m.ingressClass, Namespace: namespace},
+		Spec: gwapiv1.GatewaySpec{
+			GatewayClassName: gwapiv1.ObjectName(m.gatewayClass),
+			Listeners: []gwapiv1.Listener{{
+				Name:     migrateHTTPListener,
+				Port:     80,
+				Protocol: gwapiv1.HTTPProtocolType,
+			}},
+		},
+	}
+	m.gateways[namespace] = gw
+	return gw
+}
+
+func findListener(gw *gwapiv1.Gateway, name string) *gwapiv1.Listener {
+	for i := range gw.Spec.Listeners {
+		if string(gw.Spec.Listeners[i].Name) == name {
+			return &gw.Spec.Listeners[i]
+		}
+	}
+	return nil
+}
+
+// addTLSListeners adds an HTTPS listener to the Gateway for each TLS host of the Ingress.
+func (m *ingressNginxMigration) addTLSListeners(ing *networkingv1.Ingress) {
+	for _, tls := range ing.Spec.TLS {
+		if tls.SecretName == "" {
+			m.note(ing, "", "TLS of the hosts %s not translated, the default certificate of ingress-nginx isn't supported",
+				strings.Join(tls.Hosts, ", "))
+			continue
+		}
+		hosts := tls.Hosts
+		if len(hosts) == 0 {
+			hosts = []string{""}
+		}
+		gw := m.gateway(ing.Namespace)
+		for _, host := range hosts {
+			name := tlsListenerName(host)
+			if l := findListener(gw, name); l != nil {
+				if secret := string(l.TLS.CertificateRefs[0].Name); secret != tls.SecretName {
+					m.note(ing, "", "TLS certificate %s of the host %s not translated, the host uses the certificate %s of another Ingress",
+						tls.SecretName, host, secret)
+				}
+				continue
+			}
+			listener := gwapiv1.Listener{
+				Name:     gwapiv1.SectionName(name),
+				Port:     443,
+				Protocol: gwapiv1.HTTPSProtocolType,
+				TLS: &gwapiv1.GatewayTLSConfig{
+					Mode:            ptr.To(gwapiv1.TLSModeTerminate),
+					CertificateRefs: []gwapiv1.SecretObjectReference{{Name: gwapiv1.ObjectName(tls.SecretName)}},
+				},
+			}
+			if host != "" {
+				listener.Hostname = ptr.To(gwapiv1.Hostname(host))
+			}
+			gw.Spec.Listeners = append(gw.Spec.Listeners, listener)
+		}
+	}
+}
+
+// tlsListener returns the HTTPS listener serving the host in the namespace, or an empty
+// string if the host has no TLS certificate.
+func (m *ingressNginxMigration) tlsListener(namespace, host string) string {
+	gw, found := m.gateways[namespace]
+	if !found {
+		return ""
+	}
+	for _, name := range []string{tlsListenerName(host), migrateHTTPSListener} {
+		if findListener(gw, name) != nil {
+			return name
+		}
+	}
+	return ""
+}
+
+func (m *ingressNginxMigration) parentRef(listener string) gwapiv1.ParentReference {
+	return gwapiv1.ParentReference{
+		Name:        gwapiv1.ObjectName(m.ingressClass),
+		SectionName: ptr.To(gwapiv1.SectionName(listener)),
+	}
+}
+
+func newMigratedHTTPRoute(namespace, name, host string) *gwapiv1.HTTPRoute {
+	route := &gwapiv1.HTTPRoute{
+		TypeMeta:   metav1.TypeMeta{Kind: gwresource.KindHTTPRoute, APIVersion: gwapiv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+	if host != "" {
+		route.Spec.Hostnames = []gwapiv1.Hostname{gwapiv1.Hostname(host)}
+	}
+	return route
+}
+
+// migrateIngress translates a main Ingress into an HTTPRoute per host, and its policies.
+func (m *ingressNginxMigration) migrateIngress(ing *networkingv1.Ingress) {
+	annotations := ingressNginxAnnotations(ing)
+	for _, name := range sets.List(sets.KeySet(annotations)) {
+		if !ingressNginxTranslatedAnnotations.Has(name) {
+			m.note(ing, name, "not translated")
+		}
+	}
+
+	// The paths are regular expressions when the rewrite-target annotation is set, as with use-regex.
+	useRegex := annotations["use-regex"] == "true" || annotations["rewrite-target"] != ""
+	rewriteTarget := annotations["rewrite-target"]
+	if ingressNginxVariable.MatchString(rewriteTarget) {
+		m.note(ing, "rewrite-target", "not translated, only the $1, $2... groups captured by the path are supported")
+		rewriteTarget = ""
+	}
+
+	// The routes of the Ingress attach to the Gateway of its namespace.
+	m.gateway(ing.Namespace)
+	hosts, paths := ingressHostPaths(ing)
+	var routes, redirects []*gwapiv1.HTTPRoute
+	for _, host := range hosts {
+		name := ing.Name
+		switch {
+		case len(hosts) == 1:
+		case host == "":
+			name += "-all-hosts"
+		default:
+			name += "-" + hostSlug(host)
+		}
+		route := newMigratedHTTPRoute(ing.Namespace, name, host)
+
+		listener := m.tlsListener(ing.Namespace, host)
+		redirect := listener != "" && (annotations["ssl-redirect"] != "false" || annotations["force-ssl-redirect"] == "true")
+		if listener == "" && annotations["force-ssl-redirect"] == "true" {
+			m.note(ing, "force-ssl-redirect", "not translated for the host %s, which has no TLS certificate", host)
+		}
+		if !redirect {
+			route.Spec.ParentRefs = append(route.Spec.ParentRefs, m.parentRef(migrateHTTPListener))
+		}
+		if listener != "" {
+			route.Spec.ParentRefs = append(route.Spec.ParentRefs, m.parentRef(listener))
+		}
+
+		for _, p := range paths[host] {
+			rule, err := m.pathRule(ing, route, p, useRegex, rewriteTarget)
+			if err != nil {
+				m.note(ing, "", "path %s of the host %s not translated, %v", ingressPath(p), host, err)
+				continue
+			}
+			route.Spec.Rules = append(route.Spec.Rules, rule)
+			m.mainRules[ingressPathKey{ing.Namespace, host, ingressPath(p)}] = migratedRule{route, len(route.Spec.Rules) - 1}
+		}
+		if len(route.Spec.Rules) == 0 {
+			continue
+		}
+		routes = append(routes, route)
+
+		if redirect {
+			redirectRoute := newMigratedHTTPRoute(ing.Namespace, name+"-ssl-redirect", host)
+			redirectRoute.Spec.ParentRefs = []gwapiv1.ParentReference{m.parentRef(migrateHTTPListener)}
+			redirectRoute.Spec.Rules = []gwapiv1.HTTPRouteRule{{
+				Filters: []gwapiv1.HTTPRouteFilter{{
+					Type: gwapiv1.HTTPRouteFilterRequestRedirect,
+					RequestRedirect: &gwapiv1.HTTPRequestRedirectFilter{
+						Scheme:     ptr.To("https"),
+						StatusCode: ptr.To(301),
+					},
+				}},
+			}}
+			redirects = append(redirects, redirectRoute)
+		}
+	}
+	if ing.Spec.DefaultBackend != nil {
+		if route := m.defaultBackendRoute(ing); route != nil {
+			routes = append(routes, route)
+		}
+	}
+	m.routes = append(m.routes, routes...)
+	m.routes = append(m.routes, redirects...)
+
+	if len(routes) > 0 {
+		m.migratePolicies(ing, annotations, routes)
+	}
+	m.migrateClientValidation(ing, annotations, hosts)
+}
+
+// pathRule returns the HTTPRoute rule of a path of the Ingress.
+func (m *ingressNginxMigration) pathRule(ing *networkingv1.Ingress, route *gwapiv1.HTTPRoute, p networkingv1.HTTPIngressPath,
+	useRegex bool, rewriteTarget string,
+) (gwapiv1.HTTPRouteRule, error) {
+	backendRef, err := m.backendRef(ing, p.Backend)
+	if err != nil {
+		return gwapiv1.HTTPRouteRule{}, err
+	}
+	rule := gwapiv1.HTTPRouteRule{
+		Matches:     []gwapiv1.HTTPRouteMatch{{Path: ingressPathMatch(p, useRegex)}},
+		BackendRefs: []gwapiv1.HTTPBackendRef{backendRef},
+	}
+	if rewriteTarget != "" {
+		rule.Filters = []gwapiv1.HTTPRouteFilter{m.rewriteFilter(route, len(route.Spec.Rules), ingressPath(p), rewriteTarget)}
+	}
+	return rule, nil
+}
+
+// ingressPathMatch returns the match of a path of the Ingress. The regular expressions of
+// ingress-nginx match the beginning of the paths, while the ones of the HTTPRoutes match
+// the whole paths.
+func ingressPathMatch(p networkingv1.HTTPIngressPath, useRegex bool) *gwapiv1.HTTPPathMatch {
+	path := ingressPath(p)
+	switch {
+	case p.PathType != nil && *p.PathType == networkingv1.PathTypeExact:
+		return &gwapiv1.HTTPPathMatch{Type: ptr.To(gwapiv1.PathMatchExact), Value: ptr.To(path)}
+	case useRegex:
+		if !strings.HasSuffix(path, "$") {
+			path += ".*"
+		}
+		return &gwapiv1.HTTPPathMatch{Type: ptr.To(gwapiv1.PathMatchRegularExpression), Value: ptr.To(path)}
+	default:
+		return &gwapiv1.HTTPPathMatch{Type: ptr.To(gwapiv1.PathMatchPathPrefix), Value: ptr.To(path)}
+	}
+}
+
+// rewriteFilter returns the filter of the rewrite-target annotation, with which ingress-nginx
+// replaces the whole path, using the groups captured by the path of the Ingress.
+func (m *ingressNginxMigration) rewriteFilter(route *gwapiv1.HTTPRoute, index int, path, target string) gwapiv1.HTTPRouteFilter {
+	if !strings.Contains(target, "$") {
+		return gwapiv1.HTTPRouteFilter{
+			Type: gwapiv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gwapiv1.HTTPURLRewriteFilter{
+				Path: &gwapiv1.HTTPPathModifier{Type: gwapiv1.FullPathHTTPPathModifier, ReplaceFullPath: ptr.To(target)},
+			},
+		}
+	}
+
+	pattern := "^" + strings.TrimPrefix(path, "^")
+	if !strings.HasSuffix(pattern, "$") {
+		pattern += ".*"
+	}
+	filter := &egv1a1.HTTPRouteFilter{
+		TypeMeta:   metav1.TypeMeta{Kind: egv1a1.KindHTTPRouteFilter, APIVersion: egv1a1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-rewrite-%d", route.Name, index), Namespace: route.Namespace},
+		Spec: egv1a1.HTTPRouteFilterSpec{
+			URLRewrite: &egv1a1.HTTPURLRewriteFilter{
+				Path: &egv1a1.HTTPPathModifier{
+					Type: egv1a1.RegexHTTPPathModifier,
+					ReplaceRegexMatch: &egv1a1.ReplaceRegexMatch{
+						Pattern:      pattern,
+						Substitution: ingressNginxCaptureGroup.ReplaceAllString(target, `\${1}`),
+					},
+				},
+			},
+		},
+	}
+	m.filters = append(m.filters, filter)
+	return gwapiv1.HTTPRouteFilter{
+		Type: gwapiv1.HTTPRouteFilterExtensionRef,
+		ExtensionRef: &gwapiv1.LocalObjectReference{
+			Group: gwapiv1.Group(egv1a1.GroupName),
+			Kind:  gwapiv1.Kind(egv1a1.KindHTTPRouteFilter),
+			Name:  gwapiv1.ObjectName(filter.Name),
+		},
+	}
+}
+
+// backendRef returns the reference to the Service of an Ingress backend, whose port name is
+// resolved with the Services of the input.
+func (m *ingressNginxMigration) backendRef(ing *networkingv1.Ingress, backend networkingv1.IngressBackend) (gwapiv1.HTTPBackendRef, error) {
+	if backend.Service == nil {
+		return gwapiv1.HTTPBackendRef{}, fmt.Errorf("only the Service backends are supported")
+	}
+	port := backend.Service.Port.Number
+	if port == 0 {
+		svc, found := m.services[types.NamespacedName{Namespace: ing.Namespace, Name: backend.Service.Name}]
+		if found {
+			for _, p := range svc.Spec.Ports {
+				if p.Name == backend.Service.Port.Name {
+					port = p.Port
+				}
+			}
+		}
+		if port == 0 {
+			return gwapiv1.HTTPBackendRef{}, fmt.Errorf("the port %s of the Service %s can't be resolved, add the Service to the input",
+				backend.Service.Port.Name, backend.Service.Name)
+		}
+	}
+	return gwapiv1.HTTPBackendRef{
+		BackendRef: gwapiv1.BackendRef{
+			BackendObjectReference: gwapiv1.BackendObjectReference{
+				Name: gwapiv1.ObjectName(backend.Service.Name),
+				Port: ptr.To(gwapiv1.PortNumber(port)),
+			},
+		},
+	}, nil
+}
+
+// defaultBackendRoute returns the HTTPRoute of the default backend of the Ingress, which
+// ingress-nginx uses for the requests of the hosts without Ingress rules.
+func (m *ingressNginxMigration) defaultBackendRoute(ing *networkingv1.Ingress) *gwapiv1.HTTPRoute {
+	if m.defaultBackends.Has(ing.Namespace) {
+		m.note(ing, "", "default backend not translated, another Ingress of the namespace sets it")
+		return nil
+	}
+	backendRef, err := m.backendRef(ing, *ing.Spec.DefaultBackend)
+	if err != nil {
+		m.note(ing, "", "default backend not translated, %v", err)
+		return nil
+	}
+	m.defaultBackends.Insert(ing.Namespace)
+
+	route := newMigratedHTTPRoute(ing.Namespace, ing.Name+"-default-backend", "")
+	route.Spec.ParentRefs = []gwapiv1.ParentReference{m.parentRef(migrateHTTPListener)}
+	if m.tlsListener(ing.Namespace, "") != "" {
+		route.Spec.ParentRefs = append(route.Spec.ParentRefs, m.parentRef(migrateHTTPSListener))
+	}
+	route.Spec.Rules = []gwapiv1.HTTPRouteRule{{
+		Matches:     []gwapiv1.HTTPRouteMatch{{Path: &gwapiv1.HTTPPathMatch{Type: ptr.To(gwapiv1.PathMatchPathPrefix), Value: ptr.To("/")}}},
+		BackendRefs: []gwapiv1.HTTPBackendRef{backendRef},
+	}}
+	return route
+}
+
+// migrateCanary merges a canary Ingress with the rules of the main Ingresses serving the same paths.
+func (m *ingressNginxMigration) migrateCanary(ing *networkingv1.Ingress) {
+	annotations := ingressNginxAnnotations(ing)
+	for _, name := range sets.List(sets.KeySet(annotations)) {
+		switch {
+		case !strings.HasPrefix(name, "canary"):
+			m.note(ing, name, "not translated, ingress-nginx ignores it on the canary Ingresses")
+		case !ingressNginxTranslatedAnnotations.Has(name):
+			m.note(ing, name, "not translated")
+		}
+	}
+
+	total := ingressNginxCanaryWeightTotal
+	if value, found := annotations["canary-weight-total"]; found {
+		var err error
+		if total, err = strconv.Atoi(value); err != nil || total <= 0 {
+			m.note(ing, "canary-weight-total", "invalid value %q", value)
+			return
+		}
+	}
+	var weight int
+	if value, found := annotations["canary-weight"]; found {
+		var err error
+		if weight, err = strconv.Atoi(value); err != nil || weight < 0 || weight > total {
+			m.note(ing, "canary-weight", "invalid value %q", value)
+			return
+		}
+	}
+
+	// The matches selecting the canary, and the ones selecting the main backends whatever the weight.
+	var canaryMatches, mainMatches []gwapiv1.HTTPHeaderMatch
+	if header := annotations["canary-by-header"]; header != "" {
+		name := gwapiv1.HTTPHeaderName(header)
+		switch {
+		case annotations["canary-by-header-value"] != "":
+			canaryMatches = append(canaryMatches, gwapiv1.HTTPHeaderMatch{Name: name, Value: annotations["canary-by-header-value"]})
+		case annotations["canary-by-header-pattern"] != "":
+			canaryMatches = append(canaryMatches, gwapiv1.HTTPHeaderMatch{
+				Type:  ptr.To(gwapiv1.HeaderMatchRegularExpression),
+				Name:  name,
+				Value: annotations["canary-by-header-pattern"],
+			})
+		default:
+			canaryMatches = append(canaryMatches, gwapiv1.HTTPHeaderMatch{Name: name, Value: "always"})
+			mainMatches = append(mainMatches, gwapiv1.HTTPHeaderMatch{Name: name, Value: "never"})
+		}
+	}
+	if cookie := annotations["canary-by-cookie"]; cookie != "" {
+		cookieMatch := func(value string) gwapiv1.HTTPHeaderMatch {
+			return gwapiv1.HTTPHeaderMatch{
+				Type:  ptr.To(gwapiv1.HeaderMatchRegularExpression),
+				Name:  "Cookie",
+				Value: fmt.Sprintf(`(.*;\s*)?%s=%s(;.*)?`, regexp.QuoteMeta(cookie), value),
+			}
+		}
+		canaryMatches = append(canaryMatches, cookieMatch("always"))
+		mainMatches = append(mainMatches, cookieMatch("never"))
+	}
+	if weight == 0 {
+		// Without weight, the requests not selecting the canary already go to the main backends.
+		mainMatches = nil
+	}
+
+	hosts, paths := ingressHostPaths(ing)
+	for _, host := range hosts {
+		for _, p := range paths[host] {
+			main, found := m.mainRules[ingressPathKey{ing.Namespace, host, ingressPath(p)}]
+			if !found {
+				m.note(ing, "canary", "path %s of the host %s not translated, no main Ingress serves it", ingressPath(p), host)
+				continue
+			}
+			backendRef, err := m.backendRef(ing, p.Backend)
+			if err != nil {
+				m.note(ing, "canary", "path %s of the host %s not translated, %v", ingressPath(p), host, err)
+				continue
+			}
+
+			rule := &main.route.Spec.Rules[main.index]
+			mainBackendRefs := slices.Clone(rule.BackendRefs)
+			if weight > 0 {
+				if len(rule.BackendRefs) > 1 {
+					m.note(ing, "canary-weight", "not translated for the path %s of the host %s, which has another canary", ingressPath(p), host)
+				} else {
+					rule.BackendRefs[0].Weight = ptr.To(int32(total - weight))
+					weighted := backendRef
+					weighted.Weight = ptr.To(int32(weight))
+					rule.BackendRefs = append(rule.BackendRefs, weighted)
+				}
+			}
+			for _, match := range canaryMatches {
+				main.route.Spec.Rules = append(main.route.Spec.Rules, headerRule(main.route.Spec.Rules[main.index], match, backendRef))
+			}
+			for _, match := range mainMatches {
+				main.route.Spec.Rules = append(main.route.Spec.Rules, headerRule(main.route.Spec.Rules[main.index], match, mainBackendRefs...))
+			}
+		}
+	}
+}
+
+// headerRule returns a copy of the rule forwarding the requests with the header to the backends.
+func headerRule(rule gwapiv1.HTTPRouteRule, header gwapiv1.HTTPHeaderMatch, backendRefs ...gwapiv1.HTTPBackendRef) gwapiv1.HTTPRouteRule {
+	headerRule := *rule.DeepCopy()
+	for i := range headerRule.Matches {
+		headerRule.Matches[i].Headers = append(headerRule.Matches[i].Headers, header)
+	}
+	headerRule.BackendRefs = backendRefs
+	return headerRule
+}
+
+// migratePolicies translates the annotations of the Ingress into the policies of its HTTPRoutes.
+func (m *ingressNginxMigration) migratePolicies(ing *networkingv1.Ingress, annotations map[string]string, routes []*gwapiv1.HTTPRoute) {
+	targetRefs := make([]gwapiv1a2.LocalPolicyTargetReferenceWithSectionName, 0, len(routes))
+	for _, route := range routes {
+		targetRefs = append(targetRefs, gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{
+			LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{
+				Group: gwapiv1.GroupName,
+				Kind:  gwresource.KindHTTPRoute,
+				Name:  gwapiv1.ObjectName(route.Name),
+			},
+		})
+	}
+
+	securityPolicy := egv1a1.SecurityPolicySpec{
+		PolicyTargetReferences: egv1a1.PolicyTargetReferences{TargetRefs: targetRefs},
+		Authorization:          m.authorization(ing, annotations),
+	}
+	if annotations["enable-cors"] == "true" {
+		securityPolicy.CORS = m.cors(ing, annotations)
+	}
+	if _, found := annotations["auth-url"]; found {
+		securityPolicy.ExtAuth = m.extAuth(ing, annotations)
+	}
+	if securityPolicy.CORS != nil || securityPolicy.Authorization != nil || securityPolicy.ExtAuth != nil {
+		m.securityPolicies = append(m.securityPolicies, &egv1a1.SecurityPolicy{
+			TypeMeta:   metav1.TypeMeta{Kind: egv1a1.KindSecurityPolicy, APIVersion: egv1a1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: ing.Name, Namespace: ing.Namespace},
+			Spec:       securityPolicy,
+		})
+	}
+
+	backendTrafficPolicy := egv1a1.BackendTrafficPolicySpec{
+		PolicyTargetReferences: egv1a1.PolicyTargetReferences{TargetRefs: targetRefs},
+		RateLimit:              m.rateLimit(ing, annotations),
+	}
+	if size, found := annotations["proxy-body-size"]; found {
+		backendTrafficPolicy.RequestBuffer = m.requestBuffer(ing, size)
+	}
+	if _, found := annotations["affinity"]; found {
+		backendTrafficPolicy.LoadBalancer = m.loadBalancer(ing, annotations)
+	}
+	if backendTrafficPolicy.RateLimit != nil || backendTrafficPolicy.RequestBuffer != nil || backendTrafficPolicy.LoadBalancer != nil {
+		m.backendTrafficPolicies = append(m.backendTrafficPolicies, &egv1a1.BackendTrafficPolicy{
+			TypeMeta:   metav1.TypeMeta{Kind: egv1a1.KindBackendTrafficPolicy, APIVersion: egv1a1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: ing.Name, Namespace: ing.Namespace},
+			Spec:       backendTrafficPolicy,
+		})
+	}
+}
+
+// splitList splits a comma separated annotation.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func annotationOrDefault(annotations map[string]string, name, defaultValue string) string {
+	if value, found := annotations[name]; found {
+		return value
+	}
+	return defaultValue
+}
+
+func (m *ingressNginxMigration) cors(ing *networkingv1.Ingress, annotations map[string]string) *egv1a1.CORS {
+	cors := &egv1a1.CORS{
+		AllowMethods:     splitList(annotationOrDefault(annotations, "cors-allow-methods", ingressNginxCORSAllowMethods)),
+		AllowHeaders:     splitList(annotationOrDefault(annotations, "cors-allow-headers", ingressNginxCORSAllowHeaders)),
+		ExposeHeaders:    splitList(annotations["cors-expose-headers"]),
+		AllowCredentials: ptr.To(annotations["cors-allow-credentials"] != "false"),
+		MaxAge:           &metav1.Duration{Duration: ingressNginxCORSMaxAge * time.Second},
+	}
+	for _, origin := range splitList(annotationOrDefault(annotations, "cors-allow-origin", "*")) {
+		if !corsOrigin.MatchString(origin) {
+			m.note(ing, "cors-allow-origin", "invalid origin %q not translated", origin)
+			continue
+		}
+		cors.AllowOrigins = append(cors.AllowOrigins, egv1a1.Origin(origin))
+	}
+	if value, found := annotations["cors-max-age"]; found {
+		if maxAge, err := strconv.Atoi(value); err != nil || maxAge < 0 {
+			m.note(ing, "cors-max-age", "invalid value %q", value)
+		} else {
+			cors.MaxAge.Duration = time.Duration(maxAge) * time.Second
+		}
+	}
+	return cors
+}
+
+// authorization returns the authorization rules of the source ranges of the Ingress.
+func (m *ingressNginxMigration) authorization(ing *networkingv1.Ingress, annotations map[string]string) *egv1a1.Authorization {
+	allowAnnotation := "allowlist-source-range"
+	if _, found := annotations[allowAnnotation]; !found {
+		allowAnnotation = "whitelist-source-range"
+	}
+	allow := m.cidrs(ing, allowAnnotation, annotations[allowAnnotation])
+	deny := m.cidrs(ing, "denylist-source-range", annotations["denylist-source-range"])
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil
+	}
+
+	authorization := &egv1a1.Authorization{DefaultAction: ptr.To(egv1a1.AuthorizationActionAllow)}
+	if len(deny) > 0 {
+		authorization.Rules = append(authorization.Rules, egv1a1.AuthorizationRule{
+			Name:      ptr.To("denylist-source-range"),
+			Action:    egv1a1.AuthorizationActionDeny,
+			Principal: egv1a1.Principal{ClientCIDRs: deny},
+		})
+	}
+	if len(allow) > 0 {
+		authorization.Rules = append(authorization.Rules, egv1a1.AuthorizationRule{
+			Name:      ptr.To(allowAnnotation),
+			Action:    egv1a1.AuthorizationActionAllow,
+			Principal: egv1a1.Principal{ClientCIDRs: allow},
+		})
+		authorization.DefaultAction = ptr.To(egv1a1.AuthorizationActionDeny)
+	}
+	return authorization
+}
+
+// cidrs returns the CIDRs of a source range annotation, where the IP addresses are CIDRs too.
+func (m *ingressNginxMigration) cidrs(ing *networkingv1.Ingress, annotation, value string) []egv1a1.CIDR {
+	var cidrs []egv1a1.CIDR
+	for _, item := range splitList(value) {
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			switch {
+			case ip == nil:
+			case ip.To4() != nil:
+				item += "/32"
+			default:
+				item += "/128"
+			}
+		}
+		if _, _, err := net.ParseCIDR(item); err != nil {
+			m.note(ing, annotation, "invalid range %q not translated", item)
+			continue
+		}
+		cidrs = append(cidrs, egv1a1.CIDR(item))
+	}
+	return cidrs
+}
+
+// extAuth returns the external authorization of the auth-url annotation, which must be the URL
+// of a Service of the cluster.
+func (m *ingressNginxMigration) extAuth(ing *networkingv1.Ingress, annotations map[string]string) *egv1a1.ExtAuth {
+	authURL, err := url.Parse(annotations["auth-url"])
+	if err != nil || authURL.Host == "" {
+		m.note(ing, "auth-url", "invalid value %q", annotations["auth-url"])
+		return nil
+	}
+	if authURL.Scheme != "http" {
+		m.note(ing, "auth-url", "not translated, only the http URLs are supported")
+		return nil
+	}
+	name, namespace, found := clusterServiceHost(authURL.Hostname(), ing.Namespace)
+	if !found {
+		m.note(ing, "auth-url", "not translated, only the URLs of the Services of the cluster are supported, e.g. http://<service>.<namespace>.svc.cluster.local/<path>")
+		return nil
+	}
+	port := 80
+	if authURL.Port() != "" {
+		if port, err = strconv.Atoi(authURL.Port()); err != nil {
+			m.note(ing, "auth-url", "invalid value %q", annotations["auth-url"])
+			return nil
+		}
+	}
+
+	backendRef := egv1a1.BackendRef{
+		BackendObjectReference: gwapiv1.BackendObjectReference{
+			Name: gwapiv1.ObjectName(name),
+			Port: ptr.To(gwapiv1.PortNumber(port)),
+		},
+	}
+	if namespace != ing.Namespace {
+		backendRef.Namespace = ptr.To(gwapiv1.Namespace(namespace))
+		m.referenceGrant(ing.Namespace, egv1a1.KindSecurityPolicy, namespace, gwresource.KindService, name)
+	}
+	service := &egv1a1.HTTPExtAuthService{
+		BackendCluster:   egv1a1.BackendCluster{BackendRefs: []egv1a1.BackendRef{backendRef}},
+		HeadersToBackend: splitList(annotations["auth-response-headers"]),
+	}
+	if path := strings.TrimSuffix(authURL.Path, "/"); path != "" {
+		service.Path = ptr.To(path)
+	}
+	m.note(ing, "auth-url", "the authorization requests are sent to the path of the URL followed by the path of the request, "+
+		"instead of the path of the URL")
+	return &egv1a1.ExtAuth{HTTP: service}
+}
+
+// clusterServiceHost returns the name and the namespace of the Service of a host of the
+// cluster, e.g. auth, auth.default.svc or auth.default.svc.cluster.local.
+func clusterServiceHost(host, namespace string) (string, string, bool) {
+	labels := strings.Split(host, ".")
+	switch {
+	case len(labels) == 1:
+		return labels[0], namespace, true
+	case len(labels) >= 3 && labels[2] == "svc":
+		return labels[0], labels[1], true
+	default:
+		return "", "", false
+	}
+}
+
+// rateLimit returns the local rate limit of the limit-rps or the limit-rpm annotation.
+func (m *ingressNginxMigration) rateLimit(ing *networkingv1.Ingress, annotations map[string]string) *egv1a1.RateLimitSpec {
+	var limit *egv1a1.RateLimitValue
+	for _, l := range []struct {
+		annotation string
+		unit       egv1a1.RateLimitUnit
+	}{
+		{"limit-rps", egv1a1.RateLimitUnitSecond},
+		{"limit-rpm", egv1a1.RateLimitUnitMinute},
+	} {
+		value, found := annotations[l.annotation]
+		if !found {
+			continue
+		}
+		requests, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || requests == 0 {
+			m.note(ing, l.annotation, "invalid value %q", value)
+			continue
+		}
+		if limit != nil {
+			m.note(ing, l.annotation, "not translated, the limit-rps annotation is translated instead")
+			continue
+		}
+		limit = &egv1a1.RateLimitValue{Requests: uint(requests), Unit: l.unit}
+		m.note(ing, l.annotation, "the limit is shared by the clients and enforced by each Envoy proxy, instead of for each client IP address")
+	}
+	if limit == nil {
+		return nil
+	}
+	return &egv1a1.RateLimitSpec{
+		Type:  egv1a1.LocalRateLimitType,
+		Local: &egv1a1.LocalRateLimit{Rules: []egv1a1.RateLimitRule{{Limit: *limit}}},
+	}
+}
+
+// requestBuffer returns the buffer limiting the size of the requests to the proxy-body-size
+// annotation, nil if the size is unlimited.
+func (m *ingressNginxMigration) requestBuffer(ing *networkingv1.Ingress, size string) *egv1a1.RequestBuffer {
+	match := ingressNginxSize.FindStringSubmatch(size)
+	if match == nil {
+		m.note(ing, "proxy-body-size", "invalid value %q", size)
+		return nil
+	}
+	value, err := strconv.ParseUint(match[1], 10, 64)
+	if err != nil {
+		m.note(ing, "proxy-body-size", "invalid value %q", size)
+		return nil
+	}
+	if value == 0 {
+		return nil
+	}
+	suffix := map[string]string{"": "", "k": "Ki", "m": "Mi", "g": "Gi"}[strings.ToLower(match[2])]
+	return &egv1a1.RequestBuffer{Limit: resource.MustParse(strconv.FormatUint(value, 10) + suffix)}
+}
+
+// loadBalancer returns the load balancer of the cookie affinity.
+func (m *ingressNginxMigration) loadBalancer(ing *networkingv1.Ingress, annotations map[string]string) *egv1a1.LoadBalancer {
+	if annotations["affinity"] != "cookie" {
+		m.note(ing, "affinity", "not translated, only the cookie affinity is supported")
+		return nil
+	}
+	// The cookie is a session cookie, as the one of ingress-nginx by default.
+	cookie := &egv1a1.Cookie{
+		Name: annotationOrDefault(annotations, "session-cookie-name", ingressNginxSessionCookieName),
+		TTL:  &metav1.Duration{},
+	}
+	if value, found := annotations["session-cookie-max-age"]; found {
+		if maxAge, err := strconv.Atoi(value); err != nil || maxAge < 0 {
+			m.note(ing, "session-cookie-max-age", "invalid value %q", value)
+		} else {
+			cookie.TTL.Duration = time.Duration(maxAge) * time.Second
+		}
+	}
+	return &egv1a1.LoadBalancer{
+		Type: egv1a1.ConsistentHashLoadBalancerType,
+		ConsistentHash: &egv1a1.ConsistentHash{
+			Type:   egv1a1.CookieConsistentHashType,
+			Cookie: cookie,
+		},
+	}
+}
+
+// migrateClientValidation translates the client certificate validation of the Ingress into
+// ClientTrafficPolicies, targeting the HTTPS listeners of its hosts.
+func (m *ingressNginxMigration) migrateClientValidation(ing *networkingv1.Ingress, annotations map[string]string, hosts []string) {
+	secret, found := annotations["auth-tls-secret"]
+	if !found {
+		return
+	}
+	var optional bool
+	switch verify := annotationOrDefault(annotations, "auth-tls-verify-client", ingressNginxAuthTLSVerifyClient); verify {
+	case "off":
+		return
+	case "on":
+	case "optional":
+		optional = true
+	case "optional_no_ca":
+		optional = true
+		m.note(ing, "auth-tls-verify-client", "the client certificates are verified with the CA certificate")
+	default:
+		m.note(ing, "auth-tls-verify-client", "invalid value %q", verify)
+		return
+	}
+	namespace, name, found := strings.Cut(secret, "/")
+	if !found {
+		namespace, name = ing.Namespace, secret
+	}
+	caCertificateRef := gwapiv1.SecretObjectReference{
+		Kind: ptr.To(gwapiv1.Kind(gwresource.KindSecret)),
+		Name: gwapiv1.ObjectName(name),
+	}
+	if namespace != ing.Namespace {
+		caCertificateRef.Namespace = ptr.To(gwapiv1.Namespace(namespace))
+	}
+	validation := &egv1a1.ClientValidationContext{
+		Optional:          optional,
+		CACertificateRefs: []gwapiv1.SecretObjectReference{caCertificateRef},
+	}
+
+	for _, host := range hosts {
+		listener := m.tlsListener(ing.Namespace, host)
+		if listener == "" {
+			m.note(ing, "auth-tls-secret", "not translated for the host %s, which has no TLS certificate", host)
+			continue
+		}
+		key := types.NamespacedName{Namespace: ing.Namespace, Name: listener}
+		if policy, found := m.clientTrafficPolicies[key]; found {
+			if !reflect.DeepEqual(policy.Spec.TLS.ClientValidation, validation) {
+				m.note(ing, "auth-tls-secret", "not translated for the host %s, whose client certificates are validated by another Ingress", host)
+			}
+			continue
+		}
+		if namespace != ing.Namespace {
+			m.referenceGrant(ing.Namespace, egv1a1.KindClientTrafficPolicy, namespace, gwresource.KindSecret, name)
+		}
+		m.clientTrafficPolicies[key] = &egv1a1.ClientTrafficPolicy{
+			TypeMeta:   metav1.TypeMeta{Kind: egv1a1.KindClientTrafficPolicy, APIVersion: egv1a1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: m.ingressClass + "-" + listener, Namespace: ing.Namespace},
+			Spec: egv1a1.ClientTrafficPolicySpec{
+				PolicyTargetReferences: egv1a1.PolicyTargetReferences{
+					TargetRefs: []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{{
+						LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{
+							Group: gwapiv1.GroupName,
+							Kind:  gwresource.KindGateway,
+							Name:  gwapiv1.ObjectName(m.ingressClass),
+						},
+						SectionName: ptr.To(gwapiv1.SectionName(listener)),
+					}},
+				},
+				TLS: &egv1a1.ClientTLSSettings{ClientValidation: validation},
+			},
+		}
+	}
+}
+
+// referenceGrant allows the policies of a namespace to reference an object of another namespace.
+func (m *ingressNginxMigration) referenceGrant(fromNamespace, fromKind, toNamespace, toKind, toName string) {
+	key := types.NamespacedName{Namespace: toNamespace, Name: "from-" + fromNamespace}
+	grant, found := m.referenceGrants[key]
+	if !found {
+		grant = &gwapiv1b1.ReferenceGrant{
+			TypeMeta:   metav1.TypeMeta{Kind: gwresource.KindReferenceGrant, APIVersion: gwapiv1b1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		}
+		m.referenceGrants[key] = grant
+	}
+	from := gwapiv1b1.ReferenceGrantFrom{
+		Group:     gwapiv1.Group(egv1a1.GroupName),
+		Kind:      gwapiv1.Kind(fromKind),
+		Namespace: gwapiv1.Namespace(fromNamespace),
+	}
+	if !slices.Contains(grant.Spec.From, from) {
+		grant.Spec.From = append(grant.Spec.From, from)
+	}
+	to := gwapiv1b1.ReferenceGrantTo{Kind: gwapiv1.Kind(toKind), Name: ptr.To(gwapiv1.ObjectName(toName))}
+	if !slices.ContainsFunc(grant.Spec.To, func(t gwapiv1b1.ReferenceGrantTo) bool { return reflect.DeepEqual(t, to) }) {
+		grant.Spec.To = append(grant.Spec.To, to)
+	}
+}
+
+// objects returns the translated resources, in the order they can be applied.
+func (m *ingressNginxMigration) objects() []client.Object {
+	if len(m.gateways) == 0 {
+		return nil
+	}
+	objects := []client.Object{&gwapiv1.GatewayClass{
+		TypeMeta:   metav1.TypeMeta{Kind: gwresource.KindGatewayClass, APIVersion: gwapiv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: m.gatewayClass},
+		Spec:       gwapiv1.GatewayClassSpec{ControllerName: egv1a1.GatewayControllerName},
+	}}
+	for _, namespace := range sets.List(sets.KeySet(m.gateways)) {
+		objects = append(objects, m.gateways[namespace])
+	}
+	for _, route := range m.routes {
+		objects = append(objects, route)
+	}
+	for _, filter := range m.filters {
+		objects = append(objects, filter)
+	}
+	for _, policy := range m.securityPolicies {
+		objects = append(objects, policy)
+	}
+	for _, policy := range m.backendTrafficPolicies {
+		objects = append(objects, policy)
+	}
+	for _, key := range sortedNamespacedNames(sets.KeySet(m.clientTrafficPolicies)) {
+		objects = append(objects, m.clientTrafficPolicies[key])
+	}
+	for _, key := range sortedNamespacedNames(sets.KeySet(m.referenceGrants)) {
+		objects = append(objects, m.referenceGrants[key])
+	}
+	return objects
+}
+
+func sortedNamespacedNames(keys sets.Set[types.NamespacedName]) []types.NamespacedName {
+	sorted := keys.UnsortedList()
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// writeYAMLObjects writes the resources as YAML documents, without their empty statuses.
+func writeYAMLObjects(w io.Writer, objects []client.Object) error {
+	for i, obj := range objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		delete(content, "status")
+		unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+		removeNullFields(content)
+		out, err := yaml.Marshal(content)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeNullFields removes the unset fields without omitempty, e.g. the group of the
+// certificate references.
+func removeNullFields(content map[string]any) {
+	for name, value := range content {
+		switch value := value.(type) {
+		case nil:
+			delete(content, name)
+		case map[string]any:
+			removeNullFields(value)
+		case []any:
+			for _, item := range value {
+				if item, ok := item.(map[string]any); ok {
+					removeNullFields(item)
+				}
+			}
+		}
+	}
+}
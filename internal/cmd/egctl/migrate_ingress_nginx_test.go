@@ -0,0 +1,159 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/wukongcloud/gateway/internal/utils/file"
+	"github.com/wukongcloud/gateway/internal/utils/test"
+	"github.com/wukongcloud/gateway/pkg/offline"
+)
+
+func TestMigrateIngressNginx(t *testing.T) {
+	in := filepath.Join("testdata", "migrate", "ingress-nginx.in.yaml")
+	out := filepath.Join("testdata", "migrate", "ingress-nginx.out.yaml")
+
+	stdout, stderr := bytes.NewBufferString(""), bytes.NewBufferString("")
+	root := newMigrateIngressNginxCommand()
+	root.SetOut(stdout)
+	root.SetErr(stderr)
+	root.SetArgs([]string{in})
+	require.NoError(t, root.ExecuteContext(context.Background()))
+
+	if test.OverrideTestData() {
+		require.NoError(t, file.Write(stdout.String(), out))
+	}
+	want, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, string(want), stdout.String())
+
+	require.Equal(t, []string{
+		"Review the following settings, which aren't translated or behave differently:",
+		"  Ingress default/api: nginx.ingress.kubernetes.io/auth-url: the authorization requests are sent to the path of the URL " +
+			"followed by the path of the request, instead of the path of the URL",
+		"  Ingress default/dashboard: skipped, its class is traefik",
+		"  Ingress default/legacy: skipped, networking.k8s.io/v1beta1 isn't supported, convert it to networking.k8s.io/v1",
+		"  Ingress default/web: nginx.ingress.kubernetes.io/server-snippet: not translated",
+		"  Ingress default/web: nginx.ingress.kubernetes.io/limit-rps: the limit is shared by the clients and enforced by each " +
+			"Envoy proxy, instead of for each client IP address",
+		"  Ingress default/web-canary: nginx.ingress.kubernetes.io/limit-rps: not translated, ingress-nginx ignores it on the canary Ingresses",
+	}, strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n"))
+}
+
+// TestMigrateIngressNginxTranslation checks that the migrated resources are accepted when
+// translated along with the Services and the Secrets of the Ingresses.
+func TestMigrateIngressNginxTranslation(t *testing.T) {
+	in, err := os.ReadFile(filepath.Join("testdata", "migrate", "ingress-nginx.in.yaml"))
+	require.NoError(t, err)
+	out, err := os.ReadFile(filepath.Join("testdata", "migrate", "ingress-nginx.out.yaml"))
+	require.NoError(t, err)
+
+	resources, err := offline.LoadResources(joinInputFiles([]inputFile{{content: in}, {content: out}}), false)
+	require.NoError(t, err)
+	result, err := translateGatewayAPI(resources, "", "")
+	require.NoError(t, err)
+	r := result.Resources
+
+	requireConditionsTrue := func(kind, name string, conditions []metav1.Condition) {
+		require.NotEmpty(t, conditions, "%s %s", kind, name)
+		for _, c := range conditions {
+			require.Equal(t, metav1.ConditionTrue, c.Status, "%s %s: %s %s", kind, name, c.Type, c.Message)
+		}
+	}
+	require.Len(t, r.Gateways, 1)
+	for _, l := range r.Gateways[0].Status.Listeners {
+		requireConditionsTrue("Gateway listener", string(l.Name), l.Conditions)
+	}
+	require.Len(t, r.HTTPRoutes, 6)
+	for _, route := range r.HTTPRoutes {
+		for _, parent := range route.Status.Parents {
+			requireConditionsTrue("HTTPRoute", route.Name, parent.Conditions)
+		}
+	}
+	require.Len(t, r.SecurityPolicies, 3)
+	for _, policy := range r.SecurityPolicies {
+		for _, ancestor := range policy.Status.Ancestors {
+			requireConditionsTrue("SecurityPolicy", policy.Name, ancestor.Conditions)
+		}
+	}
+	require.Len(t, r.BackendTrafficPolicies, 1)
+	for _, ancestor := range r.BackendTrafficPolicies[0].Status.Ancestors {
+		requireConditionsTrue("BackendTrafficPolicy", r.BackendTrafficPolicies[0].Name, ancestor.Conditions)
+	}
+	require.Len(t, r.ClientTrafficPolicies, 1)
+	for _, ancestor := range r.ClientTrafficPolicies[0].Status.Ancestors {
+		requireConditionsTrue("ClientTrafficPolicy", r.ClientTrafficPolicies[0].Name, ancestor.Conditions)
+	}
+}
+
+func TestClusterServiceHost(t *testing.T) {
+	testCases := []struct {
+		host, name, namespace string
+		found                 bool
+	}{
+		{"auth", "auth", "default", true},
+		{"auth.system.svc", "auth", "system", true},
+		{"auth.system.svc.cluster.local", "auth", "system", true},
+		{"auth.example.com", "", "", false},
+		{"auth.system", "", "", false},
+	}
+	for _, tc := range testCases {
+		name, namespace, found := clusterServiceHost(tc.host, "default")
+		require.Equal(t, tc.found, found, tc.host)
+		require.Equal(t, tc.name, name, tc.host)
+		require.Equal(t, tc.namespace, namespace, tc.host)
+	}
+}
+
+func TestMigrateIngressNginxList(t *testing.T) {
+	list := `apiVersion: v1
+kind: List
+items:
+- apiVersion: networking.k8s.io/v1
+  kind: Ingress
+  metadata:
+    name: web
+    namespace: default
+  spec:
+    ingressClassName: nginx
+    rules:
+    - host: www.example.com
+      http:
+        paths:
+        - path: /
+          pathType: Prefix
+          backend:
+            service:
+              name: web
+              port:
+                name: http
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+    namespace: default
+  spec:
+    ports:
+    - name: http
+      port: 8080
+`
+	m, err := migrateIngressNginx([]inputFile{{name: "list.yaml", content: []byte(list)}},
+		&migrateIngressNginxOptions{ingressClass: "nginx", gatewayClass: "eg"})
+	require.NoError(t, err)
+	require.Empty(t, m.notes)
+	require.Len(t, m.routes, 1)
+	require.Equal(t, gwapiv1.PortNumber(8080), *m.routes[0].Spec.Rules[0].BackendRefs[0].Port)
+}
@@ -0,0 +1,144 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/pkg/offline"
+)
+
+type openAPIOptions struct {
+	name      string
+	namespace string
+	gateway   string
+	backend   string
+	hostnames []string
+	basePath  string
+}
+
+func newOpenAPICommand() *cobra.Command {
+	opts := &openAPIOptions{}
+
+	openAPICommand := &cobra.Command{
+		Use:   "openapi <file|->",
+		Short: "Generate HTTPRoutes and policies from an OpenAPI document",
+		Long: `Generate the HTTPRoutes matching the operations of an OpenAPI 3 document, on their method and their path,
+along with the SecurityPolicies of their security requirements and the BackendTrafficPolicies of their rate limits,
+printed as YAML.
+
+The bearer, OAuth2 and OpenID Connect security schemes are translated to JWT authentication, whose JWKS is set by
+the x-envoy-gateway-jwks-uri extension of the scheme, and the API key and basic schemes to API key and basic
+authentication, whose credentials are read from the Secret named by the x-envoy-gateway-secret extension of the
+scheme, or after the scheme. The x-envoy-gateway-timeout and x-envoy-gateway-rate-limit extensions of the
+operations, or of their paths, set their request timeouts and local rate limits.
+
+The generated resources are labeled with gateway.envoyproxy.io/openapi, and generating them again from the same
+document gives the same resources.`,
+		Example: `  # Generate the resources of an API served by the Service petstore on port 8080.
+  egctl x openapi petstore.yaml --backend petstore:8080 --gateway eg
+
+  # Apply them, deleting the resources of the operations removed from the document.
+  egctl x openapi petstore.yaml --backend petstore:8080 | kubectl apply --prune -l gateway.envoyproxy.io/openapi=pet-store -f -
+	  `,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			document, err := getInputBytes(args[0])
+			if err != nil {
+				return err
+			}
+			generateOpts, err := opts.generateOptions()
+			if err != nil {
+				return err
+			}
+			resources, err := offline.GenerateFromOpenAPI(document, generateOpts)
+			if err != nil {
+				return err
+			}
+
+			var objects []client.Object
+			for _, route := range resources.HTTPRoutes {
+				objects = append(objects, route)
+			}
+			for _, sp := range resources.SecurityPolicies {
+				objects = append(objects, sp)
+			}
+			for _, btp := range resources.BackendTrafficPolicies {
+				objects = append(objects, btp)
+			}
+			return writeYAMLObjects(cmd.OutOrStdout(), objects)
+		},
+	}
+
+	flags := openAPICommand.Flags()
+	flags.StringVar(&opts.name, "name", "", "Name of the generated resources. Defaults to the title of the document.")
+	flags.StringVarP(&opts.namespace, "namespace", "n", "default", "Namespace of the generated resources.")
+	flags.StringVar(&opts.gateway, "gateway", "eg", "Gateway the HTTPRoutes attach to, as [namespace/]name.")
+	flags.StringVar(&opts.backend, "backend", "", "Backend serving the API, as Service name:port or Backend/name[:port].")
+	flags.StringSliceVar(&opts.hostnames, "hostname", nil, "Hostnames of the HTTPRoutes. Defaults to the hosts of the servers of the document.")
+	flags.StringVar(&opts.basePath, "base-path", "", "Path prepended to the paths of the document. Defaults to the path of the servers of the document.")
+	_ = openAPICommand.MarkFlagRequired("backend")
+
+	return openAPICommand
+}
+
+func (o *openAPIOptions) generateOptions() (*offline.OpenAPIOptions, error) {
+	parentRef := gwapiv1.ParentReference{Name: gwapiv1.ObjectName(o.gateway)}
+	if namespace, name, found := strings.Cut(o.gateway, "/"); found {
+		parentRef.Namespace = ptr.To(gwapiv1.Namespace(namespace))
+		parentRef.Name = gwapiv1.ObjectName(name)
+	}
+	if parentRef.Name == "" {
+		return nil, fmt.Errorf("invalid gateway %q", o.gateway)
+	}
+	backendRef, err := parseOpenAPIBackend(o.backend)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &offline.OpenAPIOptions{
+		Name:       o.name,
+		Namespace:  o.namespace,
+		ParentRefs: []gwapiv1.ParentReference{parentRef},
+		BackendRef: *backendRef,
+		BasePath:   o.basePath,
+	}
+	for _, hostname := range o.hostnames {
+		opts.Hostnames = append(opts.Hostnames, gwapiv1.Hostname(hostname))
+	}
+	return opts, nil
+}
+
+// parseOpenAPIBackend parses a backend as Service name:port, or as Backend/name with an optional port.
+func parseOpenAPIBackend(backend string) (*gwapiv1.BackendObjectReference, error) {
+	ref := &gwapiv1.BackendObjectReference{}
+	name, isBackend := strings.CutPrefix(backend, egv1a1.KindBackend+"/")
+	if isBackend {
+		ref.Group = ptr.To(gwapiv1.Group(egv1a1.GroupName))
+		ref.Kind = ptr.To(gwapiv1.Kind(egv1a1.KindBackend))
+	}
+	name, port, hasPort := strings.Cut(name, ":")
+	if name == "" || (!hasPort && !isBackend) {
+		return nil, fmt.Errorf("invalid backend %q, name:port or Backend/name[:port] is expected", backend)
+	}
+	ref.Name = gwapiv1.ObjectName(name)
+	if hasPort {
+		number, err := strconv.ParseInt(port, 10, 32)
+		if err != nil || number < 1 || number > 65535 {
+			return nil, fmt.Errorf("invalid port of the backend %q", backend)
+		}
+		ref.Port = ptr.To(gwapiv1.PortNumber(number))
+	}
+	return ref, nil
+}
@@ -0,0 +1,73 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/wukongcloud/gateway/internal/utils/file"
+	"github.com/wukongcloud/gateway/internal/utils/test"
+)
+
+func TestOpenAPI(t *testing.T) {
+	out := filepath.Join("testdata", "openapi", "petstore.out.yaml")
+
+	b := bytes.NewBufferString("")
+	root := newOpenAPICommand()
+	root.SetOut(b)
+	root.SetErr(b)
+	root.SetArgs([]string{filepath.Join("testdata", "openapi", "petstore.in.yaml"), "--backend", "petstore:8080"})
+	require.NoError(t, root.ExecuteContext(context.Background()))
+
+	if test.OverrideTestData() {
+		require.NoError(t, file.Write(b.String(), out))
+	}
+	want, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, string(want), b.String())
+}
+
+func TestParseOpenAPIBackend(t *testing.T) {
+	testCases := []struct {
+		backend string
+		want    *gwapiv1.BackendObjectReference
+	}{
+		{
+			backend: "petstore:8080",
+			want:    &gwapiv1.BackendObjectReference{Name: "petstore", Port: ptr.To(gwapiv1.PortNumber(8080))},
+		},
+		{
+			backend: "Backend/petstore",
+			want: &gwapiv1.BackendObjectReference{
+				Group: ptr.To(gwapiv1.Group("gateway.envoyproxy.io")),
+				Kind:  ptr.To(gwapiv1.Kind("Backend")),
+				Name:  "petstore",
+			},
+		},
+		{backend: "petstore"},
+		{backend: "petstore:http"},
+		{backend: ":8080"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.backend, func(t *testing.T) {
+			got, err := parseOpenAPIBackend(tc.backend)
+			if tc.want == nil {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -5,7 +5,29 @@
 
 package egctl
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// ExitError is returned by the commands whose exit status carries a meaning,
+// e.g. whether differences were found. Err is nil if there is nothing to report.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
 
 // GetRootCommand returns the root cobra command to be executed
 // by egctl main.
@@ -0,0 +1,22 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newRouteCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "route",
+		Long:  "Inspect how the routes handle the requests.",
+		Short: "Inspect how the routes handle the requests.",
+	}
+
+	c.AddCommand(newRouteSimulateCommand())
+
+	return c
+}
@@ -0,0 +1,1478 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/anypb"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/yaml"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/ir"
+	"github.com/wukongcloud/gateway/pkg/offline"
+)
+
+// The outcomes of a simulated request.
+const (
+	SimulationForwarded      = "Forwarded"
+	SimulationRedirected     = "Redirected"
+	SimulationDirectResponse = "DirectResponse"
+	SimulationDenied         = "Denied"
+	SimulationNoRoute        = "NoRoute"
+	SimulationNoListener     = "NoListener"
+)
+
+// The actions of the authorization of a simulated request, in addition to the
+// AuthorizationActions.
+const simulationAuthorizationUnknown = "Unknown"
+
+// SimulatedRequest is a synthetic request traced through the translated resources.
+type SimulatedRequest struct {
+	// Gateway is the namespace/name of the Gateway receiving the request. It is required
+	// when the Gateways of several proxies listen on the port.
+	Gateway string `json:"gateway,omitempty"`
+	// Port is the port of the Gateway listener, 443 by default when SNI is set and 80 otherwise.
+	Port uint32 `json:"port,omitempty"`
+	// SNI is the server name of the TLS handshake, the host by default on the HTTPS listeners.
+	SNI string `json:"sni,omitempty"`
+	// ClientIP is the IP address of the client, as detected by the proxy.
+	ClientIP string `json:"clientIP,omitempty"`
+	// Method is the HTTP method, GET by default.
+	Method string `json:"method,omitempty"`
+	// Host is the host of the request.
+	Host string `json:"host"`
+	// Path is the path of the request, with an optional query string. It's "/" by default.
+	Path string `json:"path,omitempty"`
+	// Headers are the headers of the request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Query are the query parameters of the request, in addition to the ones of the path.
+	Query map[string]string `json:"query,omitempty"`
+}
+
+// SimulationResult is what happens to a simulated request.
+type SimulationResult struct {
+	Request       SimulatedRequest        `json:"request"`
+	Outcome       string                  `json:"outcome"`
+	Reason        string                  `json:"reason,omitempty"`
+	Listener      *SimulatedListener      `json:"listener,omitempty"`
+	Route         *SimulatedRoute         `json:"route,omitempty"`
+	Skipped       []SkippedRoute          `json:"skipped,omitempty"`
+	Filters       []string                `json:"filters,omitempty"`
+	Policies      []SimulatedPolicy       `json:"policies,omitempty"`
+	Authorization *SimulatedAuthorization `json:"authorization,omitempty"`
+	Response      *SimulatedResponse      `json:"response,omitempty"`
+	Upstream      *SimulatedUpstream      `json:"upstream,omitempty"`
+	Backends      []SimulatedBackend      `json:"backends,omitempty"`
+}
+
+// SimulatedListener is the Gateway listener receiving a simulated request.
+type SimulatedListener struct {
+	Gateway string `json:"gateway"`
+	Name    string `json:"name"`
+	Port    uint32 `json:"port"`
+	// Hostname is the hostname of the listener matching the request, "*" for any.
+	Hostname string `json:"hostname"`
+	TLS      bool   `json:"tls,omitempty"`
+}
+
+func (l *SimulatedListener) String() string {
+	return l.Gateway + "/" + l.Name
+}
+
+// SimulatedRoute is the route rule and match handling a simulated request.
+type SimulatedRoute struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Rule      int    `json:"rule"`
+	RuleName  string `json:"ruleName,omitempty"`
+	Match     int    `json:"match"`
+	// Hostname is the hostname of the virtual host of the route.
+	Hostname string `json:"hostname"`
+	// XdsName is the name of the route in the xDS IR and the xDS route configuration.
+	XdsName string `json:"xdsName"`
+}
+
+func (r *SimulatedRoute) String() string {
+	return r.Kind + " " + r.Namespace + "/" + r.Name
+}
+
+// SkippedRoute is a route of the virtual host checked before the one handling the request.
+type SkippedRoute struct {
+	Route  string `json:"route"`
+	Reason string `json:"reason"`
+}
+
+// SimulatedPolicy is a policy applying to a simulated request.
+type SimulatedPolicy struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Target is the target of the policy the request is attached to by.
+	Target string `json:"target"`
+}
+
+func (p SimulatedPolicy) String() string {
+	return p.Kind + " " + p.Namespace + "/" + p.Name
+}
+
+// SimulatedAuthorization is the decision of the SecurityPolicy authorization rules.
+type SimulatedAuthorization struct {
+	// Action is Allow, Deny, or Unknown when it depends on something the request
+	// doesn't tell, like the claims of a JWT.
+	Action string `json:"action"`
+	// Rule is the name of the rule deciding, empty for the default action.
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SimulatedResponse is the response of the proxy itself to a simulated request.
+type SimulatedResponse struct {
+	StatusCode uint32 `json:"statusCode"`
+	Location   string `json:"location,omitempty"`
+	Body       string `json:"body,omitempty"`
+}
+
+// SimulatedUpstream is the simulated request forwarded to the backends.
+type SimulatedUpstream struct {
+	Host           string            `json:"host"`
+	Path           string            `json:"path"`
+	SetHeaders     map[string]string `json:"setHeaders,omitempty"`
+	AppendHeaders  map[string]string `json:"appendHeaders,omitempty"`
+	RemovedHeaders []string          `json:"removedHeaders,omitempty"`
+}
+
+// SimulatedBackend is a backend a simulated request may be forwarded to.
+type SimulatedBackend struct {
+	Name    string  `json:"name"`
+	Weight  uint32  `json:"weight"`
+	Percent float64 `json:"percent"`
+	// Invalid is set when the backend has no endpoints, the requests sent to it fail with a 500.
+	Invalid bool `json:"invalid,omitempty"`
+}
+
+// RouteSimulationTests is the test file of egctl x route simulate.
+type RouteSimulationTests struct {
+	Tests []RouteSimulationTest `json:"tests"`
+}
+
+// RouteSimulationTest is a simulated request and its expected outcome.
+type RouteSimulationTest struct {
+	Name    string                     `json:"name"`
+	Request SimulatedRequest           `json:"request"`
+	Expect  RouteSimulationExpectation `json:"expect"`
+}
+
+// RouteSimulationExpectation is the expected outcome of a simulated request, the unset
+// fields aren't checked.
+type RouteSimulationExpectation struct {
+	Outcome string `json:"outcome,omitempty"`
+	// Listener is the namespace/gateway/listener of the listener.
+	Listener string `json:"listener,omitempty"`
+	// Route is the kind and the namespace/name of the route, e.g. "HTTPRoute default/backend".
+	Route      string  `json:"route,omitempty"`
+	Rule       *int    `json:"rule,omitempty"`
+	Match      *int    `json:"match,omitempty"`
+	StatusCode *uint32 `json:"statusCode,omitempty"`
+	Location   string  `json:"location,omitempty"`
+	// Host and Path are the host and the path of the request forwarded to the backends.
+	Host string `json:"host,omitempty"`
+	Path string `json:"path,omitempty"`
+	// Filters are filter names or types which must run, in this order.
+	Filters []string `json:"filters,omitempty"`
+	// Policies are the kinds and the namespace/names of policies which must apply.
+	Policies []string `json:"policies,omitempty"`
+	// Backends are all the backends of the route, in order.
+	Backends []ExpectedBackend `json:"backends,omitempty"`
+}
+
+// ExpectedBackend is an expected backend of a simulated request, with an optional weight.
+type ExpectedBackend struct {
+	Name   string  `json:"name"`
+	Weight *uint32 `json:"weight,omitempty"`
+}
+
+type routeSimulateOptions struct {
+	request             SimulatedRequest
+	headers             []string
+	query               []string
+	tests               string
+	output              string
+	verbose             bool
+	addMissingResources bool
+}
+
+func newRouteSimulateCommand() *cobra.Command {
+	opts := &routeSimulateOptions{}
+
+	simulateCommand := &cobra.Command{
+		Use:   "simulate <file|directory|->",
+		Short: "Trace a request through the translated resources",
+		Long: `Translate the Gateway API and Envoy Gateway resources, and show what happens to a request: the Gateway
+listener receiving it, the route rule and match handling it, following the Gateway API precedence of the matches,
+the HTTP filters running in their order, the policies applying, the redirects, the rewrites and the backends with
+their weights.
+
+With --tests, the requests and their expected outcomes are read from a test file, and the exit status is 0 if they
+all pass, 1 if some fail and 2 if an error occurred.`,
+		Example: `  # Trace a request.
+  egctl x route simulate ./manifests --host www.example.com --path /api/v1 -H "x-version: 2"
+
+  # Trace an HTTPS request, showing the routes skipped before the matching one.
+  egctl x route simulate gateway.yaml --host www.example.com --port 443 --path /login --method POST --verbose
+
+  # Check the outcomes of the requests of a test file.
+  egctl x route simulate ./manifests --tests routes_test.yaml
+	  `,
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			simulator, err := newRouteSimulatorFromPath(args[0], opts)
+			if err != nil {
+				return &ExitError{Code: 2, Err: err}
+			}
+
+			if opts.tests != "" {
+				if opts.output != "" {
+					return &ExitError{Code: 2, Err: fmt.Errorf("--output isn't supported with --tests")}
+				}
+				tests, err := readRouteSimulationTests(opts.tests)
+				if err != nil {
+					return &ExitError{Code: 2, Err: err}
+				}
+				passed, err := runRouteSimulationTests(cmd.OutOrStdout(), simulator, tests)
+				if err != nil {
+					return &ExitError{Code: 2, Err: err}
+				}
+				if !passed {
+					return &ExitError{Code: 1}
+				}
+				return nil
+			}
+
+			req, err := opts.simulatedRequest()
+			if err != nil {
+				return &ExitError{Code: 2, Err: err}
+			}
+			result, err := simulator.simulate(req)
+			if err != nil {
+				return &ExitError{Code: 2, Err: err}
+			}
+			if err := printSimulationResult(cmd.OutOrStdout(), result, opts.output); err != nil {
+				return &ExitError{Code: 2, Err: err}
+			}
+			return nil
+		},
+	}
+
+	flags := simulateCommand.Flags()
+	flags.StringVar(&opts.request.Host, "host", "", "Host of the request.")
+	flags.StringVar(&opts.request.Path, "path", "/", "Path of the request, with an optional query string.")
+	flags.StringVarP(&opts.request.Method, "method", "X", "GET", "Method of the request.")
+	flags.StringArrayVarP(&opts.headers, "header", "H", nil, "Header of the request, as 'name: value'. Can be repeated.")
+	flags.StringArrayVarP(&opts.query, "query", "", nil, "Query parameter of the request, as 'name=value'. Can be repeated.")
+	flags.StringVar(&opts.request.ClientIP, "client-ip", "", "IP address of the client.")
+	flags.StringVar(&opts.request.SNI, "sni", "", "Server name of the TLS handshake, the host by default on the HTTPS listeners.")
+	flags.Uint32Var(&opts.request.Port, "port", 0, "Port of the Gateway listener, 443 by default when --sni is set and 80 otherwise.")
+	flags.StringVar(&opts.request.Gateway, "gateway", "", "Namespace/name of the Gateway receiving the request, required when the Gateways of several proxies listen on the port.")
+	flags.StringVar(&opts.tests, "tests", "", "Test file of requests and their expected outcomes.")
+	flags.StringVarP(&opts.output, "output", "o", "", "One of 'yaml' or 'json'. Prints a human readable trace if unset.")
+	flags.BoolVarP(&opts.verbose, "verbose", "v", false, "Show the routes of the virtual host skipped before the matching one.")
+	flags.BoolVarP(&opts.addMissingResources, "add-missing-resources", "", false, "Provides dummy resources if missed")
+
+	return simulateCommand
+}
+
+// simulatedRequest returns the request of the flags.
+func (o *routeSimulateOptions) simulatedRequest() (SimulatedRequest, error) {
+	req := o.request
+	if req.Host == "" {
+		return req, fmt.Errorf("--host is required")
+	}
+	for _, h := range o.headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return req, fmt.Errorf("invalid header %q, expected 'name: value'", h)
+		}
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		req.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	for _, q := range o.query {
+		name, value, ok := strings.Cut(q, "=")
+		if !ok {
+			return req, fmt.Errorf("invalid query parameter %q, expected 'name=value'", q)
+		}
+		if req.Query == nil {
+			req.Query = make(map[string]string)
+		}
+		req.Query[name] = value
+	}
+	return req, nil
+}
+
+// routeSimulator traces the requests through a translation result.
+type routeSimulator struct {
+	result  *offline.Result
+	verbose bool
+	// ports are the ports of the Gateway listeners, by namespace/gateway/listener.
+	ports map[string]uint32
+	// regexes caches the compiled regexes of the route matches, nil if invalid.
+	regexes map[string]*regexp.Regexp
+}
+
+func newRouteSimulatorFromPath(path string, opts *routeSimulateOptions) (*routeSimulator, error) {
+	switch opts.output {
+	case "", yamlOutput, jsonOutput:
+	default:
+		return nil, fmt.Errorf("invalid output %q, valid options: yaml/json", opts.output)
+	}
+
+	files, err := readInputFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read input: %w", err)
+	}
+	resources, err := resource.LoadResourcesFromYAMLBytes(joinInputFiles(files), opts.addMissingResources)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal input: %w", err)
+	}
+	// The translation is best-effort, the routes failing the xDS translation are
+	// simulated from the xDS IR.
+	result, _ := translateGatewayAPI(resources, "", "")
+	if result == nil {
+		return nil, fmt.Errorf("unable to translate the resources")
+	}
+	return newRouteSimulator(result, opts.verbose), nil
+}
+
+func newRouteSimulator(result *offline.Result, verbose bool) *routeSimulator {
+	s := &routeSimulator{
+		result:  result,
+		verbose: verbose,
+		ports:   make(map[string]uint32),
+		regexes: make(map[string]*regexp.Regexp),
+	}
+	for _, gw := range result.Resources.Gateways {
+		for _, l := range gw.Spec.Listeners {
+			s.ports[gw.Namespace+"/"+gw.Name+"/"+string(l.Name)] = uint32(l.Port)
+		}
+	}
+	return s
+}
+
+// simulatedListener returns the Gateway listener of an HTTP listener of the xDS IR.
+func (s *routeSimulator) simulatedListener(l *ir.HTTPListener) *SimulatedListener {
+	listener := &SimulatedListener{Name: l.Name, Port: l.Port, TLS: l.TLS != nil}
+	if m := l.Metadata; m != nil {
+		listener.Gateway = m.Namespace + "/" + m.Name
+		listener.Name = m.SectionName
+		if port, ok := s.ports[listener.Gateway+"/"+listener.Name]; ok {
+			listener.Port = port
+		}
+	}
+	return listener
+}
+
+// simulate traces the request through the translation result.
+func (s *routeSimulator) simulate(req SimulatedRequest) (*SimulationResult, error) {
+	if req.Host == "" {
+		return nil, fmt.Errorf("the host of the request is required")
+	}
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	if req.Path == "" {
+		req.Path = "/"
+	}
+	if req.Port == 0 {
+		req.Port = 80
+		if req.SNI != "" {
+			req.Port = 443
+		}
+	}
+	if req.ClientIP != "" {
+		if _, err := netip.ParseAddr(req.ClientIP); err != nil {
+			return nil, fmt.Errorf("invalid client IP %q: %w", req.ClientIP, err)
+		}
+	}
+
+	path, rawQuery, _ := strings.Cut(req.Path, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query string %q: %w", rawQuery, err)
+	}
+	for name, value := range req.Query {
+		query.Set(name, value)
+	}
+	host := strings.ToLower(req.Host)
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		host = h
+	}
+
+	result := &SimulationResult{Request: req}
+
+	// The listeners of the port, of the proxy of the Gateway.
+	irKey, listeners, err := s.portListeners(req.Gateway, req.Port)
+	if err != nil {
+		return nil, err
+	}
+	if len(listeners) == 0 {
+		result.Outcome = SimulationNoListener
+		result.Reason = fmt.Sprintf("no HTTP listener on port %d", req.Port)
+		if req.Gateway != "" {
+			result.Reason += " of Gateway " + req.Gateway
+		}
+		return result, nil
+	}
+
+	// The HTTPS listeners are selected by the SNI of the TLS handshake, and the HTTP ones
+	// by the host of the request.
+	tls := slices.ContainsFunc(listeners, func(l *ir.HTTPListener) bool { return l.TLS != nil })
+	serverName := host
+	if tls && req.SNI != "" {
+		serverName = strings.ToLower(req.SNI)
+	}
+	listener, listenerHostname := selectListener(listeners, serverName)
+	if listener == nil {
+		if tls {
+			result.Outcome = SimulationNoListener
+			result.Reason = fmt.Sprintf("no HTTPS listener on port %d matches the SNI %s", req.Port, serverName)
+			return result, nil
+		}
+		result.Outcome = SimulationNoRoute
+		result.Reason = fmt.Sprintf("no listener on port %d matches the host %s", req.Port, host)
+		result.Response = &SimulatedResponse{StatusCode: 404}
+		return result, nil
+	}
+	result.Listener = s.simulatedListener(listener)
+	result.Listener.Hostname = listenerHostname
+
+	// The virtual host is the most specific route hostname matching the host.
+	vhost := ""
+	bestScore := -1
+	for _, r := range listener.Routes {
+		if score := hostnameMatchScore(r.Hostname, host); score > bestScore {
+			vhost, bestScore = r.Hostname, score
+		}
+	}
+	if bestScore < 0 {
+		result.Outcome = SimulationNoRoute
+		result.Reason = fmt.Sprintf("no route of listener %s matches the host %s", result.Listener, host)
+		result.Response = &SimulatedResponse{StatusCode: 404}
+		return result, nil
+	}
+
+	scheme := "http"
+	if listener.TLS != nil {
+		scheme = "https"
+	}
+	headers := map[string]string{
+		":method":    strings.ToUpper(req.Method),
+		":authority": req.Host,
+		":path":      req.Path,
+		":scheme":    scheme,
+	}
+	for name, value := range req.Headers {
+		headers[strings.ToLower(name)] = value
+	}
+
+	// The routes of the virtual host are checked in order, which is the Gateway API precedence
+	// of their matches unless the listener preserves the order of the routes.
+	var route *ir.HTTPRoute
+	for _, r := range listener.Routes {
+		if r.Hostname != vhost {
+			continue
+		}
+		reason := s.routeMismatch(r, path, headers, query)
+		if reason == "" {
+			route = r
+			break
+		}
+		if s.verbose {
+			result.Skipped = append(result.Skipped, SkippedRoute{Route: r.Name, Reason: reason})
+		}
+	}
+	if route == nil {
+		result.Outcome = SimulationNoRoute
+		result.Reason = fmt.Sprintf("no route of virtual host %s matches the request", vhost)
+		result.Response = &SimulatedResponse{StatusCode: 404}
+		return result, nil
+	}
+	result.Route = simulatedRoute(route, vhost)
+
+	if xds := s.result.Xds[irKey]; xds != nil {
+		result.Filters = routeFilters(xds, listener, route.Name)
+	}
+	result.Policies = s.routePolicies(result.Listener, result.Route)
+
+	// The authorization filter runs before the router filter redirecting, responding or
+	// forwarding the request.
+	if route.Security != nil && route.Security.Authorization != nil {
+		result.Authorization = evaluateAuthorization(route.Security.Authorization, headers, req.ClientIP)
+		if result.Authorization.Action == string(egv1a1.AuthorizationActionDeny) {
+			result.Outcome = SimulationDenied
+			result.Response = &SimulatedResponse{StatusCode: 403, Body: "RBAC: access denied"}
+			return result, nil
+		}
+	}
+
+	switch {
+	case route.DirectResponse != nil:
+		result.Outcome = SimulationDirectResponse
+		result.Response = &SimulatedResponse{
+			StatusCode: ptr.Deref(route.DirectResponse.StatusCode, 200),
+			Body:       ptr.Deref(route.DirectResponse.Body, ""),
+		}
+	case route.Redirect != nil:
+		result.Outcome = SimulationRedirected
+		result.Response = &SimulatedResponse{
+			StatusCode: uint32(ptr.Deref(route.Redirect.StatusCode, 302)),
+			Location:   redirectLocation(route, scheme, host, req.Port, path, rawQuery),
+		}
+	default:
+		result.Outcome = SimulationForwarded
+		result.Upstream = upstreamRequest(route, host, path, rawQuery, headers)
+		result.Backends = s.routeBackends(route, result.Route)
+	}
+	return result, nil
+}
+
+// portListeners returns the HTTP listeners of the port of the Gateway, or of the only proxy
+// listening on the port when gateway is unset.
+func (s *routeSimulator) portListeners(gateway string, port uint32) (string, []*ir.HTTPListener, error) {
+	keys := make([]string, 0, len(s.result.XdsIR))
+	for key := range s.result.XdsIR {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var (
+		matchedKeys []string
+		gateways    []string
+		listeners   = make(map[string][]*ir.HTTPListener)
+	)
+	for _, key := range keys {
+		var keyGateways []string
+		for _, l := range s.result.XdsIR[key].HTTP {
+			sl := s.simulatedListener(l)
+			if !slices.Contains(keyGateways, sl.Gateway) {
+				keyGateways = append(keyGateways, sl.Gateway)
+			}
+			if sl.Port == port {
+				listeners[key] = append(listeners[key], l)
+			}
+		}
+		if gateway != "" && !slices.Contains(keyGateways, gateway) {
+			continue
+		}
+		if len(listeners[key]) > 0 {
+			matchedKeys = append(matchedKeys, key)
+			gateways = append(gateways, keyGateways...)
+		}
+	}
+
+	switch len(matchedKeys) {
+	case 0:
+		return "", nil, nil
+	case 1:
+		return matchedKeys[0], listeners[matchedKeys[0]], nil
+	default:
+		return "", nil, fmt.Errorf("several gateways listen on port %d %v, select one with --gateway", port, gateways)
+	}
+}
+
+// selectListener returns the listener whose hostname matches the name most specifically,
+// along with that hostname.
+func selectListener(listeners []*ir.HTTPListener, name string) (*ir.HTTPListener, string) {
+	var (
+		selected *ir.HTTPListener
+		hostname string
+		best     = -1
+	)
+	for _, l := range listeners {
+		hostnames := l.Hostnames
+		if len(hostnames) == 0 {
+			hostnames = []string{"*"}
+		}
+		for _, h := range hostnames {
+			if score := hostnameMatchScore(h, name); score > best {
+				selected, hostname, best = l, h, score
+			}
+		}
+	}
+	return selected, hostname
+}
+
+// hostnameMatchScore returns how specifically a hostname, which may be a wildcard, matches
+// a host: exact hostnames first, then the longest wildcards, then "*". It returns -1 if
+// the hostname doesn't match.
+func hostnameMatchScore(hostname, host string) int {
+	hostname = strings.ToLower(hostname)
+	switch {
+	case hostname == "" || hostname == "*":
+		return 0
+	case strings.HasPrefix(hostname, "*."):
+		suffix := hostname[1:]
+		if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+			return len(hostname)
+		}
+		return -1
+	case hostname == host:
+		return math.MaxInt32
+	default:
+		return -1
+	}
+}
+
+// routeMismatch returns why the route doesn't match the request, or "" if it does.
+func (s *routeSimulator) routeMismatch(route *ir.HTTPRoute, path string, headers map[string]string, query url.Values) string {
+	if m := route.PathMatch; m != nil && !s.matchPath(m, path) {
+		return fmt.Sprintf("path %s doesn't match %s", path, describeStringMatch(m))
+	}
+	for _, m := range route.HeaderMatches {
+		value, ok := headers[strings.ToLower(m.Name)]
+		if !ok {
+			return fmt.Sprintf("header %s is missing", m.Name)
+		}
+		if !s.matchString(m, value) {
+			return fmt.Sprintf("header %s %q doesn't match %s", m.Name, value, describeStringMatch(m))
+		}
+	}
+	for _, m := range route.QueryParamMatches {
+		if !query.Has(m.Name) {
+			return fmt.Sprintf("query parameter %s is missing", m.Name)
+		}
+		if value := query.Get(m.Name); !s.matchString(m, value) {
+			return fmt.Sprintf("query parameter %s %q doesn't match %s", m.Name, value, describeStringMatch(m))
+		}
+	}
+	return ""
+}
+
+// matchPath matches a path like the xDS route matchers: the prefixes match whole path segments.
+func (s *routeSimulator) matchPath(m *ir.StringMatch, path string) bool {
+	switch {
+	case m.Exact != nil:
+		return path == *m.Exact
+	case m.Prefix != nil:
+		prefix := strings.TrimSuffix(*m.Prefix, "/")
+		return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+	case m.SafeRegex != nil:
+		return s.matchRegex(*m.SafeRegex, path)
+	default:
+		return true
+	}
+}
+
+// matchString matches a header or query parameter value, a match without a value only
+// requires the header or query parameter to be present.
+func (s *routeSimulator) matchString(m *ir.StringMatch, value string) bool {
+	switch {
+	case m.Exact != nil:
+		return value == *m.Exact
+	case m.Prefix != nil:
+		return strings.HasPrefix(value, *m.Prefix)
+	case m.Suffix != nil:
+		return strings.HasSuffix(value, *m.Suffix)
+	case m.SafeRegex != nil:
+		return s.matchRegex(*m.SafeRegex, value)
+	default:
+		return true
+	}
+}
+
+// matchRegex fully matches a value like the RE2 matchers of Envoy. The regexes are only
+// compiled once, since the same routes are matched by every simulated request.
+func (s *routeSimulator) matchRegex(regex, value string) bool {
+	re, ok := s.regexes[regex]
+	if !ok {
+		re, _ = regexp.Compile("^(?:" + regex + ")$")
+		s.regexes[regex] = re
+	}
+	return re != nil && re.MatchString(value)
+}
+
+func describeStringMatch(m *ir.StringMatch) string {
+	switch {
+	case m.Exact != nil:
+		return fmt.Sprintf("exact %q", *m.Exact)
+	case m.Prefix != nil:
+		return fmt.Sprintf("prefix %q", *m.Prefix)
+	case m.Suffix != nil:
+		return fmt.Sprintf("suffix %q", *m.Suffix)
+	case m.SafeRegex != nil:
+		return fmt.Sprintf("regex %q", *m.SafeRegex)
+	default:
+		return "any value"
+	}
+}
+
+func simulatedRoute(route *ir.HTTPRoute, vhost string) *SimulatedRoute {
+	r := &SimulatedRoute{Hostname: vhost, XdsName: route.Name}
+	if ref, ok := parseIRRouteName(route.Name); ok {
+		r.Kind, r.Namespace, r.Name = ref.kind, ref.namespace, ref.name
+		r.Rule, r.Match = ref.rule, ref.match
+	}
+	if m := route.Metadata; m != nil {
+		if m.Kind != "" {
+			r.Kind = m.Kind
+		}
+		r.Namespace, r.Name, r.RuleName = m.Namespace, m.Name, m.SectionName
+	}
+	return r
+}
+
+// routeScopedFilters are the HTTP filters added to the filter chain once for all the routes,
+// which only act on the routes configuring them.
+var routeScopedFilters = []egv1a1.EnvoyFilter{
+	egv1a1.EnvoyFilterHealthCheck,
+	egv1a1.EnvoyFilterFault,
+	egv1a1.EnvoyFilterCORS,
+	egv1a1.EnvoyFilterJWTAuthn,
+	egv1a1.EnvoyFilterRBAC,
+	egv1a1.EnvoyFilterLocalRateLimit,
+	egv1a1.EnvoyFilterRateLimit,
+}
+
+// routeFilters returns the HTTP filters of the xDS listener running for the route, in the
+// order of the filter chain, which follows the FilterOrder of the EnvoyProxy.
+func routeFilters(xds *offline.XdsResources, listener *ir.HTTPListener, routeName string) []string {
+	hcm := findHCM(xds, listener)
+	if hcm == nil {
+		return nil
+	}
+	vhost, route := findXdsRoute(xds, hcm.GetRds().GetRouteConfigName(), routeName)
+	if route == nil {
+		return nil
+	}
+	perFilterConfig := make(map[string]*anypb.Any)
+	for name, config := range vhost.GetTypedPerFilterConfig() {
+		perFilterConfig[name] = config
+	}
+	for name, config := range route.GetTypedPerFilterConfig() {
+		perFilterConfig[name] = config
+	}
+
+	var filters []string
+	for _, f := range hcm.HttpFilters {
+		config, configured := perFilterConfig[f.Name]
+		if configured {
+			filterConfig := &routev3.FilterConfig{}
+			if config.MessageIs(filterConfig) && config.UnmarshalTo(filterConfig) == nil && filterConfig.Disabled {
+				continue
+			}
+		}
+
+		var active bool
+		switch {
+		case configured, f.Name == string(egv1a1.EnvoyFilterRouter):
+			active = true
+		case f.Name == string(egv1a1.EnvoyFilterRateLimit):
+			active = len(route.GetRoute().GetRateLimits()) > 0
+		case f.Disabled:
+			active = false
+		default:
+			active = !slices.ContainsFunc(routeScopedFilters, func(t egv1a1.EnvoyFilter) bool {
+				return strings.HasPrefix(f.Name, string(t))
+			})
+		}
+		if active {
+			filters = append(filters, f.Name)
+		}
+	}
+	return filters
+}
+
+// findHCM returns the HTTP connection manager of the xDS listener of an HTTP listener of the
+// xDS IR: the filter chain named after the HTTPS listeners, or the default filter chain
+// shared by the HTTP listeners of the port.
+func findHCM(xds *offline.XdsResources, listener *ir.HTTPListener) *hcmv3.HttpConnectionManager {
+	for _, r := range xds.XdsResources[resourcev3.ListenerType] {
+		l, ok := r.(*listenerv3.Listener)
+		if !ok || l.GetAddress().GetSocketAddress().GetPortValue() != listener.Port ||
+			l.GetAddress().GetSocketAddress().GetProtocol() != corev3.SocketAddress_TCP {
+			continue
+		}
+		chain := l.DefaultFilterChain
+		for _, c := range l.FilterChains {
+			if c.Name == listener.Name {
+				chain = c
+			}
+		}
+		for _, f := range chain.GetFilters() {
+			if f.Name != wellknown.HTTPConnectionManager {
+				continue
+			}
+			hcm := &hcmv3.HttpConnectionManager{}
+			if err := f.GetTypedConfig().UnmarshalTo(hcm); err == nil {
+				return hcm
+			}
+		}
+	}
+	return nil
+}
+
+func findXdsRoute(xds *offline.XdsResources, routeConfigName, routeName string) (*routev3.VirtualHost, *routev3.Route) {
+	for _, r := range xds.XdsResources[resourcev3.RouteType] {
+		rc, ok := r.(*routev3.RouteConfiguration)
+		if !ok || rc.Name != routeConfigName {
+			continue
+		}
+		for _, vhost := range rc.VirtualHosts {
+			for _, route := range vhost.Routes {
+				if route.Name == routeName {
+					return vhost, route
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Policy attachment levels, the most specific policy of a kind applies.
+const (
+	policyLevelGateway = iota
+	policyLevelListener
+	policyLevelRoute
+	policyLevelRouteRule
+)
+
+// routePolicies returns the policies applying to the route and the listener.
+func (s *routeSimulator) routePolicies(listener *SimulatedListener, route *SimulatedRoute) []SimulatedPolicy {
+	gwNamespace, gwName, _ := strings.Cut(listener.Gateway, "/")
+	return effectivePolicies(attachedPolicies(s.result.Resources), policyScope{
+		gwNamespace:    gwNamespace,
+		gwName:         gwName,
+		listener:       listener.Name,
+		routeKind:      route.Kind,
+		routeNamespace: route.Namespace,
+		routeName:      route.Name,
+		rule:           route.RuleName,
+	})
+}
+
+// policyScope selects the policies applying to the requests of a route rule attached to a listener
+// of a Gateway. An empty listener or rule only selects the policies of the whole Gateway or route.
+type policyScope struct {
+	gwNamespace, gwName, listener        string
+	routeKind, routeNamespace, routeName string
+	rule                                 string
+}
+
+// effectivePolicies returns the policies applying in the scope: the most specific policy of each kind.
+func effectivePolicies(policies []attachedPolicy, scope policyScope) []SimulatedPolicy {
+	gwNamespace, gwName := scope.gwNamespace, scope.gwName
+
+	type candidate struct {
+		policy attachedPolicy
+		level  int
+		target string
+	}
+	candidates := make(map[string][]candidate)
+	var kinds []string
+	for _, p := range policies {
+		if !policyAcceptedFor(p, gwNamespace, gwName) {
+			continue
+		}
+		best := candidate{policy: p, level: -1}
+		for _, ref := range p.targetRefs {
+			level := -1
+			switch {
+			case ref.Kind == resource.KindGateway && p.obj.GetNamespace() == gwNamespace && string(ref.Name) == gwName:
+				level = policyLevelGateway
+				if ref.SectionName != nil {
+					level = policyLevelListener
+					if string(*ref.SectionName) != scope.listener {
+						continue
+					}
+				}
+			case p.kind != resource.KindClientTrafficPolicy && string(ref.Kind) == scope.routeKind &&
+				p.obj.GetNamespace() == scope.routeNamespace && string(ref.Name) == scope.routeName:
+				level = policyLevelRoute
+				if ref.SectionName != nil {
+					level = policyLevelRouteRule
+					if string(*ref.SectionName) != scope.rule {
+						continue
+					}
+				}
+			}
+			if level > best.level {
+				target := string(ref.Kind) + " " + p.obj.GetNamespace() + "/" + string(ref.Name)
+				if ref.SectionName != nil {
+					target += "/" + string(*ref.SectionName)
+				}
+				best.level, best.target = level, target
+			}
+		}
+		if best.level < 0 {
+			continue
+		}
+		if _, ok := candidates[p.kind]; !ok {
+			kinds = append(kinds, p.kind)
+		}
+		candidates[p.kind] = append(candidates[p.kind], best)
+	}
+
+	var applying []SimulatedPolicy
+	for _, kind := range kinds {
+		// The most specific policy applies, the oldest one among the equally specific ones.
+		c := candidates[kind]
+		sort.SliceStable(c, func(i, j int) bool {
+			if c[i].level != c[j].level {
+				return c[i].level > c[j].level
+			}
+			ti, tj := c[i].policy.obj.GetCreationTimestamp(), c[j].policy.obj.GetCreationTimestamp()
+			if !ti.Equal(&tj) {
+				return ti.Before(&tj)
+			}
+			return c[i].policy.obj.GetNamespace()+"/"+c[i].policy.obj.GetName() <
+				c[j].policy.obj.GetNamespace()+"/"+c[j].policy.obj.GetName()
+		})
+		applied := []candidate{c[0]}
+		// A BackendTrafficPolicy of a route with a merge type is merged into the one of its
+		// Gateway.
+		if btp, ok := c[0].policy.obj.(*egv1a1.BackendTrafficPolicy); ok && btp.Spec.MergeType != nil &&
+			c[0].level >= policyLevelRoute {
+			for _, other := range c[1:] {
+				if other.level < policyLevelRoute {
+					applied = append(applied, other)
+					break
+				}
+			}
+		}
+		for _, a := range applied {
+			applying = append(applying, SimulatedPolicy{
+				Kind:      a.policy.kind,
+				Namespace: a.policy.obj.GetNamespace(),
+				Name:      a.policy.obj.GetName(),
+				Target:    a.target,
+			})
+		}
+	}
+	return applying
+}
+
+// policyAcceptedFor returns false if the policy isn't accepted for the Gateway.
+func policyAcceptedFor(p attachedPolicy, gwNamespace, gwName string) bool {
+	for _, ancestor := range p.status.Ancestors {
+		ref := ancestor.AncestorRef
+		if ref.Kind != nil && *ref.Kind != resource.KindGateway || string(ref.Name) != gwName ||
+			string(ptr.Deref(ref.Namespace, gwapiv1.Namespace(p.obj.GetNamespace()))) != gwNamespace {
+			continue
+		}
+		if cond := meta.FindStatusCondition(ancestor.Conditions, string(gwapiv1a2.PolicyConditionAccepted)); cond != nil &&
+			cond.Status == metav1.ConditionFalse {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluateAuthorization returns the decision of the authorization rules for the request,
+// the first matching rule deciding.
+func evaluateAuthorization(authz *ir.Authorization, headers map[string]string, clientIP string) *SimulatedAuthorization {
+	for _, rule := range authz.Rules {
+		matched, unknown := authorizationRuleMatches(rule, headers, clientIP)
+		if unknown != "" {
+			return &SimulatedAuthorization{Action: simulationAuthorizationUnknown, Rule: rule.Name, Reason: unknown}
+		}
+		if matched {
+			return &SimulatedAuthorization{Action: string(rule.Action), Rule: rule.Name}
+		}
+	}
+	return &SimulatedAuthorization{Action: string(authz.DefaultAction)}
+}
+
+// authorizationRuleMatches returns whether the rule matches the request, or what the
+// match depends on when the request doesn't tell.
+func authorizationRuleMatches(rule *ir.AuthorizationRule, headers map[string]string, clientIP string) (bool, string) {
+	if rule.Operation != nil && !slices.ContainsFunc(rule.Operation.Methods, func(m gwapiv1.HTTPMethod) bool {
+		return strings.EqualFold(string(m), headers[":method"])
+	}) {
+		return false, ""
+	}
+	for _, h := range rule.Principal.Headers {
+		if !slices.Contains(h.Values, headers[strings.ToLower(h.Name)]) {
+			return false, ""
+		}
+	}
+	if len(rule.Principal.ClientCIDRs) > 0 {
+		if clientIP == "" {
+			return false, "the client IP, set it with --client-ip"
+		}
+		ip, _ := netip.ParseAddr(clientIP)
+		if !slices.ContainsFunc(rule.Principal.ClientCIDRs, func(c *ir.CIDRMatch) bool {
+			prefix, err := netip.ParsePrefix(c.CIDR)
+			return err == nil && prefix.Contains(ip)
+		}) {
+			return false, ""
+		}
+	}
+	if rule.Principal.JWT != nil {
+		return false, "the claims and scopes of the JWT"
+	}
+	return true, ""
+}
+
+// redirectLocation returns the location of the redirect of the route.
+func redirectLocation(route *ir.HTTPRoute, scheme, host string, port uint32, path, rawQuery string) string {
+	r := route.Redirect
+	if r.Scheme != nil && *r.Scheme != scheme {
+		scheme = *r.Scheme
+		// The port of the request isn't kept when the scheme changes.
+		port = 0
+	}
+	if r.Hostname != nil {
+		host = *r.Hostname
+	}
+	if r.Port != nil {
+		port = *r.Port
+	}
+	if r.Path != nil {
+		path = modifyPath(r.Path.FullReplace, r.Path.PrefixMatchReplace, route.PathMatch, path)
+	}
+
+	location := scheme + "://" + host
+	if port != 0 && !(scheme == "http" && port == 80) && !(scheme == "https" && port == 443) {
+		location += ":" + strconv.Itoa(int(port))
+	}
+	location += path
+	if rawQuery != "" {
+		location += "?" + rawQuery
+	}
+	return location
+}
+
+// modifyPath replaces the full path, or the prefix matched by the path match.
+func modifyPath(fullReplace, prefixMatchReplace *string, pathMatch *ir.StringMatch, path string) string {
+	switch {
+	case fullReplace != nil:
+		return *fullReplace
+	case prefixMatchReplace != nil && pathMatch != nil && pathMatch.Prefix != nil:
+		rest := strings.TrimPrefix(path, strings.TrimSuffix(*pathMatch.Prefix, "/"))
+		replaced := strings.TrimSuffix(*prefixMatchReplace, "/") + rest
+		if replaced == "" {
+			return "/"
+		}
+		return replaced
+	default:
+		return path
+	}
+}
+
+// envoyRegexSubstitution matches the capture group references of the Envoy regex substitutions.
+var envoyRegexSubstitution = regexp.MustCompile(`\\(\d+)`)
+
+// upstreamRequest returns the request forwarded to the backends, once rewritten.
+func upstreamRequest(route *ir.HTTPRoute, host, path, rawQuery string, headers map[string]string) *SimulatedUpstream {
+	upstream := &SimulatedUpstream{Host: host, Path: path}
+	if rw := route.URLRewrite; rw != nil {
+		if p := rw.Path; p != nil {
+			upstream.Path = modifyPath(p.FullReplace, p.PrefixMatchReplace, route.PathMatch, path)
+			if p.RegexMatchReplace != nil {
+				if re, err := regexp.Compile(p.RegexMatchReplace.Pattern); err == nil {
+					substitution := envoyRegexSubstitution.ReplaceAllString(p.RegexMatchReplace.Substitution, "$${$1}")
+					upstream.Path = re.ReplaceAllString(path, substitution)
+				}
+			}
+		}
+		if h := rw.Host; h != nil {
+			switch {
+			case h.Name != nil:
+				upstream.Host = *h.Name
+			case h.Header != nil:
+				if value, ok := headers[strings.ToLower(*h.Header)]; ok {
+					upstream.Host = value
+				}
+			case ptr.Deref(h.Backend, false):
+				upstream.Host = "<backend hostname>"
+			}
+		}
+	}
+	if rawQuery != "" {
+		upstream.Path += "?" + rawQuery
+	}
+
+	for _, h := range route.AddRequestHeaders {
+		value := strings.Join(h.Value, ",")
+		if h.Append {
+			if upstream.AppendHeaders == nil {
+				upstream.AppendHeaders = make(map[string]string)
+			}
+			upstream.AppendHeaders[h.Name] = value
+		} else {
+			if upstream.SetHeaders == nil {
+				upstream.SetHeaders = make(map[string]string)
+			}
+			upstream.SetHeaders[h.Name] = value
+		}
+	}
+	upstream.RemovedHeaders = route.RemoveRequestHeaders
+	return upstream
+}
+
+// irDestinationSettingNameRegex matches the names of the destination settings of the xDS IR,
+// e.g. "httproute/default/backend/rule/0/backend/1".
+var irDestinationSettingNameRegex = regexp.MustCompile(`/rule/(\d+)/backend/(\d+)$`)
+
+// routeBackends returns the backends of the route with their weights.
+func (s *routeSimulator) routeBackends(route *ir.HTTPRoute, r *SimulatedRoute) []SimulatedBackend {
+	if route.Destination == nil {
+		return nil
+	}
+
+	var total uint32
+	for _, setting := range route.Destination.Settings {
+		total += ptr.Deref(setting.Weight, 1)
+	}
+	backends := make([]SimulatedBackend, 0, len(route.Destination.Settings))
+	for _, setting := range route.Destination.Settings {
+		weight := ptr.Deref(setting.Weight, 1)
+		backend := SimulatedBackend{
+			Name:    s.backendName(setting.Name, r),
+			Weight:  weight,
+			Invalid: len(setting.Endpoints) == 0 && !setting.IsDynamicResolver,
+		}
+		if total > 0 {
+			backend.Percent = math.Round(float64(weight)*10000/float64(total)) / 100
+		}
+		backends = append(backends, backend)
+	}
+	return backends
+}
+
+// backendName returns the backend reference of a destination setting of the route.
+func (s *routeSimulator) backendName(settingName string, r *SimulatedRoute) string {
+	m := irDestinationSettingNameRegex.FindStringSubmatch(settingName)
+	if m == nil {
+		return settingName
+	}
+	ruleIdx, _ := strconv.Atoi(m[1])
+	backendIdx, _ := strconv.Atoi(m[2])
+
+	var ref *gwapiv1.BackendRef
+	switch r.Kind {
+	case resource.KindHTTPRoute:
+		for _, route := range s.result.Resources.HTTPRoutes {
+			if route.Namespace == r.Namespace && route.Name == r.Name &&
+				ruleIdx < len(route.Spec.Rules) && backendIdx < len(route.Spec.Rules[ruleIdx].BackendRefs) {
+				ref = &route.Spec.Rules[ruleIdx].BackendRefs[backendIdx].BackendRef
+			}
+		}
+	case resource.KindGRPCRoute:
+		for _, route := range s.result.Resources.GRPCRoutes {
+			if route.Namespace == r.Namespace && route.Name == r.Name &&
+				ruleIdx < len(route.Spec.Rules) && backendIdx < len(route.Spec.Rules[ruleIdx].BackendRefs) {
+				ref = &route.Spec.Rules[ruleIdx].BackendRefs[backendIdx].BackendRef
+			}
+		}
+	}
+	if ref == nil {
+		return settingName
+	}
+
+	name := string(ptr.Deref(ref.Kind, resource.KindService)) + " " +
+		string(ptr.Deref(ref.Namespace, gwapiv1.Namespace(r.Namespace))) + "/" + string(ref.Name)
+	if ref.Port != nil {
+		name += ":" + strconv.Itoa(int(*ref.Port))
+	}
+	return name
+}
+
+func printSimulationResult(w io.Writer, result *SimulationResult, output string) error {
+	var (
+		out []byte
+		err error
+	)
+	switch output {
+	case yamlOutput:
+		out, err = yaml.Marshal(result)
+	case jsonOutput:
+		out, err = json.MarshalIndent(result, "", "  ")
+	default:
+		out = []byte(formatSimulationResult(result))
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, strings.TrimRight(string(out), "\n"))
+	return err
+}
+
+// formatSimulationResult returns a human readable trace of the request.
+func formatSimulationResult(result *SimulationResult) string {
+	var sb strings.Builder
+	req := result.Request
+	fmt.Fprintf(&sb, "Request:        %s %s%s (port %d", strings.ToUpper(req.Method), req.Host, req.Path, req.Port)
+	if req.SNI != "" {
+		fmt.Fprintf(&sb, ", SNI %s", req.SNI)
+	}
+	if req.ClientIP != "" {
+		fmt.Fprintf(&sb, ", client %s", req.ClientIP)
+	}
+	sb.WriteString(")\n")
+
+	if l := result.Listener; l != nil {
+		fmt.Fprintf(&sb, "Listener:       Gateway %s (port %d, hostname %s)\n", l, l.Port, l.Hostname)
+	}
+	for _, skipped := range result.Skipped {
+		fmt.Fprintf(&sb, "Skipped:        %s: %s\n", skipped.Route, skipped.Reason)
+	}
+	if r := result.Route; r != nil {
+		rule := strconv.Itoa(r.Rule)
+		if r.RuleName != "" {
+			rule += " (" + r.RuleName + ")"
+		}
+		fmt.Fprintf(&sb, "Route:          %s rule %s match %d, virtual host %s\n", r, rule, r.Match, r.Hostname)
+	}
+	if len(result.Filters) > 0 {
+		sb.WriteString("Filters:\n")
+		for i, f := range result.Filters {
+			fmt.Fprintf(&sb, "  %d. %s\n", i+1, f)
+		}
+	}
+	if len(result.Policies) > 0 {
+		sb.WriteString("Policies:\n")
+		for _, p := range result.Policies {
+			fmt.Fprintf(&sb, "  %s (targeting %s)\n", p, p.Target)
+		}
+	}
+	if a := result.Authorization; a != nil {
+		fmt.Fprintf(&sb, "Authorization:  %s", a.Action)
+		switch {
+		case a.Reason != "":
+			fmt.Fprintf(&sb, ", rule %s depends on %s", a.Rule, a.Reason)
+		case a.Rule != "":
+			fmt.Fprintf(&sb, " by rule %s", a.Rule)
+		default:
+			sb.WriteString(" by default")
+		}
+		sb.WriteString("\n")
+	}
+	if u := result.Upstream; u != nil {
+		if u.Host != strings.ToLower(req.Host) || u.Path != req.Path {
+			fmt.Fprintf(&sb, "Rewrite:        %s%s -> %s%s\n", req.Host, req.Path, u.Host, u.Path)
+		}
+		for _, name := range sortedKeys(u.SetHeaders) {
+			fmt.Fprintf(&sb, "Set header:     %s: %s\n", name, u.SetHeaders[name])
+		}
+		for _, name := range sortedKeys(u.AppendHeaders) {
+			fmt.Fprintf(&sb, "Append header:  %s: %s\n", name, u.AppendHeaders[name])
+		}
+		for _, name := range u.RemovedHeaders {
+			fmt.Fprintf(&sb, "Remove header:  %s\n", name)
+		}
+	}
+	if len(result.Backends) > 0 {
+		sb.WriteString("Backends:\n")
+		for _, b := range result.Backends {
+			fmt.Fprintf(&sb, "  %s weight %d (%g%%)", b.Name, b.Weight, b.Percent)
+			if b.Invalid {
+				sb.WriteString(", invalid: no endpoints, responds 500")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if r := result.Response; r != nil {
+		fmt.Fprintf(&sb, "Response:       %d", r.StatusCode)
+		if r.Location != "" {
+			fmt.Fprintf(&sb, ", location %s", r.Location)
+		}
+		if r.Body != "" {
+			fmt.Fprintf(&sb, ", body %q", r.Body)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "Outcome:        %s", result.Outcome)
+	if result.Reason != "" {
+		fmt.Fprintf(&sb, ", %s", result.Reason)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func readRouteSimulationTests(path string) (*RouteSimulationTests, error) {
+	var (
+		content []byte
+		err     error
+	)
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the tests: %w", err)
+	}
+	tests := &RouteSimulationTests{}
+	if err := yaml.UnmarshalStrict(content, tests); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the tests: %w", err)
+	}
+	return tests, nil
+}
+
+// runRouteSimulationTests simulates the requests of the tests and reports whether they
+// all have their expected outcomes.
+func runRouteSimulationTests(w io.Writer, simulator *routeSimulator, tests *RouteSimulationTests) (bool, error) {
+	failed := 0
+	for i, test := range tests.Tests {
+		name := test.Name
+		if name == "" {
+			name = "#" + strconv.Itoa(i)
+		}
+		result, err := simulator.simulate(test.Request)
+		if err != nil {
+			return false, fmt.Errorf("test %s: %w", name, err)
+		}
+		failures := checkSimulationResult(result, &test.Expect)
+		if len(failures) == 0 {
+			fmt.Fprintf(w, "PASS %s\n", name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(w, "FAIL %s\n", name)
+		for _, f := range failures {
+			fmt.Fprintf(w, "    %s\n", f)
+		}
+	}
+	fmt.Fprintf(w, "\n%d passed, %d failed\n", len(tests.Tests)-failed, failed)
+	return failed == 0, nil
+}
+
+// checkSimulationResult returns how the result differs from the expectation.
+func checkSimulationResult(result *SimulationResult, expect *RouteSimulationExpectation) []string {
+	var failures []string
+	check := func(field, expected, got string) {
+		if expected != got {
+			failures = append(failures, fmt.Sprintf("expected %s %q, got %q", field, expected, got))
+		}
+	}
+
+	if expect.Outcome != "" {
+		check("outcome", expect.Outcome, result.Outcome)
+	}
+	if expect.Listener != "" {
+		got := ""
+		if result.Listener != nil {
+			got = result.Listener.String()
+		}
+		check("listener", expect.Listener, got)
+	}
+	if expect.Route != "" || expect.Rule != nil || expect.Match != nil {
+		if result.Route == nil {
+			failures = append(failures, fmt.Sprintf("expected a route, got none: %s", result.Reason))
+		} else {
+			if expect.Route != "" {
+				check("route", expect.Route, result.Route.String())
+			}
+			if expect.Rule != nil {
+				check("rule", strconv.Itoa(*expect.Rule), strconv.Itoa(result.Route.Rule))
+			}
+			if expect.Match != nil {
+				check("match", strconv.Itoa(*expect.Match), strconv.Itoa(result.Route.Match))
+			}
+		}
+	}
+	if expect.StatusCode != nil || expect.Location != "" {
+		var response SimulatedResponse
+		if result.Response != nil {
+			response = *result.Response
+		}
+		if expect.StatusCode != nil {
+			check("status code", strconv.Itoa(int(*expect.StatusCode)), strconv.Itoa(int(response.StatusCode)))
+		}
+		if expect.Location != "" {
+			check("location", expect.Location, response.Location)
+		}
+	}
+	if expect.Host != "" || expect.Path != "" {
+		var upstream SimulatedUpstream
+		if result.Upstream != nil {
+			upstream = *result.Upstream
+		}
+		if expect.Host != "" {
+			check("upstream host", expect.Host, upstream.Host)
+		}
+		if expect.Path != "" {
+			check("upstream path", expect.Path, upstream.Path)
+		}
+	}
+
+	// The expected filters must run in this order, among the others.
+	next := 0
+	for _, f := range result.Filters {
+		if next < len(expect.Filters) && (f == expect.Filters[next] || strings.HasPrefix(f, expect.Filters[next]+"/")) {
+			next++
+		}
+	}
+	if next < len(expect.Filters) {
+		failures = append(failures, fmt.Sprintf("expected filter %q after %v, got filters %v",
+			expect.Filters[next], expect.Filters[:next], result.Filters))
+	}
+
+	for _, p := range expect.Policies {
+		if !slices.ContainsFunc(result.Policies, func(policy SimulatedPolicy) bool { return policy.String() == p }) {
+			failures = append(failures, fmt.Sprintf("expected policy %q to apply", p))
+		}
+	}
+
+	if expect.Backends != nil {
+		var expected, got []string
+		for _, b := range expect.Backends {
+			s := b.Name
+			if b.Weight != nil {
+				s += " weight " + strconv.Itoa(int(*b.Weight))
+			}
+			expected = append(expected, s)
+		}
+		for i, b := range result.Backends {
+			s := b.Name
+			if i < len(expect.Backends) && expect.Backends[i].Weight != nil {
+				s += " weight " + strconv.Itoa(int(b.Weight))
+			}
+			got = append(got, s)
+		}
+		if !slices.Equal(expected, got) {
+			failures = append(failures, fmt.Sprintf("expected backends %v, got %v", expected, got))
+		}
+	}
+	return failures
+}
@@ -0,0 +1,254 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	"github.com/wukongcloud/gateway/internal/ir"
+)
+
+func TestRouteSimulate(t *testing.T) {
+	simulator, err := newRouteSimulatorFromPath(filepath.Join("testdata", "route", "resources.yaml"), &routeSimulateOptions{verbose: true})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		request SimulatedRequest
+		check   func(t *testing.T, result *SimulationResult)
+	}{
+		{
+			name:    "weighted backends",
+			request: SimulatedRequest{Host: "www.example.com", Path: "/api/users?page=2"},
+			check: func(t *testing.T, result *SimulationResult) {
+				require.Equal(t, SimulationForwarded, result.Outcome)
+				require.Equal(t, "default/eg/http", result.Listener.String())
+				require.Equal(t, uint32(80), result.Listener.Port)
+				require.Equal(t, &SimulatedRoute{
+					Kind:      "HTTPRoute",
+					Namespace: "default",
+					Name:      "backend",
+					Rule:      0,
+					RuleName:  "api",
+					Match:     0,
+					Hostname:  "www.example.com",
+					XdsName:   "httproute/default/backend/rule/0/match/0/www_example_com",
+				}, result.Route)
+				// The local rate limit filter is moved before the CORS filter by the filter order of the EnvoyProxy.
+				require.Equal(t, []string{
+					"envoy.filters.http.local_ratelimit",
+					"envoy.filters.http.cors",
+					"envoy.filters.http.router",
+				}, result.Filters)
+				require.Equal(t, []SimulatedPolicy{
+					{Kind: "BackendTrafficPolicy", Namespace: "default", Name: "api", Target: "HTTPRoute default/backend"},
+					{Kind: "SecurityPolicy", Namespace: "default", Name: "cors", Target: "Gateway default/eg"},
+				}, result.Policies)
+				require.Equal(t, &SimulatedUpstream{
+					Host:       "www.example.com",
+					Path:       "/v1/users?page=2",
+					SetHeaders: map[string]string{"x-team": "api"},
+				}, result.Upstream)
+				require.Equal(t, []SimulatedBackend{
+					{Name: "Service default/v1:8080", Weight: 90, Percent: 90},
+					{Name: "Service default/v2:8080", Weight: 10, Percent: 10},
+				}, result.Backends)
+			},
+		},
+		{
+			name:    "header match precedence",
+			request: SimulatedRequest{Host: "www.example.com", Path: "/api/users", Headers: map[string]string{"X-Version": "2"}},
+			check: func(t *testing.T, result *SimulationResult) {
+				require.Equal(t, 1, result.Route.Rule)
+				require.Equal(t, []SkippedRoute{{
+					Route:  "httproute/default/redirect/rule/0/match/0/www_example_com",
+					Reason: `path /api/users doesn't match exact "/old"`,
+				}}, result.Skipped)
+				require.Equal(t, []SimulatedBackend{{Name: "Service default/v2:8080", Weight: 1, Percent: 100}}, result.Backends)
+			},
+		},
+		{
+			name:    "redirect",
+			request: SimulatedRequest{Host: "www.example.com", Path: "/old?a=b"},
+			check: func(t *testing.T, result *SimulationResult) {
+				require.Equal(t, SimulationRedirected, result.Outcome)
+				require.Equal(t, &SimulatedResponse{StatusCode: 301, Location: "https://www.example.com/new?a=b"}, result.Response)
+				require.Nil(t, result.Backends)
+			},
+		},
+		{
+			name:    "https listener selected by SNI",
+			request: SimulatedRequest{Host: "admin.example.com", SNI: "admin.example.com", ClientIP: "10.1.2.3"},
+			check: func(t *testing.T, result *SimulationResult) {
+				require.Equal(t, SimulationForwarded, result.Outcome)
+				require.Equal(t, "default/eg/https", result.Listener.String())
+				require.Equal(t, "*.example.com", result.Listener.Hostname)
+				require.Equal(t, []string{"envoy.filters.http.rbac", "envoy.filters.http.router"}, result.Filters)
+				// The SecurityPolicy of the route overrides the one of the Gateway.
+				require.Equal(t, []SimulatedPolicy{
+					{Kind: "SecurityPolicy", Namespace: "default", Name: "admin", Target: "HTTPRoute default/admin"},
+				}, result.Policies)
+				require.Equal(t, &SimulatedAuthorization{Action: "Allow", Rule: "internal"}, result.Authorization)
+			},
+		},
+		{
+			name:    "denied by authorization",
+			request: SimulatedRequest{Host: "admin.example.com", Port: 443, ClientIP: "192.168.1.1"},
+			check: func(t *testing.T, result *SimulationResult) {
+				require.Equal(t, SimulationDenied, result.Outcome)
+				require.Equal(t, &SimulatedAuthorization{Action: "Deny"}, result.Authorization)
+				require.Equal(t, uint32(403), result.Response.StatusCode)
+			},
+		},
+		{
+			name:    "authorization without client IP",
+			request: SimulatedRequest{Host: "admin.example.com", Port: 443},
+			check: func(t *testing.T, result *SimulationResult) {
+				require.Equal(t, SimulationForwarded, result.Outcome)
+				require.Equal(t, "Unknown", result.Authorization.Action)
+				require.Equal(t, "internal", result.Authorization.Rule)
+			},
+		},
+		{
+			name:    "no route for the host",
+			request: SimulatedRequest{Host: "admin.example.com"},
+			check: func(t *testing.T, result *SimulationResult) {
+				require.Equal(t, SimulationNoRoute, result.Outcome)
+				require.Equal(t, "default/eg/http", result.Listener.String())
+				require.Nil(t, result.Route)
+				require.Equal(t, uint32(404), result.Response.StatusCode)
+			},
+		},
+		{
+			name:    "no listener for the SNI",
+			request: SimulatedRequest{Host: "www.example.org", SNI: "www.example.org"},
+			check: func(t *testing.T, result *SimulationResult) {
+				require.Equal(t, SimulationNoListener, result.Outcome)
+				require.Nil(t, result.Listener)
+			},
+		},
+		{
+			name:    "no listener on the port",
+			request: SimulatedRequest{Host: "www.example.com", Port: 8080},
+			check: func(t *testing.T, result *SimulationResult) {
+				require.Equal(t, SimulationNoListener, result.Outcome)
+				require.Equal(t, "no HTTP listener on port 8080", result.Reason)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := simulator.simulate(tc.request)
+			require.NoError(t, err)
+			tc.check(t, result)
+		})
+	}
+}
+
+func TestRouteSimulateCommand(t *testing.T) {
+	failingTests := filepath.Join(t.TempDir(), "failing.yaml")
+	require.NoError(t, os.WriteFile(failingTests, []byte(`tests:
+  - name: wrong backend
+    request:
+      host: www.example.com
+      path: /
+    expect:
+      route: HTTPRoute default/backend
+      rule: 2
+      backends:
+        - name: Service default/v2:8080
+`), 0o600))
+
+	testCases := []struct {
+		name     string
+		args     []string
+		exitCode int
+		output   []string
+	}{
+		{
+			name:     "request",
+			args:     []string{"--host", "www.example.com", "--path", "/api", "-H", "x-version: 2"},
+			exitCode: 0,
+			output:   []string{"Route:          HTTPRoute default/backend rule 1 (api-v2) match 0, virtual host www.example.com"},
+		},
+		{
+			name:     "passing tests",
+			args:     []string{"--tests", filepath.Join("testdata", "route", "tests.yaml")},
+			exitCode: 0,
+			output:   []string{"5 passed, 0 failed"},
+		},
+		{
+			name:     "failing tests",
+			args:     []string{"--tests", failingTests},
+			exitCode: 1,
+			output: []string{
+				"FAIL wrong backend",
+				"expected backends [Service default/v2:8080], got [Service default/v1:8080]",
+				"0 passed, 1 failed",
+			},
+		},
+		{
+			name:     "missing host",
+			args:     []string{},
+			exitCode: 2,
+		},
+		{
+			name:     "invalid header",
+			args:     []string{"--host", "www.example.com", "-H", "x-version"},
+			exitCode: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := bytes.NewBufferString("")
+			root := newRouteSimulateCommand()
+			// The usage is silenced by the egctl root command.
+			root.SilenceUsage = true
+			root.SetOut(b)
+			root.SetErr(b)
+			root.SetArgs(append([]string{filepath.Join("testdata", "route", "resources.yaml")}, tc.args...))
+
+			err := root.ExecuteContext(context.Background())
+			if tc.exitCode == 0 {
+				require.NoError(t, err)
+			} else {
+				var exitErr *ExitError
+				require.True(t, errors.As(err, &exitErr))
+				require.Equal(t, tc.exitCode, exitErr.Code)
+			}
+			for _, o := range tc.output {
+				require.Contains(t, b.String(), o)
+			}
+		})
+	}
+}
+
+func TestModifyPath(t *testing.T) {
+	testCases := []struct {
+		matchPrefix, replacement, path, expected string
+	}{
+		{"/api", "/v1", "/api/users", "/v1/users"},
+		{"/api/", "/v1", "/api/users", "/v1/users"},
+		{"/api", "/", "/api/users", "/users"},
+		{"/api", "/", "/api", "/"},
+		{"/api", "/v1/", "/api", "/v1"},
+		{"/", "/v1", "/users", "/v1/users"},
+	}
+	for _, tc := range testCases {
+		got := modifyPath(nil, ptr.To(tc.replacement), &ir.StringMatch{Prefix: ptr.To(tc.matchPrefix)}, tc.path)
+		require.Equal(t, tc.expected, got, "%s replaced by %s in %s", tc.matchPrefix, tc.replacement, tc.path)
+	}
+}
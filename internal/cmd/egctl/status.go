@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -46,10 +47,20 @@ func init() {
 	supportedAllTypes = append(supportedAllTypes, supportedXPolicyTypes...)
 }
 
+// statusOptions configures the status command.
+type statusOptions struct {
+	quiet, verbose, allNamespaces bool
+	namespace                     string
+	output                        string
+	watch                         bool
+	waitFor                       string
+	timeout                       time.Duration
+}
+
 func newStatusCommand() *cobra.Command {
 	var (
-		quiet, verbose, allNamespaces bool
-		resourceType, namespace       string
+		opts         = &statusOptions{}
+		resourceType string
 	)
 
 	statusCommand := &cobra.Command{
@@ -72,14 +83,17 @@ func newStatusCommand() *cobra.Command {
 
   # Show the status of all resources under all namespaces.
   egctl x status all -A
+
+  # Show the status of all resources in JSON.
+  egctl x status all -A -o json
+
+  # Stream the transitions of the conditions of the httproute resources.
+  egctl x status httproute --watch
+
+  # Wait up to 2 minutes for the gateway resources to be programmed.
+  egctl x status gateway --wait-for Programmed --timeout 2m
 	`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := context.Background()
-			k8sClient, err := newK8sClient()
-			if err != nil {
-				return err
-			}
-
 			switch {
 			case len(args) == 1:
 				resourceType = args[0]
@@ -88,43 +102,95 @@ func newStatusCommand() *cobra.Command {
 			default:
 				return fmt.Errorf("invalid args: must specific a resources type")
 			}
+			switch opts.output {
+			case "", jsonOutput, yamlOutput:
+			default:
+				return fmt.Errorf("invalid output format %q, one of 'json' or 'yaml' is expected", opts.output)
+			}
+			if opts.allNamespaces {
+				opts.namespace = ""
+			}
 
+			ctx := context.Background()
+			k8sClient, err := newK8sClient()
+			if err != nil {
+				return err
+			}
+
+			resourceTypes := []string{resourceType}
 			switch strings.ToLower(resourceType) {
 			case "all":
-				for _, rt := range supportedAllTypes {
-					if err = runStatus(ctx, cmd.OutOrStdout(), k8sClient, rt, namespace, quiet, verbose, allNamespaces, true, true); err != nil {
-						return err
-					}
-				}
-				return nil
+				resourceTypes = supportedAllTypes
 			case "xroute":
-				for _, rt := range supportedXRouteTypes {
-					if err = runStatus(ctx, cmd.OutOrStdout(), k8sClient, rt, namespace, quiet, verbose, allNamespaces, true, true); err != nil {
-						return err
-					}
-				}
-				return nil
+				resourceTypes = supportedXRouteTypes
 			case "xpolicy":
-				for _, rt := range supportedXPolicyTypes {
-					if err = runStatus(ctx, cmd.OutOrStdout(), k8sClient, rt, namespace, quiet, verbose, allNamespaces, true, true); err != nil {
-						return err
-					}
-				}
-				return nil
-			default:
-				return runStatus(ctx, cmd.OutOrStdout(), k8sClient, resourceType, namespace, quiet, verbose, allNamespaces, false, false)
+				resourceTypes = supportedXPolicyTypes
 			}
+			return runStatusCommand(ctx, cmd.OutOrStdout(), k8sClient, resourceTypes, opts)
 		},
 	}
 
-	statusCommand.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Show the first status of resources only")
-	statusCommand.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show the status of resources with details")
-	statusCommand.PersistentFlags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Get the status of resources from all namespaces")
-	statusCommand.PersistentFlags().StringVarP(&namespace, "namespace", "n", "default", "Specific a namespace to get the status of resources")
+	statusCommand.PersistentFlags().BoolVarP(&opts.quiet, "quiet", "q", false, "Show the first status of resources only")
+	statusCommand.PersistentFlags().BoolVarP(&opts.verbose, "verbose", "v", false, "Show the status of resources with details")
+	statusCommand.PersistentFlags().BoolVarP(&opts.allNamespaces, "all-namespaces", "A", false, "Get the status of resources from all namespaces")
+	statusCommand.PersistentFlags().StringVarP(&opts.namespace, "namespace", "n", "default", "Specific a namespace to get the status of resources")
+	statusCommand.PersistentFlags().StringVarP(&opts.output, "output", "o", "", "Output format of the status, one of 'json' or 'yaml', instead of tables")
+	statusCommand.PersistentFlags().BoolVarP(&opts.watch, "watch", "w", false, "Stream the transitions of the conditions of resources")
+	statusCommand.PersistentFlags().StringVar(&opts.waitFor, "wait-for", "", "Wait for the condition, e.g. Accepted, or Programmed for the gateways, "+
+		"to be true for all the resources, and exit with a non-zero status if it isn't before the timeout")
+	statusCommand.PersistentFlags().DurationVar(&opts.timeout, "timeout", 5*time.Minute, "Time to wait for the condition of --wait-for")
 
 	return statusCommand
 }
 
+// runStatusCommand shows the status of the resource types, after waiting for the condition of --wait-for, or
+// streams the transitions of their conditions with --watch.
+func runStatusCommand(ctx context.Context, out io.Writer, cli client.WithWatch, resourceTypes []string, opts *statusOptions) error {
+	if opts.waitFor != "" {
+		if err := validateWaitFor(resourceTypes, opts.waitFor); err != nil {
+			return err
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	if opts.watch {
+		return watchStatus(ctx, out, cli, resourceTypes, opts)
+	}
+
+	var pending []string
+	if opts.waitFor != "" {
+		var err error
+		if pending, err = waitForStatus(ctx, cli, resourceTypes, opts); err != nil {
+			return err
+		}
+		// The status is shown with a fresh context, even when the wait timed out.
+		ctx = context.Background()
+	}
+
+	if opts.output != "" {
+		statuses, err := collectResourceStatuses(ctx, cli, resourceTypes, opts.namespace)
+		if err != nil {
+			return err
+		}
+		if err := writeResourceStatuses(out, statuses, opts.output); err != nil {
+			return err
+		}
+	} else {
+		multiple := len(resourceTypes) > 1
+		for _, rt := range resourceTypes {
+			if err := runStatus(ctx, out, cli, rt, opts, multiple, multiple); err != nil {
+				return err
+			}
+		}
+	}
+
+	if pending != nil {
+		return &ExitError{Code: 1, Err: waitForTimeoutError(opts.waitFor, pending)}
+	}
+	return nil
+}
+
 func newStatusTableWriter(out io.Writer) *tabwriter.Writer {
 	return tabwriter.NewWriter(out, 10, 0, 3, ' ', 0)
 }
@@ -136,136 +202,106 @@ func writeStatusTable(table *tabwriter.Writer, header []string, body [][]string)
 	}
 }
 
-// runStatus find and write the summary table of status for a specific resource type.
-func runStatus(ctx context.Context, logOut io.Writer, cli client.Client, inputResourceType, namespace string, quiet, verbose, allNamespaces, ignoreEmpty, typedName bool) error {
+// listStatusResources lists the resources of a type, and returns them with their kind.
+func listStatusResources(ctx context.Context, cli client.Client, inputResourceType, namespace string) (client.ObjectList, string, error) {
+	resourcesList, resourceKind, err := newStatusResourceList(inputResourceType)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := cli.List(ctx, resourcesList, client.InNamespace(namespace)); err != nil {
+		return nil, "", err
+	}
+	return resourcesList, resourceKind, nil
+}
+
+// newStatusResourceList returns an empty list of the resources of a type, with their kind.
+func newStatusResourceList(inputResourceType string) (client.ObjectList, string, error) {
 	var (
 		resourcesList client.ObjectList
 		resourceKind  string
-		table         = newStatusTableWriter(logOut)
 	)
 
-	if allNamespaces {
-		namespace = ""
-	}
-
 	switch strings.ToLower(inputResourceType) {
 	case "gc", "gatewayclass":
-		gc := gwapiv1.GatewayClassList{}
-		if err := cli.List(ctx, &gc, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &gc
-		resourceKind = resource.KindGatewayClass
-
+		resourcesList, resourceKind = &gwapiv1.GatewayClassList{}, resource.KindGatewayClass
 	case "gtw", "gateway":
-		gtw := gwapiv1.GatewayList{}
-		if err := cli.List(ctx, &gtw, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &gtw
-		resourceKind = resource.KindGateway
-
+		resourcesList, resourceKind = &gwapiv1.GatewayList{}, resource.KindGateway
 	case "httproute":
-		httproute := gwapiv1.HTTPRouteList{}
-		if err := cli.List(ctx, &httproute, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &httproute
-		resourceKind = resource.KindHTTPRoute
-
+		resourcesList, resourceKind = &gwapiv1.HTTPRouteList{}, resource.KindHTTPRoute
 	case "grpcroute":
-		grpcroute := gwapiv1.GRPCRouteList{}
-		if err := cli.List(ctx, &grpcroute, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &grpcroute
-		resourceKind = resource.KindGRPCRoute
-
+		resourcesList, resourceKind = &gwapiv1.GRPCRouteList{}, resource.KindGRPCRoute
 	case "tcproute":
-		tcproute := gwapiv1a2.TCPRouteList{}
-		if err := cli.List(ctx, &tcproute, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &tcproute
-		resourceKind = resource.KindTCPRoute
-
+		resourcesList, resourceKind = &gwapiv1a2.TCPRouteList{}, resource.KindTCPRoute
 	case "udproute":
-		udproute := gwapiv1a2.UDPRouteList{}
-		if err := cli.List(ctx, &udproute, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &udproute
-		resourceKind = resource.KindUDPRoute
-
+		resourcesList, resourceKind = &gwapiv1a2.UDPRouteList{}, resource.KindUDPRoute
 	case "tlsroute":
-		tlsroute := gwapiv1a2.TLSRouteList{}
-		if err := cli.List(ctx, &tlsroute, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &tlsroute
-		resourceKind = resource.KindTLSRoute
-
+		resourcesList, resourceKind = &gwapiv1a2.TLSRouteList{}, resource.KindTLSRoute
 	case "btlspolicy", "backendtlspolicy":
-		btlspolicy := gwapiv1a3.BackendTLSPolicyList{}
-		if err := cli.List(ctx, &btlspolicy, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &btlspolicy
-		resourceKind = resource.KindBackendTLSPolicy
-
+		resourcesList, resourceKind = &gwapiv1a3.BackendTLSPolicyList{}, resource.KindBackendTLSPolicy
 	case "btp", "backendtrafficpolicy":
-		btp := egv1a1.BackendTrafficPolicyList{}
-		if err := cli.List(ctx, &btp, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &btp
-		resourceKind = resource.KindBackendTrafficPolicy
-
+		resourcesList, resourceKind = &egv1a1.BackendTrafficPolicyList{}, resource.KindBackendTrafficPolicy
 	case "ctp", "clienttrafficpolicy":
-		ctp := egv1a1.ClientTrafficPolicyList{}
-		if err := cli.List(ctx, &ctp, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &ctp
-		resourceKind = resource.KindClientTrafficPolicy
-
+		resourcesList, resourceKind = &egv1a1.ClientTrafficPolicyList{}, resource.KindClientTrafficPolicy
 	case "epp", "envoypatchpolicy":
-		epp := egv1a1.EnvoyPatchPolicyList{}
-		if err := cli.List(ctx, &epp, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &epp
-		resourceKind = resource.KindEnvoyPatchPolicy
-
+		resourcesList, resourceKind = &egv1a1.EnvoyPatchPolicyList{}, resource.KindEnvoyPatchPolicy
 	case "eep", "envoyextensionpolicy":
-		eep := egv1a1.EnvoyExtensionPolicyList{}
-		if err := cli.List(ctx, &eep, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &eep
-		resourceKind = resource.KindEnvoyExtensionPolicy
-
+		resourcesList, resourceKind = &egv1a1.EnvoyExtensionPolicyList{}, resource.KindEnvoyExtensionPolicy
 	case "sp", "securitypolicy":
-		sp := egv1a1.SecurityPolicyList{}
-		if err := cli.List(ctx, &sp, client.InNamespace(namespace)); err != nil {
-			return err
-		}
-		resourcesList = &sp
-		resourceKind = resource.KindSecurityPolicy
-
+		resourcesList, resourceKind = &egv1a1.SecurityPolicyList{}, resource.KindSecurityPolicy
 	default:
-		return fmt.Errorf("unknown input resource type: %s, supported input types are: %s",
+		return nil, "", fmt.Errorf("unknown input resource type: %s, supported input types are: %s",
 			inputResourceType, strings.Join(supportedAllTypes, ", "))
 	}
+	return resourcesList, resourceKind, nil
+}
+
+// listAttachedPolicies lists the policies which can apply to the routes.
+func listAttachedPolicies(ctx context.Context, cli client.Client, namespace string) ([]attachedPolicy, error) {
+	var (
+		ctps egv1a1.ClientTrafficPolicyList
+		btps egv1a1.BackendTrafficPolicyList
+		sps  egv1a1.SecurityPolicyList
+		eeps egv1a1.EnvoyExtensionPolicyList
+	)
+	for _, list := range []client.ObjectList{&ctps, &btps, &sps, &eeps} {
+		if err := cli.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+	}
+
+	r := resource.NewResources()
+	for i := range ctps.Items {
+		r.ClientTrafficPolicies = append(r.ClientTrafficPolicies, &ctps.Items[i])
+	}
+	for i := range btps.Items {
+		r.BackendTrafficPolicies = append(r.BackendTrafficPolicies, &btps.Items[i])
+	}
+	for i := range sps.Items {
+		r.SecurityPolicies = append(r.SecurityPolicies, &sps.Items[i])
+	}
+	for i := range eeps.Items {
+		r.EnvoyExtensionPolicies = append(r.EnvoyExtensionPolicies, &eeps.Items[i])
+	}
+	return attachedPolicies(r), nil
+}
+
+// runStatus find and write the summary table of status for a specific resource type.
+func runStatus(ctx context.Context, logOut io.Writer, cli client.Client, inputResourceType string, opts *statusOptions, ignoreEmpty, typedName bool) error {
+	table := newStatusTableWriter(logOut)
+
+	resourcesList, resourceKind, err := listStatusResources(ctx, cli, inputResourceType, opts.namespace)
+	if err != nil {
+		return err
+	}
 
 	namespaced, err := cli.IsObjectNamespaced(resourcesList)
 	if err != nil {
 		return err
 	}
 
-	needNamespaceHeader := allNamespaces && namespaced
-	header := fetchStatusHeader(resourceKind, verbose, needNamespaceHeader)
-	body := fetchStatusBody(resourcesList, resourceKind, quiet, verbose, needNamespaceHeader, typedName)
+	needNamespaceHeader := opts.allNamespaces && namespaced
+	header := fetchStatusHeader(resourceKind, opts.verbose, needNamespaceHeader)
+	body := fetchStatusBody(resourcesList, resourceKind, opts.quiet, opts.verbose, needNamespaceHeader, typedName)
 
 	if ignoreEmpty && len(body) == 0 {
 		return nil
@@ -276,9 +312,28 @@ func runStatus(ctx context.Context, logOut io.Writer, cli client.Client, inputRe
 		return err
 	}
 
+	// The effective policies of the routes are shown in a separate table.
+	if strings.HasSuffix(resourceKind, "Route") && !opts.quiet {
+		policies, err := listAttachedPolicies(ctx, cli, opts.namespace)
+		if err != nil {
+			return err
+		}
+		if policiesBody := fetchRoutePoliciesBody(resourcesList, resourceKind, policies, needNamespaceHeader, typedName); len(policiesBody) > 0 {
+			fmt.Fprintln(logOut)
+			header := []string{"NAME", "PARENT", "POLICIES"}
+			if needNamespaceHeader {
+				header = append([]string{"NAMESPACE"}, header...)
+			}
+			writeStatusTable(table, header, policiesBody)
+			if err = table.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Separate tables by newline if there are multiple tables.
 	if ignoreEmpty && typedName {
-		fmt.Print("\n")
+		fmt.Fprintln(logOut)
 	}
 
 	return nil
@@ -411,6 +466,30 @@ func fetchStatusBody(resourcesList client.ObjectList, resourceKind string, quiet
 	return body
 }
 
+// fetchRoutePoliciesBody fetches the effective policies of the routes for each of their parents.
+func fetchRoutePoliciesBody(resourcesList client.ObjectList, resourceKind string, policies []attachedPolicy, needNamespace, typedName bool) (body [][]string) {
+	for _, s := range resourceStatuses(resourcesList, resourceKind, policies) {
+		var rows [][]string
+		for _, parent := range s.Parents {
+			if len(parent.Policies) == 0 {
+				continue
+			}
+			names := make([]string, 0, len(parent.Policies))
+			for _, p := range parent.Policies {
+				names = append(names, p.String())
+			}
+			rows = append(rows, []string{kindName(parent.Kind, parent.Name), strings.Join(names, ", ")})
+		}
+
+		name := s.Name
+		if typedName {
+			name = kindName(resourceKind, s.Name)
+		}
+		body = append(body, extendStatusBodyWithNamespaceAndName(rows, s.Namespace, name, needNamespace)...)
+	}
+	return body
+}
+
 // fetchConditions fetches conditions from the `Conditions` field of parent
 // by calling fetchCondition for each condition.
 func fetchConditions(parent reflect.Value, quiet, verbose bool) [][]string {
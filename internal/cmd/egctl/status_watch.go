@@ -0,0 +1,506 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/yaml"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+)
+
+// statusRetryInterval is the interval between the retries of the listings and the watches of the
+// resources which failed with --watch and --wait-for.
+var statusRetryInterval = 2 * time.Second
+
+// statusConditionTypes are the types of the conditions Envoy Gateway reports for the resources of
+// every kind, which --wait-for can wait for. The conditions of the routes and the policies are
+// reported for each of their parents and ancestors.
+var statusConditionTypes = map[string][]string{
+	resource.KindGatewayClass:         {string(gwapiv1.GatewayClassConditionStatusAccepted)},
+	resource.KindGateway:              {string(gwapiv1.GatewayConditionAccepted), string(gwapiv1.GatewayConditionProgrammed)},
+	resource.KindHTTPRoute:            routeConditionTypes,
+	resource.KindGRPCRoute:            routeConditionTypes,
+	resource.KindTCPRoute:             routeConditionTypes,
+	resource.KindUDPRoute:             routeConditionTypes,
+	resource.KindTLSRoute:             routeConditionTypes,
+	resource.KindBackendTLSPolicy:     {string(gwapiv1a2.PolicyConditionAccepted)},
+	resource.KindBackendTrafficPolicy: {string(gwapiv1a2.PolicyConditionAccepted), string(egv1a1.PolicyConditionMerged), string(egv1a1.PolicyConditionOverridden)},
+	resource.KindClientTrafficPolicy:  {string(gwapiv1a2.PolicyConditionAccepted), string(egv1a1.PolicyConditionOverridden)},
+	resource.KindEnvoyPatchPolicy:     {string(gwapiv1a2.PolicyConditionAccepted), string(egv1a1.PolicyConditionProgrammed)},
+	resource.KindEnvoyExtensionPolicy: {string(gwapiv1a2.PolicyConditionAccepted), string(egv1a1.PolicyConditionOverridden)},
+	resource.KindSecurityPolicy:       {string(gwapiv1a2.PolicyConditionAccepted), string(egv1a1.PolicyConditionOverridden)},
+}
+
+var routeConditionTypes = []string{string(gwapiv1.RouteConditionAccepted), string(gwapiv1.RouteConditionResolvedRefs)}
+
+// ResourceStatus is the status of a resource, as shown with --output.
+type ResourceStatus struct {
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Generation int64  `json:"generation,omitempty"`
+	// Conditions are the conditions of the GatewayClasses and the Gateways.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Parents are the statuses of a route for its parents.
+	Parents []ResourceParentStatus `json:"parents,omitempty"`
+	// Ancestors are the statuses of a policy for its ancestors.
+	Ancestors []ResourceParentStatus `json:"ancestors,omitempty"`
+}
+
+// ResourceParentStatus is the status of a route for a parent, or of a policy for an ancestor.
+type ResourceParentStatus struct {
+	Kind        string             `json:"kind"`
+	Namespace   string             `json:"namespace"`
+	Name        string             `json:"name"`
+	SectionName string             `json:"sectionName,omitempty"`
+	Conditions  []metav1.Condition `json:"conditions"`
+	// Policies are the policies applying to the whole route for the parent, the most
+	// specific one of each kind.
+	Policies []SimulatedPolicy `json:"policies,omitempty"`
+}
+
+func (p *ResourceParentStatus) String() string {
+	s := p.Kind + " " + p.Namespace + "/" + p.Name
+	if p.SectionName != "" {
+		s += "/" + p.SectionName
+	}
+	return s
+}
+
+// resourceStatuses returns the statuses of the resources of a list, with the effective policies of the routes.
+func resourceStatuses(resourcesList client.ObjectList, resourceKind string, policies []attachedPolicy) []ResourceStatus {
+	items, err := meta.ExtractList(resourcesList)
+	if err != nil {
+		return nil
+	}
+
+	statuses := make([]ResourceStatus, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok {
+			continue
+		}
+		s := ResourceStatus{
+			Kind:       resourceKind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+			Generation: obj.GetGeneration(),
+		}
+		statusField := reflect.ValueOf(obj).Elem().FieldByName("Status")
+
+		switch {
+		// For xRoute, the conditions are storing in `Resource.Status.Parents[i].Conditions`.
+		case strings.HasSuffix(resourceKind, "Route"):
+			for _, parent := range statusField.FieldByName("Parents").Interface().([]gwapiv1.RouteParentStatus) {
+				ps := newResourceParentStatus(parent.ParentRef, obj.GetNamespace(), parent.Conditions)
+				if ps.Kind == resource.KindGateway {
+					ps.Policies = effectivePolicies(policies, policyScope{
+						gwNamespace:    ps.Namespace,
+						gwName:         ps.Name,
+						listener:       ps.SectionName,
+						routeKind:      resourceKind,
+						routeNamespace: obj.GetNamespace(),
+						routeName:      obj.GetName(),
+					})
+				}
+				s.Parents = append(s.Parents, ps)
+			}
+
+		// For xPolicy, the conditions are storing in `Resource.Status.Ancestors[i].Conditions`.
+		case strings.HasSuffix(resourceKind, "Policy"):
+			for _, ancestor := range statusField.FieldByName("Ancestors").Interface().([]gwapiv1a2.PolicyAncestorStatus) {
+				s.Ancestors = append(s.Ancestors, newResourceParentStatus(ancestor.AncestorRef, obj.GetNamespace(), ancestor.Conditions))
+			}
+
+		// For others, the conditions are storing in `Resource.Status.Conditions`.
+		default:
+			s.Conditions = statusField.FieldByName("Conditions").Interface().([]metav1.Condition)
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+func newResourceParentStatus(ref gwapiv1.ParentReference, namespace string, conditions []metav1.Condition) ResourceParentStatus {
+	return ResourceParentStatus{
+		Kind:        string(ptr.Deref(ref.Kind, resource.KindGateway)),
+		Namespace:   string(ptr.Deref(ref.Namespace, gwapiv1.Namespace(namespace))),
+		Name:        string(ref.Name),
+		SectionName: string(ptr.Deref(ref.SectionName, "")),
+		Conditions:  conditions,
+	}
+}
+
+// collectResourceStatuses lists the resources of the types, and returns their statuses.
+func collectResourceStatuses(ctx context.Context, cli client.Client, resourceTypes []string, namespace string) ([]ResourceStatus, error) {
+	var (
+		statuses []ResourceStatus
+		policies []attachedPolicy
+	)
+	for _, rt := range resourceTypes {
+		resourcesList, resourceKind, err := listStatusResources(ctx, cli, rt, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(resourceKind, "Route") && policies == nil {
+			if policies, err = listAttachedPolicies(ctx, cli, namespace); err != nil {
+				return nil, err
+			}
+		}
+		statuses = append(statuses, resourceStatuses(resourcesList, resourceKind, policies)...)
+	}
+	return statuses, nil
+}
+
+func writeResourceStatuses(out io.Writer, statuses []ResourceStatus, output string) error {
+	if statuses == nil {
+		statuses = []ResourceStatus{}
+	}
+	var (
+		content []byte
+		err     error
+	)
+	if output == jsonOutput {
+		content, err = json.MarshalIndent(statuses, "", "  ")
+		content = append(content, '\n')
+	} else {
+		content, err = yaml.Marshal(statuses)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(content)
+	return err
+}
+
+// StatusEvent is a transition of a condition of a resource, as streamed with --watch.
+type StatusEvent struct {
+	Time      metav1.Time `json:"time"`
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace,omitempty"`
+	Name      string      `json:"name"`
+	// Parent is the parent of the route, or the ancestor of the policy, the condition is for.
+	Parent             string                 `json:"parent,omitempty"`
+	Type               string                 `json:"type"`
+	Status             metav1.ConditionStatus `json:"status"`
+	Reason             string                 `json:"reason"`
+	Message            string                 `json:"message,omitempty"`
+	ObservedGeneration int64                  `json:"observedGeneration,omitempty"`
+}
+
+func (e StatusEvent) String() string {
+	s := e.Time.UTC().Format(time.RFC3339) + "  " + e.Kind + " "
+	if e.Namespace != "" {
+		s += e.Namespace + "/"
+	}
+	s += e.Name
+	if e.Parent != "" {
+		s += " for " + e.Parent
+	}
+	s += fmt.Sprintf("  %s=%s  %s", e.Type, e.Status, e.Reason)
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	return s
+}
+
+// statusEvents returns the conditions of the statuses whose status or reason changed since the
+// last ones, which are updated.
+func statusEvents(statuses []ResourceStatus, last map[string]metav1.Condition) []StatusEvent {
+	var events []StatusEvent
+	add := func(s *ResourceStatus, parent string, conditions []metav1.Condition) {
+		for _, c := range conditions {
+			key := strings.Join([]string{s.Kind, s.Namespace, s.Name, parent, c.Type}, "\x00")
+			if previous, ok := last[key]; ok && previous.Status == c.Status && previous.Reason == c.Reason {
+				continue
+			}
+			last[key] = c
+			events = append(events, StatusEvent{
+				Time:               c.LastTransitionTime,
+				Kind:               s.Kind,
+				Namespace:          s.Namespace,
+				Name:               s.Name,
+				Parent:             parent,
+				Type:               c.Type,
+				Status:             c.Status,
+				Reason:             c.Reason,
+				Message:            c.Message,
+				ObservedGeneration: c.ObservedGeneration,
+			})
+		}
+	}
+	for i := range statuses {
+		s := &statuses[i]
+		add(s, "", s.Conditions)
+		for _, p := range s.Parents {
+			add(s, p.String(), p.Conditions)
+		}
+		for _, a := range s.Ancestors {
+			add(s, a.String(), a.Conditions)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(&events[j].Time)
+	})
+	return events
+}
+
+func writeStatusEvent(out io.Writer, event StatusEvent, output string) error {
+	var (
+		content []byte
+		err     error
+	)
+	switch output {
+	case jsonOutput:
+		content, err = json.Marshal(event)
+		content = append(content, '\n')
+	case yamlOutput:
+		content, err = yaml.Marshal(event)
+		content = append([]byte("---\n"), content...)
+	default:
+		content = []byte(event.String() + "\n")
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(content)
+	return err
+}
+
+// validateWaitFor returns an error if the condition of --wait-for isn't reported for the resources of one
+// of the types, since waiting for it would always time out.
+func validateWaitFor(resourceTypes []string, conditionType string) error {
+	for _, rt := range resourceTypes {
+		_, resourceKind, err := newStatusResourceList(rt)
+		if err != nil {
+			return err
+		}
+		if types := statusConditionTypes[resourceKind]; !slices.Contains(types, conditionType) {
+			return fmt.Errorf("the %s condition isn't reported for %s resources, the reported conditions are: %s",
+				conditionType, resourceKind, strings.Join(types, ", "))
+		}
+	}
+	return nil
+}
+
+// watchStatus streams the transitions of the conditions of the resources, starting with their current
+// conditions, until the condition of --wait-for is true for all the resources, or the context is done.
+func watchStatus(ctx context.Context, out io.Writer, cli client.WithWatch, resourceTypes []string, opts *statusOptions) error {
+	last := map[string]metav1.Condition{}
+	var pending []string
+	err := followStatus(ctx, cli, resourceTypes, opts.namespace, func(statuses []ResourceStatus) (bool, error) {
+		for _, event := range statusEvents(statuses, last) {
+			if err := writeStatusEvent(out, event, opts.output); err != nil {
+				return false, err
+			}
+		}
+		if opts.waitFor == "" {
+			return false, nil
+		}
+		pending = pendingResourceStatuses(statuses, opts.waitFor)
+		return len(pending) == 0, nil
+	})
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+	return &ExitError{Code: 1, Err: waitForTimeoutError(opts.waitFor, pending)}
+}
+
+// waitForStatus waits until the condition is true for all the resources, and returns the resources
+// which aren't ready when the context is done.
+func waitForStatus(ctx context.Context, cli client.WithWatch, resourceTypes []string, opts *statusOptions) ([]string, error) {
+	var pending []string
+	err := followStatus(ctx, cli, resourceTypes, opts.namespace, func(statuses []ResourceStatus) (bool, error) {
+		pending = pendingResourceStatuses(statuses, opts.waitFor)
+		return len(pending) == 0, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// followStatus calls the handler with the statuses of the resources, and again every time the resources
+// change, until the handler is done or fails, or the context is done.
+//
+// The changes are observed with watches of the resources. The first listing of the resources must succeed,
+// whereas a later listing which fails is retried, and its error is only returned if the context is done
+// before a listing succeeds again.
+func followStatus(ctx context.Context, cli client.WithWatch, resourceTypes []string, namespace string,
+	handle func(statuses []ResourceStatus) (bool, error),
+) error {
+	var watches sync.WaitGroup
+	defer watches.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changed, err := watchStatusResources(ctx, cli, resourceTypes, namespace, &watches)
+	if err != nil {
+		return err
+	}
+
+	var listErr error
+	for listed := false; ; listed = true {
+		var retry <-chan time.Time
+		statuses, err := collectResourceStatuses(ctx, cli, resourceTypes, namespace)
+		switch {
+		case err == nil:
+			listErr = nil
+			if done, err := handle(statuses); done || err != nil {
+				return err
+			}
+		case !listed:
+			return err
+		case ctx.Err() == nil:
+			listErr = err
+			retry = time.After(statusRetryInterval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return listErr
+		case <-changed:
+		case <-retry:
+		}
+	}
+}
+
+// watchStatusResources watches the resources of the types, and notifies their changes on the returned
+// channel until the context is done. A watch which fails or is closed by the API server is opened again,
+// after which a change is notified, since changes may have been missed meanwhile.
+func watchStatusResources(ctx context.Context, cli client.WithWatch, resourceTypes []string, namespace string,
+	watches *sync.WaitGroup,
+) (<-chan struct{}, error) {
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	for _, rt := range resourceTypes {
+		resourcesList, _, err := newStatusResourceList(rt)
+		if err != nil {
+			return nil, err
+		}
+		// The watches are opened before the resources are listed, so that no change is missed.
+		w, err := cli.Watch(ctx, resourcesList, client.InNamespace(namespace))
+		if err != nil {
+			return nil, err
+		}
+
+		watches.Add(1)
+		go func() {
+			defer watches.Done()
+			for {
+				consumeWatch(ctx, w, notify)
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(statusRetryInterval):
+					}
+					var err error
+					if w, err = cli.Watch(ctx, resourcesList, client.InNamespace(namespace)); err == nil {
+						break
+					}
+				}
+				notify()
+			}
+		}()
+	}
+	return changed, nil
+}
+
+// consumeWatch notifies the events of the watch until it is closed, or the context is done.
+func consumeWatch(ctx context.Context, w watch.Interface, notify func()) {
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			notify()
+		}
+	}
+}
+
+// pendingResourceStatuses describes the resources whose condition isn't true for the current generation,
+// for all their parents or ancestors.
+func pendingResourceStatuses(statuses []ResourceStatus, conditionType string) []string {
+	if len(statuses) == 0 {
+		return []string{"no resources found"}
+	}
+
+	var pending []string
+	for i := range statuses {
+		s := &statuses[i]
+		resourceName := s.Kind + " " + s.Name
+		if s.Namespace != "" {
+			resourceName = s.Kind + " " + s.Namespace + "/" + s.Name
+		}
+		check := func(prefix string, conditions []metav1.Condition) {
+			c := meta.FindStatusCondition(conditions, conditionType)
+			switch {
+			case c == nil:
+				pending = append(pending, fmt.Sprintf("%s: %s is missing", prefix, conditionType))
+			case c.Status != metav1.ConditionTrue:
+				pending = append(pending, fmt.Sprintf("%s: %s is %s (%s)", prefix, conditionType, c.Status, c.Reason))
+			case c.ObservedGeneration < s.Generation:
+				pending = append(pending, fmt.Sprintf("%s: %s is observed for the generation %d of %d",
+					prefix, conditionType, c.ObservedGeneration, s.Generation))
+			}
+		}
+
+		switch {
+		case strings.HasSuffix(s.Kind, "Route"):
+			if len(s.Parents) == 0 {
+				pending = append(pending, resourceName+": no parent status")
+			}
+			for _, p := range s.Parents {
+				check(resourceName+" for "+p.String(), p.Conditions)
+			}
+		case strings.HasSuffix(s.Kind, "Policy"):
+			if len(s.Ancestors) == 0 {
+				pending = append(pending, resourceName+": no ancestor status")
+			}
+			for _, a := range s.Ancestors {
+				check(resourceName+" for "+a.String(), a.Conditions)
+			}
+		default:
+			check(resourceName, s.Conditions)
+		}
+	}
+	return pending
+}
+
+func waitForTimeoutError(conditionType string, pending []string) error {
+	return fmt.Errorf("timed out waiting for the %s condition:\n  %s", conditionType, strings.Join(pending, "\n  "))
+}
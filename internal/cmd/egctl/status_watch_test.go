@@ -0,0 +1,284 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package egctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/envoygateway"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+)
+
+var statusTestTime = metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+func statusTestCondition(conditionType string, status metav1.ConditionStatus, generation int64) metav1.Condition {
+	return metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             conditionType,
+		ObservedGeneration: generation,
+		LastTransitionTime: statusTestTime,
+	}
+}
+
+func statusTestAncestor(conditions ...metav1.Condition) []gwapiv1a2.PolicyAncestorStatus {
+	return []gwapiv1a2.PolicyAncestorStatus{{
+		AncestorRef:    gwapiv1.ParentReference{Name: "eg"},
+		ControllerName: "gateway.envoyproxy.io/gatewayclass-controller",
+		Conditions:     conditions,
+	}}
+}
+
+func newStatusTestClient(accepted metav1.ConditionStatus) client.WithWatch {
+	return fakeclient.NewClientBuilder().
+		WithScheme(envoygateway.GetScheme()).
+		WithObjects(statusTestObjects(accepted)...).
+		Build()
+}
+
+func statusTestObjects(accepted metav1.ConditionStatus) []client.Object {
+	gateway := &gwapiv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "eg", Namespace: "default", Generation: 1},
+		Status: gwapiv1.GatewayStatus{Conditions: []metav1.Condition{
+			statusTestCondition(string(gwapiv1.GatewayConditionAccepted), metav1.ConditionTrue, 1),
+			statusTestCondition(string(gwapiv1.GatewayConditionProgrammed), metav1.ConditionTrue, 1),
+		}},
+	}
+	route := &gwapiv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default", Generation: 2},
+		Spec: gwapiv1.HTTPRouteSpec{CommonRouteSpec: gwapiv1.CommonRouteSpec{
+			ParentRefs: []gwapiv1.ParentReference{{Name: "eg"}},
+		}},
+		Status: gwapiv1.HTTPRouteStatus{RouteStatus: gwapiv1.RouteStatus{Parents: []gwapiv1.RouteParentStatus{{
+			ParentRef:      gwapiv1.ParentReference{Name: "eg", SectionName: ptr.To(gwapiv1.SectionName("http"))},
+			ControllerName: "gateway.envoyproxy.io/gatewayclass-controller",
+			Conditions: []metav1.Condition{
+				statusTestCondition(string(gwapiv1.RouteConditionAccepted), accepted, 2),
+				statusTestCondition(string(gwapiv1.RouteConditionResolvedRefs), metav1.ConditionTrue, 2),
+			},
+		}}}},
+	}
+	btp := &egv1a1.BackendTrafficPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway-timeouts", Namespace: "default"},
+		Spec: egv1a1.BackendTrafficPolicySpec{PolicyTargetReferences: egv1a1.PolicyTargetReferences{
+			TargetRefs: []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{{
+				LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{Group: gwapiv1.GroupName, Kind: resource.KindGateway, Name: "eg"},
+			}},
+		}},
+		Status: gwapiv1a2.PolicyStatus{Ancestors: statusTestAncestor(
+			statusTestCondition(string(gwapiv1a2.PolicyConditionAccepted), metav1.ConditionTrue, 0))},
+	}
+	sp := &egv1a1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend-jwt", Namespace: "default"},
+		Spec: egv1a1.SecurityPolicySpec{PolicyTargetReferences: egv1a1.PolicyTargetReferences{
+			TargetRefs: []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{{
+				LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{Group: gwapiv1.GroupName, Kind: resource.KindHTTPRoute, Name: "backend"},
+			}},
+		}},
+		Status: gwapiv1a2.PolicyStatus{Ancestors: statusTestAncestor(
+			statusTestCondition(string(gwapiv1a2.PolicyConditionAccepted), metav1.ConditionTrue, 0))},
+	}
+
+	return []client.Object{gateway, route, btp, sp}
+}
+
+func TestCollectResourceStatuses(t *testing.T) {
+	cli := newStatusTestClient(metav1.ConditionTrue)
+
+	statuses, err := collectResourceStatuses(context.Background(), cli, []string{"gateway", "httproute"}, "default")
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	require.Equal(t, resource.KindGateway, statuses[0].Kind)
+	require.Len(t, statuses[0].Conditions, 2)
+	require.Empty(t, statuses[0].Parents)
+
+	route := statuses[1]
+	require.Equal(t, resource.KindHTTPRoute, route.Kind)
+	require.Equal(t, int64(2), route.Generation)
+	require.Len(t, route.Parents, 1)
+	parent := route.Parents[0]
+	require.Equal(t, "Gateway default/eg/http", parent.String())
+	require.Equal(t, []SimulatedPolicy{
+		{Kind: resource.KindBackendTrafficPolicy, Namespace: "default", Name: "gateway-timeouts", Target: "Gateway default/eg"},
+		{Kind: resource.KindSecurityPolicy, Namespace: "default", Name: "backend-jwt", Target: "HTTPRoute default/backend"},
+	}, parent.Policies)
+
+	var out bytes.Buffer
+	require.NoError(t, writeResourceStatuses(&out, statuses, jsonOutput))
+	var decoded []ResourceStatus
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	require.Equal(t, statuses, decoded)
+}
+
+func TestRunStatusCommandOutput(t *testing.T) {
+	cli := newStatusTestClient(metav1.ConditionTrue)
+
+	var out bytes.Buffer
+	require.NoError(t, runStatusCommand(context.Background(), &out, cli, []string{"httproute"},
+		&statusOptions{namespace: "default", output: yamlOutput}))
+	require.Contains(t, out.String(), "- generation: 2\n  kind: HTTPRoute\n  name: backend\n  namespace: default\n")
+	require.Contains(t, out.String(), "target: Gateway default/eg\n")
+
+	routes, kind, err := listStatusResources(context.Background(), cli, "httproute", "default")
+	require.NoError(t, err)
+	policies, err := listAttachedPolicies(context.Background(), cli, "default")
+	require.NoError(t, err)
+	out.Reset()
+	table := newStatusTableWriter(&out)
+	writeStatusTable(table, []string{"NAME", "PARENT", "POLICIES"}, fetchRoutePoliciesBody(routes, kind, policies, false, false))
+	require.NoError(t, table.Flush())
+	require.Equal(t, `NAME      PARENT       POLICIES
+backend   gateway/eg   BackendTrafficPolicy default/gateway-timeouts, SecurityPolicy default/backend-jwt
+`, out.String())
+}
+
+func TestRunStatusCommandWaitFor(t *testing.T) {
+	testCases := []struct {
+		name     string
+		accepted metav1.ConditionStatus
+		types    []string
+		pending  []string
+	}{
+		{
+			name:     "ready",
+			accepted: metav1.ConditionTrue,
+			types:    []string{"gateway", "httproute"},
+		},
+		{
+			name:     "route not accepted",
+			accepted: metav1.ConditionFalse,
+			types:    []string{"gateway", "httproute"},
+			pending:  []string{"HTTPRoute default/backend for Gateway default/eg/http: Accepted is False (Accepted)"},
+		},
+		{
+			name:     "no resources",
+			accepted: metav1.ConditionTrue,
+			types:    []string{"grpcroute"},
+			pending:  []string{"no resources found"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := newStatusTestClient(tc.accepted)
+			opts := &statusOptions{
+				namespace: "default",
+				output:    jsonOutput,
+				waitFor:   string(gwapiv1.RouteConditionAccepted),
+				timeout:   50 * time.Millisecond,
+			}
+
+			var out bytes.Buffer
+			err := runStatusCommand(context.Background(), &out, cli, tc.types, opts)
+			if tc.pending == nil {
+				require.NoError(t, err)
+				return
+			}
+			var exitErr *ExitError
+			require.True(t, errors.As(err, &exitErr))
+			require.Equal(t, 1, exitErr.Code)
+			require.Equal(t, waitForTimeoutError(opts.waitFor, tc.pending).Error(), err.Error())
+			// The status is shown even when the wait timed out.
+			require.True(t, strings.HasPrefix(out.String(), "["))
+		})
+	}
+}
+
+// acceptStatusTestRoute accepts the route of the status test client after a while.
+func acceptStatusTestRoute(cli client.Client) {
+	time.Sleep(50 * time.Millisecond)
+	route := &gwapiv1.HTTPRoute{}
+	if err := cli.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "backend"}, route); err != nil {
+		return
+	}
+	route.Status.Parents[0].Conditions[0] = statusTestCondition(string(gwapiv1.RouteConditionAccepted), metav1.ConditionTrue, 2)
+	route.Status.Parents[0].Conditions[0].LastTransitionTime = metav1.NewTime(statusTestTime.Add(time.Minute))
+	_ = cli.Update(context.Background(), route)
+}
+
+func TestRunStatusCommandWaitForUnreported(t *testing.T) {
+	cli := newStatusTestClient(metav1.ConditionTrue)
+	opts := &statusOptions{
+		namespace: "default",
+		output:    jsonOutput,
+		waitFor:   string(gwapiv1.GatewayConditionProgrammed),
+		timeout:   time.Minute,
+	}
+
+	require.NoError(t, runStatusCommand(context.Background(), io.Discard, cli, []string{"gateway"}, opts))
+	require.EqualError(t, runStatusCommand(context.Background(), io.Discard, cli, []string{"gateway", "httproute"}, opts),
+		"the Programmed condition isn't reported for HTTPRoute resources, the reported conditions are: Accepted, ResolvedRefs")
+	require.EqualError(t, runStatusCommand(context.Background(), io.Discard, cli, []string{"gatewayclass"}, opts),
+		"the Programmed condition isn't reported for GatewayClass resources, the reported conditions are: Accepted")
+}
+
+func TestWaitForStatusListError(t *testing.T) {
+	defer func(interval time.Duration) { statusRetryInterval = interval }(statusRetryInterval)
+	statusRetryInterval = 10 * time.Millisecond
+
+	// The listing after the first one fails.
+	var lists atomic.Int32
+	cli := fakeclient.NewClientBuilder().
+		WithScheme(envoygateway.GetScheme()).
+		WithObjects(statusTestObjects(metav1.ConditionFalse)...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, cli client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if _, ok := list.(*gwapiv1.HTTPRouteList); ok && lists.Add(1) == 2 {
+					return errors.New("connection refused")
+				}
+				return cli.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+	opts := &statusOptions{
+		namespace: "default",
+		waitFor:   string(gwapiv1.RouteConditionAccepted),
+		timeout:   10 * time.Second,
+	}
+
+	go acceptStatusTestRoute(cli)
+	pending, err := waitForStatus(context.Background(), cli, []string{"httproute"}, opts)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+	require.GreaterOrEqual(t, lists.Load(), int32(3))
+}
+
+func TestWatchStatus(t *testing.T) {
+	cli := newStatusTestClient(metav1.ConditionFalse)
+	opts := &statusOptions{
+		namespace: "default",
+		watch:     true,
+		waitFor:   string(gwapiv1.RouteConditionAccepted),
+		timeout:   10 * time.Second,
+	}
+
+	go acceptStatusTestRoute(cli)
+
+	var out bytes.Buffer
+	require.NoError(t, runStatusCommand(context.Background(), &out, cli, []string{"httproute"}, opts))
+	require.Equal(t, `2024-01-01T00:00:00Z  HTTPRoute default/backend for Gateway default/eg/http  Accepted=False  Accepted
+2024-01-01T00:00:00Z  HTTPRoute default/backend for Gateway default/eg/http  ResolvedRefs=True  ResolvedRefs
+2024-01-01T00:01:00Z  HTTPRoute default/backend for Gateway default/eg/http  Accepted=True  Accepted
+`, out.String())
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"sort"
 
 	adminv3 "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
@@ -20,23 +21,19 @@ import (
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/anypb"
-	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 	"sigs.k8s.io/yaml"
 
-	"github.com/wukongcloud/gateway/api/v1alpha1/validation"
-	"github.com/wukongcloud/gateway/internal/gatewayapi"
 	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
-	"github.com/wukongcloud/gateway/internal/gatewayapi/status"
-	"github.com/wukongcloud/gateway/internal/infrastructure/kubernetes/ratelimit"
 	"github.com/wukongcloud/gateway/internal/xds/bootstrap"
-	"github.com/wukongcloud/gateway/internal/xds/translator"
 	xds_types "github.com/wukongcloud/gateway/internal/xds/types"
+	"github.com/wukongcloud/gateway/pkg/offline"
 )
 
 const (
-	gatewayAPIType = "gateway-api"
-	xdsType        = "xds"
-	irType         = "ir"
+	gatewayAPIType     = "gateway-api"
+	xdsType            = "xds"
+	irType             = "ir"
+	envoyConfigOutType = "envoy-config"
 )
 
 type TranslationResult struct {
@@ -53,6 +50,7 @@ func newTranslateCommand() *cobra.Command {
 		outTypes                             []string
 		dnsDomain                            string
 		namespace                            string
+		gateway                              string
 	)
 
 	translateCommand := &cobra.Command{
@@ -91,9 +89,16 @@ func newTranslateCommand() *cobra.Command {
 
   # Translate Gateway API Resources into IR in YAML output,
   egctl experimental translate --from gateway-api --to ir --output yaml --file <input file>
+
+  # Translate Gateway API Resources into a standalone Envoy configuration, and run it.
+  egctl experimental translate --from gateway-api --to envoy-config --file <input file> > envoy.yaml
+  envoy -c envoy.yaml
+
+  # Translate the resources of a Gateway into a standalone Envoy configuration, when several Gateways are translated.
+  egctl x translate --to envoy-config --gateway <namespace>/<name> -f <input file>
 	`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return translate(cmd.OutOrStdout(), inFile, inType, outTypes, output, resourceType, addMissingResources, namespace, dnsDomain)
+			return translate(cmd.OutOrStdout(), inFile, inType, outTypes, output, resourceType, addMissingResources, namespace, dnsDomain, gateway)
 		},
 	}
 
@@ -108,6 +113,8 @@ func newTranslateCommand() *cobra.Command {
 	translateCommand.PersistentFlags().BoolVarP(&addMissingResources, "add-missing-resources", "", false, "Provides dummy resources if missed")
 	translateCommand.PersistentFlags().StringVarP(&dnsDomain, "dns-domain", "", "cluster.local", "DNS domain used by k8s services, default is cluster.local")
 	translateCommand.PersistentFlags().StringVarP(&namespace, "namespace", "n", "envoy-gateway-system", "Namespace where envoy gateway is installed.")
+	translateCommand.PersistentFlags().StringVarP(&gateway, "gateway", "", "", "Gateway, as namespace/name, or GatewayClass name with merged Gateways, "+
+		"whose resources are translated into the envoy-config output. Required if several Gateways are translated.")
 
 	return translateCommand
 }
@@ -130,7 +137,7 @@ func getValidInputTypesStr() string {
 }
 
 func validOutputTypes() []string {
-	return []string{xdsType, gatewayAPIType, irType}
+	return []string{xdsType, gatewayAPIType, irType, envoyConfigOutType}
 }
 
 func findInvalidOutputType(outTypes []string) string {
@@ -208,11 +215,14 @@ func validate(inFile, inType string, outTypes []string, resourceType string) err
 	if inFile == "" {
 		return fmt.Errorf("--file must be specified")
 	}
+	if len(outTypes) > 1 && slices.Contains(outTypes, envoyConfigOutType) {
+		return fmt.Errorf("%s can't be combined with other output types", envoyConfigOutType)
+	}
 
 	return nil
 }
 
-func translate(w io.Writer, inFile, inType string, outTypes []string, output, resourceType string, addMissingResources bool, namespace, dnsDomain string) error {
+func translate(w io.Writer, inFile, inType string, outTypes []string, output, resourceType string, addMissingResources bool, namespace, dnsDomain, gateway string) error {
 	if err := validate(inFile, inType, outTypes, resourceType); err != nil {
 		return err
 	}
@@ -229,28 +239,34 @@ func translate(w io.Writer, inFile, inType string, outTypes []string, output, re
 			return fmt.Errorf("unable to unmarshal input: %w", err)
 		}
 
+		// Translate
+		res, err := translateGatewayAPI(resources, namespace, dnsDomain)
+		// The failures of the xDS translation only fail the outputs including xDS resources,
+		// they are reflected in the statuses of the resources otherwise.
+		if res == nil || (err != nil && (slices.Contains(outTypes, xdsType) || slices.Contains(outTypes, envoyConfigOutType))) {
+			return err
+		}
+
+		// The Envoy configuration is printed alone, so it can be passed to Envoy as is.
+		if outTypes[0] == envoyConfigOutType {
+			envoyConfig, err := envoyConfigOutput(res, gateway)
+			if err != nil {
+				return err
+			}
+			return printEnvoyConfig(w, envoyConfig, output)
+		}
+
 		var result TranslationResult
 		for _, outType := range outTypes {
-			// Translate
-			if outType == gatewayAPIType {
-				result.Resources, err = translateGatewayAPIToGatewayAPI(resources)
-				if err != nil {
-					return err
-				}
-			}
-			if outType == xdsType {
-				res, err := TranslateGatewayAPIToXds(namespace, dnsDomain, resourceType, resources)
-				if err != nil {
-					return err
-				}
-				result.Xds = res
-			}
-			if outType == irType {
-				res, err := translateGatewayAPIToIR(resources)
-				if err != nil {
+			switch outType {
+			case gatewayAPIType:
+				result.Resources = *res.Resources
+			case xdsType:
+				if result.Xds, err = xdsOutput(res, resourceType); err != nil {
 					return err
 				}
-				result.Resources = res.Resources
+			case irType:
+				result.Resources = *res.Resources
 				result.XdsIR = res.XdsIR
 				result.InfraIR = res.InfraIR
 			}
@@ -265,114 +281,28 @@ func translate(w io.Writer, inFile, inType string, outTypes []string, output, re
 	return fmt.Errorf("unable to find translate from input type %s to output type %s", inType, outTypes)
 }
 
-func translateGatewayAPIToIR(resources *resource.Resources) (*gatewayapi.TranslateResult, error) {
-	if resources.GatewayClass == nil {
-		return nil, fmt.Errorf("the GatewayClass resource is required")
-	}
-
-	t := &gatewayapi.Translator{
-		GatewayControllerName:   string(resources.GatewayClass.Spec.ControllerName),
-		GatewayClassName:        gwapiv1.ObjectName(resources.GatewayClass.Name),
-		GlobalRateLimitEnabled:  true,
-		EndpointRoutingDisabled: true,
-		EnvoyPatchPolicyEnabled: true,
-		BackendEnabled:          true,
-	}
-
-	// Fix the services in the resources section so that they have an IP address - this prevents nasty
-	// errors in the translation.
-	for _, svc := range resources.Services {
-		if svc.Spec.ClusterIP == "" {
-			svc.Spec.ClusterIP = "10.96.1.2"
-		}
-	}
-
-	result, _ := t.Translate(resources)
-
-	return result, nil
-}
-
-func translateGatewayAPIToGatewayAPI(resources *resource.Resources) (resource.Resources, error) {
-	if resources.GatewayClass == nil {
-		return resource.Resources{}, fmt.Errorf("the GatewayClass resource is required")
-	}
-
-	// Translate from Gateway API to Xds IR
-	gTranslator := &gatewayapi.Translator{
-		GatewayControllerName:   string(resources.GatewayClass.Spec.ControllerName),
-		GatewayClassName:        gwapiv1.ObjectName(resources.GatewayClass.Name),
-		GlobalRateLimitEnabled:  true,
-		EndpointRoutingDisabled: true,
-		EnvoyPatchPolicyEnabled: true,
-		BackendEnabled:          true,
-	}
-	gRes, _ := gTranslator.Translate(resources)
-	// Update the status of the GatewayClass based on EnvoyProxy validation
-	epInvalid := false
-	if resources.EnvoyProxyForGatewayClass != nil {
-		if err := validation.ValidateEnvoyProxy(resources.EnvoyProxyForGatewayClass); err != nil {
-			epInvalid = true
-			msg := fmt.Sprintf("%s: %v", status.MsgGatewayClassInvalidParams, err)
-			status.SetGatewayClassAccepted(resources.GatewayClass, false, string(gwapiv1.GatewayClassReasonInvalidParameters), msg)
-		}
-		if err := bootstrap.Validate(resources.EnvoyProxyForGatewayClass.Spec.Bootstrap); err != nil {
-			epInvalid = true
-			msg := fmt.Sprintf("%s: %v", status.MsgGatewayClassInvalidParams, err)
-			status.SetGatewayClassAccepted(resources.GatewayClass, false, string(gwapiv1.GatewayClassReasonInvalidParameters), msg)
-		}
-		gRes.EnvoyProxyForGatewayClass = resources.EnvoyProxyForGatewayClass
-	}
-
-	if !epInvalid {
-		status.SetGatewayClassAccepted(resources.GatewayClass, true, string(gwapiv1.GatewayClassReasonAccepted), status.MsgValidGatewayClass)
-	}
-
-	gRes.GatewayClass = resources.GatewayClass
-	return gRes.Resources, nil
+// translateGatewayAPI translates the resources into the xDS IR, the xDS resources and the
+// statuses of the resources, like Envoy Gateway does. All the commands translating resources
+// go through it. The default namespace and DNS domain of Envoy Gateway are used if unset.
+func translateGatewayAPI(resources *resource.Resources, namespace, dnsDomain string) (*offline.Result, error) {
+	return offline.Translate(resources, &offline.Options{Namespace: namespace, DNSDomain: dnsDomain})
 }
 
+// TranslateGatewayAPIToXds translates the resources into the config dumps of the xDS resources
+// of the given type, keyed by IR key.
 func TranslateGatewayAPIToXds(namespace, dnsDomain, resourceType string, resources *resource.Resources) (map[string]any, error) {
-	if resources.GatewayClass == nil {
-		return nil, fmt.Errorf("the GatewayClass resource is required")
-	}
-
-	// Translate from Gateway API to Xds IR
-	gTranslator := &gatewayapi.Translator{
-		GatewayControllerName:   string(resources.GatewayClass.Spec.ControllerName),
-		GatewayClassName:        gwapiv1.ObjectName(resources.GatewayClass.Name),
-		GlobalRateLimitEnabled:  true,
-		EndpointRoutingDisabled: true,
-		EnvoyPatchPolicyEnabled: true,
-		BackendEnabled:          true,
-	}
-	gRes, _ := gTranslator.Translate(resources)
-
-	keys := []string{}
-	for key := range gRes.XdsIR {
-		keys = append(keys, key)
+	res, err := translateGatewayAPI(resources, namespace, dnsDomain)
+	if err != nil {
+		return nil, err
 	}
-	// Make output stable since XdsIR is a map
-	sort.Strings(keys)
-
-	// Translate from Xds IR to Xds
-	result := make(map[string]interface{})
-	for _, key := range keys {
-		val := gRes.XdsIR[key]
-		xTranslator := &translator.Translator{
-			// Set some default settings for translation
-			GlobalRateLimit: &translator.GlobalRateLimitSettings{
-				ServiceURL: ratelimit.GetServiceURL(namespace, dnsDomain),
-			},
-		}
-		if resources.EnvoyProxyForGatewayClass != nil {
-			xTranslator.FilterOrder = resources.EnvoyProxyForGatewayClass.Spec.FilterOrder
-		}
-		xRes, err := xTranslator.Translate(val)
-		if err != nil {
-			return nil, fmt.Errorf("failed to translate xds ir for key %s value %+v, error:%w", key, val, err)
-		}
+	return xdsOutput(res, resourceType)
+}
 
-		globalConfigs, err := constructConfigDump(resources, xRes)
+// xdsOutput returns the config dumps of the xDS resources of the given type, keyed by IR key.
+func xdsOutput(res *offline.Result, resourceType string) (map[string]any, error) {
+	result := make(map[string]any, len(res.Xds))
+	for key, xRes := range res.Xds {
+		globalConfigs, err := constructConfigDump(res.Resources, xRes)
 		if err != nil {
 			return nil, err
 		}
@@ -405,6 +335,50 @@ func TranslateGatewayAPIToXds(namespace, dnsDomain, resourceType string, resourc
 	return result, nil
 }
 
+// envoyConfigOutput returns a standalone Envoy bootstrap, serving the xDS resources of the
+// Gateway without a control plane.
+func envoyConfigOutput(res *offline.Result, gateway string) (*bootstrapv3.Bootstrap, error) {
+	keys := make([]string, 0, len(res.Xds))
+	for key := range res.Xds {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	switch {
+	case gateway != "":
+		if _, ok := res.Xds[gateway]; !ok {
+			return nil, fmt.Errorf("gateway %s not found in the translated gateways %v", gateway, keys)
+		}
+	case len(keys) == 0:
+		return nil, fmt.Errorf("no gateway translated")
+	case len(keys) > 1:
+		return nil, fmt.Errorf("several gateways translated %v, select one with --gateway", keys)
+	default:
+		gateway = keys[0]
+	}
+
+	bootstrapConfigurations, err := renderBootstrapConfig(res.Resources)
+	if err != nil {
+		return nil, err
+	}
+	return bootstrap.GetStaticBootstrapConfig(bootstrapConfigurations, res.Xds[gateway].XdsResources)
+}
+
+// printEnvoyConfig prints the Envoy bootstrap
+func printEnvoyConfig(w io.Writer, envoyConfig *bootstrapv3.Bootstrap, output string) error {
+	out, err := protojson.MarshalOptions{Multiline: true}.Marshal(envoyConfig)
+	if err != nil {
+		return err
+	}
+	if output != jsonOutput {
+		if out, err = yaml.JSONToYAML(out); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
 // printOutput prints the echo-backed gateway API and xDS output
 func printOutput(w io.Writer, result TranslationResult, output string) error {
 	var (
@@ -426,6 +400,22 @@ func printOutput(w io.Writer, result TranslationResult, output string) error {
 	return err
 }
 
+// renderBootstrapConfig renders the bootstrap YAML, with the Bootstrap of the EnvoyProxy
+// attached to the GatewayClass applied.
+func renderBootstrapConfig(resources *resource.Resources) (string, error) {
+	bootstrapConfigurations, err := bootstrap.GetRenderedBootstrapConfig(nil)
+	if err != nil {
+		return "", err
+	}
+
+	// Apply Bootstrap from EnvoyProxy API if set by the user
+	// The config should have been validated already
+	if resources.EnvoyProxyForGatewayClass != nil && resources.EnvoyProxyForGatewayClass.Spec.Bootstrap != nil {
+		return bootstrap.ApplyBootstrapConfig(resources.EnvoyProxyForGatewayClass.Spec.Bootstrap, bootstrapConfigurations)
+	}
+	return bootstrapConfigurations, nil
+}
+
 // constructConfigDump constructs configDump from ResourceVersionTable and BootstrapConfig
 func constructConfigDump(resources *resource.Resources, tCtx *xds_types.ResourceVersionTable) (*adminv3.ConfigDump, error) {
 	globalConfigs := &adminv3.ConfigDump{}
@@ -437,21 +427,11 @@ func constructConfigDump(resources *resource.Resources, tCtx *xds_types.Resource
 	endpointConfigs := &adminv3.EndpointsConfigDump{}
 
 	// construct bootstrap config
-	var bootstrapConfigurations string
-	var err error
-	if bootstrapConfigurations, err = bootstrap.GetRenderedBootstrapConfig(nil); err != nil {
+	bootstrapConfigurations, err := renderBootstrapConfig(resources)
+	if err != nil {
 		return nil, err
 	}
 
-	// Apply Bootstrap from EnvoyProxy API if set by the user
-	// The config should have been validated already
-	if resources.EnvoyProxyForGatewayClass != nil && resources.EnvoyProxyForGatewayClass.Spec.Bootstrap != nil {
-		bootstrapConfigurations, err = bootstrap.ApplyBootstrapConfig(resources.EnvoyProxyForGatewayClass.Spec.Bootstrap, bootstrapConfigurations)
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	jsonData, err := yaml.YAMLToJSON([]byte(bootstrapConfigurations))
 	if err != nil {
 		return nil, err
@@ -13,9 +13,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	bootstrapv3 "github.com/envoyproxy/go-control-plane/envoy/config/bootstrap/v3"
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/require"
@@ -25,6 +28,7 @@ import (
 	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
 	"github.com/wukongcloud/gateway/internal/utils/field"
 	"github.com/wukongcloud/gateway/internal/utils/file"
+	"github.com/wukongcloud/gateway/internal/utils/proto"
 	"github.com/wukongcloud/gateway/internal/utils/test"
 )
 
@@ -385,6 +389,75 @@ func TestTranslate(t *testing.T) {
 	}
 }
 
+func TestTranslateToEnvoyConfig(t *testing.T) {
+	testCases := []struct {
+		name      string
+		extraArgs []string
+		output    string
+		expectErr string
+	}{
+		{
+			name: "envoy-config",
+		},
+		{
+			name:   "envoy-config",
+			output: jsonOutput,
+		},
+		{
+			name:      "multiple-xds",
+			expectErr: "several gateways translated [default/eg default/eg2], select one with --gateway",
+		},
+		{
+			name:      "multiple-xds",
+			extraArgs: []string{"--gateway", "default/eg2"},
+		},
+		{
+			name:      "multiple-xds",
+			extraArgs: []string{"--gateway", "default/eg3"},
+			expectErr: "gateway default/eg3 not found",
+		},
+		{
+			name:      "envoy-config",
+			extraArgs: []string{"--to", "envoy-config,xds"},
+			expectErr: "envoy-config can't be combined with other output types",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"|"+tc.output+"|"+strings.Join(tc.extraArgs, " "), func(t *testing.T) {
+			b := bytes.NewBufferString("")
+			root := newTranslateCommand()
+			root.SetOut(b)
+			root.SetErr(b)
+			args := []string{"--to", envoyConfigOutType, "--file", "testdata/translate/in/" + tc.name + ".yaml"}
+			if tc.output != "" {
+				args = append(args, "--output", tc.output)
+			}
+			root.SetArgs(append(args, tc.extraArgs...))
+
+			err := root.ExecuteContext(context.Background())
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			envoyConfig := &bootstrapv3.Bootstrap{}
+			require.NoError(t, proto.FromYAML(b.Bytes(), envoyConfig))
+			require.NoError(t, envoyConfig.Validate())
+			require.Nil(t, envoyConfig.DynamicResources)
+			require.NotEmpty(t, envoyConfig.StaticResources.Listeners)
+			for _, cluster := range envoyConfig.StaticResources.Clusters {
+				require.NotEqual(t, clusterv3.Cluster_EDS, cluster.GetType(), cluster.Name)
+			}
+			if tc.name == "envoy-config" {
+				require.Len(t, envoyConfig.StaticResources.Secrets, 1)
+				require.Equal(t, "default/example-cert", envoyConfig.StaticResources.Secrets[0].Name)
+			}
+		})
+	}
+}
+
 func requireTestDataOutFile(t *testing.T, name ...string) []byte {
 	t.Helper()
 	elems := append([]string{"testdata", "translate", "out"}, name...)
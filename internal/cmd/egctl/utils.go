@@ -7,6 +7,8 @@ package egctl
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 
 	adminv3 "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -14,6 +16,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
 	"github.com/wukongcloud/gateway/internal/envoygateway"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
 )
 
 type envoyConfigType string
@@ -69,13 +72,39 @@ func findXDSResourceFromConfigDump(resourceType envoyConfigType, globalConfigs *
 	return nil, fmt.Errorf("unknown resourceType %s", resourceType)
 }
 
-func newK8sClient() (client.Client, error) {
+func newK8sClient() (client.WithWatch, error) {
 	scheme := envoygateway.GetScheme()
 
-	cli, err := client.New(config.GetConfigOrDie(), client.Options{Scheme: scheme})
+	cli, err := client.NewWithWatch(config.GetConfigOrDie(), client.Options{Scheme: scheme})
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize Kubernetes client: %w", err)
 	}
 
 	return cli, nil
 }
+
+// irRouteNameRegex matches the names of the routes of the xDS IR translated from the
+// HTTPRoute and GRPCRoute rules, e.g. "httproute/default/backend/rule/0/match/1/www_example_com".
+var irRouteNameRegex = regexp.MustCompile(`^(httproute|grpcroute)/([^/]+)/([^/]+)/rule/(\d+)/(?:match/(-?\d+)/)?`)
+
+// irRouteRef is the route rule and match an xDS IR route is translated from.
+type irRouteRef struct {
+	kind, namespace, name string
+	rule, match           int
+}
+
+// parseIRRouteName returns the route rule and match of an xDS IR route name, or false if
+// the route isn't translated from an HTTPRoute or GRPCRoute rule.
+func parseIRRouteName(name string) (irRouteRef, bool) {
+	m := irRouteNameRegex.FindStringSubmatch(name)
+	if m == nil {
+		return irRouteRef{}, false
+	}
+	ref := irRouteRef{kind: resource.KindHTTPRoute, namespace: m[2], name: m[3]}
+	if m[1] == "grpcroute" {
+		ref.kind = resource.KindGRPCRoute
+	}
+	ref.rule, _ = strconv.Atoi(m[4])
+	ref.match, _ = strconv.Atoi(m[5])
+	return ref, true
+}
@@ -13,6 +13,9 @@ import (
 
 	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
 	"github.com/wukongcloud/gateway/internal/admin"
+	"github.com/wukongcloud/gateway/internal/admin/configdump"
+	"github.com/wukongcloud/gateway/internal/admin/loglevel"
+	"github.com/wukongcloud/gateway/internal/admin/topology"
 	"github.com/wukongcloud/gateway/internal/envoygateway/config"
 	"github.com/wukongcloud/gateway/internal/envoygateway/config/loader"
 	extensionregistry "github.com/wukongcloud/gateway/internal/extension/registry"
@@ -144,15 +147,19 @@ func getConfigByPath(logOut io.Writer, cfgPath string) (*config.Server, error) {
 // closing all the runners.
 func startRunners(ctx context.Context, cfg *config.Server) (err error) {
 	channels := struct {
-		pResources *message.ProviderResources
-		xdsIR      *message.XdsIR
-		infraIR    *message.InfraIR
-		xds        *message.Xds
+		pResources    *message.ProviderResources
+		xdsIR         *message.XdsIR
+		infraIR       *message.InfraIR
+		xds           *message.Xds
+		irGenerations *message.IRGenerations
+		results       *message.TranslationResults
 	}{
-		pResources: new(message.ProviderResources),
-		xdsIR:      new(message.XdsIR),
-		infraIR:    new(message.InfraIR),
-		xds:        new(message.Xds),
+		pResources:    new(message.ProviderResources),
+		xdsIR:         new(message.XdsIR),
+		infraIR:       new(message.InfraIR),
+		xds:           new(message.Xds),
+		irGenerations: new(message.IRGenerations),
+		results:       new(message.TranslationResults),
 	}
 
 	// The Elected channel is used to block the tasks that are waiting for the leader to be elected.
@@ -184,11 +191,13 @@ func startRunners(ctx context.Context, cfg *config.Server) (err error) {
 			// It subscribes to the provider resources, translates it to xDS IR
 			// and infra IR resources and publishes them.
 			runner: gatewayapirunner.New(&gatewayapirunner.Config{
-				Server:            *cfg,
-				ProviderResources: channels.pResources,
-				XdsIR:             channels.xdsIR,
-				InfraIR:           channels.infraIR,
-				ExtensionManager:  extMgr,
+				Server:             *cfg,
+				ProviderResources:  channels.pResources,
+				XdsIR:              channels.xdsIR,
+				InfraIR:            channels.infraIR,
+				ExtensionManager:   extMgr,
+				IRGenerations:      channels.irGenerations,
+				TranslationResults: channels.results,
 			}),
 		},
 		{
@@ -208,8 +217,9 @@ func startRunners(ctx context.Context, cfg *config.Server) (err error) {
 			// It subscribes to the infraIR, translates it into Envoy Proxy infrastructure
 			// resources such as K8s deployment and services.
 			runner: infrarunner.New(&infrarunner.Config{
-				Server:  *cfg,
-				InfraIR: channels.infraIR,
+				Server:        *cfg,
+				InfraIR:       channels.infraIR,
+				InfraStatuses: &channels.pResources.InfraStatuses,
 			}),
 		},
 		{
@@ -217,12 +227,28 @@ func startRunners(ctx context.Context, cfg *config.Server) (err error) {
 			// It subscribes to the xds Resources and configures the remote Envoy Proxy
 			// via the xDS Protocol.
 			runner: xdsserverrunner.New(&xdsserverrunner.Config{
-				Server: *cfg,
-				Xds:    channels.xds,
+				Server:            *cfg,
+				Xds:               channels.xds,
+				IRGenerations:     channels.irGenerations,
+				ProviderResources: channels.pResources,
 			}),
 		},
 	}
 
+	// Serve the state of the runners on the admin server.
+	configdump.Register(&configdump.Config{
+		ProviderResources:  channels.pResources,
+		TranslationResults: channels.results,
+		XdsIR:              channels.xdsIR,
+		Xds:                channels.xds,
+	})
+	topology.Register(&topology.Config{
+		ProviderResources:  channels.pResources,
+		TranslationResults: channels.results,
+	})
+	loglevel.Register(cfg.Logger)
+	message.RegisterRunnersHealth()
+
 	// Start all runners
 	for _, r := range runners {
 		if err = startRunner(ctx, cfg, r.runner); err != nil {
@@ -19,6 +19,7 @@ import (
 // delivering events to related channel.
 type FileWatcher interface {
 	Add(path string) error
+	AddRecursive(path string) error
 	Remove(path string) error
 	Close() error
 	Events(path string) chan fsnotify.Event
@@ -91,6 +92,32 @@ func (fw *fileWatcher) Add(path string) error {
 	return err
 }
 
+// AddRecursive adds a directory and all of its subdirectories to watch.
+// Subdirectories created afterwards are watched as well, and the events
+// of the whole directory tree are delivered to the channels of path.
+func (fw *fileWatcher) AddRecursive(path string) error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if f, err := os.Stat(path); err != nil {
+		return err
+	} else if !f.IsDir() {
+		return fmt.Errorf("path %s is not a directory", path)
+	}
+
+	ws, cleanedPath, _, err := fw.getWorker(path)
+	if err != nil {
+		return err
+	}
+
+	if err = ws.worker.addPath(cleanedPath); err != nil {
+		return err
+	}
+	ws.count++
+
+	return ws.worker.watchTree(cleanedPath)
+}
+
 func (fw *fileWatcher) Remove(path string) error {
 	fw.mu.Lock()
 	defer fw.mu.Unlock()
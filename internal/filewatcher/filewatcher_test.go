@@ -212,6 +212,78 @@ func TestWatchDir(t *testing.T) {
 	require.NoErrorf(t, timeoutErr, "timeout waiting for event")
 }
 
+func TestWatchDirRecursive(t *testing.T) {
+	d := t.TempDir()
+	subDir := path.Join(d, "tenants", "team-a")
+	require.NoError(t, os.MkdirAll(subDir, 0o750))
+
+	w := NewWatcher()
+	defer func() {
+		_ = w.Close()
+	}()
+	require.NoError(t, w.AddRecursive(d))
+	events := w.Events(d)
+	require.NotNil(t, events)
+
+	waitEvent := func(name string) {
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case event := <-events:
+				if event.Name == name {
+					return
+				}
+			case <-timeout:
+				require.FailNow(t, "timeout waiting for event", name)
+			}
+		}
+	}
+
+	// Writing a file in an existing subdirectory.
+	existingFile := path.Join(subDir, "gateway.yaml")
+	require.NoError(t, os.WriteFile(existingFile, []byte("foo: bar\n"), 0o600))
+	waitEvent(existingFile)
+
+	// Creating a new subdirectory, then writing a file in it.
+	newDir := path.Join(d, "tenants", "team-b")
+	require.NoError(t, os.Mkdir(newDir, 0o750))
+	waitEvent(newDir)
+	newFile := path.Join(newDir, "gateway.yaml")
+	require.NoError(t, os.WriteFile(newFile, []byte("foo: bar\n"), 0o600))
+	waitEvent(newFile)
+
+	// A new hidden subdirectory isn't watched.
+	hiddenDir := path.Join(d, ".git")
+	require.NoError(t, os.Mkdir(hiddenDir, 0o750))
+	waitEvent(hiddenDir)
+	hiddenFile := path.Join(hiddenDir, "HEAD")
+	require.NoError(t, os.WriteFile(hiddenFile, []byte("ref: refs/heads/main\n"), 0o600))
+	require.NoError(t, os.WriteFile(newFile, []byte("foo: baz\n"), 0o600))
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			require.NotEqual(t, hiddenFile, event.Name)
+			if event.Name == newFile {
+				return
+			}
+		case <-timeout:
+			require.FailNow(t, "timeout waiting for event", newFile)
+		}
+	}
+}
+
+func TestAddRecursiveFile(t *testing.T) {
+	watchFile := newWatchFile(t)
+
+	w := NewWatcher()
+	defer func() {
+		_ = w.Close()
+	}()
+	require.Error(t, w.AddRecursive(watchFile))
+	require.Error(t, w.AddRecursive(path.Join(path.Dir(watchFile), "not-exist")))
+}
+
 func TestWatcherLifecycle(t *testing.T) {
 	watchFile1, watchFile2 := newTwoWatchFile(t)
 
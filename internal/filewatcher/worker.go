@@ -11,7 +11,10 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
@@ -32,6 +35,12 @@ type worker struct {
 	// do not have to be related to the file itself.
 	watchedFiles map[string]*fileTracker
 
+	// recursive indicates whether subdirectories of the watched dir,
+	// including the newly created ones, are watched.
+	recursive bool
+
+	addWatcherPath func(*fsnotify.Watcher, string) error
+
 	// tracker lifecycle
 	retireTrackerCh chan *fileTracker
 
@@ -61,6 +70,7 @@ func newWorker(path string, funcs *patchTable) (*worker, error) {
 	wk := &worker{
 		dirWatcher:      dirWatcher,
 		watchedFiles:    make(map[string]*fileTracker),
+		addWatcherPath:  funcs.addWatcherPath,
 		retireTrackerCh: make(chan *fileTracker),
 		terminateCh:     make(chan bool),
 	}
@@ -88,6 +98,14 @@ func (wk *worker) loop() {
 	for {
 		select {
 		case event := <-wk.dirWatcher.Events:
+			// start watching the newly created subdirectory and its children,
+			// skipping the hidden directories as the initial walk does
+			if event.Has(fsnotify.Create) && wk.isRecursive() && !strings.HasPrefix(filepath.Base(event.Name), ".") {
+				if f, err := os.Lstat(event.Name); err == nil && f.IsDir() {
+					_ = wk.watchTree(event.Name)
+				}
+			}
+
 			// work on a copy of the watchedFiles map, so that we don't interfere
 			// with the caller's use of the map
 			for path, ft := range wk.getTrackers() {
@@ -201,6 +219,34 @@ func (wk *worker) addPath(path string) error {
 	return nil
 }
 
+func (wk *worker) isRecursive() bool {
+	wk.mu.RLock()
+	defer wk.mu.RUnlock()
+
+	return wk.recursive
+}
+
+// watchTree watches the given directory and all of its subdirectories,
+// hidden directories are skipped.
+func (wk *worker) watchTree(root string) error {
+	wk.mu.Lock()
+	wk.recursive = true
+	wk.mu.Unlock()
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return wk.addWatcherPath(wk.dirWatcher, path)
+	})
+}
+
 func (wk *worker) removePath(path string) error {
 	wk.mu.Lock()
 	defer wk.mu.Unlock()
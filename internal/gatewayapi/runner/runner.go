@@ -60,11 +60,18 @@ type Config struct {
 	XdsIR             *message.XdsIR
 	InfraIR           *message.InfraIR
 	ExtensionManager  extension.Manager
+	// IRGenerations records the generation of the Gateway API resources
+	// each IR is translated from.
+	IRGenerations *message.IRGenerations
+	// TranslationResults receives the latest translation result of every GatewayClass.
+	TranslationResults *message.TranslationResults
 }
 
 type Runner struct {
 	Config
 	wasmCache wasm.Cache
+	// generation is incremented on every update of the Gateway API resources.
+	generation int64
 }
 
 func New(cfg *Config) *Runner {
@@ -133,8 +140,11 @@ func (r *Runner) subscribeAndTranslate(sub <-chan watchable.Snapshot[string, *re
 			if update.Delete || val == nil {
 				r.deleteAllIRKeys()
 				r.deleteAllStatusKeys()
+				r.TranslationResults.Store(nil)
+				r.IRGenerations.SetTranslated()
 				return
 			}
+			r.generation++
 
 			// IR keys for watchable
 			var curIRKeys, newIRKeys []string
@@ -148,6 +158,7 @@ func (r *Runner) subscribeAndTranslate(sub <-chan watchable.Snapshot[string, *re
 			// Iterating through the controller resources, any valid keys will be removed from statusesToDelete.
 			// Remaining keys will be deleted from watchable before we exit this function.
 			statusesToDelete := r.getAllStatuses()
+			results := make(map[string]*gatewayapi.TranslateResult, len(*val))
 
 			for _, resources := range *val {
 				// Translate and publish IRs.
@@ -179,6 +190,7 @@ func (r *Runner) subscribeAndTranslate(sub <-chan watchable.Snapshot[string, *re
 					// Currently all errors that Translate returns should just be logged
 					r.Logger.Error(err, "errors detected during translation")
 				}
+				results[resources.GatewayClass.Name] = result
 
 				// Publish the IRs.
 				// Also validate the ir before sending it.
@@ -199,6 +211,7 @@ func (r *Runner) subscribeAndTranslate(sub <-chan watchable.Snapshot[string, *re
 						r.Logger.Error(err, "unable to validate xds ir, skipped sending it")
 						errChan <- err
 					} else {
+						r.IRGenerations.Store(key, r.generation)
 						r.XdsIR.Store(key, val)
 					}
 				}
@@ -305,6 +318,8 @@ func (r *Runner) subscribeAndTranslate(sub <-chan watchable.Snapshot[string, *re
 
 			// Delete status keys
 			r.deleteStatusKeys(statusesToDelete)
+			r.TranslationResults.Store(results)
+			r.IRGenerations.SetTranslated()
 		},
 	)
 	r.Logger.Info("shutting down")
@@ -323,7 +338,7 @@ func (r *Runner) loadTLSConfig(ctx context.Context) (tlsConfig *tls.Config, salt
 			return nil, nil, fmt.Errorf("failed to create tls config: %w", err)
 		}
 
-	case r.EnvoyGateway.Provider.IsRunningOnHost():
+	case r.EnvoyGateway.Provider.IsRunningOnHost() || r.EnvoyGateway.Provider.IsRunningOnDocker():
 		salt, err = os.ReadFile(hmacSecretPath)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get hmac secret: %w", err)
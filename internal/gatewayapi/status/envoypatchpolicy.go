@@ -50,3 +50,12 @@ func SetResourceNotFoundErrorForEnvoyPatchPolicy(s *gwapiv1a2.PolicyStatus, notF
 		s.Ancestors[i].Conditions = MergeConditions(s.Ancestors[i].Conditions, cond)
 	}
 }
+
+// SetXdsNACKForEnvoyPatchPolicy sets the Programmed condition of the policy to False,
+// since Envoy rejected the xDS resources patched by the policy.
+func SetXdsNACKForEnvoyPatchPolicy(s *gwapiv1a2.PolicyStatus, message string) {
+	cond := newCondition(string(egv1a1.PolicyConditionProgrammed), metav1.ConditionFalse, string(egv1a1.PolicyReasonRejected), message, time.Now(), 0)
+	for i := range s.Ancestors {
+		s.Ancestors[i].Conditions = MergeConditions(s.Ancestors[i].Conditions, cond)
+	}
+}
@@ -189,6 +189,30 @@ func updateGatewayProgrammedCondition(gw *gwapiv1.Gateway, envoyObj client.Objec
 			messageNoResources, time.Now(), gw.Generation))
 }
 
+// UpdateGatewayStatusProgrammedConditionForProcess computes the Gateway Programmed status condition
+// from the status of the Envoy process managed by a custom infrastructure provider, which runs Envoy
+// on the host, so no address is assigned to the Gateway.
+func UpdateGatewayStatusProgrammedConditionForProcess(gw *gwapiv1.Gateway, running bool, msg string) {
+	if running {
+		gw.Status.Conditions = MergeConditions(gw.Status.Conditions,
+			newCondition(string(gwapiv1.GatewayConditionProgrammed), metav1.ConditionTrue, string(gwapiv1.GatewayConditionProgrammed),
+				msg, time.Now(), gw.Generation))
+		return
+	}
+
+	gw.Status.Conditions = MergeConditions(gw.Status.Conditions,
+		newCondition(string(gwapiv1.GatewayConditionProgrammed), metav1.ConditionFalse, string(gwapiv1.GatewayReasonNoResources),
+			msg, time.Now(), gw.Generation))
+}
+
+// UpdateGatewayStatusProgrammedConditionForXdsNACK sets the Gateway Programmed status condition
+// to False, since Envoy rejected the xDS update of the Gateway.
+func UpdateGatewayStatusProgrammedConditionForXdsNACK(gw *gwapiv1.Gateway, msg string) {
+	gw.Status.Conditions = MergeConditions(gw.Status.Conditions,
+		newCondition(string(gwapiv1.GatewayConditionProgrammed), metav1.ConditionFalse, string(gwapiv1.GatewayReasonInvalid),
+			msg, time.Now(), gw.Generation))
+}
+
 // GetGatewayListenerStatusConditions returns the status conditions for a specific listener in the gateway status.
 func GetGatewayListenerStatusConditions(gateway *gwapiv1.Gateway, listenerStatusIdx int) []metav1.Condition {
 	if gateway == nil || listenerStatusIdx < 0 || listenerStatusIdx >= len(gateway.Status.Listeners) {
@@ -12,6 +12,15 @@ import (
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
+const (
+	// RouteConditionProgrammed indicates whether the xDS resources translated from the route
+	// have been accepted by Envoy. It is only set to False, when Envoy rejected them.
+	RouteConditionProgrammed gwapiv1.RouteConditionType = "Programmed"
+	// RouteReasonRejected is used with the "Programmed" condition when Envoy rejected the
+	// xDS resources translated from the route.
+	RouteReasonRejected gwapiv1.RouteConditionReason = "Rejected"
+)
+
 func SetRouteStatusCondition(route *gwapiv1.RouteStatus, routeParentStatusIdx int, routeGeneration int64,
 	conditionType gwapiv1.RouteConditionType, status metav1.ConditionStatus, reason gwapiv1.RouteConditionReason, message string,
 ) {
@@ -26,3 +35,17 @@ func SetRouteStatusCondition(route *gwapiv1.RouteStatus, routeParentStatusIdx in
 
 	route.Parents[routeParentStatusIdx].Conditions = MergeConditions(route.Parents[routeParentStatusIdx].Conditions, cond)
 }
+
+// SetRouteStatusProgrammedConditionForXdsNACK sets the Programmed condition of the route parent
+// statuses managed by the controller to False, since Envoy rejected the xDS resources translated
+// from the route.
+func SetRouteStatusProgrammedConditionForXdsNACK(route *gwapiv1.RouteStatus, controllerName gwapiv1.GatewayController,
+	routeGeneration int64, message string,
+) {
+	for i := range route.Parents {
+		if route.Parents[i].ControllerName != controllerName {
+			continue
+		}
+		SetRouteStatusCondition(route, i, routeGeneration, RouteConditionProgrammed, metav1.ConditionFalse, RouteReasonRejected, message)
+	}
+}
@@ -220,7 +220,7 @@ func (r *Runner) loadTLSConfig() (tlsConfig *tls.Config, err error) {
 			return nil, fmt.Errorf("failed to create tls config: %w", err)
 		}
 
-	case r.EnvoyGateway.Provider.IsRunningOnHost():
+	case r.EnvoyGateway.Provider.IsRunningOnHost() || r.EnvoyGateway.Provider.IsRunningOnDocker():
 		tlsConfig, err = crypto.LoadTLSConfig(localTLSCertFilepath, localTLSKeyFilepath, localTLSCaFilepath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create tls config: %w", err)
@@ -0,0 +1,89 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package common
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/infrastructure/kubernetes/ratelimit"
+)
+
+const (
+	// rateLimitXdsServerTLSSAN is the SAN used to verify the Envoy Gateway ratelimit xDS config server.
+	rateLimitXdsServerTLSSAN = "envoy-gateway"
+
+	rateLimitTLSCertFilename = "tls.crt"
+	rateLimitTLSKeyFilename  = "tls.key"
+	rateLimitTLSCaFilename   = "ca.crt"
+)
+
+// RateLimitEnvOptions defines the options of a rate limit service running outside of Kubernetes.
+type RateLimitEnvOptions struct {
+	// RuntimeRoot is the directory the rate limit service stores its runtime data in.
+	RuntimeRoot string
+	// CertDir is the directory containing the certificates of the rate limit service.
+	CertDir string
+	// ListenHost is the address the rate limit service listens on.
+	ListenHost string
+	// XdsServerHost is the address of the Envoy Gateway ratelimit xDS config server.
+	XdsServerHost string
+}
+
+// BuildRateLimitEnv returns the environment variables used to configure a rate limit service running
+// outside of Kubernetes, which mirrors the configuration of the rate limit deployment on Kubernetes.
+// The variables are sorted by name.
+func BuildRateLimitEnv(rateLimit *egv1a1.RateLimit, opts *RateLimitEnvOptions) ([]string, error) {
+	env := map[string]string{
+		ratelimit.RuntimeRootEnvVar:                    opts.RuntimeRoot,
+		ratelimit.RuntimeSubdirectoryEnvVar:            "ratelimit",
+		ratelimit.RuntimeIgnoreDotfilesEnvVar:          "true",
+		ratelimit.RuntimeWatchRootEnvVar:               "false",
+		ratelimit.LogLevelEnvVar:                       "info",
+		ratelimit.UseStatsdEnvVar:                      "false",
+		ratelimit.ConfigTypeEnvVar:                     "GRPC_XDS_SOTW",
+		ratelimit.ConfigGrpcXdsServerURLEnvVar:         net.JoinHostPort(opts.XdsServerHost, strconv.Itoa(ratelimit.XdsGrpcSotwConfigServerPort)),
+		ratelimit.ConfigGrpcXdsNodeIDEnvVar:            ratelimit.InfraName,
+		ratelimit.GRPCServerUseTLSEnvVar:               "true",
+		ratelimit.GRPCServerTLSCertEnvVar:              filepath.Join(opts.CertDir, rateLimitTLSCertFilename),
+		ratelimit.GRPCServerTLSKeyEnvVarEnvVar:         filepath.Join(opts.CertDir, rateLimitTLSKeyFilename),
+		ratelimit.GRPCServerTLSCACertEnvVar:            filepath.Join(opts.CertDir, rateLimitTLSCaFilename),
+		ratelimit.ConfigGRPCXDSServerUseTLSEnvVar:      "true",
+		ratelimit.ConfigGRPCXDSClientTLSCertEnvVar:     filepath.Join(opts.CertDir, rateLimitTLSCertFilename),
+		ratelimit.ConfigGRPCXDSClientTLSKeyEnvVar:      filepath.Join(opts.CertDir, rateLimitTLSKeyFilename),
+		ratelimit.ConfigGRPCXDSServerTLSCACertEnvVar:   filepath.Join(opts.CertDir, rateLimitTLSCaFilename),
+		ratelimit.ForceStartWithoutInitialConfigEnvVar: "true",
+		"CONFIG_GRPC_XDS_SERVER_TLS_SAN":               rateLimitXdsServerTLSSAN,
+		"HOST":                                         opts.ListenHost,
+		"GRPC_HOST":                                    opts.ListenHost,
+		"DEBUG_HOST":                                   opts.ListenHost,
+	}
+
+	if redis := rateLimit.Backend.Redis; redis != nil {
+		env[ratelimit.RedisSocketTypeEnvVar] = "tcp"
+		env[ratelimit.RedisURLEnvVar] = redis.URL
+
+		if redis.TLS != nil {
+			env[ratelimit.RedisTLSEnvVar] = "true"
+			// Secrets can't be resolved without a Kubernetes API server.
+			if redis.TLS.CertificateRef != nil {
+				return nil, errors.New("redis tls certificateRef is not supported outside of kubernetes")
+			}
+		}
+	}
+
+	ret := make([]string, 0, len(env))
+	for k, v := range env {
+		ret = append(ret, k+"="+v)
+	}
+	// Sort the variables so that they can be compared.
+	sort.Strings(ret)
+	return ret, nil
+}
@@ -0,0 +1,155 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// configHashLabel is the label of a container holding the hash of its configuration,
+// it's used to detect whether the container has to be recreated.
+const configHashLabel = "gateway.envoyproxy.io/config-hash"
+
+// containerSpec is the desired state of a container.
+type containerSpec struct {
+	name       string
+	config     *container.Config
+	hostConfig *container.HostConfig
+}
+
+// hash returns the hash of the configuration of the container.
+func (s *containerSpec) hash() (string, error) {
+	// encoding/json sorts the keys of maps, so the encoding is stable.
+	data, err := json.Marshal([]any{s.config, s.hostConfig})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ensureContainer ensures the container is running with the given spec. A running container with
+// the same configuration is left untouched, while a container with a different configuration is
+// recreated.
+func (i *Infra) ensureContainer(ctx context.Context, spec *containerSpec) error {
+	hash, err := spec.hash()
+	if err != nil {
+		return fmt.Errorf("failed to hash container config: %w", err)
+	}
+
+	current, err := i.client.ContainerInspect(ctx, spec.name)
+	switch {
+	case client.IsErrNotFound(err):
+	case err != nil:
+		return fmt.Errorf("failed to inspect container %s: %w", spec.name, err)
+	case current.Config != nil && current.Config.Labels[configHashLabel] == hash:
+		if current.State != nil && current.State.Running {
+			return nil
+		}
+		i.Logger.Info("starting container", "name", spec.name)
+		if err := i.client.ContainerStart(ctx, current.ID, container.StartOptions{}); err != nil {
+			return fmt.Errorf("failed to start container %s: %w", spec.name, err)
+		}
+		return nil
+	default:
+		i.Logger.Info("recreating container with outdated config", "name", spec.name)
+		if err := i.removeContainer(ctx, spec.name); err != nil {
+			return err
+		}
+	}
+
+	if err := i.ensureImage(ctx, spec.config.Image); err != nil {
+		return err
+	}
+	if err := i.ensureNetwork(ctx); err != nil {
+		return err
+	}
+
+	config := *spec.config
+	config.Labels = make(map[string]string, len(spec.config.Labels)+1)
+	for k, v := range spec.config.Labels {
+		config.Labels[k] = v
+	}
+	config.Labels[configHashLabel] = hash
+	networkingConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			i.network: {Aliases: []string{spec.name}},
+		},
+	}
+
+	i.Logger.Info("creating container", "name", spec.name, "image", config.Image)
+	created, err := i.client.ContainerCreate(ctx, &config, spec.hostConfig, networkingConfig, nil, spec.name)
+	if err != nil {
+		return fmt.Errorf("failed to create container %s: %w", spec.name, err)
+	}
+	if err := i.client.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return fmt.Errorf("failed to start container %s: %w", spec.name, err)
+	}
+	return nil
+}
+
+// removeContainer removes the container by its name, if it exists.
+func (i *Infra) removeContainer(ctx context.Context, name string) error {
+	err := i.client.ContainerRemove(ctx, name, container.RemoveOptions{Force: true})
+	if err != nil && !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to remove container %s: %w", name, err)
+	}
+	return nil
+}
+
+// ensureImage pulls the image if it doesn't exist locally.
+func (i *Infra) ensureImage(ctx context.Context, ref string) error {
+	_, _, err := i.client.ImageInspectWithRaw(ctx, ref)
+	if err == nil {
+		return nil
+	}
+	if !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to inspect image %s: %w", ref, err)
+	}
+
+	i.Logger.Info("pulling image", "image", ref)
+	progress, err := i.client.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", ref, err)
+	}
+	defer progress.Close()
+	// The pull completes once its progress has been read entirely.
+	if _, err := io.Copy(io.Discard, progress); err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", ref, err)
+	}
+	return nil
+}
+
+// ensureNetwork creates the network the containers are attached to, if it doesn't exist.
+func (i *Infra) ensureNetwork(ctx context.Context) error {
+	_, err := i.client.NetworkInspect(ctx, i.network, network.InspectOptions{})
+	if err == nil {
+		return nil
+	}
+	if !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to inspect network %s: %w", i.network, err)
+	}
+
+	i.Logger.Info("creating network", "name", i.network)
+	_, err = i.client.NetworkCreate(ctx, i.network, network.CreateOptions{
+		Driver: "bridge",
+		Labels: map[string]string{managedByLabel: managedByValue},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create network %s: %w", i.network, err)
+	}
+	return nil
+}
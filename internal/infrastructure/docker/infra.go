@@ -0,0 +1,133 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/client"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/envoygateway/config"
+	"github.com/wukongcloud/gateway/internal/infrastructure/common"
+	"github.com/wukongcloud/gateway/internal/logging"
+	"github.com/wukongcloud/gateway/internal/utils/file"
+)
+
+const (
+	defaultNetwork       = "envoy-gateway"
+	defaultXdsServerHost = "host.docker.internal"
+	// hostGateway is resolved by Docker to the address of the host.
+	hostGateway = "host-gateway"
+
+	// defaultLocalCertPathDir and defaultLocalRateLimitCertPathDir are the directories containing
+	// the certificates generated by `envoy-gateway certgen --local`. They are mounted into the containers
+	// at the same path.
+	defaultLocalCertPathDir          = "/tmp/envoy-gateway/certs/envoy"
+	defaultLocalRateLimitCertPathDir = "/tmp/envoy-gateway/certs/envoy-rate-limit"
+
+	xdsTLSCertFilename = "tls.crt"
+	xdsTLSKeyFilename  = "tls.key"
+	xdsTLSCaFilename   = "ca.crt"
+)
+
+// Infra manages the creation and deletion of containers based on Infra IR resources.
+type Infra struct {
+	Logger logging.Logger
+
+	// EnvoyGateway is the configuration used to startup Envoy Gateway.
+	EnvoyGateway *egv1a1.EnvoyGateway
+
+	client *client.Client
+
+	envoyImage     string
+	rateLimitImage string
+	network        string
+	xdsServerHost  string
+
+	certPath          string
+	rateLimitCertPath string
+}
+
+// NewInfra returns a new Infra managing containers through the Docker Engine API.
+func NewInfra(cfg *config.Server, logger logging.Logger) (*Infra, error) {
+	settings := cfg.EnvoyGateway.Provider.Custom.Infrastructure.Docker
+	if settings == nil {
+		return nil, errors.New("docker infrastructure provider is not configured")
+	}
+
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if settings.Host != "" {
+		opts = append(opts, client.WithHost(settings.Host))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker client: %w", err)
+	}
+
+	// Ensure the sds config exist.
+	if err := createSdsConfig(defaultLocalCertPathDir); err != nil {
+		return nil, fmt.Errorf("failed to create sds config: %w", err)
+	}
+
+	return newInfra(cli, cfg.EnvoyGateway, settings, logger), nil
+}
+
+func newInfra(cli *client.Client, eg *egv1a1.EnvoyGateway, settings *egv1a1.EnvoyGatewayDockerInfrastructureProvider,
+	logger logging.Logger,
+) *Infra {
+	infra := &Infra{
+		Logger:            logger,
+		EnvoyGateway:      eg,
+		client:            cli,
+		envoyImage:        egv1a1.DefaultEnvoyProxyImage,
+		rateLimitImage:    egv1a1.DefaultRateLimitImage,
+		network:           defaultNetwork,
+		xdsServerHost:     defaultXdsServerHost,
+		certPath:          defaultLocalCertPathDir,
+		rateLimitCertPath: defaultLocalRateLimitCertPathDir,
+	}
+	if settings.EnvoyImage != "" {
+		infra.envoyImage = settings.EnvoyImage
+	}
+	if settings.RateLimitImage != "" {
+		infra.rateLimitImage = settings.RateLimitImage
+	}
+	if settings.Network != "" {
+		infra.network = settings.Network
+	}
+	if settings.XdsServerHost != "" {
+		infra.xdsServerHost = settings.XdsServerHost
+	}
+	return infra
+}
+
+// Close implements the Manager interface. The containers keep running, so that the data plane
+// isn't disrupted while Envoy Gateway restarts.
+func (i *Infra) Close() error {
+	return i.client.Close()
+}
+
+// createSdsConfig creates the needing SDS config under certain directory.
+func createSdsConfig(dir string) error {
+	if _, err := os.Lstat(dir); err != nil {
+		return err
+	}
+
+	if err := file.Write(common.GetSdsCAConfigMapData(
+		filepath.Join(dir, xdsTLSCaFilename)),
+		filepath.Join(dir, common.SdsCAFilename)); err != nil {
+		return err
+	}
+
+	return file.Write(common.GetSdsCertConfigMapData(
+		filepath.Join(dir, xdsTLSCertFilename),
+		filepath.Join(dir, xdsTLSKeyFilename)),
+		filepath.Join(dir, common.SdsCertFilename))
+}
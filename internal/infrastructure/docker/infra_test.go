@@ -0,0 +1,286 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/gatewayapi"
+	"github.com/wukongcloud/gateway/internal/infrastructure/kubernetes/proxy"
+	"github.com/wukongcloud/gateway/internal/infrastructure/kubernetes/ratelimit"
+	"github.com/wukongcloud/gateway/internal/ir"
+	"github.com/wukongcloud/gateway/internal/logging"
+)
+
+// fakeDocker is a fake Docker Engine API server, implementing the endpoints used by the docker infrastructure.
+type fakeDocker struct {
+	mu         sync.Mutex
+	containers map[string]*types.ContainerJSON
+	networks   map[string]bool
+	images     map[string]bool
+	// requests are the mutating requests received, e.g. "POST /containers/create".
+	requests []string
+}
+
+var apiVersionPrefix = regexp.MustCompile(`^/v[0-9.]+`)
+
+func newFakeDocker(t *testing.T) (*fakeDocker, *client.Client) {
+	f := &fakeDocker{
+		containers: map[string]*types.ContainerJSON{},
+		networks:   map[string]bool{},
+		images:     map[string]bool{},
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")),
+		client.WithVersion("1.45"))
+	require.NoError(t, err)
+	return f, cli
+}
+
+func (f *fakeDocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := apiVersionPrefix.ReplaceAllString(r.URL.Path, "")
+	if r.Method != http.MethodGet {
+		f.requests = append(f.requests, r.Method+" "+path)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/images/") && strings.HasSuffix(path, "/json"):
+		name := strings.TrimSuffix(strings.TrimPrefix(path, "/images/"), "/json")
+		// Images are pulled by their familiar name.
+		if !f.images[strings.TrimPrefix(name, "docker.io/")] {
+			notFound(w)
+			return
+		}
+		writeJSON(w, types.ImageInspect{ID: name})
+	case r.Method == http.MethodPost && path == "/images/create":
+		f.images[r.URL.Query().Get("fromImage")+":"+r.URL.Query().Get("tag")] = true
+		writeJSON(w, map[string]string{"status": "pulled"})
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/networks/"):
+		name := strings.TrimPrefix(path, "/networks/")
+		if !f.networks[name] {
+			notFound(w)
+			return
+		}
+		writeJSON(w, network.Inspect{Name: name, ID: name})
+	case r.Method == http.MethodPost && path == "/networks/create":
+		var req network.CreateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.networks[req.Name] = true
+		writeJSON(w, network.CreateResponse{ID: req.Name})
+	case r.Method == http.MethodPost && path == "/containers/create":
+		var req container.CreateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		name := r.URL.Query().Get("name")
+		f.containers[name] = &types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID:         name,
+				Name:       "/" + name,
+				State:      &types.ContainerState{},
+				HostConfig: req.HostConfig,
+			},
+			Config: req.Config,
+		}
+		writeJSON(w, container.CreateResponse{ID: name})
+	case strings.HasPrefix(path, "/containers/"):
+		parts := strings.Split(strings.TrimPrefix(path, "/containers/"), "/")
+		c, ok := f.containers[parts[0]]
+		if !ok {
+			notFound(w)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && len(parts) == 2 && parts[1] == "json":
+			writeJSON(w, c)
+		case r.Method == http.MethodPost && len(parts) == 2 && parts[1] == "start":
+			c.State.Running = true
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete && len(parts) == 1:
+			delete(f.containers, parts[0])
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "unsupported", http.StatusNotImplemented)
+		}
+	default:
+		http.Error(w, "unsupported", http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeDocker) takeRequests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := f.requests
+	f.requests = nil
+	return requests
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func notFound(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "not found"})
+}
+
+func newTestInfra(t *testing.T, eg *egv1a1.EnvoyGateway) (*fakeDocker, *Infra) {
+	f, cli := newFakeDocker(t)
+	return f, newInfra(cli, eg, &egv1a1.EnvoyGatewayDockerInfrastructureProvider{},
+		logging.DefaultLogger(os.Stdout, egv1a1.LogLevelInfo))
+}
+
+func newTestProxyInfra() *ir.Infra {
+	infra := ir.NewInfra()
+	infra.Proxy.Name = "default/eg"
+	infra.Proxy.GetProxyMetadata().Labels = map[string]string{
+		gatewayapi.OwningGatewayNamespaceLabel: "default",
+		gatewayapi.OwningGatewayNameLabel:      "eg",
+	}
+	infra.Proxy.Listeners = []*ir.ProxyListener{
+		{
+			Name: "default/eg/http",
+			Ports: []ir.ListenerPort{
+				{Name: "http", Protocol: ir.HTTPProtocolType, ServicePort: 80, ContainerPort: 10080},
+			},
+		},
+		{
+			Name:  "default/eg/https",
+			HTTP3: &ir.HTTP3Settings{},
+			Ports: []ir.ListenerPort{
+				{Name: "https", Protocol: ir.HTTPSProtocolType, ServicePort: 443, ContainerPort: 10443},
+			},
+		},
+		{
+			Name: "default/eg/udp",
+			Ports: []ir.ListenerPort{
+				{Name: "udp", Protocol: ir.UDPProtocolType, ServicePort: 53, ContainerPort: 10053},
+			},
+		},
+	}
+	return infra
+}
+
+func TestCreateOrUpdateDeleteProxyInfra(t *testing.T) {
+	ctx := context.Background()
+	f, infra := newTestInfra(t, egv1a1.DefaultEnvoyGateway())
+	proxyInfra := newTestProxyInfra()
+	name := proxy.ExpectedResourceHashedName(proxyInfra.Proxy.Name)
+
+	t.Run("create", func(t *testing.T) {
+		require.NoError(t, infra.CreateOrUpdateProxyInfra(ctx, proxyInfra))
+		require.Equal(t, []string{
+			"POST /images/create",
+			"POST /networks/create",
+			"POST /containers/create",
+			"POST /containers/" + name + "/start",
+		}, f.takeRequests())
+
+		c := f.containers[name]
+		require.NotNil(t, c)
+		require.True(t, c.State.Running)
+		require.Equal(t, egv1a1.DefaultEnvoyProxyImage, c.Config.Image)
+		require.Equal(t, "eg", c.Config.Labels[gatewayapi.OwningGatewayNameLabel])
+		require.Equal(t, "default", c.Config.Labels[gatewayapi.OwningGatewayNamespaceLabel])
+		require.Equal(t, managedByValue, c.Config.Labels[managedByLabel])
+		require.NotEmpty(t, c.Config.Labels[configHashLabel])
+		require.Equal(t, nat.PortMap{
+			"10080/tcp": {{HostPort: "80"}},
+			"10443/tcp": {{HostPort: "443"}},
+			"10443/udp": {{HostPort: "443"}},
+			"10053/udp": {{HostPort: "53"}},
+		}, c.HostConfig.PortBindings)
+		require.Contains(t, c.HostConfig.ExtraHosts, "host.docker.internal:host-gateway")
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		require.NoError(t, infra.CreateOrUpdateProxyInfra(ctx, proxyInfra))
+		require.Empty(t, f.takeRequests())
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		f.containers[name].State.Running = false
+		require.NoError(t, infra.CreateOrUpdateProxyInfra(ctx, proxyInfra))
+		require.Equal(t, []string{"POST /containers/" + name + "/start"}, f.takeRequests())
+	})
+
+	t.Run("updated", func(t *testing.T) {
+		proxyInfra.Proxy.Listeners = proxyInfra.Proxy.Listeners[:1]
+		require.NoError(t, infra.CreateOrUpdateProxyInfra(ctx, proxyInfra))
+		require.Equal(t, []string{
+			"DELETE /containers/" + name,
+			"POST /containers/create",
+			"POST /containers/" + name + "/start",
+		}, f.takeRequests())
+		require.Equal(t, nat.PortMap{"10080/tcp": {{HostPort: "80"}}}, f.containers[name].HostConfig.PortBindings)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, infra.DeleteProxyInfra(ctx, proxyInfra))
+		require.Empty(t, f.containers)
+		// Deleting a container that doesn't exist is a no-op.
+		require.NoError(t, infra.DeleteProxyInfra(ctx, proxyInfra))
+	})
+
+	t.Run("missing owning gateway labels", func(t *testing.T) {
+		require.Error(t, infra.CreateOrUpdateProxyInfra(ctx, ir.NewInfra()))
+	})
+}
+
+func TestCreateOrUpdateDeleteRateLimitInfra(t *testing.T) {
+	ctx := context.Background()
+
+	_, infra := newTestInfra(t, egv1a1.DefaultEnvoyGateway())
+	require.Error(t, infra.CreateOrUpdateRateLimitInfra(ctx))
+
+	eg := egv1a1.DefaultEnvoyGateway()
+	eg.RateLimit = &egv1a1.RateLimit{
+		Backend: egv1a1.RateLimitDatabaseBackend{
+			Type:  egv1a1.RedisBackendType,
+			Redis: &egv1a1.RateLimitRedisSettings{URL: "redis:6379"},
+		},
+	}
+	f, infra := newTestInfra(t, eg)
+	f.images[strings.TrimPrefix(egv1a1.DefaultRateLimitImage, "docker.io/")] = true
+	f.networks[defaultNetwork] = true
+
+	require.NoError(t, infra.CreateOrUpdateRateLimitInfra(ctx))
+	require.Equal(t, []string{
+		"POST /containers/create",
+		"POST /containers/" + ratelimit.InfraName + "/start",
+	}, f.takeRequests())
+	c := f.containers[ratelimit.InfraName]
+	require.Contains(t, c.Config.Env, ratelimit.RedisURLEnvVar+"=redis:6379")
+	require.Contains(t, c.Config.Env, ratelimit.ConfigGrpcXdsServerURLEnvVar+"=host.docker.internal:18001")
+	require.Contains(t, c.Config.Env, "GRPC_HOST=0.0.0.0")
+
+	require.NoError(t, infra.CreateOrUpdateRateLimitInfra(ctx))
+	require.Empty(t, f.takeRequests())
+
+	require.NoError(t, infra.DeleteRateLimitInfra(ctx))
+	require.Empty(t, f.containers)
+}
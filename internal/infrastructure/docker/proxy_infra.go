@@ -0,0 +1,132 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strconv"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"k8s.io/utils/ptr"
+
+	"github.com/wukongcloud/gateway/internal/infrastructure/common"
+	"github.com/wukongcloud/gateway/internal/infrastructure/kubernetes/proxy"
+	"github.com/wukongcloud/gateway/internal/ir"
+	"github.com/wukongcloud/gateway/internal/xds/bootstrap"
+)
+
+const (
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "envoy-gateway"
+)
+
+// CreateOrUpdateProxyInfra creates the Envoy container, or recreates it if its configuration changed.
+func (i *Infra) CreateOrUpdateProxyInfra(ctx context.Context, infra *ir.Infra) error {
+	if infra == nil {
+		return errors.New("infra ir is nil")
+	}
+
+	if infra.Proxy == nil {
+		return errors.New("infra proxy ir is nil")
+	}
+
+	spec, err := i.proxyContainerSpec(infra.GetProxyInfra())
+	if err != nil {
+		return err
+	}
+	return i.ensureContainer(ctx, spec)
+}
+
+// DeleteProxyInfra removes the Envoy container, if it exists.
+func (i *Infra) DeleteProxyInfra(ctx context.Context, infra *ir.Infra) error {
+	if infra == nil {
+		return errors.New("infra ir is nil")
+	}
+
+	return i.removeContainer(ctx, proxy.ExpectedResourceHashedName(infra.GetProxyInfra().Name))
+}
+
+// proxyContainerSpec returns the expected Envoy container of the proxy infra.
+func (i *Infra) proxyContainerSpec(proxyInfra *ir.ProxyInfra) (*containerSpec, error) {
+	labels := proxy.EnvoyAppLabel()
+	maps.Copy(labels, proxyInfra.GetProxyMetadata().Labels)
+	if proxy.OwningGatewayLabelsAbsent(labels) {
+		return nil, errors.New("missing owning gateway labels")
+	}
+
+	name := proxy.ExpectedResourceHashedName(proxyInfra.Name)
+	proxyConfig := proxyInfra.GetProxyConfig()
+	bootstrapConfigOptions := &bootstrap.RenderBootstrapConfigOptions{
+		SdsConfig: bootstrap.SdsConfigPath{
+			Certificate: filepath.Join(i.certPath, common.SdsCertFilename),
+			TrustedCA:   filepath.Join(i.certPath, common.SdsCAFilename),
+		},
+		XdsServerHost: ptr.To(i.xdsServerHost),
+		// The wasm HTTP server isn't reachable from the containers.
+		WasmServerPort: ptr.To(int32(0)),
+	}
+	args, err := common.BuildProxyArgs(proxyInfra, proxyConfig.Spec.Shutdown, bootstrapConfigOptions, name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	exposedPorts, portBindings := publishedPorts(proxyInfra.Listeners)
+	return &containerSpec{
+		name: name,
+		config: &container.Config{
+			Image:        i.envoyImage,
+			Entrypoint:   []string{"envoy"},
+			Cmd:          args,
+			Labels:       labels,
+			ExposedPorts: exposedPorts,
+		},
+		hostConfig: i.hostConfig(i.certPath, portBindings),
+	}, nil
+}
+
+// publishedPorts returns the ports of the listeners, which are published on the host with their service port.
+func publishedPorts(listeners []*ir.ProxyListener) (nat.PortSet, nat.PortMap) {
+	exposedPorts := nat.PortSet{}
+	portBindings := nat.PortMap{}
+	publish := func(port ir.ListenerPort, protocol string) {
+		p := nat.Port(fmt.Sprintf("%d/%s", port.ContainerPort, protocol))
+		exposedPorts[p] = struct{}{}
+		portBindings[p] = []nat.PortBinding{{HostPort: strconv.Itoa(int(port.ServicePort))}}
+	}
+
+	for _, listener := range listeners {
+		for _, port := range listener.Ports {
+			if port.Protocol == ir.UDPProtocolType {
+				publish(port, "udp")
+				continue
+			}
+			publish(port, "tcp")
+			if port.Protocol == ir.HTTPSProtocolType && listener.HTTP3 != nil {
+				publish(port, "udp")
+			}
+		}
+	}
+	return exposedPorts, portBindings
+}
+
+// hostConfig returns the host config of a container mounting the certificates directory.
+func (i *Infra) hostConfig(certPath string, portBindings nat.PortMap) *container.HostConfig {
+	hostConfig := &container.HostConfig{
+		Binds:         []string{certPath + ":" + certPath + ":ro"},
+		PortBindings:  portBindings,
+		RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyUnlessStopped},
+	}
+	if i.xdsServerHost == defaultXdsServerHost {
+		// host.docker.internal is only resolved by Docker Desktop by default.
+		hostConfig.ExtraHosts = []string{defaultXdsServerHost + ":" + hostGateway}
+	}
+	return hostConfig
+}
@@ -0,0 +1,69 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/wukongcloud/gateway/internal/infrastructure/common"
+	"github.com/wukongcloud/gateway/internal/infrastructure/kubernetes/ratelimit"
+)
+
+// rateLimitRuntimeRoot is the directory the rate limit service stores its runtime data in, within its container.
+const rateLimitRuntimeRoot = "/data"
+
+// GetRateLimitServiceURL returns the URL for the rate limit service managed by the docker infrastructure.
+// Envoy resolves the rate limit container by its name on the network of the containers.
+func GetRateLimitServiceURL() string {
+	return fmt.Sprintf("grpc://%s:%d", ratelimit.InfraName, ratelimit.InfraGRPCPort)
+}
+
+// GetRateLimitClientCertDir returns the directory containing the certificates used by Envoy
+// to connect to the rate limit service managed by the docker infrastructure.
+func GetRateLimitClientCertDir() string {
+	return defaultLocalCertPathDir
+}
+
+// CreateOrUpdateRateLimitInfra creates the rate limit container, or recreates it if its configuration changed.
+func (i *Infra) CreateOrUpdateRateLimitInfra(ctx context.Context) error {
+	if i.EnvoyGateway == nil || i.EnvoyGateway.RateLimit == nil {
+		return errors.New("ratelimit configuration is nil")
+	}
+
+	env, err := common.BuildRateLimitEnv(i.EnvoyGateway.RateLimit, &common.RateLimitEnvOptions{
+		RuntimeRoot:   rateLimitRuntimeRoot,
+		CertDir:       i.rateLimitCertPath,
+		ListenHost:    "0.0.0.0",
+		XdsServerHost: i.xdsServerHost,
+	})
+	if err != nil {
+		return err
+	}
+
+	return i.ensureContainer(ctx, &containerSpec{
+		name: ratelimit.InfraName,
+		config: &container.Config{
+			Image:      i.rateLimitImage,
+			Entrypoint: []string{"/bin/ratelimit"},
+			Env:        env,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":      ratelimit.InfraName,
+				"app.kubernetes.io/component": "ratelimit",
+				managedByLabel:                managedByValue,
+			},
+		},
+		hostConfig: i.hostConfig(i.rateLimitCertPath, nil),
+	})
+}
+
+// DeleteRateLimitInfra removes the rate limit container, if it exists.
+func (i *Infra) DeleteRateLimitInfra(ctx context.Context) error {
+	return i.removeContainer(ctx, ratelimit.InfraName)
+}
@@ -10,16 +10,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
 	"github.com/wukongcloud/gateway/internal/envoygateway/config"
 	"github.com/wukongcloud/gateway/internal/infrastructure/common"
 	"github.com/wukongcloud/gateway/internal/logging"
+	"github.com/wukongcloud/gateway/internal/message"
 	"github.com/wukongcloud/gateway/internal/utils/file"
 )
 
 const (
-	// TODO: Make these path configurable.
 	defaultHomeDir          = "/tmp/envoy-gateway"
 	defaultLocalCertPathDir = "/tmp/envoy-gateway/certs/envoy"
 
@@ -43,19 +44,50 @@ type Infra struct {
 	// EnvoyGateway is the configuration used to startup Envoy Gateway.
 	EnvoyGateway *egv1a1.EnvoyGateway
 
+	// settings is the configuration of the host infrastructure provider, it's never nil.
+	settings *egv1a1.EnvoyGatewayHostInfrastructureProvider
+
 	// proxyContextMap store the context of each running proxy by its name for lifecycle management.
 	proxyContextMap map[string]*proxyContext
+	// baseIDs stores the base ID allocated to each running proxy by its name.
+	baseIDs map[string]uint32
+	// statuses is where the statuses of the running proxies are reported to.
+	statuses *message.InfraStatuses
+	// envoyBinaryPath is the path of the Envoy binary installed by func-e, once it's installed.
+	envoyBinaryPath string
+	envoyBinaryMu   sync.Mutex
+
+	// rateLimitContext stores the context of the running rate limit process, if any.
+	rateLimitContext *proxyContext
+	// rateLimitConfig is the binary and the environment the running rate limit process was started with.
+	rateLimitConfig []string
+	rateLimitMu     sync.Mutex
 
 	// TODO: remove this field once it supports the configurable homeDir
 	sdsConfigPath string
+	// rateLimitCertPath is the directory containing the rate limit service certificates.
+	rateLimitCertPath string
 }
 
-func NewInfra(runnerCtx context.Context, cfg *config.Server, logger logging.Logger) (*Infra, error) {
+func NewInfra(runnerCtx context.Context, cfg *config.Server, logger logging.Logger, statuses *message.InfraStatuses) (*Infra, error) {
+	settings := hostSettings(cfg.EnvoyGateway)
+	homeDir := defaultHomeDir
+	if settings.HomeDir != "" {
+		homeDir = settings.HomeDir
+	}
+
 	// Ensure the home directory exist.
-	if err := os.MkdirAll(defaultHomeDir, 0o750); err != nil {
+	if err := os.MkdirAll(homeDir, 0o750); err != nil {
 		return nil, fmt.Errorf("failed to create dir: %w", err)
 	}
 
+	// Ensure the log directory exist.
+	if settings.LogDir != "" {
+		if err := os.MkdirAll(settings.LogDir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create log dir: %w", err)
+		}
+	}
+
 	// Check local certificates dir exist.
 	if _, err := os.Lstat(defaultLocalCertPathDir); err != nil {
 		return nil, fmt.Errorf("failed to stat dir: %w", err)
@@ -67,15 +99,29 @@ func NewInfra(runnerCtx context.Context, cfg *config.Server, logger logging.Logg
 	}
 
 	infra := &Infra{
-		HomeDir:         defaultHomeDir,
-		Logger:          logger,
-		EnvoyGateway:    cfg.EnvoyGateway,
-		proxyContextMap: make(map[string]*proxyContext),
-		sdsConfigPath:   defaultLocalCertPathDir,
+		HomeDir:           homeDir,
+		Logger:            logger,
+		EnvoyGateway:      cfg.EnvoyGateway,
+		settings:          settings,
+		proxyContextMap:   make(map[string]*proxyContext),
+		baseIDs:           make(map[string]uint32),
+		statuses:          statuses,
+		sdsConfigPath:     defaultLocalCertPathDir,
+		rateLimitCertPath: defaultLocalRateLimitCertPathDir,
 	}
 	return infra, nil
 }
 
+// hostSettings returns the configuration of the host infrastructure provider, or an empty
+// configuration if it's not set.
+func hostSettings(eg *egv1a1.EnvoyGateway) *egv1a1.EnvoyGatewayHostInfrastructureProvider {
+	if eg != nil && eg.Provider != nil && eg.Provider.Custom != nil &&
+		eg.Provider.Custom.Infrastructure != nil && eg.Provider.Custom.Infrastructure.Host != nil {
+		return eg.Provider.Custom.Infrastructure.Host
+	}
+	return &egv1a1.EnvoyGatewayHostInfrastructureProvider{}
+}
+
 // createSdsConfig creates the needing SDS config under certain directory.
 func createSdsConfig(dir string) error {
 	if err := file.Write(common.GetSdsCAConfigMapData(
@@ -0,0 +1,100 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package host
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+)
+
+// cpuPeriod is the period in microseconds used to limit the CPU of a cgroup.
+const cpuPeriod = 100000
+
+// prepareResourceLimits configures the command of the named process to start inside a cgroup with the
+// memory and cpu limits, so that they apply from the start of the process. It returns a function releasing
+// the resources allocated to limit the process, which must be called once the process exited.
+func prepareResourceLimits(name string, cmd *exec.Cmd, resources *egv1a1.HostProcessResources) (func(), error) {
+	release := func() {}
+	if resources == nil || resources.CgroupParent == "" {
+		return release, nil
+	}
+
+	// The controllers must be enabled in the parent cgroup for the limits of the child cgroups to apply.
+	var controllers []string
+	limits := map[string]string{}
+	if resources.Memory != nil {
+		controllers = append(controllers, "+memory")
+		limits["memory.max"] = strconv.FormatInt(resources.Memory.Value(), 10)
+	}
+	if resources.CPU != nil {
+		controllers = append(controllers, "+cpu")
+		limits["cpu.max"] = fmt.Sprintf("%d %d", resources.CPU.MilliValue()*cpuPeriod/1000, cpuPeriod)
+	}
+	if len(controllers) > 0 {
+		if err := writeCgroupFile(resources.CgroupParent, "cgroup.subtree_control", strings.Join(controllers, " ")); err != nil {
+			return release, err
+		}
+	}
+
+	dir := filepath.Join(resources.CgroupParent, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return release, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+	release = func() {
+		// A cgroup can only be removed once all its processes exited.
+		_ = os.Remove(dir)
+	}
+	for file, limit := range limits {
+		if err := writeCgroupFile(dir, file, limit); err != nil {
+			return release, err
+		}
+	}
+
+	// Start the process inside the cgroup once it's configured.
+	cgroup, err := os.Open(dir)
+	if err != nil {
+		return release, fmt.Errorf("failed to open cgroup: %w", err)
+	}
+	release = func() {
+		_ = cgroup.Close()
+		_ = os.Remove(dir)
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(cgroup.Fd())
+	return release, nil
+}
+
+// applyResourceLimits applies the resource limits which are set on the running process with the given pid.
+func applyResourceLimits(pid int, resources *egv1a1.HostProcessResources) error {
+	if resources == nil || resources.MaxOpenFiles == nil {
+		return nil
+	}
+
+	limit := &unix.Rlimit{Cur: *resources.MaxOpenFiles, Max: *resources.MaxOpenFiles}
+	if err := unix.Prlimit(pid, unix.RLIMIT_NOFILE, limit, nil); err != nil {
+		return fmt.Errorf("failed to set max open files: %w", err)
+	}
+	return nil
+}
+
+func writeCgroupFile(dir, file, content string) error {
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write cgroup file %s: %w", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package host
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+)
+
+func TestPrepareResourceLimits(t *testing.T) {
+	// A directory standing in for the cgroup filesystem.
+	parent := t.TempDir()
+	cmd := exec.Command("sleep", "60")
+	release, err := prepareResourceLimits("test", cmd, &egv1a1.HostProcessResources{
+		CgroupParent: parent,
+		Memory:       ptr.To(resource.MustParse("1Gi")),
+		CPU:          ptr.To(resource.MustParse("500m")),
+	})
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		filepath.Join(parent, "cgroup.subtree_control"): "+memory +cpu",
+		filepath.Join(parent, "test", "memory.max"):     "1073741824",
+		filepath.Join(parent, "test", "cpu.max"):        "50000 100000",
+	}
+	for file, content := range expected {
+		got, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, content, string(got))
+	}
+
+	// The process is started inside the cgroup.
+	require.True(t, cmd.SysProcAttr.UseCgroupFD)
+	var stat unix.Stat_t
+	require.NoError(t, unix.Fstat(cmd.SysProcAttr.CgroupFD, &stat))
+	var dirStat unix.Stat_t
+	require.NoError(t, unix.Stat(filepath.Join(parent, "test"), &dirStat))
+	require.Equal(t, dirStat.Ino, stat.Ino)
+	release()
+
+	release, err = prepareResourceLimits("test", exec.Command("sleep", "60"), &egv1a1.HostProcessResources{
+		MaxOpenFiles: ptr.To[uint64](128),
+	})
+	require.NoError(t, err)
+	release()
+}
+
+func TestApplyResourceLimits(t *testing.T) {
+	cmd := exec.Command("sleep", "60")
+	require.NoError(t, cmd.Start())
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	pid := cmd.Process.Pid
+
+	require.NoError(t, applyResourceLimits(pid, &egv1a1.HostProcessResources{MaxOpenFiles: ptr.To[uint64](128)}))
+	var limit unix.Rlimit
+	require.NoError(t, unix.Prlimit(pid, unix.RLIMIT_NOFILE, nil, &limit))
+	require.Equal(t, unix.Rlimit{Cur: 128, Max: 128}, limit)
+
+	require.NoError(t, applyResourceLimits(pid, nil))
+}
@@ -0,0 +1,29 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+//go:build !linux
+
+package host
+
+import (
+	"errors"
+	"os/exec"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+)
+
+// prepareResourceLimits configures the command of the named process to start with the resource limits.
+// Resource limits are only supported on Linux.
+func prepareResourceLimits(_ string, _ *exec.Cmd, resources *egv1a1.HostProcessResources) (func(), error) {
+	if resources != nil {
+		return func() {}, errors.New("resource limits of host processes are only supported on linux")
+	}
+	return func() {}, nil
+}
+
+// applyResourceLimits applies the resource limits which are set on the running process with the given pid.
+func applyResourceLimits(_ int, _ *egv1a1.HostProcessResources) error {
+	return nil
+}
@@ -0,0 +1,23 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package host
+
+import "github.com/wukongcloud/gateway/internal/metrics"
+
+var (
+	proxyExitTotal = metrics.NewCounter(
+		"host_proxy_exit_total",
+		"Total number of unexpected exits of the proxy processes managed by the host infrastructure, by exit code.",
+	)
+
+	proxyCrashLooping = metrics.NewGauge(
+		"host_proxy_crash_looping",
+		"Whether the proxy process managed by the host infrastructure is crash looping, the value is 1 if it is.",
+	)
+
+	nameLabel     = metrics.NewLabel("name")
+	exitCodeLabel = metrics.NewLabel("exit_code")
+)
@@ -0,0 +1,46 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package host
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// processStopTimeout is the time to wait for a process to exit after being interrupted before it gets killed.
+const processStopTimeout = 10 * time.Second
+
+// runProcess runs the command of the named process and blocks until it exits. The resource limits
+// of the host infrastructure provider are applied to the process, and its output is written to out.
+// The command must be created with exec.CommandContext, the process is interrupted when the context is done.
+func (i *Infra) runProcess(name string, cmd *exec.Cmd, out io.Writer) error {
+	cmd.Stdout = out
+	cmd.Stderr = out
+	// Give the process a chance to shut down gracefully.
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = processStopTimeout
+
+	release, err := prepareResourceLimits(name, cmd, i.settings.Resources)
+	defer release()
+	if err != nil {
+		return fmt.Errorf("failed to apply resource limits: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if err := applyResourceLimits(cmd.Process.Pid, i.settings.Resources); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("failed to apply resource limits: %w", err)
+	}
+
+	return cmd.Wait()
+}
@@ -8,26 +8,48 @@ package host
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	funcE "github.com/tetratelabs/func-e/api"
 	"k8s.io/utils/ptr"
 
 	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
 	"github.com/wukongcloud/gateway/internal/infrastructure/common"
 	"github.com/wukongcloud/gateway/internal/ir"
+	"github.com/wukongcloud/gateway/internal/message"
 	"github.com/wukongcloud/gateway/internal/utils"
 	"github.com/wukongcloud/gateway/internal/xds/bootstrap"
 )
 
-// proxyContext corresponds to the context of the Envoy process.
+// envoyLogPath is the path Envoy writes its logs to.
+const envoyLogPath = "/dev/stdout"
+
+// proxyContext corresponds to the context of a managed process, i.e. Envoy or the rate limit service.
 type proxyContext struct {
-	// cancel is the function to cancel the context passed to the Envoy process.
+	// cancel is the function to cancel the context passed to the process.
 	cancel context.CancelFunc
-	// exit will receive an item when the Envoy process completely stopped, via funcE.ExitChannel.
+	// exit will receive an item when the process completely stopped.
 	exit chan struct{}
+	// logFile is the file the output of the process is written to, if any.
+	// It's closed once the process completely stopped.
+	logFile *os.File
+}
+
+// stop stops the process. It will block until the process completely stopped.
+func (p *proxyContext) stop() {
+	p.cancel()    // Cancel causes the process to exit.
+	<-p.exit      // Wait for the process to completely exit.
+	close(p.exit) // Close the channel to avoid leaking.
+	if p.logFile != nil {
+		_ = p.logFile.Close()
+	}
 }
 
 // Close implements the Manager interface.
@@ -35,6 +57,10 @@ func (i *Infra) Close() error {
 	for name := range i.proxyContextMap {
 		i.stopEnvoy(name)
 	}
+
+	i.rateLimitMu.Lock()
+	defer i.rateLimitMu.Unlock()
+	i.stopRateLimit()
 	return nil
 }
 
@@ -78,26 +104,159 @@ func (i *Infra) CreateOrUpdateProxyInfra(ctx context.Context, infra *ir.Infra) e
 	if err != nil {
 		return err
 	}
-	i.runEnvoy(ctx, os.Stdout, proxyName, args)
+	// Envoy logs to stderr by default. Log to stdout instead, so the last logs before
+	// Envoy exits can be reported.
+	args = append(args, "--log-path", envoyLogPath)
+	args = append(args, i.allocateBaseID(proxyName)...)
+	args = append(args, i.settings.ExtraArgs...)
+
+	out, logFile, err := i.processOutput(proxyName)
+	if err != nil {
+		delete(i.baseIDs, proxyName)
+		return err
+	}
+	i.runEnvoy(ctx, out, proxyName, proxyInfra.GetProxyMetadata().Labels, args)
+	i.proxyContextMap[proxyName].logFile = logFile
 	return nil
 }
 
+// allocateBaseID returns the arguments setting the base ID of the Envoy process, according to
+// the base ID allocation of the host infrastructure provider.
+func (i *Infra) allocateBaseID(proxyName string) []string {
+	baseID := i.settings.BaseID
+	if baseID == nil {
+		return nil
+	}
+
+	switch baseID.Type {
+	case egv1a1.HostBaseIDTypeDynamic:
+		return []string{"--use-dynamic-base-id"}
+	case egv1a1.HostBaseIDTypeSequential:
+		used := make(map[uint32]bool, len(i.baseIDs))
+		for _, id := range i.baseIDs {
+			used[id] = true
+		}
+		id := ptr.Deref(baseID.Start, 0)
+		for used[id] {
+			id++
+		}
+		i.baseIDs[proxyName] = id
+		return []string{"--base-id", strconv.FormatUint(uint64(id), 10)}
+	default:
+		return nil
+	}
+}
+
+// processOutput returns where the output of the named process is written to, which is either
+// a log file under the log directory of the host infrastructure provider or the standard output.
+func (i *Infra) processOutput(name string) (io.Writer, *os.File, error) {
+	if i.settings.LogDir == "" {
+		return os.Stdout, nil, nil
+	}
+
+	logFile, err := os.OpenFile(filepath.Join(i.settings.LogDir, name+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return logFile, logFile, nil
+}
+
 // runEnvoy runs the Envoy process with the given arguments and name in a separate goroutine.
-func (i *Infra) runEnvoy(ctx context.Context, out io.Writer, name string, args []string) {
+// The process is restarted with an exponential backoff whenever it exits, until it's stopped.
+func (i *Infra) runEnvoy(ctx context.Context, out io.Writer, name string, labels map[string]string, args []string) {
 	pCtx, cancel := context.WithCancel(ctx)
 	exit := make(chan struct{}, 1)
 	i.proxyContextMap[name] = &proxyContext{cancel: cancel, exit: exit}
+
+	s := newSupervisor(name, i.envoyRunFunc(name, args), out, i.Logger, labels, func(status *message.ProxyStatus) {
+		if i.statuses != nil {
+			i.statuses.ProxyStatuses.Store(name, status)
+		}
+	})
 	go func() {
-		// Run blocks until pCtx is done or the process exits where the latter doesn't happen when
-		// Envoy successfully starts up. So, this will not return until pCtx is done in practice.
 		defer func() {
 			exit <- struct{}{}
 		}()
-		err := funcE.Run(pCtx, args, funcE.HomeDir(i.HomeDir), funcE.Out(out))
+		s.start(pCtx)
+	}()
+}
+
+// envoyRunFunc returns the function running the Envoy process, either the configured Envoy binary
+// or the configured Envoy version installed with func-e.
+func (i *Infra) envoyRunFunc(name string, args []string) runFunc {
+	return func(ctx context.Context, out io.Writer) error {
+		binary, err := i.envoyBinary(ctx)
 		if err != nil {
-			i.Logger.Error(err, "failed to run envoy")
+			return err
 		}
-	}()
+		// Run the binary directly instead of with func-e, which doesn't report the exit code of Envoy.
+		return i.runProcess(name, exec.CommandContext(ctx, binary, args...), out)
+	}
+}
+
+// envoyBinary returns the path of the Envoy binary to run. Unless a binary is configured, the configured
+// Envoy version is installed with func-e on the first call.
+func (i *Infra) envoyBinary(ctx context.Context) (string, error) {
+	if binary := i.settings.EnvoyBinaryPath; binary != "" {
+		return binary, nil
+	}
+
+	i.envoyBinaryMu.Lock()
+	defer i.envoyBinaryMu.Unlock()
+	if i.envoyBinaryPath != "" {
+		return i.envoyBinaryPath, nil
+	}
+
+	// func-e only installs Envoy when running it.
+	opts := []funcE.RunOption{funcE.HomeDir(i.HomeDir), funcE.Out(io.Discard)}
+	if i.settings.EnvoyVersion != "" {
+		opts = append(opts, funcE.EnvoyVersion(i.settings.EnvoyVersion))
+	}
+	if err := funcE.Run(ctx, []string{"--version"}, opts...); err != nil {
+		return "", fmt.Errorf("failed to install envoy: %w", err)
+	}
+	binary, err := funcEEnvoyBinary(i.HomeDir, i.settings.EnvoyVersion)
+	if err != nil {
+		return "", err
+	}
+	i.envoyBinaryPath = binary
+	return binary, nil
+}
+
+// funcEEnvoyBinary returns the path of the Envoy binary installed by func-e into its home directory,
+// which is versions/<version>/bin/envoy. Unless the version is given, it is the latest installed patch
+// of the version func-e records in its home directory.
+func funcEEnvoyBinary(homeDir, envoyVersion string) (string, error) {
+	versionsDir := filepath.Join(homeDir, "versions")
+	if envoyVersion == "" {
+		data, err := os.ReadFile(filepath.Join(homeDir, "version"))
+		if err != nil {
+			return "", fmt.Errorf("failed to read the func-e envoy version: %w", err)
+		}
+		current := strings.TrimSpace(string(data))
+		entries, err := os.ReadDir(versionsDir)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the func-e envoy versions: %w", err)
+		}
+		var latest *semver.Version
+		for _, entry := range entries {
+			if entry.Name() != current && !strings.HasPrefix(entry.Name(), current+".") {
+				continue
+			}
+			if v, err := semver.NewVersion(entry.Name()); err == nil && (latest == nil || v.GreaterThan(latest)) {
+				latest, envoyVersion = v, entry.Name()
+			}
+		}
+		if envoyVersion == "" {
+			return "", fmt.Errorf("envoy %s isn't installed in %s", current, versionsDir)
+		}
+	}
+
+	binary := filepath.Join(versionsDir, envoyVersion, "bin", "envoy")
+	if _, err := os.Stat(binary); err != nil {
+		return "", fmt.Errorf("failed to find the envoy binary installed by func-e: %w", err)
+	}
+	return binary, nil
 }
 
 // DeleteProxyInfra removes the managed host process, if it doesn't exist.
@@ -115,9 +274,11 @@ func (i *Infra) DeleteProxyInfra(_ context.Context, infra *ir.Infra) error {
 // stopEnvoy stops the Envoy process by its name. It will block until the process completely stopped.
 func (i *Infra) stopEnvoy(proxyName string) {
 	if pCtx, ok := i.proxyContextMap[proxyName]; ok {
-		pCtx.cancel()    // Cancel causes the Envoy process to exit.
-		<-pCtx.exit      // Wait for the Envoy process to completely exit.
-		close(pCtx.exit) // Close the channel to avoid leaking.
+		pCtx.stop()
 		delete(i.proxyContextMap, proxyName)
+		delete(i.baseIDs, proxyName)
+		if i.statuses != nil {
+			i.statuses.ProxyStatuses.Delete(proxyName)
+		}
 	}
 }
@@ -10,16 +10,21 @@ import (
 	"context"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	funcE "github.com/tetratelabs/func-e/api"
+	"k8s.io/utils/ptr"
 
 	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
 	"github.com/wukongcloud/gateway/internal/crypto"
 	"github.com/wukongcloud/gateway/internal/envoygateway/config"
 	"github.com/wukongcloud/gateway/internal/ir"
 	"github.com/wukongcloud/gateway/internal/logging"
+	"github.com/wukongcloud/gateway/internal/utils"
 	"github.com/wukongcloud/gateway/internal/utils/file"
 )
 
@@ -45,7 +50,9 @@ func newMockInfra(t *testing.T, cfg *config.Server) *Infra {
 		HomeDir:         homeDir,
 		Logger:          logging.DefaultLogger(os.Stdout, egv1a1.LogLevelInfo),
 		EnvoyGateway:    cfg.EnvoyGateway,
+		settings:        hostSettings(cfg.EnvoyGateway),
 		proxyContextMap: make(map[string]*proxyContext),
+		baseIDs:         make(map[string]uint32),
 		sdsConfigPath:   proxyDir,
 	}
 	return infra
@@ -94,7 +101,11 @@ func TestInfra_runEnvoy_stopEnvoy(t *testing.T) {
 	err := funcE.Run(context.Background(), []string{"--version"}, funcE.HomeDir(tmpdir))
 	require.NoError(t, err)
 
-	i := &Infra{proxyContextMap: make(map[string]*proxyContext), HomeDir: tmpdir}
+	i := &Infra{
+		proxyContextMap: make(map[string]*proxyContext),
+		HomeDir:         tmpdir,
+		settings:        &egv1a1.EnvoyGatewayHostInfrastructureProvider{},
+	}
 	// Ensures that run -> stop will successfully stop the envoy and we can
 	// run it again without any issues.
 	for range 5 {
@@ -103,7 +114,7 @@ func TestInfra_runEnvoy_stopEnvoy(t *testing.T) {
 			"admin: {address: {socket_address: {address: '127.0.0.1', port_value: 9901}}}",
 		}
 		out := &bytes.Buffer{}
-		i.runEnvoy(context.Background(), out, "test", args)
+		i.runEnvoy(context.Background(), out, "test", nil, args)
 		require.Len(t, i.proxyContextMap, 1)
 		i.stopEnvoy("test")
 		require.Empty(t, i.proxyContextMap)
@@ -111,3 +122,101 @@ func TestInfra_runEnvoy_stopEnvoy(t *testing.T) {
 		// which will panic.
 	}
 }
+
+func TestInfra_allocateBaseID(t *testing.T) {
+	testCases := []struct {
+		name   string
+		baseID *egv1a1.HostBaseID
+		expect [][]string
+	}{
+		{
+			name:   "unset",
+			expect: [][]string{nil, nil},
+		},
+		{
+			name:   "dynamic",
+			baseID: &egv1a1.HostBaseID{Type: egv1a1.HostBaseIDTypeDynamic},
+			expect: [][]string{{"--use-dynamic-base-id"}, {"--use-dynamic-base-id"}},
+		},
+		{
+			name:   "sequential",
+			baseID: &egv1a1.HostBaseID{Type: egv1a1.HostBaseIDTypeSequential},
+			expect: [][]string{{"--base-id", "0"}, {"--base-id", "1"}},
+		},
+		{
+			name:   "sequential with start",
+			baseID: &egv1a1.HostBaseID{Type: egv1a1.HostBaseIDTypeSequential, Start: ptr.To[uint32](10)},
+			expect: [][]string{{"--base-id", "10"}, {"--base-id", "11"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			i := &Infra{
+				settings: &egv1a1.EnvoyGatewayHostInfrastructureProvider{BaseID: tc.baseID},
+				baseIDs:  make(map[string]uint32),
+			}
+			require.Equal(t, tc.expect[0], i.allocateBaseID("first"))
+			require.Equal(t, tc.expect[1], i.allocateBaseID("second"))
+
+			// The base ID of a stopped proxy is allocated again.
+			delete(i.baseIDs, "first")
+			require.Equal(t, tc.expect[0], i.allocateBaseID("third"))
+		})
+	}
+}
+
+func TestFuncEEnvoyBinary(t *testing.T) {
+	homeDir := t.TempDir()
+	for _, version := range []string{"1.32.3", "1.33.0", "1.33.2", "1.34.0"} {
+		bin := filepath.Join(homeDir, "versions", version, "bin")
+		require.NoError(t, os.MkdirAll(bin, 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "envoy"), nil, 0o600))
+	}
+
+	// The configured version.
+	binary, err := funcEEnvoyBinary(homeDir, "1.32.3")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(homeDir, "versions", "1.32.3", "bin", "envoy"), binary)
+	_, err = funcEEnvoyBinary(homeDir, "1.31.0")
+	require.Error(t, err)
+
+	// The latest patch of the version recorded by func-e.
+	_, err = funcEEnvoyBinary(homeDir, "")
+	require.Error(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, "version"), []byte("1.33\n"), 0o600))
+	binary, err = funcEEnvoyBinary(homeDir, "")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(homeDir, "versions", "1.33.2", "bin", "envoy"), binary)
+}
+
+func TestInfraCreateProxyWithSettings(t *testing.T) {
+	cfg, err := config.New(os.Stdout)
+	require.NoError(t, err)
+	infra := newMockInfra(t, cfg)
+
+	// A fake Envoy binary printing each of its arguments on its own line.
+	binary := filepath.Join(t.TempDir(), "envoy")
+	require.NoError(t, os.WriteFile(binary, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\"\nexec sleep 60\n"), 0o700)) // nolint:gosec
+	logDir := t.TempDir()
+	infra.settings = &egv1a1.EnvoyGatewayHostInfrastructureProvider{
+		EnvoyBinaryPath: binary,
+		BaseID:          &egv1a1.HostBaseID{Type: egv1a1.HostBaseIDTypeSequential, Start: ptr.To[uint32](5)},
+		ExtraArgs:       []string{"--disable-hot-restart"},
+		LogDir:          logDir,
+	}
+
+	infraIR := ir.NewInfra()
+	require.NoError(t, infra.CreateOrUpdateProxyInfra(context.Background(), infraIR))
+	defer func() {
+		require.NoError(t, infra.DeleteProxyInfra(context.Background(), infraIR))
+		require.Empty(t, infra.proxyContextMap)
+		require.Empty(t, infra.baseIDs)
+	}()
+
+	logFile := filepath.Join(logDir, utils.GetHashedName(infraIR.Proxy.Name, 64)+".log")
+	require.Eventually(t, func() bool {
+		content, err := os.ReadFile(logFile)
+		return err == nil && strings.Contains(string(content), "\n--base-id\n5\n--disable-hot-restart\n")
+	}, 10*time.Second, 100*time.Millisecond)
+}
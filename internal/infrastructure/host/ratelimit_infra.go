@@ -7,17 +7,135 @@ package host
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/infrastructure/common"
+	"github.com/wukongcloud/gateway/internal/infrastructure/kubernetes/ratelimit"
+)
+
+const (
+	// defaultRateLimitBinary is the name of the rate limit service binary, it's looked up in PATH.
+	defaultRateLimitBinary = "ratelimit"
+	// defaultLocalRateLimitCertPathDir is the directory containing the rate limit service certificates,
+	// as generated by `envoy-gateway certgen --local`.
+	defaultLocalRateLimitCertPathDir = "/tmp/envoy-gateway/certs/envoy-rate-limit"
+
+	// RateLimitHost is the address the managed rate limit service listens on.
+	RateLimitHost = "127.0.0.1"
+	// rateLimitProcessName is the name of the rate limit process, e.g. for its log file.
+	rateLimitProcessName = "ratelimit"
 )
 
-// TODO: add ratelimit support for host infra
+// GetRateLimitServiceURL returns the URL for the rate limit service managed by the host infrastructure.
+func GetRateLimitServiceURL() string {
+	return fmt.Sprintf("grpc://%s", net.JoinHostPort(RateLimitHost, strconv.Itoa(ratelimit.InfraGRPCPort)))
+}
 
-// CreateOrUpdateRateLimitInfra creates the managed host rate limit process, if it doesn't exist.
+// GetRateLimitClientCertDir returns the directory containing the certificates used by Envoy
+// to connect to the rate limit service managed by the host infrastructure.
+func GetRateLimitClientCertDir() string {
+	return defaultLocalCertPathDir
+}
+
+// CreateOrUpdateRateLimitInfra creates the managed host rate limit process, if it doesn't exist,
+// or restarts it if its configuration changed.
 func (i *Infra) CreateOrUpdateRateLimitInfra(ctx context.Context) error {
-	return fmt.Errorf("create/update ratelimit infrastructure is not supported yet for host infrastructure")
+	if i.EnvoyGateway == nil || i.EnvoyGateway.RateLimit == nil {
+		return errors.New("ratelimit configuration is nil")
+	}
+
+	i.rateLimitMu.Lock()
+	defer i.rateLimitMu.Unlock()
+
+	binary, err := exec.LookPath(defaultRateLimitBinary)
+	if err != nil {
+		return fmt.Errorf("failed to find ratelimit binary: %w", err)
+	}
+
+	env, err := i.rateLimitEnv(i.EnvoyGateway.RateLimit)
+	if err != nil {
+		return err
+	}
+
+	// Return directly if the rate limit service is running with the same configuration.
+	config := append([]string{binary}, env...)
+	if i.rateLimitContext != nil {
+		if slices.Equal(i.rateLimitConfig, config) {
+			return nil
+		}
+		i.Logger.Info("ratelimit configuration changed, restarting ratelimit")
+		i.stopRateLimit()
+	}
+
+	out, logFile, err := i.processOutput(rateLimitProcessName)
+	if err != nil {
+		return err
+	}
+	i.runRateLimit(ctx, out, i.rateLimitRunFunc(binary, env))
+	i.rateLimitContext.logFile = logFile
+	i.rateLimitConfig = config
+	return nil
+}
+
+// rateLimitEnv returns the environment variables used to configure the rate limit service.
+func (i *Infra) rateLimitEnv(rateLimit *egv1a1.RateLimit) ([]string, error) {
+	return common.BuildRateLimitEnv(rateLimit, &common.RateLimitEnvOptions{
+		RuntimeRoot: filepath.Join(i.HomeDir, "ratelimit"),
+		CertDir:     i.rateLimitCertPath,
+		// Only listen on the loopback interface, since Envoy runs on the same host.
+		ListenHost:    RateLimitHost,
+		XdsServerHost: RateLimitHost,
+	})
+}
+
+// runRateLimit runs the rate limit process in a separate goroutine.
+// The process is restarted with an exponential backoff whenever it exits, until it's stopped.
+func (i *Infra) runRateLimit(ctx context.Context, out io.Writer, run runFunc) {
+	pCtx, cancel := context.WithCancel(ctx)
+	exit := make(chan struct{}, 1)
+	i.rateLimitContext = &proxyContext{cancel: cancel, exit: exit}
+
+	s := newSupervisor(rateLimitProcessName, run, out, i.Logger, nil, nil)
+	go func() {
+		defer func() {
+			exit <- struct{}{}
+		}()
+		s.start(pCtx)
+	}()
+}
+
+// rateLimitRunFunc returns the function running the rate limit process with the given binary and environment.
+func (i *Infra) rateLimitRunFunc(binary string, env []string) runFunc {
+	return func(ctx context.Context, out io.Writer) error {
+		cmd := exec.CommandContext(ctx, binary)
+		cmd.Env = append(os.Environ(), env...)
+		return i.runProcess(rateLimitProcessName, cmd, out)
+	}
 }
 
 // DeleteRateLimitInfra removes the managed host rate limit process, if it doesn't exist.
-func (i *Infra) DeleteRateLimitInfra(ctx context.Context) error {
-	return fmt.Errorf("delete ratelimit infrastructure is not supported yet for host infrastructure")
+func (i *Infra) DeleteRateLimitInfra(_ context.Context) error {
+	i.rateLimitMu.Lock()
+	defer i.rateLimitMu.Unlock()
+
+	i.stopRateLimit()
+	return nil
+}
+
+// stopRateLimit stops the rate limit process. It will block until the process completely stopped.
+func (i *Infra) stopRateLimit() {
+	if rCtx := i.rateLimitContext; rCtx != nil {
+		rCtx.stop()
+		i.rateLimitContext = nil
+		i.rateLimitConfig = nil
+	}
 }
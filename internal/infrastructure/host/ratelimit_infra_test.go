@@ -0,0 +1,141 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package host
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/envoygateway/config"
+	"github.com/wukongcloud/gateway/internal/infrastructure/kubernetes/ratelimit"
+)
+
+func TestInfraRateLimitEnv(t *testing.T) {
+	testCases := []struct {
+		name      string
+		rateLimit *egv1a1.RateLimit
+		expect    map[string]string
+		expectErr bool
+	}{
+		{
+			name: "redis",
+			rateLimit: &egv1a1.RateLimit{
+				Backend: egv1a1.RateLimitDatabaseBackend{
+					Type:  egv1a1.RedisBackendType,
+					Redis: &egv1a1.RateLimitRedisSettings{URL: "redis.local:6379"},
+				},
+			},
+			expect: map[string]string{
+				ratelimit.RedisSocketTypeEnvVar:        "tcp",
+				ratelimit.RedisURLEnvVar:               "redis.local:6379",
+				ratelimit.ConfigGrpcXdsServerURLEnvVar: "127.0.0.1:18001",
+				ratelimit.ConfigGrpcXdsNodeIDEnvVar:    ratelimit.InfraName,
+				ratelimit.GRPCServerTLSCertEnvVar:      "/certs/envoy-rate-limit/tls.crt",
+				"GRPC_HOST":                            RateLimitHost,
+			},
+		},
+		{
+			name: "redis with tls",
+			rateLimit: &egv1a1.RateLimit{
+				Backend: egv1a1.RateLimitDatabaseBackend{
+					Type: egv1a1.RedisBackendType,
+					Redis: &egv1a1.RateLimitRedisSettings{
+						URL: "redis.local:6379",
+						TLS: &egv1a1.RedisTLSSettings{},
+					},
+				},
+			},
+			expect: map[string]string{
+				ratelimit.RedisURLEnvVar: "redis.local:6379",
+				ratelimit.RedisTLSEnvVar: "true",
+			},
+		},
+		{
+			name: "redis with tls certificateRef",
+			rateLimit: &egv1a1.RateLimit{
+				Backend: egv1a1.RateLimitDatabaseBackend{
+					Type: egv1a1.RedisBackendType,
+					Redis: &egv1a1.RateLimitRedisSettings{
+						URL: "redis.local:6379",
+						TLS: &egv1a1.RedisTLSSettings{
+							CertificateRef: &gwapiv1.SecretObjectReference{Name: "redis-certs"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+	}
+
+	i := &Infra{HomeDir: "/home", rateLimitCertPath: "/certs/envoy-rate-limit"}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env, err := i.rateLimitEnv(tc.rateLimit)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for k, v := range tc.expect {
+				require.Contains(t, env, k+"="+v)
+			}
+		})
+	}
+}
+
+func TestInfraCreateDeleteRateLimit(t *testing.T) {
+	cfg, err := config.New(os.Stdout)
+	require.NoError(t, err)
+	infra := newMockInfra(t, cfg)
+
+	// Rate limit is not configured.
+	require.Error(t, infra.CreateOrUpdateRateLimitInfra(context.Background()))
+	// Deleting a rate limit service that is not running is a no-op.
+	require.NoError(t, infra.DeleteRateLimitInfra(context.Background()))
+	require.Nil(t, infra.rateLimitContext)
+}
+
+func TestInfraRateLimitRestartOnConfigChange(t *testing.T) {
+	// A fake rate limit service, which runs until it's interrupted.
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, defaultRateLimitBinary), []byte("#!/bin/sh\nexec sleep 60\n"), 0o700)) // nolint:gosec
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg, err := config.New(os.Stdout)
+	require.NoError(t, err)
+	cfg.EnvoyGateway.RateLimit = &egv1a1.RateLimit{
+		Backend: egv1a1.RateLimitDatabaseBackend{
+			Type:  egv1a1.RedisBackendType,
+			Redis: &egv1a1.RateLimitRedisSettings{URL: "redis.local:6379"},
+		},
+	}
+	infra := newMockInfra(t, cfg)
+	t.Cleanup(func() { _ = infra.Close() })
+
+	require.NoError(t, infra.CreateOrUpdateRateLimitInfra(context.Background()))
+	running := infra.rateLimitContext
+	require.NotNil(t, running)
+
+	// The process isn't restarted when the configuration is the same.
+	require.NoError(t, infra.CreateOrUpdateRateLimitInfra(context.Background()))
+	require.Same(t, running, infra.rateLimitContext)
+
+	// The process is restarted when the configuration changed.
+	cfg.EnvoyGateway.RateLimit.Backend.Redis.URL = "redis.other:6379"
+	require.NoError(t, infra.CreateOrUpdateRateLimitInfra(context.Background()))
+	require.NotSame(t, running, infra.rateLimitContext)
+	require.Contains(t, infra.rateLimitConfig, ratelimit.RedisURLEnvVar+"=redis.other:6379")
+
+	require.NoError(t, infra.DeleteRateLimitInfra(context.Background()))
+	require.Nil(t, infra.rateLimitContext)
+	require.Nil(t, infra.rateLimitConfig)
+}
@@ -0,0 +1,181 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package host
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wukongcloud/gateway/internal/logging"
+	"github.com/wukongcloud/gateway/internal/message"
+)
+
+const (
+	// defaultInitialRestartBackoff is the time to wait before the first restart of an exited process,
+	// it doubles on every following restart up to defaultMaxRestartBackoff.
+	defaultInitialRestartBackoff = 1 * time.Second
+	defaultMaxRestartBackoff     = 5 * time.Minute
+	// stableRunDuration is how long a process must run to reset its restart backoff.
+	stableRunDuration = 2 * time.Minute
+	// crashLoopThreshold is the number of exits within crashLoopWindow for a process to be
+	// considered crash looping.
+	crashLoopThreshold = 5
+	crashLoopWindow    = 10 * time.Minute
+	// logTailLines is the number of lines kept from the output of a process before it exits.
+	logTailLines = 20
+)
+
+// runFunc runs a process and blocks until it exits or ctx is done, its output is written to out.
+type runFunc func(ctx context.Context, out io.Writer) error
+
+// supervisor keeps a process running, restarting it with an exponential backoff when it exits.
+type supervisor struct {
+	name   string
+	run    runFunc
+	out    io.Writer
+	logger logging.Logger
+	// report is called with the status of the process whenever it changes, it can be nil.
+	report func(*message.ProxyStatus)
+
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	status message.ProxyStatus
+}
+
+func newSupervisor(name string, run runFunc, out io.Writer, logger logging.Logger,
+	labels map[string]string, report func(*message.ProxyStatus),
+) *supervisor {
+	return &supervisor{
+		name:           name,
+		run:            run,
+		out:            out,
+		logger:         logger,
+		report:         report,
+		initialBackoff: defaultInitialRestartBackoff,
+		maxBackoff:     defaultMaxRestartBackoff,
+		status:         message.ProxyStatus{Labels: labels},
+	}
+}
+
+// start runs the process until ctx is done.
+func (s *supervisor) start(ctx context.Context) {
+	backoff := s.initialBackoff
+	var exits []time.Time
+	for {
+		s.status.Phase = message.ProxyPhaseRunning
+		s.reportStatus()
+
+		tail := newLineTail(logTailLines)
+		started := time.Now()
+		err := s.run(ctx, io.MultiWriter(s.out, tail))
+		if ctx.Err() != nil {
+			return
+		}
+
+		now := time.Now()
+		if now.Sub(started) >= stableRunDuration {
+			backoff = s.initialBackoff
+		}
+		exits = append(exits, now)
+		for len(exits) > 0 && now.Sub(exits[0]) > crashLoopWindow {
+			exits = exits[1:]
+		}
+
+		s.status.Restarts++
+		s.status.LastExitCode = exitCode(err)
+		s.status.LastExitTime = now
+		s.status.LastLogs = tail.String()
+		s.status.Phase = message.ProxyPhaseRestarting
+		if len(exits) >= crashLoopThreshold {
+			s.status.Phase = message.ProxyPhaseCrashLoopBackOff
+		}
+		s.reportStatus()
+
+		proxyExitTotal.With(nameLabel.Value(s.name), exitCodeLabel.Value(fmt.Sprint(s.status.LastExitCode))).Increment()
+		s.logger.Error(err, "process exited unexpectedly, restarting", "name", s.name,
+			"exitCode", s.status.LastExitCode, "phase", s.status.Phase, "backoff", backoff, "logs", s.status.LastLogs)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, s.maxBackoff)
+	}
+}
+
+func (s *supervisor) reportStatus() {
+	crashLooping := 0.0
+	if s.status.Phase == message.ProxyPhaseCrashLoopBackOff {
+		crashLooping = 1
+	}
+	proxyCrashLooping.With(nameLabel.Value(s.name)).Record(crashLooping)
+
+	if s.report != nil {
+		s.report(s.status.DeepCopy())
+	}
+}
+
+// exitCode returns the exit code of the process from the error returned by running it,
+// it returns -1 if the exit code is unknown.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// lineTail is an io.Writer that keeps the last lines written to it.
+type lineTail struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial string
+}
+
+func newLineTail(maxLines int) *lineTail {
+	return &lineTail{max: maxLines}
+}
+
+func (t *lineTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := strings.Split(t.partial+string(p), "\n")
+	t.partial = lines[len(lines)-1]
+	t.lines = append(t.lines, lines[:len(lines)-1]...)
+	if len(t.lines) > t.max {
+		t.lines = t.lines[len(t.lines)-t.max:]
+	}
+	return len(p), nil
+}
+
+// String returns the last lines, including the incomplete last line if any.
+func (t *lineTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := t.lines
+	if t.partial != "" {
+		lines = append(lines[:len(lines):len(lines)], t.partial)
+		if len(lines) > t.max {
+			lines = lines[1:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,125 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package host
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wukongcloud/gateway/internal/logging"
+	"github.com/wukongcloud/gateway/internal/message"
+)
+
+func TestSupervisor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		statuses []*message.ProxyStatus
+		runs     int
+	)
+	run := func(ctx context.Context, out io.Writer) error {
+		mu.Lock()
+		runs++
+		n := runs
+		mu.Unlock()
+
+		// Keep running after crashing enough times to be considered crash looping.
+		if n > crashLoopThreshold {
+			<-ctx.Done()
+			return nil
+		}
+		_, _ = fmt.Fprintf(out, "starting run %d\ncritical error in run %d", n, n)
+		return exec.Command("sh", "-c", fmt.Sprintf("exit %d", n)).Run()
+	}
+
+	labels := map[string]string{"gateway.envoyproxy.io/owning-gateway-name": "eg"}
+	s := newSupervisor("test", run, io.Discard, logging.Logger{}, labels, func(status *message.ProxyStatus) {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses = append(statuses, status)
+	})
+	s.initialBackoff = time.Millisecond
+	s.maxBackoff = 4 * time.Millisecond
+
+	done := make(chan struct{})
+	go func() {
+		s.start(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return runs == crashLoopThreshold+1
+	}, 10*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Each run reports the running status, and each exit reports the exited status.
+	require.Len(t, statuses, 2*crashLoopThreshold+1)
+	for _, status := range statuses {
+		require.Equal(t, labels, status.Labels)
+	}
+
+	first := statuses[1]
+	require.Equal(t, message.ProxyPhaseRestarting, first.Phase)
+	require.Equal(t, 1, first.Restarts)
+	require.Equal(t, 1, first.LastExitCode)
+	require.Equal(t, "starting run 1\ncritical error in run 1", first.LastLogs)
+
+	last := statuses[len(statuses)-2]
+	require.Equal(t, message.ProxyPhaseCrashLoopBackOff, last.Phase)
+	require.Equal(t, crashLoopThreshold, last.Restarts)
+	require.Equal(t, crashLoopThreshold, last.LastExitCode)
+
+	running := statuses[len(statuses)-1]
+	require.Equal(t, message.ProxyPhaseRunning, running.Phase)
+	require.Equal(t, crashLoopThreshold, running.Restarts)
+}
+
+func TestExitCode(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+
+	testCases := []struct {
+		name   string
+		err    error
+		expect int
+	}{
+		{name: "no error", err: nil, expect: 0},
+		{name: "exec exit error", err: exitErr, expect: 3},
+		{name: "unknown error", err: errors.New("unable to start Envoy process"), expect: -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expect, exitCode(tc.err))
+		})
+	}
+}
+
+func TestLineTail(t *testing.T) {
+	tail := newLineTail(3)
+	_, _ = tail.Write([]byte("line 1\nline 2\nli"))
+	require.Equal(t, "line 1\nline 2\nli", tail.String())
+
+	_, _ = tail.Write([]byte("ne 3\nline 4\n"))
+	require.Equal(t, "line 2\nline 3\nline 4", tail.String())
+
+	_, _ = tail.Write([]byte("line 5"))
+	require.Equal(t, "line 3\nline 4\nline 5", tail.String())
+}
@@ -15,15 +15,18 @@ import (
 	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
 	"github.com/wukongcloud/gateway/internal/envoygateway"
 	"github.com/wukongcloud/gateway/internal/envoygateway/config"
+	"github.com/wukongcloud/gateway/internal/infrastructure/docker"
 	"github.com/wukongcloud/gateway/internal/infrastructure/host"
 	"github.com/wukongcloud/gateway/internal/infrastructure/kubernetes"
 	"github.com/wukongcloud/gateway/internal/ir"
 	"github.com/wukongcloud/gateway/internal/logging"
+	"github.com/wukongcloud/gateway/internal/message"
 )
 
 var (
 	_ Manager = (*kubernetes.Infra)(nil)
 	_ Manager = (*host.Infra)(nil)
+	_ Manager = (*docker.Infra)(nil)
 )
 
 // Manager provides the scaffolding for managing infrastructure.
@@ -40,13 +43,14 @@ type Manager interface {
 	DeleteRateLimitInfra(ctx context.Context) error
 }
 
-// NewManager returns a new infrastructure Manager.
-func NewManager(ctx context.Context, cfg *config.Server, logger logging.Logger) (mgr Manager, err error) {
+// NewManager returns a new infrastructure Manager. The statuses of the infrastructure managed by
+// a custom infrastructure provider are reported to statuses.
+func NewManager(ctx context.Context, cfg *config.Server, logger logging.Logger, statuses *message.InfraStatuses) (mgr Manager, err error) {
 	switch cfg.EnvoyGateway.Provider.Type {
 	case egv1a1.ProviderTypeKubernetes:
 		mgr, err = newManagerForKubernetes(cfg)
 	case egv1a1.ProviderTypeCustom:
-		mgr, err = newManagerForCustom(ctx, cfg, logger)
+		mgr, err = newManagerForCustom(ctx, cfg, logger, statuses)
 	}
 
 	if err != nil {
@@ -65,11 +69,13 @@ func newManagerForKubernetes(cfg *config.Server) (Manager, error) {
 	return kubernetes.NewInfra(cli, cfg), nil
 }
 
-func newManagerForCustom(ctx context.Context, cfg *config.Server, logger logging.Logger) (Manager, error) {
+func newManagerForCustom(ctx context.Context, cfg *config.Server, logger logging.Logger, statuses *message.InfraStatuses) (Manager, error) {
 	infra := cfg.EnvoyGateway.Provider.Custom.Infrastructure
 	switch infra.Type {
 	case egv1a1.InfrastructureProviderTypeHost:
-		return host.NewInfra(ctx, cfg, logger)
+		return host.NewInfra(ctx, cfg, logger, statuses)
+	case egv1a1.InfrastructureProviderTypeDocker:
+		return docker.NewInfra(cfg, logger)
 	default:
 		return nil, fmt.Errorf("unsupported provider type: %s", infra.Type)
 	}
@@ -21,6 +21,9 @@ import (
 type Config struct {
 	config.Server
 	InfraIR *message.InfraIR
+	// InfraStatuses is where the statuses of the infrastructure are reported to, if supported
+	// by the infrastructure provider.
+	InfraStatuses *message.InfraStatuses
 }
 
 type Runner struct {
@@ -51,7 +54,7 @@ func (r *Runner) Start(ctx context.Context) (err error) {
 		return nil
 	}
 
-	r.mgr, err = infrastructure.NewManager(ctx, &r.Server, r.Logger)
+	r.mgr, err = infrastructure.NewManager(ctx, &r.Server, r.Logger, r.InfraStatuses)
 	if err != nil {
 		r.Logger.Error(err, "failed to create new manager")
 		return err
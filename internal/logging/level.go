@@ -0,0 +1,187 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package logging
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+)
+
+// Components are the components whose logging level can be changed at runtime.
+var Components = []egv1a1.EnvoyGatewayLogComponent{
+	egv1a1.LogComponentGatewayDefault,
+	egv1a1.LogComponentProviderRunner,
+	egv1a1.LogComponentGatewayAPIRunner,
+	egv1a1.LogComponentXdsTranslatorRunner,
+	egv1a1.LogComponentXdsServerRunner,
+	egv1a1.LogComponentInfrastructureRunner,
+	egv1a1.LogComponentGlobalRateLimitRunner,
+}
+
+// ComponentLevel is the logging level of a component.
+type ComponentLevel struct {
+	Component egv1a1.EnvoyGatewayLogComponent `json:"component"`
+	// Level is the current level of the component.
+	Level egv1a1.LogLevel `json:"level"`
+	// ConfiguredLevel is the level of the component in the EnvoyGatewayLogging configuration.
+	ConfiguredLevel egv1a1.LogLevel `json:"configuredLevel"`
+	// RevertAt is the time the level is reverted to the configured level, if it was
+	// changed at runtime with a timeout.
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+// levels holds the atomic levels of the loggers of every name, so the level of
+// the loggers created from the same Logger can be changed at runtime.
+type levels struct {
+	mu      sync.Mutex
+	logging *egv1a1.EnvoyGatewayLogging
+	// overrides holds the levels set at runtime by component.
+	overrides map[egv1a1.EnvoyGatewayLogComponent]egv1a1.LogLevel
+	// reverts holds the timers reverting the overrides set with a timeout.
+	reverts map[egv1a1.EnvoyGatewayLogComponent]*revert
+	atomic  map[egv1a1.EnvoyGatewayLogComponent]zap.AtomicLevel
+}
+
+type revert struct {
+	timer *time.Timer
+	at    time.Time
+}
+
+func newLevels(logging *egv1a1.EnvoyGatewayLogging) *levels {
+	return &levels{
+		logging:   logging,
+		overrides: make(map[egv1a1.EnvoyGatewayLogComponent]egv1a1.LogLevel),
+		reverts:   make(map[egv1a1.EnvoyGatewayLogComponent]*revert),
+		atomic:    make(map[egv1a1.EnvoyGatewayLogComponent]zap.AtomicLevel),
+	}
+}
+
+func atomicLevelAt(level egv1a1.LogLevel) zap.AtomicLevel {
+	parseLevel, _ := zapcore.ParseLevel(string(level))
+	return zap.NewAtomicLevelAt(parseLevel)
+}
+
+// atomicLevel returns the atomic level shared by the loggers of the component.
+func (l *levels) atomicLevel(component egv1a1.EnvoyGatewayLogComponent) zap.AtomicLevel {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	level, ok := l.atomic[component]
+	if !ok {
+		level = atomicLevelAt(l.level(component))
+		l.atomic[component] = level
+	}
+	return level
+}
+
+// level returns the effective level of the component: the level set at runtime, or
+// the configured one, falling back to the level of the default component.
+// The caller must hold the lock.
+func (l *levels) level(component egv1a1.EnvoyGatewayLogComponent) egv1a1.LogLevel {
+	if level, ok := l.overrides[component]; ok {
+		return level
+	}
+	if level := l.logging.Level[component]; level != "" {
+		return level
+	}
+	if level, ok := l.overrides[egv1a1.LogComponentGatewayDefault]; ok {
+		return level
+	}
+	return l.logging.DefaultEnvoyGatewayLoggingLevel("")
+}
+
+// update applies the effective levels to the loggers of every component.
+// The caller must hold the lock.
+func (l *levels) update() {
+	for component, atomic := range l.atomic {
+		parseLevel, _ := zapcore.ParseLevel(string(l.level(component)))
+		atomic.SetLevel(parseLevel)
+	}
+}
+
+// set sets the level of the component, and reverts it after the timeout if not zero.
+// An empty level reverts the component to its configured level.
+func (l *levels) set(component egv1a1.EnvoyGatewayLogComponent, level egv1a1.LogLevel, timeout time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r, ok := l.reverts[component]; ok {
+		r.timer.Stop()
+		delete(l.reverts, component)
+	}
+	if level == "" {
+		delete(l.overrides, component)
+	} else {
+		l.overrides[component] = level
+	}
+	if level != "" && timeout > 0 {
+		r := &revert{at: time.Now().Add(timeout)}
+		r.timer = time.AfterFunc(timeout, func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			// The level may have been set again since.
+			if l.reverts[component] != r {
+				return
+			}
+			delete(l.reverts, component)
+			delete(l.overrides, component)
+			l.update()
+		})
+		l.reverts[component] = r
+	}
+	l.update()
+}
+
+// SetLevel sets the logging level of the component at runtime, overriding its
+// configured level for the loggers created from this Logger. Setting the level of
+// the default component changes the level of the components without a configured
+// or runtime level. If the timeout isn't zero, the level is reverted to the
+// configured one once it expires. An empty level reverts the component right away.
+func (l Logger) SetLevel(component egv1a1.EnvoyGatewayLogComponent, level egv1a1.LogLevel, timeout time.Duration) error {
+	if !slices.Contains(Components, component) {
+		return fmt.Errorf("invalid logging component %q, valid options: %v", component, Components)
+	}
+	switch level {
+	case "", egv1a1.LogLevelDebug, egv1a1.LogLevelInfo, egv1a1.LogLevelWarn, egv1a1.LogLevelError:
+	default:
+		return fmt.Errorf("invalid logging level %q, valid options: debug/info/warn/error", level)
+	}
+	if timeout < 0 {
+		return fmt.Errorf("invalid timeout %s", timeout)
+	}
+
+	l.levels.set(component, level, timeout)
+	return nil
+}
+
+// Levels returns the logging level of every component.
+func (l Logger) Levels() []ComponentLevel {
+	l.levels.mu.Lock()
+	defer l.levels.mu.Unlock()
+
+	levels := make([]ComponentLevel, 0, len(Components))
+	for _, component := range Components {
+		level := ComponentLevel{
+			Component:       component,
+			Level:           l.levels.level(component),
+			ConfiguredLevel: l.levels.logging.DefaultEnvoyGatewayLoggingLevel(l.levels.logging.Level[component]),
+		}
+		if r, ok := l.levels.reverts[component]; ok {
+			at := r.at
+			level.RevertAt = &at
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
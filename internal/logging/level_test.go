@@ -0,0 +1,67 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package logging
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+)
+
+func TestSetLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	config := egv1a1.DefaultEnvoyGatewayLogging()
+	config.Level[egv1a1.LogComponentInfrastructureRunner] = egv1a1.LogLevelError
+	logger := NewLogger(out, config)
+	xdsServer := logger.WithName(string(egv1a1.LogComponentXdsServerRunner))
+	infra := logger.WithName(string(egv1a1.LogComponentInfrastructureRunner))
+
+	xdsServer.Sugar().Debug("debug message before")
+	require.Empty(t, out.String())
+
+	// The level of the loggers already created is changed.
+	require.NoError(t, logger.SetLevel(egv1a1.LogComponentXdsServerRunner, egv1a1.LogLevelDebug, 0))
+	xdsServer.Sugar().Debug("debug message after")
+	require.Contains(t, out.String(), "debug message after")
+
+	// The default level applies to the components without a configured level.
+	require.NoError(t, logger.SetLevel(egv1a1.LogComponentGatewayDefault, egv1a1.LogLevelWarn, 0))
+	levels := logger.Levels()
+	require.Equal(t, egv1a1.LogComponentGatewayDefault, levels[0].Component)
+	require.Equal(t, egv1a1.LogLevelWarn, levels[0].Level)
+	for _, level := range levels {
+		switch level.Component {
+		case egv1a1.LogComponentXdsServerRunner:
+			require.Equal(t, egv1a1.LogLevelDebug, level.Level)
+			require.Equal(t, egv1a1.LogLevelInfo, level.ConfiguredLevel)
+		case egv1a1.LogComponentInfrastructureRunner:
+			require.Equal(t, egv1a1.LogLevelError, level.Level)
+		default:
+			require.Equal(t, egv1a1.LogLevelWarn, level.Level)
+		}
+	}
+
+	// The level is reverted once the timeout expires.
+	require.NoError(t, logger.SetLevel(egv1a1.LogComponentInfrastructureRunner, egv1a1.LogLevelInfo, 50*time.Millisecond))
+	require.NotNil(t, logger.Levels()[5].RevertAt)
+	infra.Info("info message")
+	require.Contains(t, out.String(), "info message")
+	require.Eventually(t, func() bool {
+		return logger.Levels()[5].Level == egv1a1.LogLevelError
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Nil(t, logger.Levels()[5].RevertAt)
+
+	// An empty level reverts the component right away.
+	require.NoError(t, logger.SetLevel(egv1a1.LogComponentXdsServerRunner, "", 0))
+	require.Equal(t, egv1a1.LogLevelWarn, logger.Levels()[4].Level)
+
+	require.Error(t, logger.SetLevel("unknown", egv1a1.LogLevelDebug, 0))
+	require.Error(t, logger.SetLevel(egv1a1.LogComponentXdsServerRunner, "verbose", 0))
+}
@@ -21,16 +21,19 @@ type Logger struct {
 	logr.Logger
 	out           io.Writer
 	logging       *egv1a1.EnvoyGatewayLogging
+	levels        *levels
 	sugaredLogger *zap.SugaredLogger
 }
 
 func NewLogger(w io.Writer, logging *egv1a1.EnvoyGatewayLogging) Logger {
-	logger := initZapLogger(w, logging, logging.Level[egv1a1.LogComponentGatewayDefault])
+	levels := newLevels(logging)
+	logger := initZapLogger(w, levels.atomicLevel(egv1a1.LogComponentGatewayDefault))
 
 	return Logger{
 		Logger:        zapr.NewLogger(logger),
 		out:           w,
 		logging:       logging,
+		levels:        levels,
 		sugaredLogger: logger.Sugar(),
 	}
 }
@@ -42,11 +45,12 @@ func FileLogger(file, name string, level egv1a1.LogLevel) Logger {
 	}
 
 	logging := egv1a1.DefaultEnvoyGatewayLogging()
-	logger := initZapLogger(writer, logging, level)
+	logger := initZapLogger(writer, atomicLevelAt(logging.DefaultEnvoyGatewayLoggingLevel(level)))
 
 	return Logger{
 		Logger:        zapr.NewLogger(logger).WithName(name),
 		logging:       logging,
+		levels:        newLevels(logging),
 		out:           writer,
 		sugaredLogger: logger.Sugar(),
 	}
@@ -54,12 +58,13 @@ func FileLogger(file, name string, level egv1a1.LogLevel) Logger {
 
 func DefaultLogger(out io.Writer, level egv1a1.LogLevel) Logger {
 	logging := egv1a1.DefaultEnvoyGatewayLogging()
-	logger := initZapLogger(out, logging, level)
+	logger := initZapLogger(out, atomicLevelAt(logging.DefaultEnvoyGatewayLoggingLevel(level)))
 
 	return Logger{
 		Logger:        zapr.NewLogger(logger),
 		out:           out,
 		logging:       logging,
+		levels:        newLevels(logging),
 		sugaredLogger: logger.Sugar(),
 	}
 }
@@ -70,12 +75,12 @@ func DefaultLogger(out io.Writer, level egv1a1.LogLevel) Logger {
 // contain only letters, digits, and hyphens (see the package documentation for
 // more information).
 func (l Logger) WithName(name string) Logger {
-	logLevel := l.logging.Level[egv1a1.EnvoyGatewayLogComponent(name)]
-	logger := initZapLogger(l.out, l.logging, logLevel)
+	logger := initZapLogger(l.out, l.levels.atomicLevel(egv1a1.EnvoyGatewayLogComponent(name)))
 
 	return Logger{
 		Logger:        zapr.NewLogger(logger).WithName(name),
 		logging:       l.logging,
+		levels:        l.levels,
 		out:           l.out,
 		sugaredLogger: logger.Sugar().Named(name),
 	}
@@ -110,9 +115,8 @@ func (l Logger) Sugar() *zap.SugaredLogger {
 	return l.sugaredLogger
 }
 
-func initZapLogger(w io.Writer, logging *egv1a1.EnvoyGatewayLogging, level egv1a1.LogLevel) *zap.Logger {
-	parseLevel, _ := zapcore.ParseLevel(string(logging.DefaultEnvoyGatewayLoggingLevel(level)))
-	core := zapcore.NewCore(zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()), zapcore.AddSync(w), zap.NewAtomicLevelAt(parseLevel))
+func initZapLogger(w io.Writer, level zap.AtomicLevel) *zap.Logger {
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()), zapcore.AddSync(w), level)
 
 	return zap.New(core, zap.AddCaller())
 }
@@ -0,0 +1,249 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package message
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wukongcloud/gateway/internal/admin"
+)
+
+const (
+	// RunnersHealthPath is the admin server path serving the health of the runners.
+	RunnersHealthPath = "/api/runners"
+
+	// RunnerStuckTimeout is the time after which a runner still processing an update
+	// is reported unhealthy. It isn't configurable: it's well above the time it takes to
+	// translate the largest configurations, so it only catches a runner which is stuck
+	// for good, and a lower value would restart Envoy Gateway while it's busy instead.
+	RunnerStuckTimeout = 5 * time.Minute
+)
+
+// SubscriptionHealth is the health of the subscription of a runner to a message.
+type SubscriptionHealth struct {
+	Message string `json:"message"`
+	Healthy bool   `json:"healthy"`
+	// LastSuccessTime is the time the last update was processed successfully.
+	LastSuccessTime *time.Time `json:"lastSuccessTime,omitempty"`
+	// LastError is the last error observed while processing the updates, including panics.
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+	// Panics is the number of updates whose processing panicked.
+	Panics int `json:"panics"`
+	// ProcessingSince is the time the processing of the current update started, if any.
+	ProcessingSince *time.Time `json:"processingSince,omitempty"`
+}
+
+// RunnerHealth is the health of a runner, aggregated from the health of its subscriptions.
+type RunnerHealth struct {
+	Runner          string               `json:"runner"`
+	Healthy         bool                 `json:"healthy"`
+	LastSuccessTime *time.Time           `json:"lastSuccessTime,omitempty"`
+	LastError       string               `json:"lastError,omitempty"`
+	LastErrorTime   *time.Time           `json:"lastErrorTime,omitempty"`
+	Subscriptions   []SubscriptionHealth `json:"subscriptions"`
+}
+
+// subscriptionHealth tracks the processing of the updates of a subscription.
+type subscriptionHealth struct {
+	meta            Metadata
+	lastSuccessTime time.Time
+	lastError       error
+	lastErrorTime   time.Time
+	// panicked is true if the processing of the last update panicked.
+	panicked        bool
+	panics          int
+	processingSince time.Time
+}
+
+var (
+	subscriptionsHealthMu sync.Mutex
+	subscriptionsHealth   = map[Metadata]*subscriptionHealth{}
+)
+
+// trackSubscription starts tracking the health of the subscription, until the
+// returned function is called.
+func trackSubscription(meta Metadata) (*subscriptionHealth, func()) {
+	subscriptionsHealthMu.Lock()
+	defer subscriptionsHealthMu.Unlock()
+
+	h := &subscriptionHealth{meta: meta}
+	subscriptionsHealth[meta] = h
+	return h, func() {
+		subscriptionsHealthMu.Lock()
+		defer subscriptionsHealthMu.Unlock()
+
+		// The runner may have subscribed again since.
+		if subscriptionsHealth[meta] == h {
+			delete(subscriptionsHealth, meta)
+		}
+	}
+}
+
+func (h *subscriptionHealth) processing() {
+	subscriptionsHealthMu.Lock()
+	defer subscriptionsHealthMu.Unlock()
+
+	h.processingSince = time.Now()
+}
+
+func (h *subscriptionHealth) succeeded() {
+	subscriptionsHealthMu.Lock()
+	defer subscriptionsHealthMu.Unlock()
+
+	h.processingSince = time.Time{}
+	h.lastSuccessTime = time.Now()
+	h.panicked = false
+}
+
+// failed records the error. A panic makes the subscription unhealthy until an update
+// is processed successfully.
+func (h *subscriptionHealth) failed(err error, panicked bool) {
+	subscriptionsHealthMu.Lock()
+	defer subscriptionsHealthMu.Unlock()
+
+	h.lastError = err
+	h.lastErrorTime = time.Now()
+	if panicked {
+		h.processingSince = time.Time{}
+		h.panicked = true
+		h.panics++
+	}
+}
+
+// healthy returns whether the subscription is healthy, i.e. neither the processing of its
+// last update panicked nor it is stuck.
+// The caller must hold the lock.
+func (h *subscriptionHealth) healthy(now time.Time, stuckTimeout time.Duration) error {
+	if h.panicked {
+		return fmt.Errorf("%s: processing the last update panicked: %w", h.meta.Message, h.lastError)
+	}
+	return h.stuck(now, stuckTimeout)
+}
+
+// stuck returns whether the subscription has been processing an update for longer
+// than the timeout.
+// The caller must hold the lock.
+func (h *subscriptionHealth) stuck(now time.Time, stuckTimeout time.Duration) error {
+	if !h.processingSince.IsZero() && now.Sub(h.processingSince) > stuckTimeout {
+		return fmt.Errorf("%s: processing an update for %s", h.meta.Message, now.Sub(h.processingSince).Round(time.Second))
+	}
+	return nil
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// RunnersHealth returns the health of the runners, sorted by name, reporting a runner
+// processing an update for longer than the stuck timeout as unhealthy.
+func RunnersHealth(stuckTimeout time.Duration) []RunnerHealth {
+	subscriptionsHealthMu.Lock()
+	defer subscriptionsHealthMu.Unlock()
+
+	now := time.Now()
+	runners := make(map[string]*RunnerHealth)
+	for _, h := range subscriptionsHealth {
+		runner, ok := runners[h.meta.Runner]
+		if !ok {
+			runner = &RunnerHealth{Runner: h.meta.Runner, Healthy: true}
+			runners[h.meta.Runner] = runner
+		}
+
+		subscription := SubscriptionHealth{
+			Message:         h.meta.Message,
+			Healthy:         h.healthy(now, stuckTimeout) == nil,
+			LastSuccessTime: timePtr(h.lastSuccessTime),
+			LastErrorTime:   timePtr(h.lastErrorTime),
+			Panics:          h.panics,
+			ProcessingSince: timePtr(h.processingSince),
+		}
+		if h.lastError != nil {
+			subscription.LastError = h.lastError.Error()
+		}
+		runner.Subscriptions = append(runner.Subscriptions, subscription)
+
+		runner.Healthy = runner.Healthy && subscription.Healthy
+		if subscription.LastSuccessTime != nil &&
+			(runner.LastSuccessTime == nil || subscription.LastSuccessTime.After(*runner.LastSuccessTime)) {
+			runner.LastSuccessTime = subscription.LastSuccessTime
+		}
+		if subscription.LastErrorTime != nil &&
+			(runner.LastErrorTime == nil || subscription.LastErrorTime.After(*runner.LastErrorTime)) {
+			runner.LastErrorTime = subscription.LastErrorTime
+			runner.LastError = subscription.LastError
+		}
+	}
+
+	health := make([]RunnerHealth, 0, len(runners))
+	for _, runner := range runners {
+		sort.Slice(runner.Subscriptions, func(i, j int) bool {
+			return runner.Subscriptions[i].Message < runner.Subscriptions[j].Message
+		})
+		health = append(health, *runner)
+	}
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].Runner < health[j].Runner
+	})
+	return health
+}
+
+// CheckRunners is a health probe checker failing if a runner has been processing an update
+// for longer than RunnerStuckTimeout. A panic doesn't fail it, since the runner keeps
+// processing the next updates, and it's only reported by the runners health endpoint:
+// failing the readiness probe until the same subscription processes another update, which
+// may never happen in a quiet cluster, would remove Envoy Gateway from the endpoints of
+// the xDS Service.
+func CheckRunners(_ *http.Request) error {
+	subscriptionsHealthMu.Lock()
+	defer subscriptionsHealthMu.Unlock()
+
+	now := time.Now()
+	var errs []string
+	for _, h := range subscriptionsHealth {
+		if err := h.stuck(now, RunnerStuckTimeout); err != nil {
+			errs = append(errs, h.meta.Runner+" runner unhealthy: "+err.Error())
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return errors.New(strings.Join(errs, "; "))
+}
+
+// RegisterRunnersHealth registers the endpoint serving the health of the runners
+// on the admin server.
+func RegisterRunnersHealth() {
+	admin.Handle(RunnersHealthPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		health := RunnersHealth(RunnerStuckTimeout)
+		w.Header().Set("Content-Type", "application/json")
+		for _, runner := range health {
+			if !runner.Healthy {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				break
+			}
+		}
+		if err := json.NewEncoder(w).Encode(health); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+}
@@ -0,0 +1,101 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package message_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/telepresenceio/watchable"
+
+	"github.com/wukongcloud/gateway/internal/message"
+)
+
+// runnerHealth returns the health of the runner, if tracked.
+func runnerHealth(runner string, stuckTimeout time.Duration) *message.RunnerHealth {
+	for _, h := range message.RunnersHealth(stuckTimeout) {
+		if h.Runner == runner {
+			return &h
+		}
+	}
+	return nil
+}
+
+func TestRunnersHealth(t *testing.T) {
+	var m watchable.Map[string, string]
+	updates := make(chan string)
+	done := make(chan struct{})
+	go func() {
+		message.HandleSubscription[string, string](
+			message.Metadata{Runner: "health", Message: "demo"},
+			m.Subscribe(context.Background()),
+			func(update message.Update[string, string], errChans chan error) {
+				switch update.Value {
+				case "panic":
+					panic("oops")
+				case "error":
+					errChans <- errors.New("failed to update status")
+				case "block":
+					<-updates
+				}
+				updates <- update.Value
+			},
+		)
+		close(done)
+	}()
+
+	// The runner is healthy once it processes an update successfully.
+	m.Store("foo", "ok")
+	require.Equal(t, "ok", <-updates)
+	require.Eventually(t, func() bool {
+		h := runnerHealth("health", time.Minute)
+		return h != nil && h.Healthy && h.LastSuccessTime != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, message.CheckRunners(nil))
+
+	// An error reported by the handler doesn't make the runner unhealthy.
+	m.Store("foo", "error")
+	require.Equal(t, "error", <-updates)
+	require.Eventually(t, func() bool {
+		return runnerHealth("health", time.Minute).LastError == "failed to update status"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.True(t, runnerHealth("health", time.Minute).Healthy)
+
+	// A panic makes the runner unhealthy until an update is processed successfully.
+	m.Store("foo", "panic")
+	require.Eventually(t, func() bool {
+		return !runnerHealth("health", time.Minute).Healthy
+	}, 5*time.Second, 10*time.Millisecond)
+	h := runnerHealth("health", time.Minute)
+	require.Contains(t, h.LastError, "panic: oops")
+	require.Equal(t, 1, h.Subscriptions[0].Panics)
+	// A panic doesn't fail the health probes.
+	require.NoError(t, message.CheckRunners(nil))
+
+	m.Store("foo", "ok")
+	require.Equal(t, "ok", <-updates)
+	require.Eventually(t, func() bool {
+		return runnerHealth("health", time.Minute).Healthy
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A runner processing an update for longer than the stuck timeout is unhealthy.
+	m.Store("foo", "block")
+	require.Eventually(t, func() bool {
+		h := runnerHealth("health", time.Millisecond)
+		return h.Subscriptions[0].ProcessingSince != nil && !h.Healthy
+	}, 5*time.Second, 10*time.Millisecond)
+	require.True(t, runnerHealth("health", time.Minute).Healthy)
+	updates <- "unblock"
+	require.Equal(t, "block", <-updates)
+
+	// The runner isn't tracked anymore once its subscription is closed.
+	m.Close()
+	<-done
+	require.Nil(t, runnerHealth("health", time.Minute))
+}
@@ -6,6 +6,9 @@
 package message
 
 import (
+	"sync"
+	"time"
+
 	"github.com/telepresenceio/watchable"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -13,6 +16,7 @@ import (
 	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/gatewayapi"
 	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
 	"github.com/wukongcloud/gateway/internal/ir"
 	xdstypes "github.com/wukongcloud/gateway/internal/xds/types"
@@ -33,6 +37,12 @@ type ProviderResources struct {
 
 	// ExtensionStatuses is a group of gw-api extension resource statuses map.
 	ExtensionStatuses
+
+	// InfraStatuses is a group of infrastructure statuses maps.
+	InfraStatuses
+
+	// XdsStatuses is a group of xds statuses maps.
+	XdsStatuses
 }
 
 func (p *ProviderResources) GetResources() []*resource.Resources {
@@ -71,6 +81,8 @@ func (p *ProviderResources) Close() {
 	p.GatewayAPIResources.Close()
 	p.GatewayAPIStatuses.Close()
 	p.PolicyStatuses.Close()
+	p.InfraStatuses.Close()
+	p.XdsStatuses.Close()
 }
 
 // GatewayAPIStatuses contains gateway API resources statuses
@@ -123,6 +135,130 @@ func (p *PolicyStatuses) Close() {
 	p.ExtensionPolicyStatuses.Close()
 }
 
+// InfraStatuses contains the statuses of the infrastructure managed by a custom infrastructure provider.
+type InfraStatuses struct {
+	// ProxyStatuses is a map from the name of the proxy infra to the status of its processes.
+	ProxyStatuses watchable.Map[string, *ProxyStatus]
+}
+
+func (s *InfraStatuses) Close() {
+	s.ProxyStatuses.Close()
+}
+
+// ProxyPhase is the phase of a proxy process.
+type ProxyPhase string
+
+const (
+	// ProxyPhaseRunning means the proxy process is running.
+	ProxyPhaseRunning ProxyPhase = "Running"
+	// ProxyPhaseRestarting means the proxy process exited, and is going to be restarted.
+	ProxyPhaseRestarting ProxyPhase = "Restarting"
+	// ProxyPhaseCrashLoopBackOff means the proxy process keeps exiting shortly after
+	// being restarted, and is going to be restarted with a long backoff.
+	ProxyPhaseCrashLoopBackOff ProxyPhase = "CrashLoopBackOff"
+)
+
+// ProxyStatus is the status of a proxy process managed by a custom infrastructure provider.
+type ProxyStatus struct {
+	// Labels are the labels of the proxy infra, which map the proxy back to its owning Gateway.
+	Labels map[string]string
+	// Phase is the current phase of the proxy process.
+	Phase ProxyPhase
+	// Restarts is the number of times the proxy process has been restarted.
+	Restarts int
+	// LastExitCode is the exit code of the last exit of the proxy process, it's -1 if
+	// the exit code is unknown, e.g. the process was killed by a signal.
+	LastExitCode int
+	// LastExitTime is the time of the last exit of the proxy process.
+	LastExitTime time.Time
+	// LastLogs are the last lines the proxy process logged before its last exit.
+	LastLogs string
+}
+
+// DeepCopy returns a deep copy of the ProxyStatus.
+func (s *ProxyStatus) DeepCopy() *ProxyStatus {
+	if s == nil {
+		return nil
+	}
+	out := *s
+	if s.Labels != nil {
+		out.Labels = make(map[string]string, len(s.Labels))
+		for k, v := range s.Labels {
+			out.Labels[k] = v
+		}
+	}
+	return &out
+}
+
+// XdsStatuses contains the statuses of the xds resources reported by the Envoy proxies.
+type XdsStatuses struct {
+	// XdsNACKStatuses is a map from the IR key to the xds updates rejected by its Envoy proxies.
+	XdsNACKStatuses watchable.Map[string, *XdsNACKStatus]
+}
+
+func (s *XdsStatuses) Close() {
+	s.XdsNACKStatuses.Close()
+}
+
+// XdsNACKStatus holds the xds updates rejected by the Envoy proxies of an IR key.
+type XdsNACKStatus struct {
+	NACKs []XdsNACK
+}
+
+// XdsNACK is an xds update rejected by an Envoy proxy.
+type XdsNACK struct {
+	// NodeID is the ID of the Envoy proxy which rejected the update.
+	NodeID string
+	// TypeURL is the type URL of the rejected xds resources.
+	TypeURL string
+	// Message is the error detail reported by the Envoy proxy.
+	Message string
+	// ResourceNames are the names of the rejected xds resources, when they are known.
+	ResourceNames []string
+	// Sources are the resources the rejected xds resources are translated from.
+	// It's empty when the rejected xds resources can't be mapped to their sources.
+	Sources []XdsResourceSource
+}
+
+// XdsResourceSource is a resource xds resources are translated from.
+type XdsResourceSource struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// DeepCopy returns a deep copy of the XdsNACKStatus.
+func (s *XdsNACKStatus) DeepCopy() *XdsNACKStatus {
+	if s == nil {
+		return nil
+	}
+	out := &XdsNACKStatus{}
+	if s.NACKs != nil {
+		out.NACKs = make([]XdsNACK, len(s.NACKs))
+		for i, nack := range s.NACKs {
+			out.NACKs[i] = nack
+			out.NACKs[i].ResourceNames = append([]string(nil), nack.ResourceNames...)
+			out.NACKs[i].Sources = append([]XdsResourceSource(nil), nack.Sources...)
+		}
+	}
+	return out
+}
+
+// NACKFor returns the first NACK caused by the given source, or nil if there is none.
+func (s *XdsNACKStatus) NACKFor(source XdsResourceSource) *XdsNACK {
+	if s == nil {
+		return nil
+	}
+	for i := range s.NACKs {
+		for _, src := range s.NACKs[i].Sources {
+			if src == source {
+				return &s.NACKs[i]
+			}
+		}
+	}
+	return nil
+}
+
 // XdsIR message
 type XdsIR struct {
 	watchable.Map[string, *ir.Xds]
@@ -137,3 +273,99 @@ type InfraIR struct {
 type Xds struct {
 	watchable.Map[string, *xdstypes.ResourceVersionTable]
 }
+
+// IRGenerations holds, for every IR key, the generation of the Gateway API
+// resources the IR was last translated from. The generation is incremented
+// on every update of the Gateway API resources.
+type IRGenerations struct {
+	mu          sync.RWMutex
+	generations map[string]int64
+	translated  chan struct{}
+	once        sync.Once
+}
+
+// Store sets the generation of the Gateway API resources the IR key was translated from.
+func (g *IRGenerations) Store(key string, generation int64) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.generations == nil {
+		g.generations = make(map[string]int64)
+	}
+	g.generations[key] = generation
+}
+
+// Load returns the generation of the Gateway API resources the IR key was last
+// translated from, or 0 if it is unknown.
+func (g *IRGenerations) Load(key string) int64 {
+	if g == nil {
+		return 0
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.generations[key]
+}
+
+// SetTranslated records that the Gateway API resources have been translated,
+// so the IR keys of the resources have been stored.
+func (g *IRGenerations) SetTranslated() {
+	if g == nil {
+		return
+	}
+	g.once.Do(func() {
+		close(g.translatedChan())
+	})
+}
+
+// Translated returns a channel which is closed once the Gateway API resources
+// have been translated for the first time.
+func (g *IRGenerations) Translated() <-chan struct{} {
+	if g == nil {
+		return nil
+	}
+	return g.translatedChan()
+}
+
+func (g *IRGenerations) translatedChan() chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.translated == nil {
+		g.translated = make(chan struct{})
+	}
+	return g.translated
+}
+
+// TranslationResults holds the latest result of the Gateway API translation of
+// every GatewayClass, for the config dump of the admin server.
+type TranslationResults struct {
+	mu      sync.RWMutex
+	results map[string]*gatewayapi.TranslateResult
+}
+
+// Store replaces the translation results, keyed by GatewayClass name.
+func (t *TranslationResults) Store(results map[string]*gatewayapi.TranslateResult) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.results = results
+}
+
+// LoadAll returns the translation results, keyed by GatewayClass name.
+// The results must not be modified.
+func (t *TranslationResults) LoadAll() map[string]*gatewayapi.TranslateResult {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.results
+}
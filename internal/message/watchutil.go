@@ -47,17 +47,22 @@ func handleWithCrashRecovery[K comparable, V any](
 	update Update[K, V],
 	meta Metadata,
 	errChans chan error,
+	health *subscriptionHealth,
 ) {
 	defer func() {
 		if r := recover(); r != nil {
-			logger.WithValues("runner", meta.Runner).Error(fmt.Errorf("%+v", r), "observed a panic",
+			err := fmt.Errorf("%+v", r)
+			logger.WithValues("runner", meta.Runner).Error(err, "observed a panic",
 				"stackTrace", string(debug.Stack()))
 			watchableSubscribeTotal.WithFailure(metrics.ReasonError, meta.LabelValues()...).Increment()
 			panicCounter.WithFailure(metrics.ReasonError, meta.LabelValues()...).Increment()
+			health.failed(fmt.Errorf("panic: %w", err), true)
 		}
 	}()
 	startHandleTime := time.Now()
+	health.processing()
 	handle(update, errChans)
+	health.succeeded()
 	watchableSubscribeTotal.WithSuccess(meta.LabelValues()...).Increment()
 	watchableSubscribeDurationSeconds.With(meta.LabelValues()...).Record(time.Since(startHandleTime).Seconds())
 }
@@ -75,12 +80,16 @@ func HandleSubscription[K comparable, V any](
 	subscription <-chan watchable.Snapshot[K, V],
 	handle func(updateFunc Update[K, V], errChans chan error),
 ) {
+	health, untrack := trackSubscription(meta)
+	defer untrack()
+
 	// TODO: find a suitable value
 	errChans := make(chan error, 10)
 	go func() {
 		for err := range errChans {
 			logger.WithValues("runner", meta.Runner).Error(err, "observed an error")
 			watchableSubscribeTotal.WithFailure(metrics.ReasonError, meta.LabelValues()...).Increment()
+			health.failed(err, false)
 		}
 	}()
 	defer close(errChans)
@@ -90,13 +99,13 @@ func HandleSubscription[K comparable, V any](
 			handleWithCrashRecovery(handle, Update[K, V]{
 				Key:   k,
 				Value: v,
-			}, meta, errChans)
+			}, meta, errChans, health)
 		}
 	}
 	for snapshot := range subscription {
 		watchableDepth.With(meta.LabelValues()...).Record(float64(len(subscription)))
 		for _, update := range snapshot.Updates {
-			handleWithCrashRecovery(handle, Update[K, V](update), meta, errChans)
+			handleWithCrashRecovery(handle, Update[K, V](update), meta, errChans, health)
 		}
 	}
 }
@@ -125,6 +125,11 @@ func metricFunc() {
 	currentIRsNum.With(NewLabel("ir-type").Value("xds")).Record(1)
 	currentIRsNum.With(NewLabel("ir-type").Value("xds")).Record(3)
 	currentIRsNum.With(NewLabel("ir-type").Value("xds")).Record(2)
+
+	// the deleted series isn't exported
+	infraIRsNum := currentIRsNum.With(NewLabel("ir-type").Value("infra"))
+	infraIRsNum.Record(1)
+	infraIRsNum.Delete()
 }
 
 func TestHistogram(t *testing.T) {
@@ -19,6 +19,7 @@ type Gauge struct {
 	g       api.Float64ObservableGauge
 	mutex   *sync.RWMutex
 	stores  map[attribute.Set]*GaugeValues
+	set     attribute.Set
 	current *GaugeValues
 }
 
@@ -33,9 +34,20 @@ func (f *Gauge) Record(value float64) {
 
 	if f.current == nil {
 		f.current = &GaugeValues{}
-		f.stores[attribute.NewSet()] = f.current
+		f.set = attribute.NewSet()
 	}
 	f.current.val = value
+	f.stores[f.set] = f.current
+}
+
+// Delete removes the series of the gauge, it isn't exported anymore until a value is recorded again.
+func (f *Gauge) Delete() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.current != nil {
+		delete(f.stores, f.set)
+	}
 }
 
 func (f *Gauge) With(labelValues ...LabelValue) *Gauge {
@@ -49,6 +61,7 @@ func (f *Gauge) With(labelValues ...LabelValue) *Gauge {
 		stores: f.stores,
 		name:   f.name,
 		attrs:  attrs,
+		set:    set,
 	}
 	if _, f := m.stores[set]; !f {
 		m.stores[set] = &GaugeValues{
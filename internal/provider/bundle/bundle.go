@@ -0,0 +1,215 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package bundle
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/admin"
+	"github.com/wukongcloud/gateway/internal/envoygateway/config"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/message"
+	"github.com/wukongcloud/gateway/internal/provider/file"
+)
+
+const (
+	// StatusPath is the path of the admin server endpoint that serves the sync status of the http provider.
+	StatusPath = "/api/bundle"
+
+	defaultPollInterval = 30 * time.Second
+	defaultTimeout      = 10 * time.Second
+
+	// maxBundleSize is the maximum size of a bundle, a larger bundle is rejected.
+	maxBundleSize = 64 << 20
+)
+
+// SyncStatus is the sync status of the http provider.
+type SyncStatus struct {
+	URL string `json:"url"`
+	// Digest is the SHA256 digest of the bundle currently applied.
+	Digest string `json:"digest,omitempty"`
+	// ETag and LastModified are the validators of the bundle currently applied.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+
+	file.SyncState
+}
+
+// validators are the response headers used to make conditional requests for the bundle.
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+// Provider applies the bundle served by an HTTP server, the polling and the sync status are
+// handled by the file.Poller.
+type Provider struct {
+	*file.Poller[SyncStatus]
+
+	url    string
+	client *http.Client
+	// fetched is the last fetched bundle, it's fetched with conditional requests and returned
+	// again while it's not modified.
+	fetched *bundleRevision
+}
+
+func New(ctx context.Context, svr *config.Server, resources *message.ProviderResources) (*Provider, error) {
+	httpProvider := svr.EnvoyGateway.Provider.Custom.Resource.HTTP
+	if httpProvider == nil {
+		return nil, fmt.Errorf("http resource provider is not configured")
+	}
+
+	interval, err := parseDuration(httpProvider.PollInterval, defaultPollInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid poll interval: %w", err)
+	}
+	timeout, err := parseDuration(httpProvider.Timeout, defaultTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+	client, err := newHTTPClient(httpProvider.CACertificatePath, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := file.NewOfflineStore(ctx, svr, resources, httpProvider.StatusPath)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Provider{
+		url:    httpProvider.URL,
+		client: client,
+	}
+	p.Poller = file.NewPoller(store, file.PollerConfig[SyncStatus]{
+		Name:                    "http",
+		Source:                  p,
+		Interval:                interval,
+		Logger:                  svr.Logger.Logger.WithValues("url", httpProvider.URL),
+		Status:                  SyncStatus{URL: httpProvider.URL},
+		State:                   func(status *SyncStatus) *file.SyncState { return &status.SyncState },
+		SyncTotal:               bundleFetchTotal.With(urlLabel.Value(httpProvider.URL)),
+		AppliedTimestampSeconds: bundleAppliedTimestampSeconds.With(urlLabel.Value(httpProvider.URL)),
+	})
+	admin.Handle(StatusPath, p)
+
+	return p, nil
+}
+
+func parseDuration(d *gwapiv1.Duration, defaultDuration time.Duration) (time.Duration, error) {
+	if d == nil {
+		return defaultDuration, nil
+	}
+	return time.ParseDuration(string(*d))
+}
+
+func newHTTPClient(caCertificatePath string, timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if caCertificatePath != "" {
+		caCert, err := os.ReadFile(caCertificatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid ca certificate found in %s", caCertificatePath)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+func (p *Provider) Type() egv1a1.ProviderType {
+	return egv1a1.ProviderTypeCustom
+}
+
+// Fetch fetches the bundle with the validators of the last fetched bundle, which is returned
+// again if the bundle has not been modified.
+func (p *Provider) Fetch(ctx context.Context) (file.Revision[SyncStatus], error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.fetched != nil {
+		if p.fetched.etag != "" {
+			req.Header.Set("If-None-Match", p.fetched.etag)
+		}
+		if p.fetched.lastModified != "" {
+			req.Header.Set("If-Modified-Since", p.fetched.lastModified)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotModified && p.fetched != nil:
+		return p.fetched, nil
+	default:
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	content, err := readAllLimited(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	// A bundle fetched again without any change, e.g. the server doesn't support conditional
+	// requests, has the same digest and isn't loaded again.
+	sum := sha256.Sum256(content)
+	p.fetched = &bundleRevision{
+		content: content,
+		digest:  hex.EncodeToString(sum[:]),
+		validators: validators{
+			etag:         resp.Header.Get("ETag"),
+			lastModified: resp.Header.Get("Last-Modified"),
+		},
+	}
+	return p.fetched, nil
+}
+
+// bundleRevision is a fetched bundle, identified by its digest.
+type bundleRevision struct {
+	validators
+
+	content []byte
+	digest  string
+}
+
+func (r *bundleRevision) ID() string {
+	return r.digest
+}
+
+func (r *bundleRevision) Load(_ context.Context) ([]*resource.Resources, error) {
+	resources, err := loadBundle(r.content)
+	if err != nil {
+		// Loading the same bundle again is going to fail the same way.
+		return nil, &file.InvalidResourcesError{Err: err}
+	}
+	return resources, nil
+}
+
+func (r *bundleRevision) Applied(status *SyncStatus) {
+	status.Digest = r.digest
+	status.ETag = r.etag
+	status.LastModified = r.lastModified
+}
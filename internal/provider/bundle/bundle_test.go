@@ -0,0 +1,133 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package bundle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/envoygateway/config"
+	"github.com/wukongcloud/gateway/internal/message"
+)
+
+const (
+	resourcesUpdateTimeout = 1 * time.Minute
+	resourcesUpdateTick    = 100 * time.Millisecond
+)
+
+// bundleServer serves a bundle, and supports conditional requests with ETag.
+type bundleServer struct {
+	mu          sync.Mutex
+	content     []byte
+	notModified atomic.Int32
+}
+
+func (s *bundleServer) set(content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.content = content
+}
+
+func (s *bundleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sum := sha256.Sum256(s.content)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		s.notModified.Add(1)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	_, _ = w.Write(s.content)
+}
+
+func TestHTTPProvider(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bs := &bundleServer{content: makeTar(t, map[string]string{"eg-1.yaml": gatewayClass("eg-1")}, true)}
+	srv := httptest.NewServer(bs)
+	defer srv.Close()
+
+	cfg, err := config.New(os.Stdout)
+	require.NoError(t, err)
+	cfg.EnvoyGateway.Provider = &egv1a1.EnvoyGatewayProvider{
+		Type: egv1a1.ProviderTypeCustom,
+		Custom: &egv1a1.EnvoyGatewayCustomProvider{
+			Resource: egv1a1.EnvoyGatewayResourceProvider{
+				Type: egv1a1.ResourceProviderTypeHTTP,
+				HTTP: &egv1a1.EnvoyGatewayHTTPResourceProvider{
+					URL:          srv.URL + "/bundle.tar.gz",
+					PollInterval: ptr.To(gwapiv1.Duration("100ms")),
+				},
+			},
+		},
+	}
+
+	pResources := new(message.ProviderResources)
+	hp, err := New(ctx, cfg, pResources)
+	require.NoError(t, err)
+	go func() {
+		if err := hp.Start(ctx); err != nil {
+			t.Errorf("failed to start http provider: %v", err)
+		}
+	}()
+
+	t.Run("initial bundle", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			return hp.Status().ETag != "" && pResources.GetResourcesByGatewayClass("eg-1") != nil
+		}, resourcesUpdateTimeout, resourcesUpdateTick)
+		require.True(t, hp.Ready())
+	})
+
+	t.Run("unchanged bundle", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			return bs.notModified.Load() > 1
+		}, resourcesUpdateTimeout, resourcesUpdateTick)
+	})
+
+	t.Run("new bundle", func(t *testing.T) {
+		applied := hp.Status().Digest
+		bs.set([]byte(gatewayClass("eg-2")))
+		require.Eventually(t, func() bool {
+			return hp.Status().Digest != applied &&
+				pResources.GetResourcesByGatewayClass("eg-2") != nil &&
+				pResources.GetResourcesByGatewayClass("eg-1") == nil
+		}, resourcesUpdateTimeout, resourcesUpdateTick)
+	})
+
+	t.Run("invalid bundle keeps the last good bundle", func(t *testing.T) {
+		applied := hp.Status()
+		bs.set([]byte("kind: [invalid"))
+		require.Eventually(t, func() bool {
+			return hp.Status().LastError != ""
+		}, resourcesUpdateTimeout, resourcesUpdateTick)
+		require.Equal(t, applied.Digest, hp.Status().Digest)
+		require.Equal(t, applied.ETag, hp.Status().ETag)
+		require.NotNil(t, pResources.GetResourcesByGatewayClass("eg-2"))
+
+		// The invalid bundle isn't loaded again until it changes.
+		notModified := bs.notModified.Load()
+		require.Eventually(t, func() bool {
+			return bs.notModified.Load() > notModified
+		}, resourcesUpdateTimeout, resourcesUpdateTick)
+	})
+}
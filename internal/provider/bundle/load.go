@@ -0,0 +1,89 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/utils/path"
+)
+
+// tarMagicOffset is the offset of the "ustar" magic in the header of a tar archive.
+const tarMagicOffset = 257
+
+// loadBundle loads and validates the resources of a bundle, which is either a tar archive,
+// optionally gzip compressed, or a single YAML file. All the files of the bundle must be
+// loaded successfully, so that a broken bundle is never partially applied.
+func loadBundle(content []byte) ([]*resource.Resources, error) {
+	if bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress bundle: %w", err)
+		}
+		defer zr.Close()
+		if content, err = readAllLimited(zr); err != nil {
+			return nil, fmt.Errorf("failed to decompress bundle: %w", err)
+		}
+	}
+
+	if !isTar(content) {
+		r, err := resource.LoadResourcesFromYAMLBytes(content, false)
+		if err != nil {
+			return nil, err
+		}
+		return []*resource.Resources{r}, nil
+	}
+
+	var rs []*resource.Resources
+	tr := tar.NewReader(bytes.NewReader(content))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || path.IsHidden(hdr.Name) {
+			continue
+		}
+
+		data, err := readAllLimited(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s of bundle: %w", hdr.Name, err)
+		}
+		r, err := resource.LoadResourcesFromYAMLBytes(data, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load resources from file %s of bundle: %w", hdr.Name, err)
+		}
+		rs = append(rs, r)
+	}
+
+	return rs, nil
+}
+
+// readAllLimited reads all the content of r, which must not be larger than maxBundleSize,
+// so that a small compressed bundle can't exhaust the memory.
+func readAllLimited(r io.Reader) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxBundleSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxBundleSize {
+		return nil, fmt.Errorf("bundle is larger than %d bytes", maxBundleSize)
+	}
+	return content, nil
+}
+
+func isTar(content []byte) bool {
+	return len(content) > tarMagicOffset+5 && string(content[tarMagicOffset:tarMagicOffset+5]) == "ustar"
+}
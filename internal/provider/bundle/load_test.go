@@ -0,0 +1,131 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const gatewayClassTemplate = `apiVersion: gateway.networking.k8s.io/v1
+kind: GatewayClass
+metadata:
+  name: %s
+spec:
+  controllerName: gateway.envoyproxy.io/gatewayclass-controller
+`
+
+func gatewayClass(name string) string {
+	return fmt.Sprintf(gatewayClassTemplate, name)
+}
+
+func makeTar(t *testing.T, files map[string]string, compress bool) []byte {
+	buf := new(bytes.Buffer)
+	var zw *gzip.Writer
+	tw := tar.NewWriter(buf)
+	if compress {
+		zw = gzip.NewWriter(buf)
+		tw = tar.NewWriter(zw)
+	}
+
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0o600,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	if zw != nil {
+		require.NoError(t, zw.Close())
+	}
+	return buf.Bytes()
+}
+
+// gzipZeros returns size zero bytes gzip compressed, which compresses to a small fraction of size.
+func gzipZeros(t *testing.T, size int) []byte {
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+	_, err := zw.Write(make([]byte, size))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.Less(t, buf.Len(), maxBundleSize)
+	return buf.Bytes()
+}
+
+func TestLoadBundle(t *testing.T) {
+	files := map[string]string{
+		"gateway-class.yaml":          gatewayClass("eg-1"),
+		"tenants/a/gateway-class.yml": gatewayClass("eg-2"),
+		".metadata/info.yaml":         "not: resources",
+	}
+
+	testCases := []struct {
+		name       string
+		content    []byte
+		expectGCNs []string
+		expectErr  bool
+	}{
+		{
+			name:       "yaml",
+			content:    []byte(gatewayClass("eg-1")),
+			expectGCNs: []string{"eg-1"},
+		},
+		{
+			name:       "tar",
+			content:    makeTar(t, files, false),
+			expectGCNs: []string{"eg-1", "eg-2"},
+		},
+		{
+			name:       "tar.gz",
+			content:    makeTar(t, files, true),
+			expectGCNs: []string{"eg-1", "eg-2"},
+		},
+		{
+			name:      "invalid yaml",
+			content:   []byte("kind: [invalid"),
+			expectErr: true,
+		},
+		{
+			name: "tar with an invalid file",
+			content: makeTar(t, map[string]string{
+				"gateway-class.yaml": gatewayClass("eg-1"),
+				"invalid.yaml":       "kind: [invalid",
+			}, true),
+			expectErr: true,
+		},
+		{
+			name:      "decompressed bundle too large",
+			content:   gzipZeros(t, maxBundleSize+1),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs, err := loadBundle(tc.content)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var names []string
+			for _, r := range rs {
+				names = append(names, r.GatewayClass.Name)
+			}
+			require.ElementsMatch(t, tc.expectGCNs, names)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package bundle
+
+import "github.com/wukongcloud/gateway/internal/metrics"
+
+var (
+	bundleFetchTotal = metrics.NewCounter(
+		"http_provider_fetch_total",
+		"Total number of fetches of the bundle by the http resource provider.",
+	)
+
+	bundleAppliedTimestampSeconds = metrics.NewGauge(
+		"http_provider_applied_timestamp_seconds",
+		"The unix timestamp of the last time a bundle was applied by the http resource provider.",
+		metrics.WithUnit(metrics.Seconds),
+	)
+
+	urlLabel = metrics.NewLabel("url")
+)
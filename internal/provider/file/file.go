@@ -7,15 +7,12 @@ package file
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
 	"sync/atomic"
-	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/go-logr/logr"
@@ -26,45 +23,44 @@ import (
 	"github.com/wukongcloud/gateway/internal/envoygateway/config"
 	"github.com/wukongcloud/gateway/internal/filewatcher"
 	"github.com/wukongcloud/gateway/internal/message"
-	"github.com/wukongcloud/gateway/internal/provider/kubernetes"
 	"github.com/wukongcloud/gateway/internal/utils/path"
 )
 
 type Provider struct {
-	paths      []string
-	logger     logr.Logger
-	watcher    filewatcher.FileWatcher
-	resources  *message.ProviderResources
-	reconciler *kubernetes.OfflineGatewayAPIReconciler
-	store      *resourcesStore
-	status     *StatusHandler
+	*OfflineStore
+
+	paths     []string
+	opts      *loadOptions
+	logger    logr.Logger
+	watcher   filewatcher.FileWatcher
+	resources *message.ProviderResources
 
 	// ready indicates whether the provider can start watching filesystem events.
 	ready atomic.Bool
 }
 
 func New(ctx context.Context, svr *config.Server, resources *message.ProviderResources) (*Provider, error) {
-	logger := svr.Logger.Logger
 	paths := sets.New[string]()
-	if svr.EnvoyGateway.Provider.Custom.Resource.File != nil {
-		paths.Insert(svr.EnvoyGateway.Provider.Custom.Resource.File.Paths...)
+	fileProvider := svr.EnvoyGateway.Provider.Custom.Resource.File
+	var statusDir string
+	if fileProvider != nil {
+		paths.Insert(fileProvider.Paths...)
+		statusDir = fileProvider.StatusPath
 	}
+	opts := newLoadOptions(fileProvider)
 
-	// Create gateway-api offline reconciler.
-	statusHandler := NewStatusHandler(logger)
-	reconciler, err := kubernetes.NewOfflineGatewayAPIController(ctx, svr, statusHandler.Writer(), resources)
+	store, err := newOfflineStore(ctx, svr, resources, opts, statusDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create offline gateway-api controller")
+		return nil, err
 	}
 
 	return &Provider{
-		paths:      paths.UnsortedList(),
-		logger:     logger,
-		watcher:    filewatcher.NewWatcher(),
-		resources:  resources,
-		reconciler: reconciler,
-		store:      newResourcesStore(svr.EnvoyGateway.Gateway.ControllerName, reconciler.Client, resources, logger),
-		status:     statusHandler,
+		OfflineStore: store,
+		paths:        paths.UnsortedList(),
+		opts:         opts,
+		logger:       svr.Logger.Logger,
+		watcher:      filewatcher.NewWatcher(),
+		resources:    resources,
 	}, nil
 }
 
@@ -84,15 +80,11 @@ func (p *Provider) Start(ctx context.Context) error {
 		}
 		return nil
 	}
-	go p.startHealthProbeServer(ctx, readyzChecker)
+	go StartHealthProbeServer(ctx, p.logger, readyzChecker)
 
 	// Offline controller should be started before initial resources load.
 	// Nor we may lose some messages from controller.
-	wg := new(sync.WaitGroup)
-	wg.Add(2)
-	go p.startReconciling(ctx, wg)
-	go p.status.Start(ctx, wg)
-	wg.Wait()
+	p.StartReconciling(ctx)
 
 	initDirs, initFiles := path.ListDirsAndFiles(p.paths)
 	// Initially load resources.
@@ -103,7 +95,11 @@ func (p *Provider) Start(ctx context.Context) error {
 	// Add paths to the watcher, and aggregate all path channels into one.
 	aggCh := make(chan fsnotify.Event)
 	for _, path := range p.paths {
-		if err := p.watcher.Add(path); err != nil {
+		add := p.watcher.Add
+		if p.opts.isRecursive() && initDirs.Has(path) {
+			add = p.watcher.AddRecursive
+		}
+		if err := add(path); err != nil {
 			p.logger.Error(err, "failed to add watch", "path", path)
 		} else {
 			p.logger.Info("Watching path added", "path", path)
@@ -166,6 +162,11 @@ func (p *Provider) Start(ctx context.Context) error {
 			if _, name := filepath.Split(event.Name); strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
 				continue
 			}
+
+			// Ignore the change event of a file that is filtered out by the include and exclude patterns.
+			if p.isFilteredFile(curDirs, event.Name) {
+				continue
+			}
 			p.logger.Info("file changed", "op", event.Op, "name", event.Name, "dir", filepath.Dir(event.Name))
 
 		handle:
@@ -176,69 +177,21 @@ func (p *Provider) Start(ctx context.Context) error {
 	}
 }
 
-// startReconciling starts reconcile on offline controller when receiving signal from resources store.
-func (p *Provider) startReconciling(ctx context.Context, ready *sync.WaitGroup) {
-	p.logger.Info("start reconciling")
-	defer p.logger.Info("stop reconciling")
-	ready.Done()
-
-	for {
-		select {
-		case rid := <-p.store.reconcile:
-			p.logger.Info("start reconcile", "id", rid, "time", time.Now())
-			if err := p.reconciler.Reconcile(ctx); err != nil {
-				p.logger.Error(err, "failed to reconcile", "id", rid)
-			}
-			p.logger.Info("reconcile finished", "id", rid, "time", time.Now())
-
-		case <-ctx.Done():
-			return
-		}
-	}
-}
-
-func (p *Provider) startHealthProbeServer(ctx context.Context, readyzChecker healthz.Checker) {
-	const (
-		readyzEndpoint  = "/readyz"
-		healthzEndpoint = "/healthz"
-	)
-
-	mux := http.NewServeMux()
-	srv := &http.Server{
-		Addr:              ":8081",
-		Handler:           mux,
-		MaxHeaderBytes:    1 << 20,
-		IdleTimeout:       90 * time.Second, // matches http.DefaultTransport keep-alive timeout
-		ReadHeaderTimeout: 32 * time.Second,
+// isFilteredFile returns true if the given name is an existing file under the watched directories,
+// and it is not going to be loaded.
+func (p *Provider) isFilteredFile(dirs sets.Set[string], name string) bool {
+	if f, err := os.Lstat(name); err != nil || f.IsDir() {
+		return false
 	}
 
-	readyzHandler := &healthz.Handler{
-		Checks: map[string]healthz.Checker{
-			readyzEndpoint: readyzChecker,
-		},
-	}
-	mux.Handle(readyzEndpoint, http.StripPrefix(readyzEndpoint, readyzHandler))
-	// Append '/' suffix to handle subpaths.
-	mux.Handle(readyzEndpoint+"/", http.StripPrefix(readyzEndpoint, readyzHandler))
-
-	healthzHandler := &healthz.Handler{
-		Checks: map[string]healthz.Checker{
-			healthzEndpoint: healthz.Ping,
-		},
-	}
-	mux.Handle(healthzEndpoint, http.StripPrefix(healthzEndpoint, healthzHandler))
-	// Append '/' suffix to handle subpaths.
-	mux.Handle(healthzEndpoint+"/", http.StripPrefix(healthzEndpoint, readyzHandler))
-
-	go func() {
-		<-ctx.Done()
-		if err := srv.Close(); err != nil {
-			p.logger.Error(err, "failed to close health probe server")
+	for dir := range dirs {
+		rel, err := filepath.Rel(dir, name)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if p.opts.matches(rel) {
+			return false
 		}
-	}()
-
-	p.logger.Info("starting health probe server", "address", srv.Addr)
-	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		p.logger.Error(err, "failed to start health probe server")
 	}
+	return true
 }
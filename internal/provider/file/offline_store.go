@@ -0,0 +1,165 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/wukongcloud/gateway/internal/admin"
+	"github.com/wukongcloud/gateway/internal/envoygateway/config"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/message"
+	"github.com/wukongcloud/gateway/internal/provider/kubernetes"
+)
+
+// OfflineStore stores the resources loaded by a custom resource provider via the
+// gateway-api offline controller, reconciles them and keeps their statuses. The
+// Programmed condition of the Gateways also reflects the proxy statuses reported by
+// a custom infrastructure provider, e.g. an Envoy process stuck in a crash loop.
+// It can be used by custom resource providers that load resources from somewhere
+// other than the local filesystem.
+type OfflineStore struct {
+	logger     logr.Logger
+	reconciler *kubernetes.OfflineGatewayAPIReconciler
+	store      *resourcesStore
+	status     *StatusHandler
+	statuses   *statusStore
+}
+
+// NewOfflineStore returns an OfflineStore for the given server. If statusDir is set, the
+// statuses of the resources are also written into the directory.
+func NewOfflineStore(ctx context.Context, svr *config.Server, resources *message.ProviderResources,
+	statusDir string,
+) (*OfflineStore, error) {
+	return newOfflineStore(ctx, svr, resources, nil, statusDir)
+}
+
+func newOfflineStore(ctx context.Context, svr *config.Server, resources *message.ProviderResources,
+	opts *loadOptions, statusDir string,
+) (*OfflineStore, error) {
+	logger := svr.Logger.Logger
+
+	// Keep the statuses of resources, and serve them over the admin server.
+	statuses := newStatusStore(statusDir)
+	admin.Handle(StatusPath, statuses)
+
+	// Create gateway-api offline reconciler.
+	statusHandler := NewStatusHandler(logger, statuses)
+	reconciler, err := kubernetes.NewOfflineGatewayAPIController(ctx, svr, statusHandler.Writer(), resources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create offline gateway-api controller")
+	}
+	statusHandler.client = reconciler.Client
+
+	return &OfflineStore{
+		logger:     logger,
+		reconciler: reconciler,
+		store:      newResourcesStore(svr.EnvoyGateway.Gateway.ControllerName, reconciler.Client, resources, opts, statuses, logger),
+		status:     statusHandler,
+		statuses:   statuses,
+	}, nil
+}
+
+// StartReconciling starts the offline controller and the status handler, it returns once
+// both of them are ready. The offline controller should be started before storing any
+// resources, otherwise some messages from the controller may be lost.
+func (s *OfflineStore) StartReconciling(ctx context.Context) {
+	wg := new(sync.WaitGroup)
+	wg.Add(2)
+	go s.startReconciling(ctx, wg)
+	go s.status.Start(ctx, wg)
+	wg.Wait()
+}
+
+// StoreAll stores all the given resources, and removes the previously stored
+// resources that no longer exist.
+func (s *OfflineStore) StoreAll(ctx context.Context, resources []*resource.Resources) error {
+	return s.store.StoreAll(ctx, resources)
+}
+
+// SetRevision sets the revision of the stored resources, e.g. the commit they are loaded from,
+// which is reported along with the statuses of the resources.
+func (s *OfflineStore) SetRevision(revision string) error {
+	return s.statuses.SetRevision(revision)
+}
+
+// startReconciling starts reconcile on offline controller when receiving signal from resources store.
+func (s *OfflineStore) startReconciling(ctx context.Context, ready *sync.WaitGroup) {
+	s.logger.Info("start reconciling")
+	defer s.logger.Info("stop reconciling")
+	ready.Done()
+
+	for {
+		select {
+		case rid := <-s.store.reconcile:
+			s.logger.Info("start reconcile", "id", rid, "time", time.Now())
+			if err := s.reconciler.Reconcile(ctx); err != nil {
+				s.logger.Error(err, "failed to reconcile", "id", rid)
+			}
+			s.logger.Info("reconcile finished", "id", rid, "time", time.Now())
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// StartHealthProbeServer serves the readyz and healthz endpoints of a custom resource provider.
+func StartHealthProbeServer(ctx context.Context, logger logr.Logger, readyzChecker healthz.Checker) {
+	const (
+		readyzEndpoint  = "/readyz"
+		healthzEndpoint = "/healthz"
+	)
+
+	mux := http.NewServeMux()
+	srv := &http.Server{
+		Addr:              ":8081",
+		Handler:           mux,
+		MaxHeaderBytes:    1 << 20,
+		IdleTimeout:       90 * time.Second, // matches http.DefaultTransport keep-alive timeout
+		ReadHeaderTimeout: 32 * time.Second,
+	}
+
+	readyzHandler := &healthz.Handler{
+		Checks: map[string]healthz.Checker{
+			readyzEndpoint: readyzChecker,
+			"runners":      message.CheckRunners,
+		},
+	}
+	mux.Handle(readyzEndpoint, http.StripPrefix(readyzEndpoint, readyzHandler))
+	// Append '/' suffix to handle subpaths.
+	mux.Handle(readyzEndpoint+"/", http.StripPrefix(readyzEndpoint, readyzHandler))
+
+	healthzHandler := &healthz.Handler{
+		Checks: map[string]healthz.Checker{
+			healthzEndpoint: healthz.Ping,
+			"runners":       message.CheckRunners,
+		},
+	}
+	mux.Handle(healthzEndpoint, http.StripPrefix(healthzEndpoint, healthzHandler))
+	// Append '/' suffix to handle subpaths.
+	mux.Handle(healthzEndpoint+"/", http.StripPrefix(healthzEndpoint, healthzHandler))
+
+	go func() {
+		<-ctx.Done()
+		if err := srv.Close(); err != nil {
+			logger.Error(err, "failed to close health probe server")
+		}
+	}()
+
+	logger.Info("starting health probe server", "address", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error(err, "failed to start health probe server")
+	}
+}
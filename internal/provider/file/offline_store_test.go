@@ -0,0 +1,83 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package file
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/wukongcloud/gateway/internal/gatewayapi"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/message"
+)
+
+func TestOfflineStoreProxyStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg, err := newFileProviderConfig(nil)
+	require.NoError(t, err)
+	pResources := new(message.ProviderResources)
+	store, err := NewOfflineStore(ctx, cfg, pResources, "")
+	require.NoError(t, err)
+	store.StartReconciling(ctx)
+
+	gtw := &gwapiv1.Gateway{
+		TypeMeta:   metav1.TypeMeta{Kind: resource.KindGateway, APIVersion: gwapiv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "eg", Generation: 1},
+		Spec: gwapiv1.GatewaySpec{
+			GatewayClassName: "eg",
+			Listeners:        []gwapiv1.Listener{{Name: "http", Protocol: gwapiv1.HTTPProtocolType, Port: 80}},
+		},
+	}
+	resources := resource.NewResources()
+	resources.GatewayClass = &gwapiv1.GatewayClass{
+		TypeMeta:   metav1.TypeMeta{Kind: resource.KindGatewayClass, APIVersion: gwapiv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "eg"},
+		Spec:       gwapiv1.GatewayClassSpec{ControllerName: gwapiv1.GatewayController(cfg.EnvoyGateway.Gateway.ControllerName)},
+	}
+	resources.Gateways = []*gwapiv1.Gateway{gtw}
+	require.NoError(t, store.StoreAll(ctx, []*resource.Resources{resources}))
+
+	// The Gateway is accepted by the translator, and its Envoy process keeps crashing.
+	pResources.GatewayStatuses.Store(types.NamespacedName{Namespace: "default", Name: "eg"}, &gwapiv1.GatewayStatus{
+		Conditions: []metav1.Condition{{
+			Type:               string(gwapiv1.GatewayConditionAccepted),
+			Status:             metav1.ConditionTrue,
+			Reason:             string(gwapiv1.GatewayReasonAccepted),
+			ObservedGeneration: 1,
+		}},
+	})
+	pResources.ProxyStatuses.Store("default/eg", &message.ProxyStatus{
+		Labels:       gatewayapi.OwnerLabels(gtw, false),
+		Phase:        message.ProxyPhaseCrashLoopBackOff,
+		Restarts:     5,
+		LastExitCode: 1,
+		LastLogs:     "error initializing configuration",
+	})
+
+	require.Eventually(t, func() bool {
+		items := store.statuses.List(resource.KindGateway, "default", "eg")
+		if len(items) != 1 {
+			return false
+		}
+		got := &gwapiv1.Gateway{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(items[0].Object, got); err != nil {
+			return false
+		}
+		programmed := meta.FindStatusCondition(got.Status.Conditions, string(gwapiv1.GatewayConditionProgrammed))
+		return programmed != nil && programmed.Status == metav1.ConditionFalse &&
+			strings.Contains(programmed.Message, "Envoy process crashloopbackoff, restarted 5 times, last exit code 1")
+	}, resourcesUpdateTimeout, resourcesUpdateTick)
+}
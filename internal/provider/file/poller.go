@@ -0,0 +1,217 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/metrics"
+)
+
+// syncNotModified is the status of the syncs finding the revision already applied, or
+// failing to load, since the previous sync.
+const syncNotModified = "not_modified"
+
+// SyncState is the state of the syncs of a Poller, it's embedded in the sync status of the
+// provider along with the applied revision.
+type SyncState struct {
+	// AppliedAt is the time the current revision was applied.
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	// LastError is the error of the last sync, it's empty if the last sync succeeded.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Source is a remote source of resources polled by a Poller, S is the sync status of the provider.
+type Source[S any] interface {
+	// Fetch returns the current revision of the source.
+	Fetch(ctx context.Context) (Revision[S], error)
+}
+
+// Revision is a revision of the resources of a Source.
+type Revision[S any] interface {
+	// ID identifies the content of the revision, e.g. a commit SHA. A revision isn't loaded
+	// again once it has been applied or has failed to load with an InvalidResourcesError.
+	ID() string
+	// Load loads the resources of the revision.
+	Load(ctx context.Context) ([]*resource.Resources, error)
+	// Applied records the revision in the sync status once its resources are stored.
+	Applied(status *S)
+}
+
+// InvalidResourcesError is returned by Revision.Load when the content of the revision can't
+// be loaded as resources, which is not going to change by trying again.
+type InvalidResourcesError struct {
+	Err error
+}
+
+func (e *InvalidResourcesError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InvalidResourcesError) Unwrap() error {
+	return e.Err
+}
+
+// PollerConfig is the configuration of a Poller.
+type PollerConfig[S any] struct {
+	// Name is the name of the provider, e.g. "git".
+	Name     string
+	Source   Source[S]
+	Interval time.Duration
+	Logger   logr.Logger
+	// Status is the initial sync status, and State returns the SyncState embedded in it.
+	Status S
+	State  func(status *S) *SyncState
+	// SyncTotal counts the syncs by status, and AppliedTimestampSeconds records the time
+	// a revision was last applied.
+	SyncTotal               *metrics.Counter
+	AppliedTimestampSeconds *metrics.Gauge
+}
+
+// Poller periodically fetches the revision of a remote source of resources, and stores its
+// resources in an OfflineStore when it changes. The resources of the last applied revision
+// are kept if a revision can't be fetched or loaded. The sync status is served as JSON.
+type Poller[S any] struct {
+	*OfflineStore
+
+	cfg PollerConfig[S]
+
+	mu     sync.RWMutex
+	status S
+	// applied is the ID of the applied revision, and invalid of the last revision which
+	// failed to load. The revisions failing to be stored are tried again.
+	applied string
+	invalid string
+
+	// ready indicates whether the poller has tried to apply the initial revision.
+	ready atomic.Bool
+}
+
+func NewPoller[S any](store *OfflineStore, cfg PollerConfig[S]) *Poller[S] {
+	return &Poller[S]{
+		OfflineStore: store,
+		cfg:          cfg,
+		status:       cfg.Status,
+	}
+}
+
+func (p *Poller[S]) Start(ctx context.Context) error {
+	// Start runnable servers.
+	var readyzChecker healthz.Checker = func(req *http.Request) error {
+		if !p.Ready() {
+			return fmt.Errorf("%s provider not ready yet", p.cfg.Name)
+		}
+		return nil
+	}
+	go StartHealthProbeServer(ctx, p.cfg.Logger, readyzChecker)
+
+	// Offline controller should be started before initial resources load.
+	p.StartReconciling(ctx)
+
+	// Initially load resources.
+	p.sync(ctx)
+	p.ready.Store(true)
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			p.sync(ctx)
+		}
+	}
+}
+
+// Ready reports whether the poller has tried to apply the initial revision.
+func (p *Poller[S]) Ready() bool {
+	return p.ready.Load()
+}
+
+// Status returns the current sync status of the provider.
+func (p *Poller[S]) Status() S {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.status
+}
+
+// ServeHTTP serves the sync status as JSON.
+func (p *Poller[S]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(p.Status()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// sync applies the current revision of the source, if it hasn't been applied yet and it
+// didn't fail to load.
+func (p *Poller[S]) sync(ctx context.Context) {
+	rev, err := p.cfg.Source.Fetch(ctx)
+	if err != nil {
+		p.syncFailed(err, "fetch")
+		return
+	}
+	id := rev.ID()
+	if id == p.applied || id == p.invalid {
+		p.cfg.SyncTotal.WithStatus(syncNotModified).Increment()
+		return
+	}
+
+	p.cfg.Logger.Info("applying revision", "revision", id)
+	resources, err := rev.Load(ctx)
+	if err != nil {
+		var invalid *InvalidResourcesError
+		if errors.As(err, &invalid) {
+			p.invalid = id
+		}
+		p.syncFailed(err, "load")
+		return
+	}
+	if err := p.StoreAll(ctx, resources); err != nil {
+		p.syncFailed(err, "store")
+		return
+	}
+	previous := p.applied
+	p.applied = id
+
+	p.mu.Lock()
+	now := time.Now()
+	rev.Applied(&p.status)
+	state := p.cfg.State(&p.status)
+	state.AppliedAt = &now
+	state.LastError = ""
+	p.mu.Unlock()
+
+	p.cfg.AppliedTimestampSeconds.Record(float64(now.Unix()))
+	p.cfg.SyncTotal.WithSuccess().Increment()
+	p.cfg.Logger.Info("revision applied", "revision", id, "previous", previous)
+}
+
+func (p *Poller[S]) syncFailed(err error, reason string) {
+	p.mu.Lock()
+	p.cfg.State(&p.status).LastError = err.Error()
+	p.mu.Unlock()
+
+	p.cfg.SyncTotal.WithFailure(reason).Increment()
+	p.cfg.Logger.Error(err, "failed to sync, keep serving the last applied revision", "reason", reason)
+}
@@ -11,11 +11,65 @@ import (
 	"path/filepath"
 	"strings"
 
+	"k8s.io/utils/ptr"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
 	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/utils/path"
 )
 
+// loadOptions controls which files under a directory are loaded.
+// A nil loadOptions loads all the files directly under a directory.
+type loadOptions struct {
+	// recursive enables loading files from subdirectories.
+	recursive bool
+	// include and exclude are glob patterns matched against the file path
+	// relative to the directory.
+	include []string
+	exclude []string
+}
+
+func newLoadOptions(file *egv1a1.EnvoyGatewayFileResourceProvider) *loadOptions {
+	if file == nil {
+		return nil
+	}
+
+	return &loadOptions{
+		recursive: ptr.Deref(file.Recursive, false),
+		include:   file.Include,
+		exclude:   file.Exclude,
+	}
+}
+
+func (o *loadOptions) isRecursive() bool {
+	return o != nil && o.recursive
+}
+
+// matches reports whether the file with the given path relative to a directory should be loaded.
+func (o *loadOptions) matches(rel string) bool {
+	if o == nil {
+		return true
+	}
+
+	for _, pattern := range o.exclude {
+		if path.MatchGlob(pattern, rel) {
+			return false
+		}
+	}
+
+	if len(o.include) == 0 {
+		return true
+	}
+	for _, pattern := range o.include {
+		if path.MatchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
 // loadFromFilesAndDirs loads resources from specific files and directories.
-func loadFromFilesAndDirs(files, dirs []string) ([]*resource.Resources, error) {
+func loadFromFilesAndDirs(files, dirs []string, opts *loadOptions) ([]*resource.Resources, error) {
 	var rs []*resource.Resources
 
 	for _, file := range files {
@@ -27,7 +81,7 @@ func loadFromFilesAndDirs(files, dirs []string) ([]*resource.Resources, error) {
 	}
 
 	for _, dir := range dirs {
-		r, err := loadFromDir(dir)
+		r, err := loadFromDir(dir, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -54,20 +108,43 @@ func loadFromFile(path string) (*resource.Resources, error) {
 	return resource.LoadResourcesFromYAMLBytes(bytes, false)
 }
 
-// loadFromDir loads resources from all the files under a specific directory excluding subdirectories.
-func loadFromDir(path string) ([]*resource.Resources, error) {
-	entries, err := os.ReadDir(path)
+// loadFromDir loads resources from all the files under a specific directory,
+// subdirectories are only loaded if the recursive option is enabled.
+func loadFromDir(path string, opts *loadOptions) ([]*resource.Resources, error) {
+	return loadFromSubDir(path, "", opts)
+}
+
+// loadFromSubDir loads resources from the subdirectory rel of the root directory.
+func loadFromSubDir(root, rel string, opts *loadOptions) ([]*resource.Resources, error) {
+	entries, err := os.ReadDir(filepath.Join(root, rel))
 	if err != nil {
 		return nil, err
 	}
 
 	var rs []*resource.Resources
 	for _, entry := range entries {
-		// Ignoring subdirectories and all hidden files and directories.
-		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+		// Ignoring all hidden files and directories.
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		entryRel := filepath.Join(rel, entry.Name())
+		if entry.IsDir() {
+			if !opts.isRecursive() {
+				continue
+			}
+			r, err := loadFromSubDir(root, entryRel, opts)
+			if err != nil {
+				return nil, err
+			}
+			rs = append(rs, r...)
+			continue
+		}
+
+		if !opts.matches(entryRel) {
 			continue
 		}
-		full := filepath.Join(path, entry.Name())
+		full := filepath.Join(root, entryRel)
 		r, err := loadFromFile(full)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load resources from file %s: %w", full, err)
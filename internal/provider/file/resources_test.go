@@ -16,26 +16,26 @@ import (
 
 func Test_loadFromFilesAndDirs(t *testing.T) {
 	t.Run("non-existent file", func(t *testing.T) {
-		_, err := loadFromFilesAndDirs([]string{"non-existent-file"}, nil)
+		_, err := loadFromFilesAndDirs([]string{"non-existent-file"}, nil, nil)
 		require.ErrorContains(t, err, "file non-existent-file is not exist")
 	})
 	t.Run("invalid content in a file", func(t *testing.T) {
 		tmpfile := t.TempDir() + "/invalid.yaml"
 		err := os.WriteFile(tmpfile, []byte("invalid"), 0o600)
 		require.NoError(t, err)
-		_, err = loadFromFilesAndDirs([]string{tmpfile}, nil)
+		_, err = loadFromFilesAndDirs([]string{tmpfile}, nil, nil)
 		require.ErrorContains(t, err,
 			fmt.Sprintf("failed to load resources from file %s", tmpfile))
 	})
 	t.Run("non-existent directory", func(t *testing.T) {
-		_, err := loadFromFilesAndDirs(nil, []string{"non-existent-directory"})
+		_, err := loadFromFilesAndDirs(nil, []string{"non-existent-directory"}, nil)
 		require.ErrorContains(t, err, "no such file or directory")
 	})
 	t.Run("invalid content in a file in a directory", func(t *testing.T) {
 		tmpdir := t.TempDir()
 		err := os.WriteFile(filepath.Join(tmpdir, "invalid.yaml"), []byte("invalid"), 0o600)
 		require.NoError(t, err)
-		_, err = loadFromFilesAndDirs(nil, []string{tmpdir})
+		_, err = loadFromFilesAndDirs(nil, []string{tmpdir}, nil)
 		require.ErrorContains(t, err,
 			fmt.Sprintf("failed to load resources from file %s", filepath.Join(tmpdir, "invalid.yaml")))
 	})
@@ -50,7 +50,7 @@ spec:
   controllerName: gateway.envoyproxy.io/gatewayclass-controller
 `), 0o600)
 		require.NoError(t, err)
-		rs, err := loadFromFilesAndDirs([]string{tmpfile}, nil)
+		rs, err := loadFromFilesAndDirs([]string{tmpfile}, nil, nil)
 		require.NoError(t, err)
 		require.Len(t, rs, 1)
 	})
@@ -58,14 +58,14 @@ spec:
 
 func Test_loadFromDir(t *testing.T) {
 	t.Run("non-existent directory", func(t *testing.T) {
-		_, err := loadFromDir("non-existent-directory")
+		_, err := loadFromDir("non-existent-directory", nil)
 		require.ErrorContains(t, err, "no such file or directory")
 	})
 	t.Run("invalid content in a file", func(t *testing.T) {
 		tmpdir := t.TempDir()
 		err := os.WriteFile(filepath.Join(tmpdir, "invalid.yaml"), []byte("invalid"), 0o600)
 		require.NoError(t, err)
-		_, err = loadFromDir(tmpdir)
+		_, err = loadFromDir(tmpdir, nil)
 		require.ErrorContains(t, err,
 			fmt.Sprintf("failed to load resources from file %s", filepath.Join(tmpdir, "invalid.yaml")))
 	})
@@ -80,8 +80,71 @@ spec:
   controllerName: gateway.envoyproxy.io/gatewayclass-controller
 `), 0o600)
 		require.NoError(t, err)
-		rs, err := loadFromDir(tmpdir)
+		rs, err := loadFromDir(tmpdir, nil)
 		require.NoError(t, err)
 		require.Len(t, rs, 1)
 	})
 }
+
+func Test_loadFromDirWithOptions(t *testing.T) {
+	gatewayClass := []byte(`
+apiVersion: gateway.networking.k8s.io/v1
+kind: GatewayClass
+metadata:
+  name: aigw-run
+spec:
+  controllerName: gateway.envoyproxy.io/gatewayclass-controller
+`)
+	tmpdir := t.TempDir()
+	for _, f := range []string{
+		"root.yaml",
+		"tenants/team-a/gw-1/gateway.yaml",
+		"tenants/team-a/gw-1/_draft.yaml",
+		"tenants/team-b/gw-2/gateway.yaml",
+		"tenants/team-b/README.md",
+		".git/config.yaml",
+	} {
+		full := filepath.Join(tmpdir, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o750))
+		require.NoError(t, os.WriteFile(full, gatewayClass, 0o600))
+	}
+
+	testCases := []struct {
+		name   string
+		opts   *loadOptions
+		expect int
+	}{
+		{
+			name:   "non-recursive",
+			opts:   &loadOptions{},
+			expect: 1,
+		},
+		{
+			name:   "recursive",
+			opts:   &loadOptions{recursive: true, include: []string{"**/*.yaml"}},
+			expect: 4,
+		},
+		{
+			name:   "recursive with include",
+			opts:   &loadOptions{recursive: true, include: []string{"tenants/**/*.yaml"}},
+			expect: 3,
+		},
+		{
+			name: "recursive with include and exclude",
+			opts: &loadOptions{
+				recursive: true,
+				include:   []string{"tenants/**/*.yaml"},
+				exclude:   []string{"**/_*", "tenants/team-b/**"},
+			},
+			expect: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs, err := loadFromDir(tmpdir, tc.opts)
+			require.NoError(t, err)
+			require.Len(t, rs, tc.expect)
+		})
+	}
+}
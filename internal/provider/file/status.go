@@ -12,6 +12,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
 	"github.com/wukongcloud/gateway/internal/provider/kubernetes"
@@ -21,13 +22,17 @@ type StatusHandler struct {
 	logger        logr.Logger
 	updateChannel chan kubernetes.Update
 	wg            *sync.WaitGroup
+	statuses      *statusStore
+	// client is used to get the object the status update is for, if set.
+	client client.Client
 }
 
-func NewStatusHandler(log logr.Logger) *StatusHandler {
+func NewStatusHandler(log logr.Logger, statuses *statusStore) *StatusHandler {
 	u := &StatusHandler{
 		logger:        log,
 		updateChannel: make(chan kubernetes.Update, 1000),
 		wg:            new(sync.WaitGroup),
+		statuses:      statuses,
 	}
 
 	u.wg.Add(1)
@@ -52,16 +57,32 @@ func (u *StatusHandler) Start(ctx context.Context, ready *sync.WaitGroup) {
 			u.logger.Info("received a status update", "namespace", update.NamespacedName.Namespace,
 				"name", update.NamespacedName.Name)
 
-			u.logStatus(update)
+			u.handleStatus(update)
 		}
 	}
 }
 
-func (u *StatusHandler) logStatus(update kubernetes.Update) {
+// handleStatus logs the resource status and persists it into the status store.
+func (u *StatusHandler) handleStatus(update kubernetes.Update) {
 	obj := update.Resource
-	newObj := update.Mutator.Mutate(obj)
 	log := u.logger.WithValues("key", update.NamespacedName.String())
 
+	// The mutators only update the status, so the object is got first to keep its metadata.
+	if u.client != nil {
+		if err := u.client.Get(context.Background(), update.NamespacedName, obj); err != nil {
+			log.Error(err, "failed to get object")
+		}
+	}
+	obj.SetNamespace(update.NamespacedName.Namespace)
+	obj.SetName(update.NamespacedName.Name)
+	newObj := update.Mutator.Mutate(obj)
+
+	if u.statuses != nil {
+		if err := u.statuses.Store(newObj); err != nil {
+			log.Error(err, "failed to store status")
+		}
+	}
+
 	// Log the resource status.
 	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(newObj)
 	if err != nil {
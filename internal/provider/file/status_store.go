@@ -0,0 +1,208 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/wukongcloud/gateway/internal/envoygateway"
+)
+
+const (
+	// StatusPath is the path of the admin server endpoint that serves the resource statuses.
+	StatusPath = "/api/status"
+
+	// revisionFilename is the name of the file the revision of the statuses is written to.
+	revisionFilename = "revision"
+)
+
+type statusKey struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// statusStore keeps the latest status of every resource, in the same shape as
+// the Kubernetes resource with its `.status` field. If dir is set, the statuses
+// are also written into the directory as `<kind>/[<namespace>/]<name>.yaml` files.
+type statusStore struct {
+	mu       sync.RWMutex
+	dir      string
+	statuses map[statusKey]*unstructured.Unstructured
+	// revision identifies the version of the resources the statuses are computed for, e.g. a commit.
+	revision string
+}
+
+func newStatusStore(dir string) *statusStore {
+	return &statusStore{
+		dir:      dir,
+		statuses: make(map[statusKey]*unstructured.Unstructured),
+	}
+}
+
+// Store stores the status of the given object.
+func (s *statusStore) Store(obj client.Object) error {
+	gvks, _, err := envoygateway.GetScheme().ObjectKinds(obj)
+	if err != nil {
+		return err
+	}
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	rawStatus, ok := raw["status"]
+	if !ok {
+		return fmt.Errorf("no status field")
+	}
+
+	u := &unstructured.Unstructured{Object: map[string]any{"status": rawStatus}}
+	u.SetGroupVersionKind(gvks[0])
+	u.SetNamespace(obj.GetNamespace())
+	u.SetName(obj.GetName())
+	u.SetGeneration(obj.GetGeneration())
+
+	key := statusKey{Kind: u.GetKind(), Namespace: u.GetNamespace(), Name: u.GetName()}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[key] = u
+
+	if s.dir == "" {
+		return nil
+	}
+	out, err := yaml.Marshal(u.Object)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.filePath(key), out)
+}
+
+// SetRevision sets the revision of the resources the statuses are computed for. If dir is set,
+// it's also written into the `revision` file of the directory.
+func (s *statusStore) SetRevision(revision string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revision = revision
+
+	if s.dir == "" {
+		return nil
+	}
+	return writeFileAtomic(filepath.Join(s.dir, revisionFilename), []byte(revision+"\n"))
+}
+
+// Revision returns the revision of the resources the statuses are computed for, if any.
+func (s *statusStore) Revision() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.revision
+}
+
+// Delete removes the status of the given object.
+func (s *statusStore) Delete(kind string, nn types.NamespacedName) error {
+	key := statusKey{Kind: kind, Namespace: nn.Namespace, Name: nn.Name}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.statuses, key)
+
+	if s.dir == "" {
+		return nil
+	}
+	if err := os.Remove(s.filePath(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List returns the statuses matching the given kind, namespace and name, the empty ones match all.
+func (s *statusStore) List(kind, namespace, name string) []*unstructured.Unstructured {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []*unstructured.Unstructured
+	for key, u := range s.statuses {
+		if (kind != "" && key.Kind != kind) ||
+			(namespace != "" && key.Namespace != namespace) ||
+			(name != "" && key.Name != name) {
+			continue
+		}
+		out = append(out, u)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].GetKind() != out[j].GetKind() {
+			return out[i].GetKind() < out[j].GetKind()
+		}
+		if out[i].GetNamespace() != out[j].GetNamespace() {
+			return out[i].GetNamespace() < out[j].GetNamespace()
+		}
+		return out[i].GetName() < out[j].GetName()
+	})
+	return out
+}
+
+// ServeHTTP serves the statuses as a JSON list, which can be filtered
+// by the `kind`, `namespace` and `name` query parameters, along with their revision if any.
+func (s *statusStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	items := s.List(q.Get("kind"), q.Get("namespace"), q.Get("name"))
+	objs := make([]map[string]any, 0, len(items))
+	for _, u := range items {
+		objs = append(objs, u.Object)
+	}
+
+	body := map[string]any{"items": objs}
+	if revision := s.Revision(); revision != "" {
+		body["revision"] = revision
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *statusStore) filePath(key statusKey) string {
+	return filepath.Join(s.dir, key.Kind, key.Namespace, key.Name+".yaml")
+}
+
+// writeFileAtomic writes the file in an atomic way, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
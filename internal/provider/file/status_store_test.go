@@ -0,0 +1,150 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/yaml"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/envoygateway"
+	"github.com/wukongcloud/gateway/internal/logging"
+	"github.com/wukongcloud/gateway/internal/provider/kubernetes"
+)
+
+func TestStatusStore(t *testing.T) {
+	dir := t.TempDir()
+	s := newStatusStore(dir)
+
+	gc := &gwapiv1.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "eg", Generation: 2},
+		Status: gwapiv1.GatewayClassStatus{
+			Conditions: []metav1.Condition{{
+				Type:   string(gwapiv1.GatewayClassConditionStatusAccepted),
+				Status: metav1.ConditionTrue,
+				Reason: string(gwapiv1.GatewayClassReasonAccepted),
+			}},
+		},
+	}
+	route := &gwapiv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backend"},
+		Status: gwapiv1.HTTPRouteStatus{
+			RouteStatus: gwapiv1.RouteStatus{
+				Parents: []gwapiv1.RouteParentStatus{{
+					ParentRef:      gwapiv1.ParentReference{Name: "eg"},
+					ControllerName: "gateway.envoyproxy.io/gatewayclass-controller",
+				}},
+			},
+		},
+	}
+	require.NoError(t, s.Store(gc))
+	require.NoError(t, s.Store(route))
+
+	t.Run("list", func(t *testing.T) {
+		require.Len(t, s.List("", "", ""), 2)
+		items := s.List("HTTPRoute", "default", "")
+		require.Len(t, items, 1)
+		require.Equal(t, "gateway.networking.k8s.io/v1", items[0].GetAPIVersion())
+		require.Equal(t, "backend", items[0].GetName())
+	})
+
+	t.Run("status files", func(t *testing.T) {
+		content, err := os.ReadFile(filepath.Join(dir, "GatewayClass", "eg.yaml"))
+		require.NoError(t, err)
+		got := &gwapiv1.GatewayClass{}
+		require.NoError(t, yaml.Unmarshal(content, got))
+		require.Equal(t, "eg", got.Name)
+		require.Equal(t, int64(2), got.Generation)
+		require.Equal(t, gc.Status, got.Status)
+
+		require.FileExists(t, filepath.Join(dir, "HTTPRoute", "default", "backend.yaml"))
+	})
+
+	t.Run("serve http", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, StatusPath+"?kind=GatewayClass", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		got := struct {
+			Items []gwapiv1.GatewayClass `json:"items"`
+		}{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Len(t, got.Items, 1)
+		require.Equal(t, gc.Status, got.Items[0].Status)
+	})
+
+	t.Run("revision", func(t *testing.T) {
+		require.NoError(t, s.SetRevision("0123abcd"))
+		content, err := os.ReadFile(filepath.Join(dir, "revision"))
+		require.NoError(t, err)
+		require.Equal(t, "0123abcd\n", string(content))
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, StatusPath, nil))
+		got := struct {
+			Revision string `json:"revision"`
+		}{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Equal(t, "0123abcd", got.Revision)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, s.Delete("HTTPRoute", types.NamespacedName{Namespace: "default", Name: "backend"}))
+		require.Empty(t, s.List("HTTPRoute", "", ""))
+		require.NoFileExists(t, filepath.Join(dir, "HTTPRoute", "default", "backend.yaml"))
+		// Deleting a status that doesn't exist is a no-op.
+		require.NoError(t, s.Delete("HTTPRoute", types.NamespacedName{Namespace: "default", Name: "backend"}))
+	})
+}
+
+func TestStatusHandlerStore(t *testing.T) {
+	gtw := &gwapiv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "eg", Generation: 3},
+	}
+	statuses := newStatusStore("")
+	handler := NewStatusHandler(logging.DefaultLogger(os.Stdout, egv1a1.LogLevelInfo).Logger, statuses)
+	handler.client = fakeclient.NewClientBuilder().
+		WithScheme(envoygateway.GetScheme()).
+		WithObjects(gtw).
+		Build()
+
+	conditions := []metav1.Condition{{
+		Type:   string(gwapiv1.GatewayConditionProgrammed),
+		Status: metav1.ConditionTrue,
+		Reason: string(gwapiv1.GatewayReasonProgrammed),
+	}}
+	// Like the Kubernetes status updates, the mutator only updates the status of the object.
+	handler.handleStatus(kubernetes.Update{
+		NamespacedName: types.NamespacedName{Namespace: "default", Name: "eg"},
+		Resource:       new(gwapiv1.Gateway),
+		Mutator: kubernetes.MutatorFunc(func(obj client.Object) client.Object {
+			g, ok := obj.(*gwapiv1.Gateway)
+			if !ok {
+				panic(fmt.Sprintf("unsupported object type %T", obj))
+			}
+			gCopy := g.DeepCopy()
+			gCopy.Status.Conditions = conditions
+			return gCopy
+		}),
+	})
+
+	items := statuses.List("Gateway", "default", "eg")
+	require.Len(t, items, 1)
+	require.Equal(t, int64(3), items[0].GetGeneration())
+}
@@ -38,6 +38,8 @@ type resourcesStore struct {
 	client    client.Client
 	resources *message.ProviderResources
 	reconcile chan int64
+	opts      *loadOptions
+	statuses  *statusStore
 
 	logger logr.Logger
 }
@@ -56,13 +58,17 @@ func (s storeKey) String() string {
 		s.GroupVersionKind.String(), s.NamespacedName.String(), s.deletionOrder)
 }
 
-func newResourcesStore(name string, client client.Client, resources *message.ProviderResources, logger logr.Logger) *resourcesStore {
+func newResourcesStore(name string, client client.Client, resources *message.ProviderResources,
+	opts *loadOptions, statuses *statusStore, logger logr.Logger,
+) *resourcesStore {
 	return &resourcesStore{
 		name:      name,
 		keys:      sets.New[storeKey](),
 		client:    client,
 		resources: resources,
 		reconcile: make(chan int64),
+		opts:      opts,
+		statuses:  statuses,
 		logger:    logger,
 	}
 }
@@ -77,11 +83,16 @@ func newStoreKey(obj client.Object) storeKey {
 // ReloadAll loads and stores all resources from all given files and directories.
 func (r *resourcesStore) ReloadAll(ctx context.Context, files, dirs []string) error {
 	// TODO(sh2): add arbitrary number of resources support for load function.
-	resources, err := loadFromFilesAndDirs(files, dirs)
+	resources, err := loadFromFilesAndDirs(files, dirs, r.opts)
 	if err != nil {
 		return err
 	}
 
+	return r.StoreAll(ctx, resources)
+}
+
+// StoreAll stores all the given resources, and removes the previously stored resources that no longer exist.
+func (r *resourcesStore) StoreAll(ctx context.Context, resources []*resource.Resources) error {
 	var errList error
 	currentKeys := sets.New[storeKey]()
 	for _, res := range resources {
@@ -106,7 +117,15 @@ func (r *resourcesStore) ReloadAll(ctx context.Context, files, dirs []string) er
 			errList = errors.Join(errList, err)
 			// Insert back if the object is not be removed.
 			currentKeys.Insert(k)
-		} else if k.deletionOrder <= GatewayDeletionOrder {
+			continue
+		}
+
+		if r.statuses != nil {
+			if err := r.statuses.Delete(k.Kind, k.NamespacedName); err != nil {
+				r.logger.Error(err, "failed to delete status", "key", k.String())
+			}
+		}
+		if k.deletionOrder <= GatewayDeletionOrder {
 			// Reconcile once if gateway got deleted, this may be able to
 			// remove the finalizer on gatewayclass.
 			r.reconcile <- generateReconcileID()
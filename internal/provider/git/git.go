@@ -0,0 +1,137 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/admin"
+	"github.com/wukongcloud/gateway/internal/envoygateway/config"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/message"
+	"github.com/wukongcloud/gateway/internal/metrics"
+	"github.com/wukongcloud/gateway/internal/provider/file"
+)
+
+const (
+	// StatusPath is the path of the admin server endpoint that serves the sync status of the git provider.
+	StatusPath = "/api/git"
+
+	defaultPollInterval = 30 * time.Second
+)
+
+// SyncStatus is the sync status of the git provider.
+type SyncStatus struct {
+	Repository string `json:"repository"`
+	Ref        string `json:"ref,omitempty"`
+	// Commit is the SHA of the commit currently applied.
+	Commit string `json:"commit,omitempty"`
+
+	file.SyncState
+}
+
+// Provider applies the commit a branch or tag of a Git repository points to, the polling
+// and the sync status are handled by the file.Poller.
+type Provider struct {
+	*file.Poller[SyncStatus]
+
+	repo   *repository
+	ref    string
+	paths  []string
+	logger logr.Logger
+}
+
+func New(ctx context.Context, svr *config.Server, resources *message.ProviderResources) (*Provider, error) {
+	gitProvider := svr.EnvoyGateway.Provider.Custom.Resource.Git
+	if gitProvider == nil {
+		return nil, fmt.Errorf("git resource provider is not configured")
+	}
+
+	interval := defaultPollInterval
+	if gitProvider.PollInterval != nil {
+		d, err := time.ParseDuration(string(*gitProvider.PollInterval))
+		if err != nil {
+			return nil, fmt.Errorf("invalid poll interval: %w", err)
+		}
+		interval = d
+	}
+
+	store, err := file.NewOfflineStore(ctx, svr, resources, gitProvider.StatusPath)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Provider{
+		repo:   newRepository(gitProvider.Repository),
+		ref:    gitProvider.Ref,
+		paths:  gitProvider.Paths,
+		logger: svr.Logger.Logger.WithValues("repository", gitProvider.Repository, "ref", gitProvider.Ref),
+	}
+	labels := []metrics.LabelValue{repositoryLabel.Value(gitProvider.Repository), refLabel.Value(gitProvider.Ref)}
+	p.Poller = file.NewPoller(store, file.PollerConfig[SyncStatus]{
+		Name:     "git",
+		Source:   p,
+		Interval: interval,
+		Logger:   p.logger,
+		Status: SyncStatus{
+			Repository: gitProvider.Repository,
+			Ref:        gitProvider.Ref,
+		},
+		State:                   func(status *SyncStatus) *file.SyncState { return &status.SyncState },
+		SyncTotal:               gitSyncTotal.With(labels...),
+		AppliedTimestampSeconds: gitAppliedTimestampSeconds.With(labels...),
+	})
+	admin.Handle(StatusPath, p)
+
+	return p, nil
+}
+
+func (p *Provider) Type() egv1a1.ProviderType {
+	return egv1a1.ProviderTypeCustom
+}
+
+// Fetch returns the commit the ref points to.
+func (p *Provider) Fetch(ctx context.Context) (file.Revision[SyncStatus], error) {
+	commit, err := p.repo.resolve(ctx, p.ref)
+	if err != nil {
+		return nil, err
+	}
+	return &commitRevision{p: p, commit: commit}, nil
+}
+
+// commitRevision is the revision of the resources at a commit of the repository.
+type commitRevision struct {
+	p      *Provider
+	commit string
+}
+
+func (r *commitRevision) ID() string {
+	return r.commit
+}
+
+func (r *commitRevision) Load(ctx context.Context) ([]*resource.Resources, error) {
+	return r.p.repo.loadResources(ctx, r.commit, r.p.paths)
+}
+
+// Applied records the commit in the sync status, the applied commit metric and the revision
+// of the statuses.
+func (r *commitRevision) Applied(status *SyncStatus) {
+	repository, ref := repositoryLabel.Value(status.Repository), refLabel.Value(status.Ref)
+	if status.Commit != "" {
+		gitAppliedCommitInfo.With(repository, ref, commitLabel.Value(status.Commit)).Delete()
+	}
+	gitAppliedCommitInfo.With(repository, ref, commitLabel.Value(r.commit)).Record(1)
+	status.Commit = r.commit
+
+	if err := r.p.SetRevision(r.commit); err != nil {
+		r.p.logger.Error(err, "failed to write the revision of the statuses", "commit", r.commit)
+	}
+}
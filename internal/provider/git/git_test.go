@@ -0,0 +1,253 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/envoygateway/config"
+	"github.com/wukongcloud/gateway/internal/message"
+	"github.com/wukongcloud/gateway/internal/provider/file"
+)
+
+const (
+	resourcesUpdateTimeout = 1 * time.Minute
+	resourcesUpdateTick    = 100 * time.Millisecond
+
+	gatewayClassTemplate = `apiVersion: gateway.networking.k8s.io/v1
+kind: GatewayClass
+metadata:
+  name: %s
+spec:
+  controllerName: gateway.envoyproxy.io/gatewayclass-controller
+`
+)
+
+// testRepository is a working tree whose commits are pushed to a bare repository.
+type testRepository struct {
+	t    *testing.T
+	work string
+	bare string
+}
+
+func newTestRepository(t *testing.T) *testRepository {
+	if _, err := exec.LookPath(gitBinary); err != nil {
+		t.Skip("git binary not found")
+	}
+
+	r := &testRepository{t: t, work: t.TempDir(), bare: t.TempDir()}
+	r.git(r.bare, "init", "--bare", "--initial-branch=main")
+	r.git(r.work, "init", "--initial-branch=main")
+	r.git(r.work, "remote", "add", "origin", r.bare)
+	return r
+}
+
+func (r *testRepository) git(dir string, args ...string) string {
+	cmd := exec.Command(gitBinary, append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	out, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes the files into the working tree, removes the files with empty content,
+// and pushes the commit to the bare repository. It returns the SHA of the commit.
+func (r *testRepository) commit(files map[string]string) string {
+	for name, content := range files {
+		path := filepath.Join(r.work, name)
+		if content == "" {
+			require.NoError(r.t, os.Remove(path))
+			continue
+		}
+		require.NoError(r.t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(r.t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	r.git(r.work, "add", "-A")
+	r.git(r.work, "commit", "-m", "update")
+	r.git(r.work, "push", "origin", "HEAD:main")
+	return r.git(r.work, "rev-parse", "HEAD")
+}
+
+func gatewayClass(name string) string {
+	return fmt.Sprintf(gatewayClassTemplate, name)
+}
+
+func TestRepositoryLoadResources(t *testing.T) {
+	r := newTestRepository(t)
+	first := r.commit(map[string]string{
+		"gateway-class.yaml":     gatewayClass("eg-1"),
+		"tenants/a/gateway.yaml": gatewayClass("eg-2"),
+		".github/config.yaml":    "not: resources",
+		"README.md":              "Gateway API resources of the cluster.",
+	})
+	r.git(r.work, "tag", "v1")
+	r.git(r.work, "push", "origin", "v1")
+	second := r.commit(map[string]string{"tenants/a/gateway.yaml": ""})
+
+	testCases := []struct {
+		name       string
+		url        string
+		ref        string
+		paths      []string
+		expect     string
+		expectGCNs []string
+	}{
+		{
+			name:       "default branch of bare repository",
+			url:        r.bare,
+			expect:     second,
+			expectGCNs: []string{"eg-1"},
+		},
+		{
+			name:       "tag of file url",
+			url:        "file://" + r.bare,
+			ref:        "v1",
+			expect:     first,
+			expectGCNs: []string{"eg-1", "eg-2"},
+		},
+		{
+			name:       "paths of working tree",
+			url:        r.work,
+			ref:        "v1",
+			paths:      []string{"/tenants"},
+			expect:     first,
+			expectGCNs: []string{"eg-2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newRepository(tc.url)
+			commit, err := repo.resolve(context.Background(), tc.ref)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, commit)
+
+			rs, err := repo.loadResources(context.Background(), commit, tc.paths)
+			require.NoError(t, err)
+			var names []string
+			for _, res := range rs {
+				names = append(names, res.GatewayClass.Name)
+			}
+			require.ElementsMatch(t, tc.expectGCNs, names)
+		})
+	}
+
+	t.Run("unknown ref", func(t *testing.T) {
+		_, err := newRepository(r.bare).resolve(context.Background(), "unknown")
+		require.Error(t, err)
+	})
+
+	t.Run("invalid resources", func(t *testing.T) {
+		invalid := r.commit(map[string]string{"invalid.yaml": "kind: [invalid"})
+		_, err := newRepository(r.bare).loadResources(context.Background(), invalid, nil)
+		var invalidErr *file.InvalidResourcesError
+		require.ErrorAs(t, err, &invalidErr)
+
+		// Failing to read the commit may be transient, so it isn't reported as invalid.
+		_, err = newRepository(filepath.Join(t.TempDir(), "missing")).loadResources(context.Background(), invalid, nil)
+		require.Error(t, err)
+		require.False(t, errors.As(err, &invalidErr))
+	})
+}
+
+func TestGitProvider(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := newTestRepository(t)
+	first := r.commit(map[string]string{"gateway-class.yaml": gatewayClass("eg-1")})
+	statusDir := t.TempDir()
+
+	cfg, err := config.New(os.Stdout)
+	require.NoError(t, err)
+	cfg.EnvoyGateway.Provider = &egv1a1.EnvoyGatewayProvider{
+		Type: egv1a1.ProviderTypeCustom,
+		Custom: &egv1a1.EnvoyGatewayCustomProvider{
+			Resource: egv1a1.EnvoyGatewayResourceProvider{
+				Type: egv1a1.ResourceProviderTypeGit,
+				Git: &egv1a1.EnvoyGatewayGitResourceProvider{
+					Repository:   "file://" + r.bare,
+					Ref:          "main",
+					PollInterval: ptr.To(gwapiv1.Duration("100ms")),
+					StatusPath:   statusDir,
+				},
+			},
+		},
+	}
+
+	pResources := new(message.ProviderResources)
+	gp, err := New(ctx, cfg, pResources)
+	require.NoError(t, err)
+	go func() {
+		if err := gp.Start(ctx); err != nil {
+			t.Errorf("failed to start git provider: %v", err)
+		}
+	}()
+
+	t.Run("initial commit", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			return gp.Status().Commit == first && pResources.GetResourcesByGatewayClass("eg-1") != nil
+		}, resourcesUpdateTimeout, resourcesUpdateTick)
+		require.True(t, gp.Ready())
+
+		// The applied commit is reported along with the statuses.
+		revision, err := os.ReadFile(filepath.Join(statusDir, "revision"))
+		require.NoError(t, err)
+		require.Equal(t, first+"\n", string(revision))
+	})
+
+	t.Run("new commit", func(t *testing.T) {
+		second := r.commit(map[string]string{
+			"gateway-class.yaml": "",
+			"eg-2.yaml":          gatewayClass("eg-2"),
+		})
+		require.Eventually(t, func() bool {
+			return gp.Status().Commit == second &&
+				pResources.GetResourcesByGatewayClass("eg-2") != nil &&
+				pResources.GetResourcesByGatewayClass("eg-1") == nil
+		}, resourcesUpdateTimeout, resourcesUpdateTick)
+	})
+
+	t.Run("invalid commit keeps the applied commit", func(t *testing.T) {
+		applied := gp.Status().Commit
+		r.commit(map[string]string{"invalid.yaml": "kind: [invalid"})
+		require.Eventually(t, func() bool {
+			return gp.Status().LastError != ""
+		}, resourcesUpdateTimeout, resourcesUpdateTick)
+		require.Equal(t, applied, gp.Status().Commit)
+		require.NotNil(t, pResources.GetResourcesByGatewayClass("eg-2"))
+	})
+
+	t.Run("serve http", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		gp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, StatusPath, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		got := SyncStatus{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Equal(t, gp.Status().Commit, got.Commit)
+		require.Equal(t, "main", got.Ref)
+		require.NotEmpty(t, got.LastError)
+	})
+}
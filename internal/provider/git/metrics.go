@@ -0,0 +1,30 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package git
+
+import "github.com/wukongcloud/gateway/internal/metrics"
+
+var (
+	gitSyncTotal = metrics.NewCounter(
+		"git_provider_sync_total",
+		"Total number of syncs of the commit of the git resource provider.",
+	)
+
+	gitAppliedTimestampSeconds = metrics.NewGauge(
+		"git_provider_applied_timestamp_seconds",
+		"The unix timestamp of the last time a commit was applied by the git resource provider.",
+		metrics.WithUnit(metrics.Seconds),
+	)
+
+	gitAppliedCommitInfo = metrics.NewGauge(
+		"git_provider_applied_commit_info",
+		"The commit currently applied by the git resource provider, set to 1 for the applied commit.",
+	)
+
+	repositoryLabel = metrics.NewLabel("repository")
+	refLabel        = metrics.NewLabel("ref")
+	commitLabel     = metrics.NewLabel("commit")
+)
@@ -0,0 +1,157 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/provider/file"
+	utilpath "github.com/wukongcloud/gateway/internal/utils/path"
+)
+
+// gitBinary is the name of the git binary, it's looked up in PATH.
+const gitBinary = "git"
+
+// repository reads the resource configuration from a local Git repository by running the git binary.
+type repository struct {
+	// dir is the directory of the repository, it can be either a bare repository or a working tree.
+	dir string
+}
+
+func newRepository(url string) *repository {
+	return &repository{dir: strings.TrimPrefix(url, "file://")}
+}
+
+// resolve returns the SHA of the commit the given branch or tag points to.
+func (r *repository) resolve(ctx context.Context, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	out, err := r.run(ctx, "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve ref %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// resourceFileExtensions are the extensions of the files resources are loaded from, the other
+// files, such as a README, are ignored.
+var resourceFileExtensions = []string{".yaml", ".yml", ".json"}
+
+// loadResources loads resources from the YAML and JSON files under the given paths in the tree
+// of the commit, all the files are loaded if no path is given. Hidden files and directories are
+// ignored.
+func (r *repository) loadResources(ctx context.Context, commit string, paths []string) ([]*resource.Resources, error) {
+	args := []string{"ls-tree", "-r", "-z", "--full-tree", commit, "--"}
+	for _, p := range paths {
+		args = append(args, path.Clean(strings.TrimPrefix(p, "/")))
+	}
+	out, err := r.run(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of commit %s: %w", commit, err)
+	}
+
+	var names, objects []string
+	for _, entry := range bytes.Split(out, []byte{0}) {
+		if len(entry) == 0 {
+			continue
+		}
+
+		// Each entry is in the format of "<mode> SP <type> SP <object> TAB <file>".
+		meta, name, ok := strings.Cut(string(entry), "\t")
+		if !ok {
+			return nil, fmt.Errorf("unexpected tree entry %q", entry)
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 || fields[1] != "blob" || utilpath.IsHidden(name) ||
+			!slices.Contains(resourceFileExtensions, strings.ToLower(path.Ext(name))) {
+			continue
+		}
+		names = append(names, name)
+		objects = append(objects, fields[2])
+	}
+	if len(objects) == 0 {
+		return nil, nil
+	}
+
+	// Read all the files with a single git process rather than one per file.
+	out, err = r.runWithInput(ctx, strings.NewReader(strings.Join(objects, "\n")+"\n"), "cat-file", "--batch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files of commit %s: %w", commit, err)
+	}
+	contents, err := parseBatchOutput(out, len(objects))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files of commit %s: %w", commit, err)
+	}
+
+	rs := make([]*resource.Resources, 0, len(contents))
+	for i, content := range contents {
+		res, err := resource.LoadResourcesFromYAMLBytes(content, false)
+		if err != nil {
+			return nil, &file.InvalidResourcesError{Err: fmt.Errorf("failed to load resources from file %s of commit %s: %w", names[i], commit, err)}
+		}
+		rs = append(rs, res)
+	}
+
+	return rs, nil
+}
+
+// parseBatchOutput returns the contents of the objects printed by "git cat-file --batch",
+// each in the format of "<object> SP <type> SP <size> LF <content> LF".
+func parseBatchOutput(out []byte, count int) ([][]byte, error) {
+	br := bufio.NewReader(bytes.NewReader(out))
+	contents := make([][]byte, 0, count)
+	for range count {
+		header, err := br.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("unexpected end of output: %w", err)
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected object %q", strings.TrimSpace(header))
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected object %q", strings.TrimSpace(header))
+		}
+		content := make([]byte, size+1)
+		if _, err := io.ReadFull(br, content); err != nil {
+			return nil, fmt.Errorf("unexpected end of object %s: %w", fields[0], err)
+		}
+		contents = append(contents, content[:size])
+	}
+	return contents, nil
+}
+
+func (r *repository) run(ctx context.Context, args ...string) ([]byte, error) {
+	return r.runWithInput(ctx, nil, args...)
+}
+
+func (r *repository) runWithInput(ctx context.Context, stdin io.Reader, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, gitBinary, append([]string{"-C", r.dir}, args...)...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
@@ -151,6 +151,14 @@ func New(ctx context.Context, restCfg *rest.Config, svrCfg *ec.Server, resources
 		return nil, fmt.Errorf("unable to set up ready check: %w", err)
 	}
 
+	// Fail the health probes when a runner is stuck, so that it is restarted.
+	if err := mgr.AddHealthzCheck("runners", message.CheckRunners); err != nil {
+		return nil, fmt.Errorf("unable to set up runners health check: %w", err)
+	}
+	if err := mgr.AddReadyzCheck("runners", message.CheckRunners); err != nil {
+		return nil, fmt.Errorf("unable to set up runners ready check: %w", err)
+	}
+
 	// Emit elected & continue with the tasks that require leadership.
 	go func() {
 		<-mgr.Elected()
@@ -27,6 +27,7 @@ import (
 
 	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
 	"github.com/wukongcloud/gateway/internal/gatewayapi"
+	"github.com/wukongcloud/gateway/internal/message"
 	"github.com/wukongcloud/gateway/internal/utils"
 )
 
@@ -611,6 +612,27 @@ func (r *gatewayAPIReconciler) envoyObjectForGateway(ctx context.Context, gatewa
 	return nil, nil
 }
 
+// proxyStatusForGateway returns the status of the proxy process reported by a custom infrastructure
+// provider for the given gateway, returning nil if there is none.
+func (r *gatewayAPIReconciler) proxyStatusForGateway(gateway *gwapiv1.Gateway) *message.ProxyStatus {
+	merged := r.mergeGateways.Has(string(gateway.Spec.GatewayClassName))
+	for _, proxyStatus := range r.resources.ProxyStatuses.LoadAll() {
+		if proxyStatusMatchesGateway(proxyStatus, gateway, merged) {
+			return proxyStatus
+		}
+	}
+	return nil
+}
+
+// proxyStatusMatchesGateway returns true if the proxy of the status belongs to the given gateway.
+func proxyStatusMatchesGateway(proxyStatus *message.ProxyStatus, gateway *gwapiv1.Gateway, merged bool) bool {
+	if proxyStatus == nil || len(proxyStatus.Labels) == 0 {
+		return false
+	}
+	selector := labels.SelectorFromSet(gatewayapi.OwnerLabels(gateway, merged))
+	return selector.Matches(labels.Set(proxyStatus.Labels))
+}
+
 // envoyServiceForGateway returns the Envoy service, returning nil if the service doesn't exist.
 func (r *gatewayAPIReconciler) envoyServiceForGateway(ctx context.Context, gateway *gwapiv1.Gateway) (*corev1.Service, error) {
 	var services corev1.ServiceList
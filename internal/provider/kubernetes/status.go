@@ -9,6 +9,8 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"unicode/utf8"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
@@ -81,6 +83,71 @@ func (r *gatewayAPIReconciler) subscribeAndUpdateStatus(ctx context.Context, ext
 		r.log.Info("gateway status subscriber shutting down")
 	}()
 
+	// Gateway object status updater for the proxy statuses reported by a custom infrastructure provider.
+	go func() {
+		message.HandleSubscription(
+			message.Metadata{Runner: string(egv1a1.LogComponentProviderRunner), Message: "proxy-status"},
+			r.resources.ProxyStatuses.Subscribe(ctx),
+			func(update message.Update[string, *message.ProxyStatus], errChan chan error) {
+				// The status of a deleted proxy is not relevant anymore, since its gateways are deleted as well.
+				if update.Delete {
+					return
+				}
+				for key, gwStatus := range r.resources.GatewayStatuses.LoadAll() {
+					gtw := new(gwapiv1.Gateway)
+					if err := r.client.Get(ctx, key, gtw); err != nil {
+						continue
+					}
+					if !proxyStatusMatchesGateway(update.Value, gtw, r.mergeGateways.Has(string(gtw.Spec.GatewayClassName))) {
+						continue
+					}
+					gtw.Status = *gwStatus
+					r.updateStatusForGateway(ctx, gtw)
+				}
+			},
+		)
+		r.log.Info("proxy status subscriber shutting down")
+	}()
+
+	// Gateway, route and EnvoyPatchPolicy object status updater for the xds updates rejected by the Envoy proxies.
+	go func() {
+		// sources holds the sources of the rejected xds updates of every IR key, so their
+		// statuses are updated again once the updates are accepted.
+		sources := make(map[string][]message.XdsResourceSource)
+		message.HandleSubscription(
+			message.Metadata{Runner: string(egv1a1.LogComponentProviderRunner), Message: "xds-nack-status"},
+			r.resources.XdsNACKStatuses.Subscribe(ctx),
+			func(update message.Update[string, *message.XdsNACKStatus], errChan chan error) {
+				affected := sources[update.Key]
+				if update.Delete {
+					delete(sources, update.Key)
+				} else {
+					sources[update.Key] = nil
+					for _, nack := range update.Value.NACKs {
+						sources[update.Key] = append(sources[update.Key], nack.Sources...)
+					}
+					affected = append(affected, sources[update.Key]...)
+				}
+
+				for key, gwStatus := range r.resources.GatewayStatuses.LoadAll() {
+					gtw := new(gwapiv1.Gateway)
+					if err := r.client.Get(ctx, key, gtw); err != nil {
+						continue
+					}
+					if r.gatewayIRKey(gtw) != update.Key {
+						continue
+					}
+					gtw.Status = *gwStatus
+					r.updateStatusForGateway(ctx, gtw)
+				}
+				for _, source := range affected {
+					r.retriggerStatusUpdate(source, errChan)
+				}
+			},
+		)
+		r.log.Info("xds nack status subscriber shutting down")
+	}()
+
 	// HTTPRoute object status updater
 	go func() {
 		message.HandleSubscription(
@@ -91,23 +158,7 @@ func (r *gatewayAPIReconciler) subscribeAndUpdateStatus(ctx context.Context, ext
 				if update.Delete {
 					return
 				}
-				key := update.Key
-				val := update.Value
-				r.statusUpdater.Send(Update{
-					NamespacedName: key,
-					Resource:       new(gwapiv1.HTTPRoute),
-					Mutator: MutatorFunc(func(obj client.Object) client.Object {
-						h, ok := obj.(*gwapiv1.HTTPRoute)
-						if !ok {
-							err := fmt.Errorf("unsupported object type %T", obj)
-							errChan <- err
-							panic(err)
-						}
-						hCopy := h.DeepCopy()
-						hCopy.Status.Parents = mergeRouteParentStatus(h.Namespace, h.Status.Parents, val.Parents)
-						return hCopy
-					}),
-				})
+				r.updateStatusForHTTPRoute(update.Key, update.Value, errChan)
 			},
 		)
 		r.log.Info("httpRoute status subscriber shutting down")
@@ -121,23 +172,7 @@ func (r *gatewayAPIReconciler) subscribeAndUpdateStatus(ctx context.Context, ext
 				if update.Delete {
 					return
 				}
-				key := update.Key
-				val := update.Value
-				r.statusUpdater.Send(Update{
-					NamespacedName: key,
-					Resource:       new(gwapiv1.GRPCRoute),
-					Mutator: MutatorFunc(func(obj client.Object) client.Object {
-						g, ok := obj.(*gwapiv1.GRPCRoute)
-						if !ok {
-							err := fmt.Errorf("unsupported object type %T", obj)
-							errChan <- err
-							panic(err)
-						}
-						gCopy := g.DeepCopy()
-						gCopy.Status.Parents = mergeRouteParentStatus(g.Namespace, g.Status.Parents, val.Parents)
-						return gCopy
-					}),
-				})
+				r.updateStatusForGRPCRoute(update.Key, update.Value, errChan)
 			},
 		)
 		r.log.Info("grpcRoute status subscriber shutting down")
@@ -153,23 +188,7 @@ func (r *gatewayAPIReconciler) subscribeAndUpdateStatus(ctx context.Context, ext
 				if update.Delete {
 					return
 				}
-				key := update.Key
-				val := update.Value
-				r.statusUpdater.Send(Update{
-					NamespacedName: key,
-					Resource:       new(gwapiv1a2.TLSRoute),
-					Mutator: MutatorFunc(func(obj client.Object) client.Object {
-						t, ok := obj.(*gwapiv1a2.TLSRoute)
-						if !ok {
-							err := fmt.Errorf("unsupported object type %T", obj)
-							errChan <- err
-							panic(err)
-						}
-						tCopy := t.DeepCopy()
-						tCopy.Status.Parents = mergeRouteParentStatus(t.Namespace, t.Status.Parents, val.Parents)
-						return tCopy
-					}),
-				})
+				r.updateStatusForTLSRoute(update.Key, update.Value, errChan)
 			},
 		)
 		r.log.Info("tlsRoute status subscriber shutting down")
@@ -185,23 +204,7 @@ func (r *gatewayAPIReconciler) subscribeAndUpdateStatus(ctx context.Context, ext
 				if update.Delete {
 					return
 				}
-				key := update.Key
-				val := update.Value
-				r.statusUpdater.Send(Update{
-					NamespacedName: key,
-					Resource:       new(gwapiv1a2.TCPRoute),
-					Mutator: MutatorFunc(func(obj client.Object) client.Object {
-						t, ok := obj.(*gwapiv1a2.TCPRoute)
-						if !ok {
-							err := fmt.Errorf("unsupported object type %T", obj)
-							errChan <- err
-							panic(err)
-						}
-						tCopy := t.DeepCopy()
-						tCopy.Status.Parents = mergeRouteParentStatus(t.Namespace, t.Status.Parents, val.Parents)
-						return tCopy
-					}),
-				})
+				r.updateStatusForTCPRoute(update.Key, update.Value, errChan)
 			},
 		)
 		r.log.Info("tcpRoute status subscriber shutting down")
@@ -217,23 +220,7 @@ func (r *gatewayAPIReconciler) subscribeAndUpdateStatus(ctx context.Context, ext
 				if update.Delete {
 					return
 				}
-				key := update.Key
-				val := update.Value
-				r.statusUpdater.Send(Update{
-					NamespacedName: key,
-					Resource:       new(gwapiv1a2.UDPRoute),
-					Mutator: MutatorFunc(func(obj client.Object) client.Object {
-						u, ok := obj.(*gwapiv1a2.UDPRoute)
-						if !ok {
-							err := fmt.Errorf("unsupported object type %T", obj)
-							errChan <- err
-							panic(err)
-						}
-						uCopy := u.DeepCopy()
-						uCopy.Status.Parents = mergeRouteParentStatus(u.Namespace, u.Status.Parents, val.Parents)
-						return uCopy
-					}),
-				})
+				r.updateStatusForUDPRoute(update.Key, update.Value, errChan)
 			},
 		)
 		r.log.Info("udpRoute status subscriber shutting down")
@@ -249,23 +236,7 @@ func (r *gatewayAPIReconciler) subscribeAndUpdateStatus(ctx context.Context, ext
 				if update.Delete {
 					return
 				}
-				key := update.Key
-				val := update.Value
-				r.statusUpdater.Send(Update{
-					NamespacedName: key,
-					Resource:       new(egv1a1.EnvoyPatchPolicy),
-					Mutator: MutatorFunc(func(obj client.Object) client.Object {
-						t, ok := obj.(*egv1a1.EnvoyPatchPolicy)
-						if !ok {
-							err := fmt.Errorf("unsupported object type %T", obj)
-							errChan <- err
-							panic(err)
-						}
-						tCopy := t.DeepCopy()
-						tCopy.Status = *val
-						return tCopy
-					}),
-				})
+				r.updateStatusForEnvoyPatchPolicy(update.Key, update.Value, errChan)
 			},
 		)
 		r.log.Info("envoyPatchPolicy status subscriber shutting down")
@@ -577,6 +548,17 @@ func (r *gatewayAPIReconciler) updateStatusForGateway(ctx context.Context, gtw *
 		status.UpdateGatewayStatusAccepted(gtw)
 		// update address field and programmed condition
 		status.UpdateGatewayStatusProgrammedCondition(gtw, svc, envoyObj, r.store.listNodeAddresses()...)
+		// the proxy may be managed by a custom infrastructure provider instead
+		if envoyObj == nil {
+			if proxyStatus := r.proxyStatusForGateway(gtw); proxyStatus != nil {
+				status.UpdateGatewayStatusProgrammedConditionForProcess(gtw,
+					proxyStatus.Phase == message.ProxyPhaseRunning, proxyStatusMessage(proxyStatus))
+			}
+		}
+		// the proxy may have rejected the xds update
+		if nack := r.xdsNACKForGateway(gtw); nack != nil {
+			status.UpdateGatewayStatusProgrammedConditionForXdsNACK(gtw, xdsNACKMessage(nack))
+		}
 	}
 
 	key := utils.NamespacedName(gtw)
@@ -598,3 +580,259 @@ func (r *gatewayAPIReconciler) updateStatusForGateway(ctx context.Context, gtw *
 		}),
 	})
 }
+
+// maxProxyLogsInMessage is the maximum length of the proxy logs included in a status message.
+const maxProxyLogsInMessage = 1024
+
+// proxyStatusMessage returns the message of the Gateway Programmed condition for the given proxy status.
+func proxyStatusMessage(proxyStatus *message.ProxyStatus) string {
+	if proxyStatus.Restarts == 0 {
+		return "Envoy process running"
+	}
+
+	msg := fmt.Sprintf("Envoy process %s, restarted %d times, last exit code %d",
+		strings.ToLower(string(proxyStatus.Phase)), proxyStatus.Restarts, proxyStatus.LastExitCode)
+	if proxyStatus.Phase == message.ProxyPhaseRunning {
+		return msg
+	}
+
+	if logs := lastBytes(proxyStatus.LastLogs, maxProxyLogsInMessage); logs != "" {
+		msg += ", last logs:\n" + logs
+	}
+	return msg
+}
+
+// maxXdsNACKMessageLength is the maximum length of the Envoy error detail included in a status message.
+const maxXdsNACKMessageLength = 1024
+
+// xdsNACKMessage returns the message of the Programmed condition for the given rejected xds update.
+func xdsNACKMessage(nack *message.XdsNACK) string {
+	detail := nack.Message
+	if len(detail) > maxXdsNACKMessageLength {
+		detail = firstBytes(detail, maxXdsNACKMessageLength) + "..."
+	}
+	typeName := nack.TypeURL[strings.LastIndex(nack.TypeURL, ".")+1:]
+	return fmt.Sprintf("Envoy %s rejected the %s update: %s", nack.NodeID, typeName, detail)
+}
+
+// firstBytes returns the first bytes of the string, at most n, without cutting a UTF-8 character.
+func firstBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// lastBytes returns the last bytes of the string, at most n, without cutting a UTF-8 character.
+func lastBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	i := len(s) - n
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return s[i:]
+}
+
+// gatewayIRKey returns the key of the IR the gateway is translated into.
+func (r *gatewayAPIReconciler) gatewayIRKey(gateway *gwapiv1.Gateway) string {
+	if r.mergeGateways.Has(string(gateway.Spec.GatewayClassName)) {
+		return string(gateway.Spec.GatewayClassName)
+	}
+	return utils.NamespacedName(gateway).String()
+}
+
+// xdsNACKForGateway returns the first xds update rejected by the Envoy proxies of the gateway,
+// returning nil if there is none.
+func (r *gatewayAPIReconciler) xdsNACKForGateway(gateway *gwapiv1.Gateway) *message.XdsNACK {
+	nackStatus, ok := r.resources.XdsNACKStatuses.Load(r.gatewayIRKey(gateway))
+	if !ok || len(nackStatus.NACKs) == 0 {
+		return nil
+	}
+	return &nackStatus.NACKs[0]
+}
+
+// xdsNACKFor returns the first xds update rejected by an Envoy proxy because of the
+// given source, returning nil if there is none.
+func (r *gatewayAPIReconciler) xdsNACKFor(source message.XdsResourceSource) *message.XdsNACK {
+	for _, nackStatus := range r.resources.XdsNACKStatuses.LoadAll() {
+		if nack := nackStatus.NACKFor(source); nack != nil {
+			return nack
+		}
+	}
+	return nil
+}
+
+// setRouteXdsNACKCondition sets the Programmed condition of the route parents to False if
+// an Envoy proxy rejected the xds resources translated from the route.
+func (r *gatewayAPIReconciler) setRouteXdsNACKCondition(kind string, route client.Object, routeStatus *gwapiv1.RouteStatus) {
+	source := message.XdsResourceSource{Kind: kind, Namespace: route.GetNamespace(), Name: route.GetName()}
+	if nack := r.xdsNACKFor(source); nack != nil {
+		status.SetRouteStatusProgrammedConditionForXdsNACK(routeStatus, r.classController, route.GetGeneration(), xdsNACKMessage(nack))
+	}
+}
+
+// retriggerStatusUpdate updates the status of the route or EnvoyPatchPolicy again, so that
+// the condition for its rejected xds updates is updated.
+func (r *gatewayAPIReconciler) retriggerStatusUpdate(source message.XdsResourceSource, errChan chan error) {
+	key := types.NamespacedName{Namespace: source.Namespace, Name: source.Name}
+	switch source.Kind {
+	case resource.KindHTTPRoute:
+		if val, ok := r.resources.HTTPRouteStatuses.Load(key); ok {
+			r.updateStatusForHTTPRoute(key, val, errChan)
+		}
+	case resource.KindGRPCRoute:
+		if val, ok := r.resources.GRPCRouteStatuses.Load(key); ok {
+			r.updateStatusForGRPCRoute(key, val, errChan)
+		}
+	case resource.KindTLSRoute:
+		if val, ok := r.resources.TLSRouteStatuses.Load(key); ok {
+			r.updateStatusForTLSRoute(key, val, errChan)
+		}
+	case resource.KindTCPRoute:
+		if val, ok := r.resources.TCPRouteStatuses.Load(key); ok {
+			r.updateStatusForTCPRoute(key, val, errChan)
+		}
+	case resource.KindUDPRoute:
+		if val, ok := r.resources.UDPRouteStatuses.Load(key); ok {
+			r.updateStatusForUDPRoute(key, val, errChan)
+		}
+	case resource.KindEnvoyPatchPolicy:
+		if val, ok := r.resources.EnvoyPatchPolicyStatuses.Load(key); ok {
+			r.updateStatusForEnvoyPatchPolicy(key, val, errChan)
+		}
+	}
+}
+
+// updateStatusForHTTPRoute updates the status of the HTTPRoute, along with the condition for the
+// xds updates of the HTTPRoute rejected by the Envoy proxies.
+func (r *gatewayAPIReconciler) updateStatusForHTTPRoute(key types.NamespacedName, val *gwapiv1.HTTPRouteStatus, errChan chan error) {
+	r.statusUpdater.Send(Update{
+		NamespacedName: key,
+		Resource:       new(gwapiv1.HTTPRoute),
+		Mutator: MutatorFunc(func(obj client.Object) client.Object {
+			h, ok := obj.(*gwapiv1.HTTPRoute)
+			if !ok {
+				err := fmt.Errorf("unsupported object type %T", obj)
+				errChan <- err
+				panic(err)
+			}
+			hCopy := h.DeepCopy()
+			hCopy.Status.Parents = mergeRouteParentStatus(h.Namespace, h.Status.Parents, val.Parents)
+			r.setRouteXdsNACKCondition(resource.KindHTTPRoute, hCopy, &hCopy.Status.RouteStatus)
+			return hCopy
+		}),
+	})
+}
+
+// updateStatusForGRPCRoute updates the status of the GRPCRoute, along with the condition for the
+// xds updates of the GRPCRoute rejected by the Envoy proxies.
+func (r *gatewayAPIReconciler) updateStatusForGRPCRoute(key types.NamespacedName, val *gwapiv1.GRPCRouteStatus, errChan chan error) {
+	r.statusUpdater.Send(Update{
+		NamespacedName: key,
+		Resource:       new(gwapiv1.GRPCRoute),
+		Mutator: MutatorFunc(func(obj client.Object) client.Object {
+			g, ok := obj.(*gwapiv1.GRPCRoute)
+			if !ok {
+				err := fmt.Errorf("unsupported object type %T", obj)
+				errChan <- err
+				panic(err)
+			}
+			gCopy := g.DeepCopy()
+			gCopy.Status.Parents = mergeRouteParentStatus(g.Namespace, g.Status.Parents, val.Parents)
+			r.setRouteXdsNACKCondition(resource.KindGRPCRoute, gCopy, &gCopy.Status.RouteStatus)
+			return gCopy
+		}),
+	})
+}
+
+// updateStatusForTLSRoute updates the status of the TLSRoute, along with the condition for the
+// xds updates of the TLSRoute rejected by the Envoy proxies.
+func (r *gatewayAPIReconciler) updateStatusForTLSRoute(key types.NamespacedName, val *gwapiv1a2.TLSRouteStatus, errChan chan error) {
+	r.statusUpdater.Send(Update{
+		NamespacedName: key,
+		Resource:       new(gwapiv1a2.TLSRoute),
+		Mutator: MutatorFunc(func(obj client.Object) client.Object {
+			t, ok := obj.(*gwapiv1a2.TLSRoute)
+			if !ok {
+				err := fmt.Errorf("unsupported object type %T", obj)
+				errChan <- err
+				panic(err)
+			}
+			tCopy := t.DeepCopy()
+			tCopy.Status.Parents = mergeRouteParentStatus(t.Namespace, t.Status.Parents, val.Parents)
+			r.setRouteXdsNACKCondition(resource.KindTLSRoute, tCopy, &tCopy.Status.RouteStatus)
+			return tCopy
+		}),
+	})
+}
+
+// updateStatusForTCPRoute updates the status of the TCPRoute, along with the condition for the
+// xds updates of the TCPRoute rejected by the Envoy proxies.
+func (r *gatewayAPIReconciler) updateStatusForTCPRoute(key types.NamespacedName, val *gwapiv1a2.TCPRouteStatus, errChan chan error) {
+	r.statusUpdater.Send(Update{
+		NamespacedName: key,
+		Resource:       new(gwapiv1a2.TCPRoute),
+		Mutator: MutatorFunc(func(obj client.Object) client.Object {
+			t, ok := obj.(*gwapiv1a2.TCPRoute)
+			if !ok {
+				err := fmt.Errorf("unsupported object type %T", obj)
+				errChan <- err
+				panic(err)
+			}
+			tCopy := t.DeepCopy()
+			tCopy.Status.Parents = mergeRouteParentStatus(t.Namespace, t.Status.Parents, val.Parents)
+			r.setRouteXdsNACKCondition(resource.KindTCPRoute, tCopy, &tCopy.Status.RouteStatus)
+			return tCopy
+		}),
+	})
+}
+
+// updateStatusForUDPRoute updates the status of the UDPRoute, along with the condition for the
+// xds updates of the UDPRoute rejected by the Envoy proxies.
+func (r *gatewayAPIReconciler) updateStatusForUDPRoute(key types.NamespacedName, val *gwapiv1a2.UDPRouteStatus, errChan chan error) {
+	r.statusUpdater.Send(Update{
+		NamespacedName: key,
+		Resource:       new(gwapiv1a2.UDPRoute),
+		Mutator: MutatorFunc(func(obj client.Object) client.Object {
+			u, ok := obj.(*gwapiv1a2.UDPRoute)
+			if !ok {
+				err := fmt.Errorf("unsupported object type %T", obj)
+				errChan <- err
+				panic(err)
+			}
+			uCopy := u.DeepCopy()
+			uCopy.Status.Parents = mergeRouteParentStatus(u.Namespace, u.Status.Parents, val.Parents)
+			r.setRouteXdsNACKCondition(resource.KindUDPRoute, uCopy, &uCopy.Status.RouteStatus)
+			return uCopy
+		}),
+	})
+}
+
+// updateStatusForEnvoyPatchPolicy updates the status of the EnvoyPatchPolicy, along with the
+// condition for the xds updates of the EnvoyPatchPolicy rejected by the Envoy proxies.
+func (r *gatewayAPIReconciler) updateStatusForEnvoyPatchPolicy(key types.NamespacedName, val *gwapiv1a2.PolicyStatus, errChan chan error) {
+	r.statusUpdater.Send(Update{
+		NamespacedName: key,
+		Resource:       new(egv1a1.EnvoyPatchPolicy),
+		Mutator: MutatorFunc(func(obj client.Object) client.Object {
+			t, ok := obj.(*egv1a1.EnvoyPatchPolicy)
+			if !ok {
+				err := fmt.Errorf("unsupported object type %T", obj)
+				errChan <- err
+				panic(err)
+			}
+			tCopy := t.DeepCopy()
+			tCopy.Status = *val
+			source := message.XdsResourceSource{Kind: resource.KindEnvoyPatchPolicy, Namespace: t.Namespace, Name: t.Name}
+			if nack := r.xdsNACKFor(source); nack != nil {
+				status.SetXdsNACKForEnvoyPatchPolicy(&tCopy.Status, xdsNACKMessage(nack))
+			}
+			return tCopy
+		}),
+	})
+}
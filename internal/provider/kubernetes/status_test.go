@@ -7,11 +7,15 @@ package kubernetes
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/wukongcloud/gateway/internal/message"
 )
 
 func Test_mergeRouteParentStatus(t *testing.T) {
@@ -292,3 +296,19 @@ func Test_mergeRouteParentStatus(t *testing.T) {
 		})
 	}
 }
+
+func Test_statusMessageTruncation(t *testing.T) {
+	logs := strings.Repeat("é", maxProxyLogsInMessage)
+	msg := proxyStatusMessage(&message.ProxyStatus{Phase: message.ProxyPhaseCrashLoopBackOff, Restarts: 1, LastLogs: logs})
+	if !utf8.ValidString(msg) {
+		t.Errorf("proxyStatusMessage() = %q, isn't valid UTF-8", msg)
+	}
+	if !strings.HasSuffix(msg, strings.Repeat("é", maxProxyLogsInMessage/2)) {
+		t.Errorf("proxyStatusMessage() = %q, doesn't end with the last logs", msg)
+	}
+
+	msg = xdsNACKMessage(&message.XdsNACK{NodeID: "envoy", TypeURL: "type.googleapis.com/envoy.config.cluster.v3.Cluster", Message: "x" + logs})
+	if !utf8.ValidString(msg) || !strings.HasSuffix(msg, "é...") {
+		t.Errorf("xdsNACKMessage() = %q, isn't valid UTF-8", msg)
+	}
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/wukongcloud/gateway/internal/envoygateway/config"
 	"github.com/wukongcloud/gateway/internal/message"
 	"github.com/wukongcloud/gateway/internal/provider"
+	"github.com/wukongcloud/gateway/internal/provider/bundle"
 	"github.com/wukongcloud/gateway/internal/provider/file"
+	"github.com/wukongcloud/gateway/internal/provider/git"
 	"github.com/wukongcloud/gateway/internal/provider/kubernetes"
 )
 
@@ -95,6 +97,18 @@ func (r *Runner) createCustomResourceProvider(ctx context.Context) (p provider.P
 			return nil, fmt.Errorf("failed to create provider %s: %w", egv1a1.ProviderTypeCustom, err)
 		}
 
+	case egv1a1.ResourceProviderTypeGit:
+		p, err = git.New(ctx, &r.Server, r.ProviderResources)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create provider %s: %w", egv1a1.ProviderTypeCustom, err)
+		}
+
+	case egv1a1.ResourceProviderTypeHTTP:
+		p, err = bundle.New(ctx, &r.Server, r.ProviderResources)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create provider %s: %w", egv1a1.ProviderTypeCustom, err)
+		}
+
 	default:
 		return nil, fmt.Errorf("unsupported resource provider type")
 	}
@@ -7,7 +7,9 @@ package path
 
 import (
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -65,3 +67,50 @@ func GetParentDirs(files []string) sets.Set[string] {
 	}
 	return parents
 }
+
+// IsHidden reports whether the slash-separated file name or any of its parent directories is hidden.
+func IsHidden(name string) bool {
+	for _, segment := range strings.Split(strings.TrimPrefix(name, "./"), "/") {
+		if strings.HasPrefix(segment, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob reports whether name matches the slash-separated glob pattern.
+// In addition to the syntax supported by path.Match, a `**` segment matches
+// zero or more path segments.
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(filepath.ToSlash(name), "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive `**` segments.
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range name {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -123,3 +123,48 @@ func TestGetParentDirs(t *testing.T) {
 		})
 	}
 }
+
+func TestIsHidden(t *testing.T) {
+	testCases := []struct {
+		name   string
+		expect bool
+	}{
+		{name: "gateway.yaml", expect: false},
+		{name: "./tenants/a/gateway.yaml", expect: false},
+		{name: ".gateway.yaml", expect: true},
+		{name: "tenants/.a/gateway.yaml", expect: true},
+		{name: "./.github/config.yaml", expect: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expect, IsHidden(tc.name))
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		name    string
+		expect  bool
+	}{
+		{pattern: "*.yaml", name: "foo.yaml", expect: true},
+		{pattern: "*.yaml", name: "foo/bar.yaml", expect: false},
+		{pattern: "**/*.yaml", name: "bar.yaml", expect: true},
+		{pattern: "**/*.yaml", name: "foo/bar/baz.yaml", expect: true},
+		{pattern: "tenants/**", name: "tenants/a/b/gw.yaml", expect: true},
+		{pattern: "tenants/*/gw.yaml", name: "tenants/a/gw.yaml", expect: true},
+		{pattern: "tenants/*/gw.yaml", name: "tenants/a/b/gw.yaml", expect: false},
+		{pattern: "tenants/**/gw.yaml", name: "tenants/a/b/gw.yaml", expect: true},
+		{pattern: "tenants/**/gw.yaml", name: "other/a/gw.yaml", expect: false},
+		{pattern: "**/_*", name: "tenants/_draft.yaml", expect: true},
+		{pattern: "[", name: "foo", expect: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+"~"+tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expect, MatchGlob(tc.pattern, tc.name))
+		})
+	}
+}
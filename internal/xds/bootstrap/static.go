@@ -0,0 +1,182 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package bootstrap
+
+import (
+	"errors"
+	"fmt"
+
+	bootstrapv3 "github.com/envoyproxy/go-control-plane/envoy/config/bootstrap/v3"
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/wukongcloud/gateway/internal/utils/proto"
+)
+
+const (
+	xdsClusterName  = "xds_cluster"
+	wasmClusterName = "wasm_cluster"
+)
+
+// GetStaticBootstrapConfig returns a standalone bootstrap serving the xDS resources without
+// a control plane, from the rendered bootstrap YAML.
+//
+// The listeners, clusters and secrets are added to the static resources of the bootstrap,
+// with the route configurations inlined in the listeners, the endpoints inlined in the EDS
+// clusters and the references to the secrets served by ADS pointing to the static secrets.
+// The dynamic resources, and the clusters of the xDS and Wasm servers of Envoy Gateway are
+// removed.
+func GetStaticBootstrapConfig(bootstrapYAML string, resources map[resourcev3.Type][]types.Resource) (*bootstrapv3.Bootstrap, error) {
+	bootstrap := &bootstrapv3.Bootstrap{}
+	if err := proto.FromYAML([]byte(bootstrapYAML), bootstrap); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal bootstrap: %w", err)
+	}
+	bootstrap.DynamicResources = nil
+	if bootstrap.StaticResources == nil {
+		bootstrap.StaticResources = &bootstrapv3.Bootstrap_StaticResources{}
+	}
+	static := bootstrap.StaticResources
+
+	clusters := static.Clusters[:0]
+	for _, cluster := range static.Clusters {
+		if cluster.Name != xdsClusterName && cluster.Name != wasmClusterName {
+			clusters = append(clusters, cluster)
+		}
+	}
+	static.Clusters = clusters
+
+	routes := make(map[string]*routev3.RouteConfiguration)
+	for _, r := range resources[resourcev3.RouteType] {
+		if route, ok := r.(*routev3.RouteConfiguration); ok {
+			routes[route.Name] = route
+		}
+	}
+	endpoints := make(map[string]*endpointv3.ClusterLoadAssignment)
+	for _, r := range resources[resourcev3.EndpointType] {
+		if cla, ok := r.(*endpointv3.ClusterLoadAssignment); ok {
+			endpoints[cla.ClusterName] = cla
+		}
+	}
+
+	for _, r := range resources[resourcev3.ListenerType] {
+		listener, ok := r.(*listenerv3.Listener)
+		if !ok {
+			continue
+		}
+		listener = protobuf.Clone(listener).(*listenerv3.Listener)
+		if err := makeStatic(listener.ProtoReflect(), routes); err != nil {
+			return nil, fmt.Errorf("failed to convert listener %s: %w", listener.Name, err)
+		}
+		static.Listeners = append(static.Listeners, listener)
+	}
+
+	for _, r := range resources[resourcev3.ClusterType] {
+		cluster, ok := r.(*clusterv3.Cluster)
+		if !ok {
+			continue
+		}
+		cluster = protobuf.Clone(cluster).(*clusterv3.Cluster)
+		if cluster.GetType() == clusterv3.Cluster_EDS {
+			serviceName := cluster.GetEdsClusterConfig().GetServiceName()
+			if serviceName == "" {
+				serviceName = cluster.Name
+			}
+			cla := &endpointv3.ClusterLoadAssignment{ClusterName: serviceName}
+			if e, ok := endpoints[serviceName]; ok {
+				cla = protobuf.Clone(e).(*endpointv3.ClusterLoadAssignment)
+			}
+			cluster.ClusterDiscoveryType = &clusterv3.Cluster_Type{Type: clusterv3.Cluster_STATIC}
+			cluster.EdsClusterConfig = nil
+			cluster.LoadAssignment = cla
+		}
+		if err := makeStatic(cluster.ProtoReflect(), routes); err != nil {
+			return nil, fmt.Errorf("failed to convert cluster %s: %w", cluster.Name, err)
+		}
+		static.Clusters = append(static.Clusters, cluster)
+	}
+
+	for _, r := range resources[resourcev3.SecretType] {
+		if secret, ok := r.(*tlsv3.Secret); ok {
+			static.Secrets = append(static.Secrets, protobuf.Clone(secret).(*tlsv3.Secret))
+		}
+	}
+
+	if err := bootstrap.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid static bootstrap: %w", err)
+	}
+	return bootstrap, nil
+}
+
+// makeStatic rewrites the message and the messages it holds, including the ones packed
+// in Any, to refer to the static resources instead of the ones served by ADS.
+func makeStatic(m protoreflect.Message, routes map[string]*routev3.RouteConfiguration) error {
+	switch msg := m.Interface().(type) {
+	case *hcmv3.HttpConnectionManager:
+		if rds := msg.GetRds(); rds != nil {
+			route, ok := routes[rds.RouteConfigName]
+			if !ok {
+				return fmt.Errorf("route configuration %s not found", rds.RouteConfigName)
+			}
+			msg.RouteSpecifier = &hcmv3.HttpConnectionManager_RouteConfig{
+				RouteConfig: protobuf.Clone(route).(*routev3.RouteConfiguration),
+			}
+		}
+	case *tlsv3.SdsSecretConfig:
+		// A secret without a config source is a static one.
+		if msg.GetSdsConfig().GetAds() != nil {
+			msg.SdsConfig = nil
+		}
+	case *anypb.Any:
+		inner, err := msg.UnmarshalNew()
+		if errors.Is(err, protoregistry.NotFound) {
+			// A type unknown to Envoy Gateway can't refer to its resources.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := makeStatic(inner.ProtoReflect(), routes); err != nil {
+			return err
+		}
+		return msg.MarshalFrom(inner)
+	}
+
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList():
+			if fd.Message() == nil {
+				return true
+			}
+			list := v.List()
+			for i := 0; i < list.Len() && err == nil; i++ {
+				err = makeStatic(list.Get(i).Message(), routes)
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() == nil {
+				return true
+			}
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				err = makeStatic(mv.Message(), routes)
+				return err == nil
+			})
+		case fd.Message() != nil:
+			err = makeStatic(v.Message(), routes)
+		}
+		return err == nil
+	})
+	return err
+}
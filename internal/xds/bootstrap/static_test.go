@@ -0,0 +1,147 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package bootstrap
+
+import (
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	routerv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func adsConfigSource() *corev3.ConfigSource {
+	return &corev3.ConfigSource{
+		ResourceApiVersion:    resourcev3.DefaultAPIVersion,
+		ConfigSourceSpecifier: &corev3.ConfigSource_Ads{Ads: &corev3.AggregatedConfigSource{}},
+	}
+}
+
+func newStaticTestResources(t *testing.T, routeName string) map[resourcev3.Type][]types.Resource {
+	router, err := anypb.New(&routerv3.Router{})
+	require.NoError(t, err)
+	hcm, err := anypb.New(&hcmv3.HttpConnectionManager{
+		StatPrefix: "https",
+		RouteSpecifier: &hcmv3.HttpConnectionManager_Rds{
+			Rds: &hcmv3.Rds{ConfigSource: adsConfigSource(), RouteConfigName: routeName},
+		},
+		HttpFilters: []*hcmv3.HttpFilter{{
+			Name:       "envoy.filters.http.router",
+			ConfigType: &hcmv3.HttpFilter_TypedConfig{TypedConfig: router},
+		}},
+	})
+	require.NoError(t, err)
+	tls, err := anypb.New(&tlsv3.DownstreamTlsContext{
+		CommonTlsContext: &tlsv3.CommonTlsContext{
+			TlsCertificateSdsSecretConfigs: []*tlsv3.SdsSecretConfig{{Name: "default/cert", SdsConfig: adsConfigSource()}},
+		},
+	})
+	require.NoError(t, err)
+
+	return map[resourcev3.Type][]types.Resource{
+		resourcev3.ListenerType: {&listenerv3.Listener{
+			Name: "default/eg/https",
+			Address: &corev3.Address{Address: &corev3.Address_SocketAddress{SocketAddress: &corev3.SocketAddress{
+				Address:       "0.0.0.0",
+				PortSpecifier: &corev3.SocketAddress_PortValue{PortValue: 10443},
+			}}},
+			FilterChains: []*listenerv3.FilterChain{{
+				Filters: []*listenerv3.Filter{{
+					Name:       "envoy.filters.network.http_connection_manager",
+					ConfigType: &listenerv3.Filter_TypedConfig{TypedConfig: hcm},
+				}},
+				TransportSocket: &corev3.TransportSocket{
+					Name:       "envoy.transport_sockets.tls",
+					ConfigType: &corev3.TransportSocket_TypedConfig{TypedConfig: tls},
+				},
+			}},
+		}},
+		resourcev3.RouteType: {&routev3.RouteConfiguration{
+			Name: "default/eg/https",
+			VirtualHosts: []*routev3.VirtualHost{{
+				Name:    "default/eg/https/www_example_com",
+				Domains: []string{"www.example.com"},
+				Routes: []*routev3.Route{{
+					Match:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/"}},
+					Action: &routev3.Route_Route{Route: &routev3.RouteAction{ClusterSpecifier: &routev3.RouteAction_Cluster{Cluster: "httproute/default/backend/rule/0"}}},
+				}},
+			}},
+		}},
+		resourcev3.ClusterType: {&clusterv3.Cluster{
+			Name:                 "httproute/default/backend/rule/0",
+			ClusterDiscoveryType: &clusterv3.Cluster_Type{Type: clusterv3.Cluster_EDS},
+			EdsClusterConfig: &clusterv3.Cluster_EdsClusterConfig{
+				EdsConfig:   adsConfigSource(),
+				ServiceName: "httproute/default/backend/rule/0",
+			},
+		}},
+		resourcev3.EndpointType: {&endpointv3.ClusterLoadAssignment{
+			ClusterName: "httproute/default/backend/rule/0",
+			Endpoints: []*endpointv3.LocalityLbEndpoints{{
+				LbEndpoints: []*endpointv3.LbEndpoint{{
+					HostIdentifier: &endpointv3.LbEndpoint_Endpoint{Endpoint: &endpointv3.Endpoint{
+						Address: &corev3.Address{Address: &corev3.Address_SocketAddress{SocketAddress: &corev3.SocketAddress{
+							Address:       "7.7.7.7",
+							PortSpecifier: &corev3.SocketAddress_PortValue{PortValue: 3000},
+						}}},
+					}},
+				}},
+			}},
+		}},
+		resourcev3.SecretType: {&tlsv3.Secret{Name: "default/cert"}},
+	}
+}
+
+func TestGetStaticBootstrapConfig(t *testing.T) {
+	defaultBootstrap, err := GetRenderedBootstrapConfig(nil)
+	require.NoError(t, err)
+
+	resources := newStaticTestResources(t, "default/eg/https")
+	bootstrap, err := GetStaticBootstrapConfig(defaultBootstrap, resources)
+	require.NoError(t, err)
+
+	require.Nil(t, bootstrap.DynamicResources)
+	var clusterNames []string
+	for _, cluster := range bootstrap.StaticResources.Clusters {
+		clusterNames = append(clusterNames, cluster.Name)
+	}
+	require.Equal(t, []string{"prometheus_stats", "local_cluster", "httproute/default/backend/rule/0"}, clusterNames)
+
+	// The EDS cluster is converted to a static one.
+	cluster := bootstrap.StaticResources.Clusters[2]
+	require.Equal(t, clusterv3.Cluster_STATIC, cluster.GetType())
+	require.Nil(t, cluster.EdsClusterConfig)
+	require.Equal(t, "7.7.7.7", cluster.LoadAssignment.Endpoints[0].LbEndpoints[0].GetEndpoint().Address.GetSocketAddress().Address)
+
+	// The route configuration and the secret are inlined in the listener.
+	require.Len(t, bootstrap.StaticResources.Listeners, 2)
+	listener := bootstrap.StaticResources.Listeners[1]
+	require.Equal(t, "default/eg/https", listener.Name)
+	hcm := &hcmv3.HttpConnectionManager{}
+	require.NoError(t, listener.FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(hcm))
+	require.Nil(t, hcm.GetRds())
+	require.Equal(t, "default/eg/https", hcm.GetRouteConfig().Name)
+	tls := &tlsv3.DownstreamTlsContext{}
+	require.NoError(t, listener.FilterChains[0].TransportSocket.GetTypedConfig().UnmarshalTo(tls))
+	require.Nil(t, tls.CommonTlsContext.TlsCertificateSdsSecretConfigs[0].SdsConfig)
+	require.Len(t, bootstrap.StaticResources.Secrets, 1)
+
+	// The translated resources are left untouched.
+	original := resources[resourcev3.ClusterType][0].(*clusterv3.Cluster)
+	require.Equal(t, clusterv3.Cluster_EDS, original.GetType())
+
+	_, err = GetStaticBootstrapConfig(defaultBootstrap, newStaticTestResources(t, "missing"))
+	require.ErrorContains(t, err, "route configuration missing not found")
+}
@@ -0,0 +1,313 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+)
+
+// ErrRolloutNotFound is returned when an IR key has no canary rollout.
+var ErrRolloutNotFound = errors.New("rollout not found")
+
+// CanaryRollout configures the canary rollout of the snapshots of every IR key:
+// a new snapshot is first served to a subset of the nodes of the IR key, the
+// canary nodes, and only promoted to the other nodes once a canary node has
+// accepted it and the canary nodes have run it for the soak duration without
+// rejecting it.
+type CanaryRollout struct {
+	// Replicas is the number of canary nodes, used when Percentage is 0.
+	Replicas int
+	// Percentage is the percentage of the nodes which are canary nodes, rounded up.
+	Percentage int
+	// SoakDuration is how long the canary nodes run a new snapshot before it can be promoted.
+	SoakDuration time.Duration
+	// AutoPromote promotes a snapshot once the soak duration has passed, instead of
+	// waiting for PromoteRollout.
+	AutoPromote bool
+	// AutoAbort reverts the canary nodes as soon as one of them rejects a snapshot,
+	// instead of waiting for AbortRollout.
+	AutoAbort bool
+}
+
+// canaryNodes returns the number of canary nodes out of the nodes of an IR key.
+func (c *CanaryRollout) canaryNodes(nodes int) int {
+	if c.Percentage > 0 {
+		return int(math.Ceil(float64(nodes) * float64(c.Percentage) / 100))
+	}
+	return max(c.Replicas, 1)
+}
+
+// RolloutPhase is the phase of the canary rollout of a snapshot.
+type RolloutPhase string
+
+const (
+	// RolloutPhaseSoaking means the canary nodes are running the snapshot for the soak duration,
+	// or no canary node has accepted the snapshot yet.
+	RolloutPhaseSoaking RolloutPhase = "Soaking"
+	// RolloutPhaseSoaked means a canary node has accepted the snapshot and the soak duration
+	// has passed, and the snapshot waits to be promoted.
+	RolloutPhaseSoaked RolloutPhase = "Soaked"
+	// RolloutPhaseFailed means a canary node rejected the snapshot, and the rollout waits to be aborted.
+	RolloutPhaseFailed RolloutPhase = "Failed"
+	// RolloutPhaseAborted means the canary nodes are reverted to the previous snapshot,
+	// until a new snapshot is generated.
+	RolloutPhaseAborted RolloutPhase = "Aborted"
+)
+
+// Rollout describes the canary rollout of a snapshot of an IR key.
+type Rollout struct {
+	IRKey string `json:"irKey"`
+	// Snapshot is the ID of the rolled out snapshot in the history of the IR key.
+	Snapshot int64 `json:"snapshot"`
+	// Generation is the generation of the Gateway API resources the snapshot is translated from.
+	Generation int64        `json:"generation"`
+	Phase      RolloutPhase `json:"phase"`
+	// CanaryNodes are the IDs of the nodes the snapshot is served to first.
+	CanaryNodes []string `json:"canaryNodes"`
+	// Accepted is true once a canary node has accepted the snapshot.
+	Accepted  bool      `json:"accepted"`
+	StartedAt time.Time `json:"startedAt"`
+	// Message is the error detail of the canary node which rejected the snapshot.
+	Message string `json:"message,omitempty"`
+}
+
+// rollout is the canary rollout of a snapshot of an IR key.
+type rollout struct {
+	Rollout
+	// snapshot is served to the canary nodes, and stable to the other nodes.
+	snapshot *cachev3.Snapshot
+	stable   *cachev3.Snapshot
+	nodes    map[string]struct{}
+	timer    *time.Timer
+	// soakElapsed is true once the soak duration has passed.
+	soakElapsed bool
+}
+
+// SetCanaryRollout enables the canary rollout of the snapshots, or disables it if nil.
+func (s *snapshotCache) SetCanaryRollout(canary *CanaryRollout) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.canary = canary
+}
+
+// rolloutSnapshot starts the canary rollout of the latest snapshot of the IR key,
+// replacing the rollout in progress, and returns false if the snapshot must be served
+// to all the nodes instead.
+// The caller must hold the lock.
+func (s *snapshotCache) rolloutSnapshot(irKey string, stable *cachev3.Snapshot, changed bool) (bool, error) {
+	current := s.rollouts[irKey]
+	if !changed {
+		// Keep the rollout in progress, if any, going.
+		return current != nil, nil
+	}
+
+	nodes := s.getNodeIDs(irKey)
+	canaries := s.canary.canaryNodes(len(nodes))
+	if stable == nil || canaries >= len(nodes) {
+		// There is nothing to roll back to, or no other node to protect.
+		s.stopRollout(irKey)
+		return false, nil
+	}
+
+	// The canary nodes are picked by node ID, so that a new rollout keeps the canary
+	// nodes of the rollout it replaces.
+	sort.Strings(nodes)
+	entry := s.history[irKey][len(s.history[irKey])-1]
+	r := &rollout{
+		Rollout: Rollout{
+			IRKey:       irKey,
+			Snapshot:    entry.ID,
+			Generation:  entry.Generation,
+			Phase:       RolloutPhaseSoaking,
+			CanaryNodes: nodes[:canaries],
+			StartedAt:   time.Now(),
+		},
+		snapshot: entry.snapshot,
+		stable:   stable,
+		nodes:    make(map[string]struct{}, canaries),
+	}
+	for _, node := range r.CanaryNodes {
+		r.nodes[node] = struct{}{}
+	}
+	s.stopRollout(irKey)
+	s.rollouts[irKey] = r
+	s.log.Infof("Rolling out snapshot %d for %s to canary nodes %v", r.Snapshot, irKey, r.CanaryNodes)
+
+	for _, node := range nodes {
+		snapshot := r.stable
+		if _, ok := r.nodes[node]; ok {
+			snapshot = r.snapshot
+		}
+		if err := s.SetSnapshot(context.TODO(), node, snapshot); err != nil {
+			return true, err
+		}
+	}
+
+	r.timer = time.AfterFunc(s.canary.SoakDuration, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		r.soakElapsed = true
+		s.soaked(irKey, r)
+	})
+	return true, nil
+}
+
+// soaked moves the rollout to the soaked phase once a canary node has accepted the
+// snapshot and the soak duration has passed.
+// The caller must hold the lock.
+func (s *snapshotCache) soaked(irKey string, r *rollout) {
+	if s.rollouts[irKey] != r || r.Phase != RolloutPhaseSoaking || !r.Accepted || !r.soakElapsed {
+		return
+	}
+
+	r.Phase = RolloutPhaseSoaked
+	s.log.Infof("Snapshot %d for %s soaked on canary nodes %v", r.Snapshot, irKey, r.CanaryNodes)
+	if s.canary != nil && s.canary.AutoPromote {
+		if err := s.promote(irKey, r); err != nil {
+			s.log.Errorf("Failed to promote snapshot %d for %s: %v", r.Snapshot, irKey, err)
+		}
+	}
+}
+
+// canaryAccepted records that a canary node accepted the snapshot of the rollout of the IR key,
+// when the accepted version of the resource type is the one of the snapshot.
+// The caller must hold the lock.
+func (s *snapshotCache) canaryAccepted(irKey, nodeID, typeURL, version string) {
+	r := s.rollouts[irKey]
+	if r == nil || r.Accepted || version == "" {
+		return
+	}
+	if _, ok := r.nodes[nodeID]; !ok {
+		return
+	}
+	// The versions of the resource types the snapshot doesn't change are the stable ones as well.
+	if version != r.snapshot.GetVersion(typeURL) || version == r.stable.GetVersion(typeURL) {
+		return
+	}
+
+	r.Accepted = true
+	s.log.Infof("Envoy %s accepted the %s update of snapshot %d for %s", nodeID, typeURL, r.Snapshot, irKey)
+	s.soaked(irKey, r)
+}
+
+// canaryRejected fails the rollout of the IR key if the node rejecting an update is a canary node.
+// The caller must hold the lock.
+func (s *snapshotCache) canaryRejected(irKey, nodeID, typeURL, message string) {
+	r := s.rollouts[irKey]
+	if r == nil || (r.Phase != RolloutPhaseSoaking && r.Phase != RolloutPhaseSoaked) {
+		return
+	}
+	if _, ok := r.nodes[nodeID]; !ok {
+		return
+	}
+
+	r.Phase = RolloutPhaseFailed
+	r.Message = fmt.Sprintf("Envoy %s rejected the %s update: %s", nodeID, typeURL, message)
+	s.log.Errorf("Rollout of snapshot %d for %s failed: %s", r.Snapshot, irKey, r.Message)
+	if s.canary != nil && s.canary.AutoAbort {
+		if err := s.abort(irKey, r); err != nil {
+			s.log.Errorf("Failed to abort the rollout of snapshot %d for %s: %v", r.Snapshot, irKey, err)
+		}
+	}
+}
+
+// promote serves the snapshot of the rollout to all the nodes of the IR key.
+// The caller must hold the lock.
+func (s *snapshotCache) promote(irKey string, r *rollout) error {
+	s.stopRollout(irKey)
+	xdsRolloutTotal.With(resultLabel.Value("promoted")).Increment()
+	s.log.Infof("Promoting snapshot %d for %s", r.Snapshot, irKey)
+	return s.serveSnapshot(irKey, r.snapshot)
+}
+
+// abort serves the previous snapshot to the canary nodes of the rollout.
+// The caller must hold the lock.
+func (s *snapshotCache) abort(irKey string, r *rollout) error {
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.Phase = RolloutPhaseAborted
+	xdsRolloutTotal.With(resultLabel.Value("aborted")).Increment()
+	s.log.Infof("Aborting the rollout of snapshot %d for %s", r.Snapshot, irKey)
+	for _, node := range r.CanaryNodes {
+		if err := s.SetSnapshot(context.TODO(), node, r.stable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stopRollout forgets the rollout of the IR key, if any.
+// The caller must hold the lock.
+func (s *snapshotCache) stopRollout(irKey string) {
+	if r := s.rollouts[irKey]; r != nil && r.timer != nil {
+		r.timer.Stop()
+	}
+	delete(s.rollouts, irKey)
+}
+
+// Rollouts returns the canary rollout of every IR key, sorted by IR key.
+func (s *snapshotCache) Rollouts() []Rollout {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rollouts := make([]Rollout, 0, len(s.rollouts))
+	for _, r := range s.rollouts {
+		rollouts = append(rollouts, r.Rollout)
+	}
+	sort.Slice(rollouts, func(i, j int) bool {
+		return rollouts[i].IRKey < rollouts[j].IRKey
+	})
+
+	return rollouts
+}
+
+// PromoteRollout serves the snapshot rolled out to the canary nodes of the IR key to
+// all its nodes, whatever the phase of the rollout.
+func (s *snapshotCache) PromoteRollout(irKey string) (*Rollout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.rollouts[irKey]
+	if r == nil {
+		return nil, fmt.Errorf("%w: %s has no rollout", ErrRolloutNotFound, irKey)
+	}
+	if err := s.promote(irKey, r); err != nil {
+		return nil, err
+	}
+
+	info := r.Rollout
+	return &info, nil
+}
+
+// AbortRollout serves the previous snapshot to the canary nodes of the IR key again.
+// The rollout is kept in the aborted phase until a new snapshot is generated.
+func (s *snapshotCache) AbortRollout(irKey string) (*Rollout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.rollouts[irKey]
+	if r == nil {
+		return nil, fmt.Errorf("%w: %s has no rollout", ErrRolloutNotFound, irKey)
+	}
+	if r.Phase != RolloutPhaseAborted {
+		if err := s.abort(irKey, r); err != nil {
+			return nil, err
+		}
+	}
+
+	info := r.Rollout
+	return &info, nil
+}
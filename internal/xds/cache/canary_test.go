@@ -0,0 +1,208 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/status"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/logging"
+)
+
+// newCanaryTestCache returns a cache with the nodes envoy-0 to envoy-<nodes-1> of the default/eg IR key,
+// connected on the streams 0 to nodes-1.
+func newCanaryTestCache(t *testing.T, nodes int, canary *CanaryRollout) *snapshotCache {
+	t.Helper()
+	logger := logging.DefaultLogger(os.Stdout, egv1a1.LogLevelInfo)
+	cache := NewSnapshotCache(true, egv1a1.DefaultSnapshotHistorySize, logger).(*snapshotCache)
+	cache.SetCanaryRollout(canary)
+
+	for i := 0; i < nodes; i++ {
+		node := &corev3.Node{Id: fmt.Sprintf("envoy-%d", i), Cluster: "default/eg"}
+		require.NoError(t, cache.OnDeltaStreamOpen(context.Background(), int64(i), resourcev3.ClusterType))
+		require.NoError(t, cache.OnStreamDeltaRequest(int64(i), &discoveryv3.DeltaDiscoveryRequest{Node: node}))
+	}
+	return cache
+}
+
+// nodeTimeout returns the connect timeout of cluster b served to the node.
+func nodeTimeout(t *testing.T, cache *snapshotCache, node string) time.Duration {
+	t.Helper()
+	snapshot, err := cache.GetSnapshot(node)
+	require.NoError(t, err)
+	return snapshot.GetResources(resourcev3.ClusterType)["b"].(*clusterv3.Cluster).GetConnectTimeout().AsDuration()
+}
+
+// accept sends the cluster update of the node on the stream, and ACKs it.
+func accept(t *testing.T, cache *snapshotCache, streamID int64, node string) {
+	t.Helper()
+	snapshot, err := cache.GetSnapshot(node)
+	require.NoError(t, err)
+	nonce := fmt.Sprintf("nonce-%d", time.Now().UnixNano())
+	cache.OnStreamDeltaResponse(streamID, &discoveryv3.DeltaDiscoveryRequest{TypeUrl: resourcev3.ClusterType}, &discoveryv3.DeltaDiscoveryResponse{
+		TypeUrl:           resourcev3.ClusterType,
+		Nonce:             nonce,
+		SystemVersionInfo: snapshot.GetVersion(resourcev3.ClusterType),
+	})
+	require.NoError(t, cache.OnStreamDeltaRequest(streamID, &discoveryv3.DeltaDiscoveryRequest{
+		TypeUrl:       resourcev3.ClusterType,
+		ResponseNonce: nonce,
+	}))
+}
+
+func TestCanaryRolloutAutoPromote(t *testing.T) {
+	cache := newCanaryTestCache(t, 3, &CanaryRollout{
+		Percentage:   50,
+		SoakDuration: 50 * time.Millisecond,
+		AutoPromote:  true,
+		AutoAbort:    true,
+	})
+
+	// The first snapshot is served to all the nodes.
+	generateClusters(t, cache, 1, 1*time.Second)
+	require.Empty(t, cache.Rollouts())
+
+	// A new snapshot is only served to the canary nodes.
+	generateClusters(t, cache, 2, 2*time.Second)
+	rollouts := cache.Rollouts()
+	require.Len(t, rollouts, 1)
+	require.Equal(t, RolloutPhaseSoaking, rollouts[0].Phase)
+	require.Equal(t, []string{"envoy-0", "envoy-1"}, rollouts[0].CanaryNodes)
+	require.Equal(t, int64(2), rollouts[0].Generation)
+	require.Equal(t, 2*time.Second, nodeTimeout(t, cache, "envoy-0"))
+	require.Equal(t, 2*time.Second, nodeTimeout(t, cache, "envoy-1"))
+	require.Equal(t, 1*time.Second, nodeTimeout(t, cache, "envoy-2"))
+
+	// The snapshot isn't promoted until a canary node accepts it.
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, RolloutPhaseSoaking, cache.Rollouts()[0].Phase)
+	// The ACKs by the other nodes, or of the stable snapshot, don't count.
+	accept(t, cache, 2, "envoy-2")
+	require.False(t, cache.Rollouts()[0].Accepted)
+
+	// The snapshot is promoted once accepted and soaked.
+	accept(t, cache, 0, "envoy-0")
+	require.Eventually(t, func() bool {
+		return len(cache.Rollouts()) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 2*time.Second, nodeTimeout(t, cache, "envoy-2"))
+}
+
+func TestCanaryRolloutReconnect(t *testing.T) {
+	cache := newCanaryTestCache(t, 2, &CanaryRollout{Replicas: 1, SoakDuration: time.Hour})
+
+	generateClusters(t, cache, 1, 1*time.Second)
+	generateClusters(t, cache, 2, 2*time.Second)
+
+	// The nodes reconnecting during the rollout are served the snapshot of their kind of node.
+	for i, timeout := range []time.Duration{2 * time.Second, 1 * time.Second} {
+		node := &corev3.Node{Id: fmt.Sprintf("envoy-%d", i), Cluster: "default/eg"}
+		cache.OnDeltaStreamClosed(int64(i), node)
+		cache.ClearSnapshot(node.Id)
+		require.NoError(t, cache.OnDeltaStreamOpen(context.Background(), int64(10+i), resourcev3.ClusterType))
+		require.NoError(t, cache.OnStreamDeltaRequest(int64(10+i), &discoveryv3.DeltaDiscoveryRequest{Node: node}))
+		require.Equal(t, timeout, nodeTimeout(t, cache, node.Id))
+	}
+}
+
+func TestCanaryRolloutAbort(t *testing.T) {
+	cache := newCanaryTestCache(t, 3, &CanaryRollout{
+		Replicas:     1,
+		SoakDuration: time.Hour,
+		AutoAbort:    true,
+	})
+
+	generateClusters(t, cache, 1, 1*time.Second)
+	generateClusters(t, cache, 2, 2*time.Second)
+	require.Equal(t, 2*time.Second, nodeTimeout(t, cache, "envoy-0"))
+
+	// Rejections by the other nodes don't fail the rollout.
+	reject := func(streamID int64) {
+		require.NoError(t, cache.OnStreamDeltaRequest(streamID, &discoveryv3.DeltaDiscoveryRequest{
+			TypeUrl:       resourcev3.ClusterType,
+			ResponseNonce: "1",
+			ErrorDetail:   &status.Status{Code: 13, Message: "rejected"},
+		}))
+	}
+	reject(1)
+	require.Equal(t, RolloutPhaseSoaking, cache.Rollouts()[0].Phase)
+
+	// The canary node is reverted when it rejects the snapshot.
+	reject(0)
+	rollout := cache.Rollouts()[0]
+	require.Equal(t, RolloutPhaseAborted, rollout.Phase)
+	require.Contains(t, rollout.Message, "rejected")
+	require.Equal(t, 1*time.Second, nodeTimeout(t, cache, "envoy-0"))
+
+	// The same snapshot isn't rolled out again.
+	generateClusters(t, cache, 3, 2*time.Second)
+	require.Equal(t, RolloutPhaseAborted, cache.Rollouts()[0].Phase)
+	require.Equal(t, 1*time.Second, nodeTimeout(t, cache, "envoy-0"))
+
+	// A new snapshot is rolled out from the snapshot served before.
+	generateClusters(t, cache, 4, 3*time.Second)
+	require.Equal(t, RolloutPhaseSoaking, cache.Rollouts()[0].Phase)
+	require.Equal(t, 3*time.Second, nodeTimeout(t, cache, "envoy-0"))
+	require.Equal(t, 1*time.Second, nodeTimeout(t, cache, "envoy-1"))
+
+	// A manual promotion doesn't wait for the soak duration.
+	promoted, err := cache.PromoteRollout("default/eg")
+	require.NoError(t, err)
+	require.Equal(t, int64(4), promoted.Generation)
+	require.Empty(t, cache.Rollouts())
+	for _, node := range []string{"envoy-0", "envoy-1", "envoy-2"} {
+		require.Equal(t, 3*time.Second, nodeTimeout(t, cache, node))
+	}
+
+	_, err = cache.PromoteRollout("default/eg")
+	require.ErrorIs(t, err, ErrRolloutNotFound)
+	_, err = cache.AbortRollout("default/eg")
+	require.ErrorIs(t, err, ErrRolloutNotFound)
+}
+
+func TestCanaryRolloutManual(t *testing.T) {
+	cache := newCanaryTestCache(t, 2, &CanaryRollout{Replicas: 1})
+
+	generateClusters(t, cache, 1, 1*time.Second)
+	generateClusters(t, cache, 2, 2*time.Second)
+	accept(t, cache, 0, "envoy-0")
+
+	// The rollout waits to be promoted once soaked.
+	require.Eventually(t, func() bool {
+		return cache.Rollouts()[0].Phase == RolloutPhaseSoaked
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1*time.Second, nodeTimeout(t, cache, "envoy-1"))
+
+	// The rollout waits to be aborted once rejected.
+	require.NoError(t, cache.OnStreamDeltaRequest(0, &discoveryv3.DeltaDiscoveryRequest{
+		TypeUrl:       resourcev3.ClusterType,
+		ResponseNonce: "1",
+		ErrorDetail:   &status.Status{Code: 13, Message: "rejected"},
+	}))
+	require.Equal(t, RolloutPhaseFailed, cache.Rollouts()[0].Phase)
+	require.Equal(t, 2*time.Second, nodeTimeout(t, cache, "envoy-0"))
+
+	aborted, err := cache.AbortRollout("default/eg")
+	require.NoError(t, err)
+	require.Equal(t, RolloutPhaseAborted, aborted.Phase)
+	require.Equal(t, 1*time.Second, nodeTimeout(t, cache, "envoy-0"))
+
+	// Pinning a snapshot ends the rollout.
+	_, err = cache.RollbackSnapshot("default/eg")
+	require.NoError(t, err)
+	require.Empty(t, cache.Rollouts())
+}
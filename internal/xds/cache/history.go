@@ -0,0 +1,308 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// ErrSnapshotNotFound is returned when a snapshot is missing from the history of an IR key.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// SnapshotInfo describes a snapshot kept in the history of an IR key.
+type SnapshotInfo struct {
+	// ID identifies the snapshot, IDs increase with every new snapshot.
+	ID int64 `json:"id"`
+	// Generation is the generation of the Gateway API resources the snapshot is translated from.
+	Generation int64 `json:"generation"`
+	// CreatedAt is the time the snapshot was generated.
+	CreatedAt time.Time `json:"createdAt"`
+	// Versions holds the version of every resource type of the snapshot, keyed by type URL.
+	Versions map[string]string `json:"versions"`
+	// Restored is true if the snapshot was restored from disk on startup.
+	Restored bool `json:"restored,omitempty"`
+}
+
+// SnapshotHistory holds the snapshots kept for an IR key, from the oldest to the latest.
+type SnapshotHistory struct {
+	IRKey string `json:"irKey"`
+	// Pinned is the ID of the snapshot served to the nodes of the IR key instead of
+	// the latest one, or 0 if no snapshot is pinned.
+	Pinned    int64          `json:"pinned,omitempty"`
+	Snapshots []SnapshotInfo `json:"snapshots"`
+}
+
+// SnapshotDiff holds the differences between two snapshots of an IR key.
+type SnapshotDiff struct {
+	IRKey string `json:"irKey"`
+	From  int64  `json:"from"`
+	To    int64  `json:"to"`
+	// Resources holds the differences of every changed resource type, keyed by type URL.
+	Resources map[string]*ResourcesDiff `json:"resources,omitempty"`
+}
+
+// ResourcesDiff holds the names of the added, removed and changed resources of a
+// resource type, and the differences of the content of the changed resources.
+type ResourcesDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+	// Diff is a human readable report of the differences of the changed resources.
+	// It is never reported for the secrets, so that their key material isn't exposed.
+	Diff string `json:"diff,omitempty"`
+}
+
+// snapshotEntry is a snapshot kept in the history of an IR key.
+type snapshotEntry struct {
+	SnapshotInfo
+	snapshot *cachev3.Snapshot
+}
+
+// recordSnapshot adds the snapshot to the history of the IR key, evicting the
+// oldest snapshots which are not pinned once the history is full.
+// The caller must hold the lock.
+func (s *snapshotCache) recordSnapshot(irKey string, generation int64, snapshot *cachev3.Snapshot) {
+	s.lastID++
+	entry := &snapshotEntry{
+		SnapshotInfo: SnapshotInfo{
+			ID:         s.lastID,
+			Generation: generation,
+			CreatedAt:  time.Now(),
+			Versions:   make(map[string]string, len(snapshot.VersionMap)),
+		},
+		snapshot: snapshot,
+	}
+	for typeURL := range snapshot.VersionMap {
+		entry.Versions[typeURL] = snapshot.GetVersion(typeURL)
+	}
+
+	history := append(s.history[irKey], entry)
+	for i := 0; len(history) > s.historySize && i < len(history); {
+		if history[i] == s.pinned[irKey] {
+			i++
+			continue
+		}
+		history = append(history[:i], history[i+1:]...)
+	}
+	s.history[irKey] = history
+}
+
+// servedSnapshot returns the snapshot served to the nodes of the IR key, except
+// the canary nodes of a rollout.
+// The caller must hold the lock.
+func (s *snapshotCache) servedSnapshot(irKey string) *cachev3.Snapshot {
+	if pinned := s.pinned[irKey]; pinned != nil {
+		return pinned.snapshot
+	}
+	if r := s.rollouts[irKey]; r != nil {
+		return r.stable
+	}
+	return s.lastSnapshot[irKey]
+}
+
+// nodeSnapshot returns the snapshot served to the node of the IR key, which is the
+// snapshot of the rollout for the canary nodes of a rollout in progress.
+// The caller must hold the lock.
+func (s *snapshotCache) nodeSnapshot(irKey, nodeID string) *cachev3.Snapshot {
+	if r := s.rollouts[irKey]; r != nil && s.pinned[irKey] == nil && r.Phase != RolloutPhaseAborted {
+		if _, ok := r.nodes[nodeID]; ok {
+			return r.snapshot
+		}
+	}
+	return s.servedSnapshot(irKey)
+}
+
+// findSnapshot returns the snapshot of the IR key history with the given ID.
+// The caller must hold the lock.
+func (s *snapshotCache) findSnapshot(irKey string, id int64) (int, *snapshotEntry, error) {
+	for i, entry := range s.history[irKey] {
+		if entry.ID == id {
+			return i, entry, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("%w: %s/%d", ErrSnapshotNotFound, irKey, id)
+}
+
+// serveSnapshot sets the snapshot of all the nodes of the IR key.
+// The caller must hold the lock.
+func (s *snapshotCache) serveSnapshot(irKey string, snapshot *cachev3.Snapshot) error {
+	for _, node := range s.getNodeIDs(irKey) {
+		if err := s.SetSnapshot(context.TODO(), node, snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SnapshotHistories returns the snapshot history of every IR key, sorted by IR key.
+func (s *snapshotCache) SnapshotHistories() []SnapshotHistory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	histories := make([]SnapshotHistory, 0, len(s.history))
+	for irKey, entries := range s.history {
+		history := SnapshotHistory{
+			IRKey:     irKey,
+			Snapshots: make([]SnapshotInfo, 0, len(entries)),
+		}
+		if pinned := s.pinned[irKey]; pinned != nil {
+			history.Pinned = pinned.ID
+		}
+		for _, entry := range entries {
+			history.Snapshots = append(history.Snapshots, entry.SnapshotInfo)
+		}
+		histories = append(histories, history)
+	}
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].IRKey < histories[j].IRKey
+	})
+
+	return histories
+}
+
+// DiffSnapshots returns the differences between two snapshots of the IR key history.
+func (s *snapshotCache) DiffSnapshots(irKey string, from, to int64) (*SnapshotDiff, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, fromEntry, err := s.findSnapshot(irKey, from)
+	if err != nil {
+		return nil, err
+	}
+	_, toEntry, err := s.findSnapshot(irKey, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SnapshotDiff{
+		IRKey:     irKey,
+		From:      from,
+		To:        to,
+		Resources: make(map[string]*ResourcesDiff),
+	}
+	for typeURL, toVersions := range toEntry.snapshot.VersionMap {
+		fromVersions := fromEntry.snapshot.GetVersionMap(typeURL)
+		if resources := diffResources(typeURL, fromEntry.snapshot, toEntry.snapshot, fromVersions, toVersions); resources != nil {
+			diff.Resources[typeURL] = resources
+		}
+	}
+
+	return diff, nil
+}
+
+// diffResources returns the differences of the resources of a type between two
+// snapshots, or nil if they are the same.
+func diffResources(typeURL string, from, to *cachev3.Snapshot, fromVersions, toVersions map[string]string) *ResourcesDiff {
+	diff := &ResourcesDiff{}
+	fromResources, toResources := from.GetResources(typeURL), to.GetResources(typeURL)
+	for name, version := range toVersions {
+		fromVersion, ok := fromVersions[name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, name)
+		case fromVersion != version:
+			diff.Changed = append(diff.Changed, name)
+		}
+	}
+	for name := range fromVersions {
+		if _, ok := toVersions[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+		return nil
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	if typeURL == resourcev3.SecretType {
+		return diff
+	}
+	for _, name := range diff.Changed {
+		diff.Diff += fmt.Sprintf("%s:\n%s", name, cmp.Diff(fromResources[name], toResources[name], protocmp.Transform()))
+	}
+
+	return diff
+}
+
+// PinSnapshot serves the snapshot of the IR key history with the given ID to the
+// nodes of the IR key until the pin is released by UnpinSnapshot. The snapshots
+// generated in the meantime are still kept in the history.
+func (s *snapshotCache) PinSnapshot(irKey string, id int64) (*SnapshotInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, entry, err := s.findSnapshot(irKey, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pin(irKey, entry)
+}
+
+// RollbackSnapshot pins the snapshot generated before the one served to the nodes of the IR key.
+func (s *snapshotCache) RollbackSnapshot(irKey string) (*SnapshotInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.history[irKey]
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w: %s has no snapshot", ErrSnapshotNotFound, irKey)
+	}
+
+	served := len(history) - 1
+	if pinned := s.pinned[irKey]; pinned != nil {
+		served, _, _ = s.findSnapshot(irKey, pinned.ID)
+	}
+	if served == 0 {
+		return nil, fmt.Errorf("%w: %s has no snapshot before %d", ErrSnapshotNotFound, irKey, history[served].ID)
+	}
+
+	return s.pin(irKey, history[served-1])
+}
+
+// pin serves the snapshot entry to the nodes of the IR key.
+// The caller must hold the lock.
+func (s *snapshotCache) pin(irKey string, entry *snapshotEntry) (*SnapshotInfo, error) {
+	// A pinned snapshot is served to all the nodes, including the canary nodes.
+	s.stopRollout(irKey)
+	s.pinned[irKey] = entry
+	s.log.Infof("Pinning snapshot %d with generation %d for %s", entry.ID, entry.Generation, irKey)
+	if err := s.serveSnapshot(irKey, entry.snapshot); err != nil {
+		return nil, err
+	}
+
+	info := entry.SnapshotInfo
+	return &info, nil
+}
+
+// UnpinSnapshot releases the pinned snapshot of the IR key, and serves the latest
+// snapshot to the nodes of the IR key again.
+func (s *snapshotCache) UnpinSnapshot(irKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pinned[irKey] == nil {
+		return fmt.Errorf("%w: %s has no pinned snapshot", ErrSnapshotNotFound, irKey)
+	}
+	delete(s.pinned, irKey)
+	s.log.Infof("Unpinning snapshot for %s", irKey)
+
+	if snapshot := s.lastSnapshot[irKey]; snapshot != nil {
+		return s.serveSnapshot(irKey, snapshot)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package cache
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/require"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/logging"
+	"github.com/wukongcloud/gateway/internal/xds/types"
+)
+
+func newHistoryTestCache(t *testing.T, historySize int) *snapshotCache {
+	t.Helper()
+	logger := logging.DefaultLogger(os.Stdout, egv1a1.LogLevelInfo)
+	cache := NewSnapshotCache(true, historySize, logger).(*snapshotCache)
+	newTestNode(t, cache)
+	return cache
+}
+
+// newTestNode connects the envoy node of the default/eg IR key on the stream 1.
+func newTestNode(t *testing.T, cache *snapshotCache) {
+	t.Helper()
+	node := &corev3.Node{Id: "envoy", Cluster: "default/eg"}
+	require.NoError(t, cache.OnDeltaStreamOpen(context.Background(), 1, resourcev3.ClusterType))
+	require.NoError(t, cache.OnStreamDeltaRequest(1, &discoveryv3.DeltaDiscoveryRequest{Node: node}))
+}
+
+func generateClusters(t *testing.T, cache *snapshotCache, generation int64, timeout time.Duration) {
+	t.Helper()
+	require.NoError(t, cache.GenerateNewSnapshot("default/eg", generation, types.XdsResources{
+		resourcev3.ClusterType: {newCluster("a", time.Second), newCluster("b", timeout)},
+	}))
+}
+
+// servedTimeout returns the connect timeout of cluster b served to the node.
+func servedTimeout(t *testing.T, cache *snapshotCache) time.Duration {
+	t.Helper()
+	snapshot, err := cache.GetSnapshot("envoy")
+	require.NoError(t, err)
+	cluster := snapshot.GetResources(resourcev3.ClusterType)["b"]
+	require.NotNil(t, cluster)
+	return cluster.(*clusterv3.Cluster).GetConnectTimeout().AsDuration()
+}
+
+func TestSnapshotHistory(t *testing.T) {
+	cache := newHistoryTestCache(t, 3)
+
+	generateClusters(t, cache, 1, 1*time.Second)
+	// An unchanged snapshot isn't kept.
+	generateClusters(t, cache, 2, 1*time.Second)
+	generateClusters(t, cache, 3, 2*time.Second)
+	generateClusters(t, cache, 4, 3*time.Second)
+	generateClusters(t, cache, 5, 4*time.Second)
+
+	histories := cache.SnapshotHistories()
+	require.Len(t, histories, 1)
+	require.Equal(t, "default/eg", histories[0].IRKey)
+	require.Zero(t, histories[0].Pinned)
+
+	// The oldest snapshot is evicted.
+	var generations []int64
+	for _, snapshot := range histories[0].Snapshots {
+		generations = append(generations, snapshot.Generation)
+	}
+	require.Equal(t, []int64{3, 4, 5}, generations)
+
+	snapshots := histories[0].Snapshots
+	diff, err := cache.DiffSnapshots("default/eg", snapshots[0].ID, snapshots[2].ID)
+	require.NoError(t, err)
+	require.Len(t, diff.Resources, 1)
+	clusters := diff.Resources[resourcev3.ClusterType]
+	require.Equal(t, []string{"b"}, clusters.Changed)
+	require.Empty(t, clusters.Added)
+	require.Empty(t, clusters.Removed)
+	require.Contains(t, clusters.Diff, "connect_timeout")
+
+	_, err = cache.DiffSnapshots("default/eg", 1, snapshots[2].ID)
+	require.ErrorIs(t, err, ErrSnapshotNotFound)
+}
+
+func TestSnapshotDiffSecrets(t *testing.T) {
+	cache := newHistoryTestCache(t, 3)
+
+	generateSecret := func(generation int64, key string) {
+		require.NoError(t, cache.GenerateNewSnapshot("default/eg", generation, types.XdsResources{
+			resourcev3.SecretType: {&tlsv3.Secret{
+				Name: "default/eg/https",
+				Type: &tlsv3.Secret_TlsCertificate{TlsCertificate: &tlsv3.TlsCertificate{
+					CertificateChain: &corev3.DataSource{Specifier: &corev3.DataSource_InlineBytes{InlineBytes: []byte("certificate")}},
+					PrivateKey:       &corev3.DataSource{Specifier: &corev3.DataSource_InlineBytes{InlineBytes: []byte(key)}},
+				}},
+			}},
+		}))
+	}
+	generateSecret(1, "first-private-key")
+	generateSecret(2, "second-private-key")
+
+	snapshots := cache.SnapshotHistories()[0].Snapshots
+	diff, err := cache.DiffSnapshots("default/eg", snapshots[0].ID, snapshots[1].ID)
+	require.NoError(t, err)
+	secrets := diff.Resources[resourcev3.SecretType]
+	require.Equal(t, []string{"default/eg/https"}, secrets.Changed)
+	require.Empty(t, secrets.Diff)
+
+	data, err := json.Marshal(diff)
+	require.NoError(t, err)
+	for _, key := range []string{"first-private-key", "second-private-key"} {
+		require.NotContains(t, string(data), key)
+		require.NotContains(t, string(data), base64.StdEncoding.EncodeToString([]byte(key)))
+	}
+}
+
+func TestSnapshotPinAndRollback(t *testing.T) {
+	cache := newHistoryTestCache(t, 2)
+
+	generateClusters(t, cache, 1, 1*time.Second)
+	generateClusters(t, cache, 2, 2*time.Second)
+	require.Equal(t, 2*time.Second, servedTimeout(t, cache))
+
+	// Rolling back serves the previous snapshot.
+	info, err := cache.RollbackSnapshot("default/eg")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), info.Generation)
+	require.Equal(t, 1*time.Second, servedTimeout(t, cache))
+
+	// There is nothing before the first snapshot.
+	_, err = cache.RollbackSnapshot("default/eg")
+	require.ErrorIs(t, err, ErrSnapshotNotFound)
+
+	// New snapshots are kept but not served while pinned, and the pinned
+	// snapshot isn't evicted.
+	generateClusters(t, cache, 3, 3*time.Second)
+	generateClusters(t, cache, 4, 4*time.Second)
+	require.Equal(t, 1*time.Second, servedTimeout(t, cache))
+	histories := cache.SnapshotHistories()
+	require.Equal(t, info.ID, histories[0].Pinned)
+	require.Len(t, histories[0].Snapshots, 2)
+	require.Equal(t, info.ID, histories[0].Snapshots[0].ID)
+
+	// Pinning another snapshot serves it.
+	latest := histories[0].Snapshots[1]
+	_, err = cache.PinSnapshot("default/eg", latest.ID)
+	require.NoError(t, err)
+	require.Equal(t, 4*time.Second, servedTimeout(t, cache))
+	_, err = cache.PinSnapshot("default/eg", 100)
+	require.ErrorIs(t, err, ErrSnapshotNotFound)
+
+	// Releasing the pin serves the latest snapshot.
+	_, err = cache.RollbackSnapshot("default/eg")
+	require.NoError(t, err)
+	require.NoError(t, cache.UnpinSnapshot("default/eg"))
+	require.Equal(t, 4*time.Second, servedTimeout(t, cache))
+	require.ErrorIs(t, cache.UnpinSnapshot("default/eg"), ErrSnapshotNotFound)
+}
@@ -18,6 +18,26 @@ var (
 		"Total number of xds snapshot cache updates by node id.",
 	)
 
+	xdsSnapshotChangedResourcesTotal = metrics.NewCounter(
+		"xds_snapshot_changed_resources_total",
+		"Total number of xds resources added, updated or removed by the snapshot cache updates by type url.",
+	)
+
+	xdsNACKTotal = metrics.NewCounter(
+		"xds_nack_total",
+		"Total number of xds updates rejected by Envoy by node id and type url.",
+	)
+
+	xdsNACKActive = metrics.NewGauge(
+		"xds_nack_active",
+		"Current number of xds updates rejected by Envoy and not yet superseded by an accepted update by type url.",
+	)
+
+	xdsRolloutTotal = metrics.NewCounter(
+		"xds_rollout_total",
+		"Total number of canary xds snapshot rollouts promoted or aborted by result.",
+	)
+
 	xdsStreamDurationSeconds = metrics.NewHistogram(
 		"xds_stream_duration_seconds",
 		"How long a xds stream takes to finish.",
@@ -27,4 +47,6 @@ var (
 	nodeIDLabel        = metrics.NewLabel("nodeID")
 	streamIDLabel      = metrics.NewLabel("streamID")
 	isDeltaStreamLabel = metrics.NewLabel("isDeltaStream")
+	typeURLLabel       = metrics.NewLabel("typeURL")
+	resultLabel        = metrics.NewLabel("result")
 )
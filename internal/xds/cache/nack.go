@@ -0,0 +1,212 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package cache
+
+import (
+	"sort"
+	"strings"
+
+	envoytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"google.golang.org/genproto/googleapis/rpc/status"
+)
+
+// NACK is an xDS update rejected by an Envoy proxy.
+type NACK struct {
+	// NodeID is the ID of the Envoy proxy which rejected the update.
+	NodeID string
+	// TypeURL is the type URL of the rejected resources.
+	TypeURL string
+	// Message is the error detail reported by the Envoy proxy.
+	Message string
+	// Resources are the rejected resources, keyed by name.
+	//
+	// Envoy doesn't report which resources of an update are rejected, so these are the
+	// resources named in the error detail or, if none is named, the resources changed by
+	// the rejected snapshot. It's empty when neither of them can be found.
+	Resources map[string]envoytypes.Resource
+}
+
+// NACKHandler is called with all the NACKs of the Envoy proxies of an IR key whenever
+// they change. It's called with the cache lock held, so it must not call the cache.
+type NACKHandler func(irKey string, nacks []NACK)
+
+// nackKey identifies the NACK of a resource type by an Envoy proxy.
+type nackKey struct {
+	nodeID  string
+	typeURL string
+}
+
+// SetNACKHandler sets the handler called when the NACKs of an IR key change.
+func (s *snapshotCache) SetNACKHandler(handler NACKHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nackHandler = handler
+}
+
+// NACKs returns the NACKs of the Envoy proxies of the IR key, sorted by node ID and type URL.
+func (s *snapshotCache) NACKs(irKey string) []NACK {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.irKeyNACKs(irKey)
+}
+
+// irKeyNACKs returns the NACKs of the Envoy proxies of the IR key.
+// The caller must hold the lock.
+func (s *snapshotCache) irKeyNACKs(irKey string) []NACK {
+	nacks := make([]NACK, 0, len(s.nacks[irKey]))
+	for _, nack := range s.nacks[irKey] {
+		nacks = append(nacks, *nack)
+	}
+	sort.Slice(nacks, func(i, j int) bool {
+		if nacks[i].NodeID != nacks[j].NodeID {
+			return nacks[i].NodeID < nacks[j].NodeID
+		}
+		return nacks[i].TypeURL < nacks[j].TypeURL
+	})
+	return nacks
+}
+
+// handleResponseStatus tracks the ACK or NACK of an update of the resource type by the
+// Envoy proxy, carried by a discovery request. The version is the version of the resource
+// type of the update, it's empty if unknown.
+// The caller must hold the lock.
+func (s *snapshotCache) handleResponseStatus(irKey, nodeID, typeURL, responseNonce, version string, errorDetail *status.Status) {
+	key := nackKey{nodeID: nodeID, typeURL: typeURL}
+	if errorDetail == nil {
+		// Only the requests answering a response ACK an update.
+		if responseNonce == "" {
+			return
+		}
+		s.canaryAccepted(irKey, nodeID, typeURL, version)
+		if s.nacks[irKey][key] == nil {
+			return
+		}
+		delete(s.nacks[irKey], key)
+		s.log.Infof("Envoy %s accepted the %s update of %s", nodeID, typeURL, irKey)
+		s.nacksChanged(irKey)
+		return
+	}
+
+	xdsNACKTotal.With(nodeIDLabel.Value(nodeID), typeURLLabel.Value(typeURL)).Increment()
+	nack := &NACK{
+		NodeID:    nodeID,
+		TypeURL:   typeURL,
+		Message:   errorDetail.Message,
+		Resources: s.rejectedResources(irKey, nodeID, typeURL, errorDetail.Message),
+	}
+	if s.nacks[irKey] == nil {
+		s.nacks[irKey] = make(map[nackKey]*NACK)
+	}
+	s.nacks[irKey][key] = nack
+	s.canaryRejected(irKey, nodeID, typeURL, errorDetail.Message)
+	s.nacksChanged(irKey)
+}
+
+// rejectedResources returns the resources of the type rejected by the node with the error message.
+// The caller must hold the lock.
+func (s *snapshotCache) rejectedResources(irKey, nodeID, typeURL, message string) map[string]envoytypes.Resource {
+	served := s.servedSnapshot(irKey)
+	if snapshot, err := s.GetSnapshot(nodeID); err == nil {
+		// The canary nodes of a rollout are served another snapshot.
+		if snapshot, ok := snapshot.(*cachev3.Snapshot); ok {
+			served = snapshot
+		}
+	}
+	if served == nil {
+		return nil
+	}
+	resources := served.GetResources(typeURL)
+
+	// The resources named in the error detail.
+	rejected := make(map[string]envoytypes.Resource)
+	for name, resource := range resources {
+		if name != "" && strings.Contains(message, name) {
+			rejected[name] = resource
+		}
+	}
+	if len(rejected) > 0 {
+		return rejected
+	}
+
+	// The resources changed by the served snapshot.
+	previous := s.previousSnapshot(irKey, served)
+	if previous == nil {
+		return nil
+	}
+	previousVersions := previous.GetVersionMap(typeURL)
+	for name, version := range served.GetVersionMap(typeURL) {
+		if previousVersions[name] != version {
+			rejected[name] = resources[name]
+		}
+	}
+	return rejected
+}
+
+// previousSnapshot returns the snapshot kept in the history of the IR key before the given one.
+// The caller must hold the lock.
+func (s *snapshotCache) previousSnapshot(irKey string, snapshot *cachev3.Snapshot) *cachev3.Snapshot {
+	history := s.history[irKey]
+	for i := len(history) - 1; i > 0; i-- {
+		if history[i].snapshot == snapshot {
+			return history[i-1].snapshot
+		}
+	}
+	return nil
+}
+
+// removeNodeNACKs removes the NACKs of the Envoy proxy, since it's disconnected.
+// The caller must hold the lock.
+func (s *snapshotCache) removeNodeNACKs(nodeID string) {
+	for irKey, nacks := range s.nacks {
+		changed := false
+		for key := range nacks {
+			if key.nodeID == nodeID {
+				delete(nacks, key)
+				changed = true
+			}
+		}
+		if changed {
+			s.nacksChanged(irKey)
+		}
+	}
+}
+
+// nacksChanged records the NACK metrics and calls the NACK handler for the IR key.
+// The caller must hold the lock.
+func (s *snapshotCache) nacksChanged(irKey string) {
+	nacks := s.irKeyNACKs(irKey)
+	if len(nacks) == 0 {
+		delete(s.nacks, irKey)
+	}
+
+	active := make(map[string]int)
+	for _, irKeyNACKs := range s.nacks {
+		for key := range irKeyNACKs {
+			active[key.typeURL]++
+		}
+	}
+	for _, typeURL := range resourceTypeURLs() {
+		xdsNACKActive.With(typeURLLabel.Value(typeURL)).Record(float64(active[typeURL]))
+	}
+
+	if s.nackHandler != nil {
+		s.nackHandler(irKey, nacks)
+	}
+}
+
+// resourceTypeURLs returns the type URLs of all the resource types.
+func resourceTypeURLs() []string {
+	var typeURLs []string
+	for i := envoytypes.ResponseType(0); i < envoytypes.UnknownType; i++ {
+		if typeURL, err := cachev3.GetResponseTypeURL(i); err == nil {
+			typeURLs = append(typeURLs, typeURL)
+		}
+	}
+	return typeURLs
+}
@@ -0,0 +1,83 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/status"
+)
+
+func TestNACKs(t *testing.T) {
+	cache := newHistoryTestCache(t, 2)
+	var handled []NACK
+	cache.SetNACKHandler(func(irKey string, nacks []NACK) {
+		require.Equal(t, "default/eg", irKey)
+		handled = nacks
+	})
+
+	generateClusters(t, cache, 1, 1*time.Second)
+	generateClusters(t, cache, 2, 2*time.Second)
+
+	nack := func(message string) {
+		require.NoError(t, cache.OnStreamDeltaRequest(1, &discoveryv3.DeltaDiscoveryRequest{
+			TypeUrl:       resourcev3.ClusterType,
+			ResponseNonce: "1",
+			ErrorDetail:   &status.Status{Code: 13, Message: message},
+		}))
+	}
+
+	// The resources named in the error detail are rejected.
+	nack("cluster a")
+	require.Len(t, handled, 1)
+	require.Equal(t, "envoy", handled[0].NodeID)
+	require.Equal(t, resourcev3.ClusterType, handled[0].TypeURL)
+	require.Equal(t, "cluster a", handled[0].Message)
+	require.Contains(t, handled[0].Resources, "a")
+	require.Len(t, handled[0].Resources, 1)
+
+	// The resources changed by the snapshot are rejected otherwise.
+	nack("rejected")
+	require.Len(t, handled, 1)
+	require.Contains(t, handled[0].Resources, "b")
+	require.Len(t, handled[0].Resources, 1)
+	require.Equal(t, handled, cache.NACKs("default/eg"))
+
+	// Requests which don't answer a response don't ACK an update.
+	require.NoError(t, cache.OnStreamDeltaRequest(1, &discoveryv3.DeltaDiscoveryRequest{TypeUrl: resourcev3.ClusterType}))
+	require.Len(t, cache.NACKs("default/eg"), 1)
+
+	// An ACK clears the NACK.
+	require.NoError(t, cache.OnStreamDeltaRequest(1, &discoveryv3.DeltaDiscoveryRequest{TypeUrl: resourcev3.ClusterType, ResponseNonce: "2"}))
+	require.Empty(t, handled)
+	require.Empty(t, cache.NACKs("default/eg"))
+
+	// Closing the stream clears the NACKs of the node.
+	nack("rejected")
+	require.Len(t, handled, 1)
+	cache.OnDeltaStreamClosed(1, &corev3.Node{Id: "envoy", Cluster: "default/eg"})
+	require.Empty(t, handled)
+	require.Empty(t, cache.NACKs("default/eg"))
+}
+
+func TestNACKsWithoutSnapshot(t *testing.T) {
+	cache := newHistoryTestCache(t, 2)
+	require.NoError(t, cache.OnStreamOpen(context.Background(), 2, resourcev3.ListenerType))
+	require.NoError(t, cache.OnStreamRequest(2, &discoveryv3.DiscoveryRequest{
+		Node:          &corev3.Node{Id: "other", Cluster: "default/other"},
+		TypeUrl:       resourcev3.ListenerType,
+		ResponseNonce: "1",
+		ErrorDetail:   &status.Status{Code: 13, Message: "invalid"},
+	}))
+	// No snapshot has been generated for the node, so there is nothing to reject yet.
+	require.Empty(t, cache.NACKs("default/other"))
+}
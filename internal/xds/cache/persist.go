@@ -0,0 +1,208 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package cache
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	envoytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/wukongcloud/gateway/internal/xds/types"
+)
+
+// snapshotFileExt is the extension of the files the snapshots are persisted into.
+const snapshotFileExt = ".pb"
+
+// PersistSnapshots persists the latest snapshot of every IR key into the directory
+// from now on, and restores the snapshots persisted there before, which are served
+// to the nodes of their IR keys until new snapshots are generated. It returns the IR
+// keys of the restored snapshots.
+//
+// The restored snapshots of the IR keys which are gone by the time the resources
+// are translated, e.g. of the Gateways deleted while Envoy Gateway was down, are
+// dropped by DropRestoredSnapshots.
+//
+// A snapshot which can't be restored is skipped, so that a corrupted file doesn't
+// keep the nodes from getting their configuration once the resources are translated.
+func (s *snapshotCache) PersistSnapshots(dir string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	s.persistDir = dir
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var restored []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), snapshotFileExt) {
+			continue
+		}
+		irKey, err := url.PathUnescape(strings.TrimSuffix(entry.Name(), snapshotFileExt))
+		if err != nil {
+			s.log.Errorf("Skipped restoring snapshot file %s: %v", entry.Name(), err)
+			continue
+		}
+		if s.lastSnapshot[irKey] != nil {
+			// A snapshot has already been generated.
+			continue
+		}
+
+		snapshot, err := readSnapshot(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			s.log.Errorf("Skipped restoring the snapshot of %s: %v", irKey, err)
+			continue
+		}
+		s.recordSnapshot(irKey, 0, snapshot)
+		s.history[irKey][len(s.history[irKey])-1].Restored = true
+		s.lastSnapshot[irKey] = snapshot
+		s.restored[irKey] = true
+		restored = append(restored, irKey)
+	}
+	sort.Strings(restored)
+
+	return restored, nil
+}
+
+// DropRestoredSnapshots drops the restored snapshots which haven't been replaced
+// by generated ones, except those of the IR keys kept, and removes their persisted
+// files. It returns the IR keys of the dropped snapshots.
+//
+// It is called once the resources have been translated, after which the restored
+// snapshots that are kept are expected to be replaced shortly.
+func (s *snapshotCache) DropRestoredSnapshots(keep func(irKey string) bool) []string {
+	s.mu.Lock()
+	var dropped []string
+	for irKey := range s.restored {
+		if keep(irKey) {
+			continue
+		}
+		for _, node := range s.getNodeIDs(irKey) {
+			s.ClearSnapshot(node)
+		}
+		delete(s.lastSnapshot, irKey)
+		delete(s.history, irKey)
+		delete(s.pinned, irKey)
+		dropped = append(dropped, irKey)
+	}
+	clear(s.restored)
+	dir := s.persistDir
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+	s.mu.Unlock()
+
+	sort.Strings(dropped)
+	for _, irKey := range dropped {
+		persistSnapshot(s.log, dir, irKey, nil)
+	}
+	return dropped
+}
+
+// persistSnapshot writes the snapshot of the IR key into the persistence directory,
+// or removes the persisted snapshot if the snapshot is nil.
+//
+// The file I/O isn't done under the lock, so that it doesn't hold up the xDS streams.
+// The caller must hold persistMu instead, taken before releasing the lock, so that
+// the snapshots of an IR key are written in the order they are generated.
+func persistSnapshot(log *zap.SugaredLogger, dir, irKey string, snapshot *cachev3.Snapshot) {
+	if dir == "" {
+		return
+	}
+
+	path := filepath.Join(dir, url.PathEscape(irKey)+snapshotFileExt)
+	if snapshot == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Errorf("Failed to remove the persisted snapshot of %s: %v", irKey, err)
+		}
+		return
+	}
+	if err := writeSnapshot(path, snapshot); err != nil {
+		log.Errorf("Failed to persist the snapshot of %s: %v", irKey, err)
+	}
+}
+
+// writeSnapshot writes the resources of the snapshot, as a discovery response holding
+// all of them, into the file in an atomic way, so a restart never reads a partial file.
+func writeSnapshot(path string, snapshot *cachev3.Snapshot) error {
+	response := &discoveryv3.DiscoveryResponse{}
+	typeURLs := make([]string, 0, len(snapshot.VersionMap))
+	for typeURL := range snapshot.VersionMap {
+		typeURLs = append(typeURLs, typeURL)
+	}
+	sort.Strings(typeURLs)
+	for _, typeURL := range typeURLs {
+		resources := snapshot.GetResources(typeURL)
+		names := make([]string, 0, len(resources))
+		for name := range resources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			resource, err := anypb.New(resources[name])
+			if err != nil {
+				return err
+			}
+			response.Resources = append(response.Resources, resource)
+		}
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readSnapshot reads a snapshot written by writeSnapshot.
+func readSnapshot(path string) (*cachev3.Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	response := &discoveryv3.DiscoveryResponse{}
+	if err := proto.Unmarshal(data, response); err != nil {
+		return nil, err
+	}
+
+	resources := make(types.XdsResources)
+	for _, resource := range response.Resources {
+		message, err := resource.UnmarshalNew()
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s resource: %w", resource.TypeUrl, err)
+		}
+		resources[resource.TypeUrl] = append(resources[resource.TypeUrl], envoytypes.Resource(message))
+	}
+	return newVersionedSnapshot(resources)
+}
@@ -0,0 +1,93 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/require"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/logging"
+)
+
+func TestPersistSnapshots(t *testing.T) {
+	dir := t.TempDir()
+
+	// Snapshots are persisted as they are generated.
+	cache := newHistoryTestCache(t, 2)
+	restored, err := cache.PersistSnapshots(dir)
+	require.NoError(t, err)
+	require.Empty(t, restored)
+	generateClusters(t, cache, 1, 2*time.Second)
+	require.FileExists(t, filepath.Join(dir, "default%2Feg.pb"))
+	require.NoError(t, cache.GenerateNewSnapshot("default/deleted", 1, nil))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "default%2Fcorrupted.pb"), []byte("corrupted"), 0o600))
+	persisted, err := cache.GetSnapshot("envoy")
+	require.NoError(t, err)
+
+	// A restarted cache serves the persisted snapshot right away.
+	cache = NewSnapshotCache(true, 2, logging.DefaultLogger(os.Stdout, egv1a1.LogLevelInfo)).(*snapshotCache)
+	restored, err = cache.PersistSnapshots(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"default/eg"}, restored)
+	require.True(t, cache.SnapshotHasIrKey("default/eg"))
+	histories := cache.SnapshotHistories()
+	require.Len(t, histories, 1)
+	require.True(t, histories[0].Snapshots[0].Restored)
+
+	newTestNode(t, cache)
+	require.Equal(t, 2*time.Second, servedTimeout(t, cache))
+	snapshot, err := cache.GetSnapshot("envoy")
+	require.NoError(t, err)
+	require.Equal(t, persisted.GetVersion(resourcev3.ClusterType), snapshot.GetVersion(resourcev3.ClusterType))
+
+	// The translated snapshot replaces the restored one, and the persisted snapshot
+	// is removed along with the IR key.
+	generateClusters(t, cache, 1, 3*time.Second)
+	require.Equal(t, 3*time.Second, servedTimeout(t, cache))
+	require.NoError(t, cache.GenerateNewSnapshot("default/eg", 2, nil))
+	require.NoFileExists(t, filepath.Join(dir, "default%2Feg.pb"))
+}
+
+func TestDropRestoredSnapshots(t *testing.T) {
+	dir := t.TempDir()
+
+	cache := newHistoryTestCache(t, 2)
+	_, err := cache.PersistSnapshots(dir)
+	require.NoError(t, err)
+	generateClusters(t, cache, 1, 2*time.Second)
+	data, err := os.ReadFile(filepath.Join(dir, "default%2Feg.pb"))
+	require.NoError(t, err)
+	for _, name := range []string{"default%2Fgone.pb", "default%2Fkept.pb"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+	}
+
+	cache = NewSnapshotCache(true, 2, logging.DefaultLogger(os.Stdout, egv1a1.LogLevelInfo)).(*snapshotCache)
+	restored, err := cache.PersistSnapshots(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"default/eg", "default/gone", "default/kept"}, restored)
+
+	// The refreshed snapshots and the snapshots of the kept IR keys aren't dropped.
+	generateClusters(t, cache, 1, 3*time.Second)
+	dropped := cache.DropRestoredSnapshots(func(irKey string) bool { return irKey == "default/kept" })
+	require.Equal(t, []string{"default/gone"}, dropped)
+	require.False(t, cache.SnapshotHasIrKey("default/gone"))
+	require.True(t, cache.SnapshotHasIrKey("default/eg"))
+	require.True(t, cache.SnapshotHasIrKey("default/kept"))
+	require.Len(t, cache.SnapshotHistories(), 2)
+	require.NoFileExists(t, filepath.Join(dir, "default%2Fgone.pb"))
+	require.FileExists(t, filepath.Join(dir, "default%2Feg.pb"))
+	require.FileExists(t, filepath.Join(dir, "default%2Fkept.pb"))
+
+	// The snapshots are only dropped once.
+	require.Empty(t, cache.DropRestoredSnapshots(func(string) bool { return false }))
+	require.True(t, cache.SnapshotHasIrKey("default/kept"))
+}
@@ -16,16 +16,19 @@ package cache
 import (
 	"context"
 	"fmt"
-	"math"
+	"hash/fnv"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	envoytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
 	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
 	serverv3 "github.com/envoyproxy/go-control-plane/pkg/server/v3"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/wukongcloud/gateway/internal/logging"
 	"github.com/wukongcloud/gateway/internal/metrics"
@@ -46,9 +49,35 @@ var Hash = cachev3.IDHash{}
 type SnapshotCacheWithCallbacks interface {
 	cachev3.SnapshotCache
 	serverv3.Callbacks
-	GenerateNewSnapshot(string, types.XdsResources) error
+	GenerateNewSnapshot(string, int64, types.XdsResources) error
 	SnapshotHasIrKey(string) bool
 	GetIrKeys() []string
+
+	// SnapshotHistories, DiffSnapshots and the *Snapshot functions give access to
+	// the snapshots kept for every IR key, see history.go.
+	SnapshotHistories() []SnapshotHistory
+	DiffSnapshots(irKey string, from, to int64) (*SnapshotDiff, error)
+	PinSnapshot(irKey string, id int64) (*SnapshotInfo, error)
+	RollbackSnapshot(irKey string) (*SnapshotInfo, error)
+	UnpinSnapshot(irKey string) error
+
+	// SetNACKHandler and NACKs give access to the xDS updates rejected by the
+	// Envoy proxies, see nack.go.
+	SetNACKHandler(NACKHandler)
+	NACKs(irKey string) []NACK
+
+	// SetCanaryRollout and the *Rollout functions control the canary rollout of
+	// the snapshots to the Envoy proxies, see canary.go.
+	SetCanaryRollout(*CanaryRollout)
+	Rollouts() []Rollout
+	PromoteRollout(irKey string) (*Rollout, error)
+	AbortRollout(irKey string) (*Rollout, error)
+
+	// PersistSnapshots persists the snapshots into a directory, and restores the
+	// snapshots persisted there, which DropRestoredSnapshots drops once they are
+	// stale, see persist.go.
+	PersistSnapshots(dir string) ([]string, error)
+	DropRestoredSnapshots(keep func(irKey string) bool) []string
 }
 
 type snapshotMap map[string]*cachev3.Snapshot
@@ -57,78 +86,244 @@ type nodeInfoMap map[int64]*corev3.Node
 
 type streamDurationMap map[int64]time.Time
 
+// deltaResponseVersion is the nonce and the version of the last delta response of a resource type.
+type deltaResponseVersion struct {
+	nonce   string
+	version string
+}
+
+type deltaResponseVersionMap map[int64]map[string]deltaResponseVersion
+
 type snapshotCache struct {
 	cachev3.SnapshotCache
 	streamIDNodeInfo    nodeInfoMap
 	streamDuration      streamDurationMap
 	deltaStreamDuration streamDurationMap
-	snapshotVersion     int64
+	deltaResponses      deltaResponseVersionMap
 	lastSnapshot        snapshotMap
+	history             map[string][]*snapshotEntry
+	pinned              map[string]*snapshotEntry
+	historySize         int
+	lastID              int64
+	nacks               map[string]map[nackKey]*NACK
+	nackHandler         NACKHandler
+	canary              *CanaryRollout
+	rollouts            map[string]*rollout
+	persistDir          string
+	persistMu           sync.Mutex
+	restored            map[string]bool
 	log                 *zap.SugaredLogger
 	mu                  sync.Mutex
 }
 
 // GenerateNewSnapshot takes a table of resources (the output from the IR->xDS
-// translator) and updates the snapshot version.
-func (s *snapshotCache) GenerateNewSnapshot(irKey string, resources types.XdsResources) error {
+// translator) and updates the snapshot of the nodes of the IR key.
+//
+// Every resource is versioned by a hash of its content, and every resource type
+// by a hash of the versions of its resources. The versions are the version map the
+// delta xDS server compares with the versions acknowledged by every Envoy, so the
+// delta streams only receive the added, updated and removed resources, and the
+// state-of-the-world streams only the changed resource types. They are computed once
+// per update, rather than by go-control-plane for every snapshot it serves, and kept
+// in the history and the persisted snapshots.
+//
+// The snapshot is kept in the history of the IR key along with the generation of
+// the Gateway API resources it is translated from. While a snapshot of the IR key
+// is pinned, the new snapshot is only kept in the history.
+//
+// With the canary rollout enabled, a changed snapshot is first only served to the
+// canary nodes of the IR key, and the other nodes keep the snapshot served before.
+//
+// With the persistence enabled, the changed snapshot is also written to disk, so that
+// it can be served right away after a restart.
+func (s *snapshotCache) GenerateNewSnapshot(irKey string, generation int64, resources types.XdsResources) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	recorded, err := s.generateNewSnapshot(irKey, generation, resources)
+	var persisted *cachev3.Snapshot
+	if resources != nil {
+		persisted = s.lastSnapshot[irKey]
+	}
+	dir := s.persistDir
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+	s.mu.Unlock()
 
-	version := s.newSnapshotVersion()
+	if recorded {
+		persistSnapshot(s.log, dir, irKey, persisted)
+	}
+	return err
+}
+
+// generateNewSnapshot updates the snapshot of the nodes of the IR key, see
+// GenerateNewSnapshot. It returns true if the snapshot is recorded in the history,
+// which is when it has to be persisted.
+// The caller must hold the lock.
+func (s *snapshotCache) generateNewSnapshot(irKey string, generation int64, resources types.XdsResources) (bool, error) {
+	delete(s.restored, irKey)
 
 	// Create a snapshot with all xDS resources.
-	snapshot, err := cachev3.NewSnapshot(
-		version,
-		resources,
-	)
+	snapshot, err := newVersionedSnapshot(resources)
 	if err != nil {
 		xdsSnapshotCreateTotal.WithFailure(metrics.ReasonError).Increment()
-		return err
+		return false, err
 	}
 	xdsSnapshotCreateTotal.WithSuccess().Increment()
 
+	served := s.servedSnapshot(irKey)
+	changed := changedResources(s.lastSnapshot[irKey], snapshot)
+	for typeURL, count := range changed {
+		s.log.Debugf("Generating a snapshot for %s with %d changed %s resources", irKey, count, typeURL)
+		xdsSnapshotChangedResourcesTotal.With(typeURLLabel.Value(typeURL)).Add(float64(count))
+	}
+
+	// Only keep the snapshots that differ from the previous one in the history.
+	recorded := len(changed) > 0 || len(s.history[irKey]) == 0
+	if recorded {
+		s.recordSnapshot(irKey, generation, snapshot)
+	}
 	s.lastSnapshot[irKey] = snapshot
 
+	if pinned := s.pinned[irKey]; pinned != nil {
+		s.log.Infof("Snapshot %d is pinned for %s, skipped updating the nodes", pinned.ID, irKey)
+		return recorded, nil
+	}
+
+	if s.canary != nil {
+		if rolledOut, err := s.rolloutSnapshot(irKey, served, len(changed) > 0); rolledOut || err != nil {
+			return recorded, err
+		}
+	}
+
 	for _, node := range s.getNodeIDs(irKey) {
 		s.log.Debugf("Generating a snapshot with Node %s", node)
 
 		if err = s.SetSnapshot(context.TODO(), node, snapshot); err != nil {
 			xdsSnapshotUpdateTotal.WithFailure(metrics.ReasonError, nodeIDLabel.Value(node)).Increment()
-			return err
+			return recorded, err
 		} else {
 			xdsSnapshotUpdateTotal.WithSuccess(nodeIDLabel.Value(node)).Increment()
 		}
 	}
 
-	return nil
+	return recorded, nil
+}
+
+// newVersionedSnapshot creates a snapshot of the resources, with the version of
+// every resource computed from its content, and the version of every resource
+// type computed from the names and versions of its resources.
+func newVersionedSnapshot(resources types.XdsResources) (*cachev3.Snapshot, error) {
+	snapshot := &cachev3.Snapshot{
+		VersionMap: make(map[string]map[string]string, len(resources)),
+	}
+
+	// The resources are marshaled into the same buffer and hashed with FNV-1a, so
+	// versioning them costs little more than marshaling them once.
+	var buf []byte
+	marshal := proto.MarshalOptions{Deterministic: true}
+
+	for typeURL, items := range resources {
+		index := cachev3.GetResponseType(typeURL)
+		if index == envoytypes.UnknownType {
+			return nil, fmt.Errorf("unknown resource type: %s", typeURL)
+		}
+
+		versions := make(map[string]string, len(items))
+		for _, item := range items {
+			var err error
+			if buf, err = marshal.MarshalAppend(buf[:0], item); err != nil {
+				return nil, fmt.Errorf("failed to marshal %s resource: %w", typeURL, err)
+			}
+			versions[cachev3.GetResourceName(item)] = hashVersion(buf)
+		}
+
+		snapshot.Resources[index] = cachev3.NewResources(typeVersion(versions), items)
+		snapshot.VersionMap[typeURL] = versions
+	}
+
+	// The version maps of the resource types missing from the resources are
+	// expected by the delta xDS responses as well.
+	for i := range snapshot.Resources {
+		typeURL, err := cachev3.GetResponseTypeURL(envoytypes.ResponseType(i))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := snapshot.VersionMap[typeURL]; !ok {
+			snapshot.VersionMap[typeURL] = map[string]string{}
+		}
+	}
+
+	return snapshot, nil
 }
 
-// newSnapshotVersion increments the current snapshotVersion
-// and returns as a string.
-func (s *snapshotCache) newSnapshotVersion() string {
-	// Reset the snapshotVersion if it ever hits max size.
-	if s.snapshotVersion == math.MaxInt64 {
-		s.snapshotVersion = 0
+// typeVersion returns the version of a resource type from the versions of its resources.
+func typeVersion(versions map[string]string) string {
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+
+	var b []byte
+	for _, name := range names {
+		b = append(b, name...)
+		b = append(b, '=')
+		b = append(b, versions[name]...)
+		b = append(b, '\n')
+	}
+	return hashVersion(b)
+}
+
+// hashVersion returns a version from the hash of the content.
+func hashVersion(content []byte) string {
+	h := fnv.New64a()
+	_, _ = h.Write(content)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
 
-	// Increment the snapshot version & return as string.
-	s.snapshotVersion++
-	return strconv.FormatInt(s.snapshotVersion, 10)
+// changedResources returns the number of added, updated and removed resources
+// of every resource type between two snapshots.
+func changedResources(last, snapshot *cachev3.Snapshot) map[string]int {
+	changed := make(map[string]int)
+	for typeURL, versions := range snapshot.VersionMap {
+		var lastVersions map[string]string
+		if last != nil {
+			lastVersions = last.GetVersionMap(typeURL)
+		}
+		for name, version := range versions {
+			if lastVersions[name] != version {
+				changed[typeURL]++
+			}
+		}
+		for name := range lastVersions {
+			if _, ok := versions[name]; !ok {
+				changed[typeURL]++
+			}
+		}
+	}
+	return changed
 }
 
-// NewSnapshotCache gives you a fresh SnapshotCache.
+// NewSnapshotCache gives you a fresh SnapshotCache, which keeps the last
+// historySize snapshots of every IR key.
 // It needs a logger that supports the go-control-plane
 // required interface (Debugf, Infof, Warnf, and Errorf).
-func NewSnapshotCache(ads bool, logger logging.Logger) SnapshotCacheWithCallbacks {
+func NewSnapshotCache(ads bool, historySize int, logger logging.Logger) SnapshotCacheWithCallbacks {
 	// Set up the nasty wrapper hack.
 	wrappedLogger := logger.Sugar()
 	return &snapshotCache{
 		SnapshotCache:       cachev3.NewSnapshotCache(ads, &Hash, wrappedLogger),
 		log:                 wrappedLogger,
 		lastSnapshot:        make(snapshotMap),
+		history:             make(map[string][]*snapshotEntry),
+		pinned:              make(map[string]*snapshotEntry),
+		historySize:         historySize,
+		nacks:               make(map[string]map[nackKey]*NACK),
+		rollouts:            make(map[string]*rollout),
+		restored:            make(map[string]bool),
 		streamIDNodeInfo:    make(nodeInfoMap),
 		streamDuration:      make(streamDurationMap),
 		deltaStreamDuration: make(streamDurationMap),
+		deltaResponses:      make(deltaResponseVersionMap),
 	}
 }
 
@@ -173,6 +368,9 @@ func (s *snapshotCache) OnStreamClosed(streamID int64, node *corev3.Node) {
 
 	delete(s.streamIDNodeInfo, streamID)
 	delete(s.streamDuration, streamID)
+	if node != nil {
+		s.removeNodeNACKs(node.Id)
+	}
 }
 
 func (s *snapshotCache) OnStreamRequest(streamID int64, req *discoveryv3.DiscoveryRequest) error {
@@ -207,7 +405,7 @@ func (s *snapshotCache) OnStreamRequest(streamID int64, req *discoveryv3.Discove
 
 	_, err := s.GetSnapshot(nodeID)
 	if err != nil {
-		err = s.SetSnapshot(context.TODO(), nodeID, s.lastSnapshot[cluster])
+		err = s.SetSnapshot(context.TODO(), nodeID, s.nodeSnapshot(cluster, nodeID))
 		if err != nil {
 			return err
 		}
@@ -223,10 +421,10 @@ func (s *snapshotCache) OnStreamRequest(streamID int64, req *discoveryv3.Discove
 
 	if status := req.ErrorDetail; status != nil {
 		// if Envoy rejected the last update log the details here.
-		// TODO(youngnick): Handle NACK properly
 		errorCode = status.Code
 		errorMessage = status.Message
 	}
+	s.handleResponseStatus(cluster, nodeID, req.GetTypeUrl(), req.ResponseNonce, req.VersionInfo, req.ErrorDetail)
 
 	s.log.Debugf("handling v3 xDS resource request, version_info %s, response_nonce %s, nodeID %s, node_version %s, resource_names %v, type_url %s, errorCode %d, errorMessage %s",
 		req.VersionInfo, req.ResponseNonce,
@@ -280,6 +478,10 @@ func (s *snapshotCache) OnDeltaStreamClosed(streamID int64, node *corev3.Node) {
 
 	delete(s.streamIDNodeInfo, streamID)
 	delete(s.deltaStreamDuration, streamID)
+	delete(s.deltaResponses, streamID)
+	if node != nil {
+		s.removeNodeNACKs(node.Id)
+	}
 }
 
 func (s *snapshotCache) OnStreamDeltaRequest(streamID int64, req *discoveryv3.DeltaDiscoveryRequest) error {
@@ -315,7 +517,7 @@ func (s *snapshotCache) OnStreamDeltaRequest(streamID int64, req *discoveryv3.De
 
 	_, err := s.GetSnapshot(nodeID)
 	if err != nil {
-		err = s.SetSnapshot(context.TODO(), nodeID, s.lastSnapshot[cluster])
+		err = s.SetSnapshot(context.TODO(), nodeID, s.nodeSnapshot(cluster, nodeID))
 		if err != nil {
 			return err
 		}
@@ -331,10 +533,15 @@ func (s *snapshotCache) OnStreamDeltaRequest(streamID int64, req *discoveryv3.De
 		req.ResponseNonce, nodeID, nodeVersion)
 	if status := req.ErrorDetail; status != nil {
 		// if Envoy rejected the last update log the details here.
-		// TODO(youngnick): Handle NACK properly
 		errorCode = status.Code
 		errorMessage = status.Message
 	}
+	// The delta requests don't carry the version they answer, it's the version of the response with the nonce.
+	var version string
+	if resp := s.deltaResponses[streamID][req.GetTypeUrl()]; resp.nonce == req.ResponseNonce {
+		version = resp.version
+	}
+	s.handleResponseStatus(cluster, nodeID, req.GetTypeUrl(), req.ResponseNonce, version, req.ErrorDetail)
 	s.log.Debugf("handling v3 xDS resource request, response_nonce %s, nodeID %s, node_version %s, resource_names_subscribe %v, resource_names_unsubscribe %v, type_url %s, errorCode %d, errorMessage %s",
 		req.ResponseNonce,
 		nodeID, nodeVersion,
@@ -349,8 +556,16 @@ func (s *snapshotCache) OnStreamDeltaRequest(streamID int64, req *discoveryv3.De
 	return nil
 }
 
-func (s *snapshotCache) OnStreamDeltaResponse(streamID int64, _ *discoveryv3.DeltaDiscoveryRequest, _ *discoveryv3.DeltaDiscoveryResponse) {
-	// No mutex lock required here because no writing to the cache.
+func (s *snapshotCache) OnStreamDeltaResponse(streamID int64, _ *discoveryv3.DeltaDiscoveryRequest, resp *discoveryv3.DeltaDiscoveryResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Keep the version of the response, which the requests ACKing or NACKing it refer to by nonce.
+	if s.deltaResponses[streamID] == nil {
+		s.deltaResponses[streamID] = make(map[string]deltaResponseVersion)
+	}
+	s.deltaResponses[streamID][resp.GetTypeUrl()] = deltaResponseVersion{nonce: resp.GetNonce(), version: resp.GetSystemVersionInfo()}
+
 	node := s.streamIDNodeInfo[streamID]
 	if node == nil {
 		s.log.Errorf("Tried to send a response to a node we haven't seen yet on stream %d", streamID)
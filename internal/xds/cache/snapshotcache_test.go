@@ -0,0 +1,130 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package cache
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	serverv3 "github.com/envoyproxy/go-control-plane/pkg/server/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/logging"
+	"github.com/wukongcloud/gateway/internal/xds/types"
+)
+
+func newCluster(name string, timeout time.Duration) *clusterv3.Cluster {
+	return &clusterv3.Cluster{Name: name, ConnectTimeout: durationpb.New(timeout)}
+}
+
+func TestNewVersionedSnapshot(t *testing.T) {
+	first, err := newVersionedSnapshot(types.XdsResources{
+		resourcev3.ClusterType: {newCluster("a", time.Second), newCluster("b", time.Second)},
+	})
+	require.NoError(t, err)
+
+	// The same resources have the same versions.
+	same, err := newVersionedSnapshot(types.XdsResources{
+		resourcev3.ClusterType: {newCluster("b", time.Second), newCluster("a", time.Second)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, first.VersionMap, same.VersionMap)
+	require.Equal(t, first.GetVersion(resourcev3.ClusterType), same.GetVersion(resourcev3.ClusterType))
+	require.Empty(t, changedResources(first, same))
+
+	// Only the version of the updated resource changes.
+	updated, err := newVersionedSnapshot(types.XdsResources{
+		resourcev3.ClusterType: {newCluster("a", time.Second), newCluster("b", 2*time.Second)},
+	})
+	require.NoError(t, err)
+	firstVersions, updatedVersions := first.GetVersionMap(resourcev3.ClusterType), updated.GetVersionMap(resourcev3.ClusterType)
+	require.Equal(t, firstVersions["a"], updatedVersions["a"])
+	require.NotEqual(t, firstVersions["b"], updatedVersions["b"])
+	require.NotEqual(t, first.GetVersion(resourcev3.ClusterType), updated.GetVersion(resourcev3.ClusterType))
+	require.Equal(t, first.GetVersion(resourcev3.ListenerType), updated.GetVersion(resourcev3.ListenerType))
+	require.Equal(t, map[string]int{resourcev3.ClusterType: 1}, changedResources(first, updated))
+
+	// Removed resources are changes as well.
+	removed, err := newVersionedSnapshot(nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{resourcev3.ClusterType: 2}, changedResources(first, removed))
+	require.Equal(t, map[string]int{resourcev3.ClusterType: 2}, changedResources(nil, first))
+
+	_, err = newVersionedSnapshot(types.XdsResources{"unknown": {newCluster("a", time.Second)}})
+	require.Error(t, err)
+}
+
+func TestGenerateNewSnapshotDelta(t *testing.T) {
+	logger := logging.DefaultLogger(os.Stdout, egv1a1.LogLevelInfo)
+	cache := NewSnapshotCache(true, egv1a1.DefaultSnapshotHistorySize, logger).(*snapshotCache)
+	require.NoError(t, cache.GenerateNewSnapshot("default/eg", 1, types.XdsResources{
+		resourcev3.ClusterType: {newCluster("a", time.Second), newCluster("b", time.Second)},
+	}))
+
+	// Serve the cache with the xDS server of go-control-plane, the way the xDS runner does.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	discoveryv3.RegisterAggregatedDiscoveryServiceServer(server, serverv3.NewServer(ctx, cache, cache))
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	stream, err := discoveryv3.NewAggregatedDiscoveryServiceClient(conn).DeltaAggregatedResources(ctx)
+	require.NoError(t, err)
+
+	// The first wildcard request receives all the clusters.
+	node := &corev3.Node{Id: "envoy", Cluster: "default/eg"}
+	require.NoError(t, stream.Send(&discoveryv3.DeltaDiscoveryRequest{Node: node, TypeUrl: resourcev3.ClusterType}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b"}, deltaResourceNames(resp))
+	require.NoError(t, stream.Send(&discoveryv3.DeltaDiscoveryRequest{Node: node, TypeUrl: resourcev3.ClusterType, ResponseNonce: resp.Nonce}))
+
+	// An update only pushes the changed cluster.
+	require.NoError(t, cache.GenerateNewSnapshot("default/eg", 1, types.XdsResources{
+		resourcev3.ClusterType: {newCluster("a", time.Second), newCluster("b", 2*time.Second)},
+	}))
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, deltaResourceNames(resp))
+	require.Empty(t, resp.RemovedResources)
+	require.NoError(t, stream.Send(&discoveryv3.DeltaDiscoveryRequest{Node: node, TypeUrl: resourcev3.ClusterType, ResponseNonce: resp.Nonce}))
+
+	// A removal only pushes the name of the removed cluster.
+	require.NoError(t, cache.GenerateNewSnapshot("default/eg", 1, types.XdsResources{
+		resourcev3.ClusterType: {newCluster("a", time.Second)},
+	}))
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Empty(t, resp.Resources)
+	require.Equal(t, []string{"b"}, resp.RemovedResources)
+}
+
+func deltaResourceNames(resp *discoveryv3.DeltaDiscoveryResponse) []string {
+	names := make([]string, 0, len(resp.Resources))
+	for _, resource := range resp.Resources {
+		names = append(names, resource.Name)
+	}
+	return names
+}
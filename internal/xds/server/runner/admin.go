@@ -0,0 +1,205 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/wukongcloud/gateway/internal/admin"
+	"github.com/wukongcloud/gateway/internal/xds/cache"
+)
+
+const (
+	// SnapshotsPath is the admin server path listing the xDS snapshots kept for every IR key.
+	SnapshotsPath = "/api/xds/snapshots"
+	// SnapshotDiffPath is the admin server path diffing two xDS snapshots of an IR key,
+	// given by the "key", "from" and "to" query parameters.
+	SnapshotDiffPath = "/api/xds/snapshots/diff"
+	// SnapshotPinPath is the admin server path pinning, with POST, the xDS snapshot of an IR key
+	// given by the "key" and "id" query parameters, or releasing the pin, with DELETE.
+	SnapshotPinPath = "/api/xds/snapshots/pin"
+	// SnapshotRollbackPath is the admin server path pinning, with POST, the xDS snapshot
+	// generated before the one served for the IR key given by the "key" query parameter.
+	SnapshotRollbackPath = "/api/xds/snapshots/rollback"
+	// RolloutsPath is the admin server path listing the canary rollouts of the xDS snapshots.
+	RolloutsPath = "/api/xds/rollouts"
+	// RolloutPromotePath is the admin server path promoting, with POST, the xDS snapshot rolled
+	// out to the canary Envoy proxies of the IR key given by the "key" query parameter.
+	RolloutPromotePath = "/api/xds/rollouts/promote"
+	// RolloutAbortPath is the admin server path reverting, with POST, the canary Envoy proxies
+	// of the IR key given by the "key" query parameter to the previous xDS snapshot.
+	RolloutAbortPath = "/api/xds/rollouts/abort"
+)
+
+// registerSnapshotHandlers registers the endpoints of the xDS snapshot history and
+// the canary rollouts on the admin server.
+func registerSnapshotHandlers(c cache.SnapshotCacheWithCallbacks) {
+	h := &snapshotHandlers{cache: c}
+	admin.Handle(SnapshotsPath, http.HandlerFunc(h.list))
+	admin.Handle(SnapshotDiffPath, http.HandlerFunc(h.diff))
+	admin.Handle(SnapshotPinPath, http.HandlerFunc(h.pin))
+	admin.Handle(SnapshotRollbackPath, http.HandlerFunc(h.rollback))
+	admin.Handle(RolloutsPath, http.HandlerFunc(h.rollouts))
+	admin.Handle(RolloutPromotePath, http.HandlerFunc(h.promote))
+	admin.Handle(RolloutAbortPath, http.HandlerFunc(h.abort))
+}
+
+type snapshotHandlers struct {
+	cache cache.SnapshotCacheWithCallbacks
+}
+
+func (h *snapshotHandlers) list(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, h.cache.SnapshotHistories(), nil)
+}
+
+func (h *snapshotHandlers) diff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key, err := queryKey(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, err := queryID(r, "from")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := queryID(r, "to")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.cache.DiffSnapshots(key, from, to)
+	writeJSON(w, diff, err)
+}
+
+func (h *snapshotHandlers) pin(w http.ResponseWriter, r *http.Request) {
+	key, err := queryKey(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		id, err := queryID(r, "id")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		info, err := h.cache.PinSnapshot(key, id)
+		writeJSON(w, info, err)
+	case http.MethodDelete:
+		if err := h.cache.UnpinSnapshot(key); err != nil {
+			writeJSON(w, nil, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *snapshotHandlers) rollback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key, err := queryKey(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	info, err := h.cache.RollbackSnapshot(key)
+	writeJSON(w, info, err)
+}
+
+func (h *snapshotHandlers) rollouts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, h.cache.Rollouts(), nil)
+}
+
+func (h *snapshotHandlers) promote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key, err := queryKey(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rollout, err := h.cache.PromoteRollout(key)
+	writeJSON(w, rollout, err)
+}
+
+func (h *snapshotHandlers) abort(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key, err := queryKey(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rollout, err := h.cache.AbortRollout(key)
+	writeJSON(w, rollout, err)
+}
+
+func queryKey(r *http.Request) (string, error) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		return "", fmt.Errorf("missing key query parameter")
+	}
+	return key, nil
+}
+
+func queryID(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(r.URL.Query().Get(name), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s query parameter: %w", name, err)
+	}
+	return id, nil
+}
+
+// writeJSON writes v as JSON, or the error with a status code matching it.
+func writeJSON(w http.ResponseWriter, v any, err error) {
+	switch {
+	case errors.Is(err, cache.ErrSnapshotNotFound), errors.Is(err, cache.ErrRolloutNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/logging"
+	"github.com/wukongcloud/gateway/internal/xds/cache"
+	xdstypes "github.com/wukongcloud/gateway/internal/xds/types"
+)
+
+func TestSnapshotHandlers(t *testing.T) {
+	c := cache.NewSnapshotCache(true, egv1a1.DefaultSnapshotHistorySize, logging.DefaultLogger(os.Stdout, egv1a1.LogLevelInfo))
+	h := &snapshotHandlers{cache: c}
+	for i, timeout := range []time.Duration{time.Second, 2 * time.Second} {
+		require.NoError(t, c.GenerateNewSnapshot("default/eg", int64(i+1), xdstypes.XdsResources{
+			resourcev3.ClusterType: {&clusterv3.Cluster{Name: "a", ConnectTimeout: durationpb.New(timeout)}},
+		}))
+	}
+
+	serve := func(handler http.HandlerFunc, method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	rec := serve(h.list, http.MethodGet, SnapshotsPath)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var histories []cache.SnapshotHistory
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &histories))
+	require.Len(t, histories, 1)
+	require.Len(t, histories[0].Snapshots, 2)
+	first, second := histories[0].Snapshots[0], histories[0].Snapshots[1]
+
+	rec = serve(h.diff, http.MethodGet, SnapshotDiffPath+"?key=default/eg&from=1&to=2")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var diff cache.SnapshotDiff
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &diff))
+	require.Equal(t, []string{"a"}, diff.Resources[resourcev3.ClusterType].Changed)
+
+	rec = serve(h.rollback, http.MethodPost, SnapshotRollbackPath+"?key=default/eg")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var info cache.SnapshotInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	require.Equal(t, first.ID, info.ID)
+
+	rec = serve(h.pin, http.MethodPost, SnapshotPinPath+"?key=default/eg&id=2")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	require.Equal(t, second.ID, info.ID)
+
+	require.Equal(t, http.StatusNoContent, serve(h.pin, http.MethodDelete, SnapshotPinPath+"?key=default/eg").Code)
+	require.Equal(t, http.StatusNotFound, serve(h.pin, http.MethodDelete, SnapshotPinPath+"?key=default/eg").Code)
+	require.Equal(t, http.StatusNotFound, serve(h.pin, http.MethodPost, SnapshotPinPath+"?key=default/eg&id=10").Code)
+	require.Equal(t, http.StatusBadRequest, serve(h.pin, http.MethodPost, SnapshotPinPath+"?key=default/eg").Code)
+	require.Equal(t, http.StatusBadRequest, serve(h.rollback, http.MethodPost, SnapshotRollbackPath).Code)
+	require.Equal(t, http.StatusMethodNotAllowed, serve(h.rollback, http.MethodGet, SnapshotRollbackPath+"?key=default/eg").Code)
+
+	rec = serve(h.rollouts, http.MethodGet, RolloutsPath)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, "[]", rec.Body.String())
+	require.Equal(t, http.StatusNotFound, serve(h.promote, http.MethodPost, RolloutPromotePath+"?key=default/eg").Code)
+	require.Equal(t, http.StatusNotFound, serve(h.abort, http.MethodPost, RolloutAbortPath+"?key=default/eg").Code)
+	require.Equal(t, http.StatusBadRequest, serve(h.abort, http.MethodPost, RolloutAbortPath).Code)
+}
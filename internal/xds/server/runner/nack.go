@@ -0,0 +1,211 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package runner
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	ktypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/message"
+	"github.com/wukongcloud/gateway/internal/xds/cache"
+)
+
+// envoyGatewayXdsMetadataNamespace is the metadata namespace the xds translator
+// records the resources an xds resource is translated from in.
+const envoyGatewayXdsMetadataNamespace = "envoy-gateway"
+
+// routeKinds maps the lower case route kinds, which prefix the names of the xds
+// resources translated from the routes, to the route kinds.
+var routeKinds = map[string]string{
+	strings.ToLower(resource.KindHTTPRoute): resource.KindHTTPRoute,
+	strings.ToLower(resource.KindGRPCRoute): resource.KindGRPCRoute,
+	strings.ToLower(resource.KindTLSRoute):  resource.KindTLSRoute,
+	strings.ToLower(resource.KindTCPRoute):  resource.KindTCPRoute,
+	strings.ToLower(resource.KindUDPRoute):  resource.KindUDPRoute,
+}
+
+// patchedResources holds the xds resources targeted by EnvoyPatchPolicies for every IR key.
+type patchedResources struct {
+	mu        sync.RWMutex
+	resources map[string]map[resourcev3.Type]map[string][]ktypes.NamespacedName
+}
+
+func (p *patchedResources) store(irKey string, resources map[resourcev3.Type]map[string][]ktypes.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.resources == nil {
+		p.resources = make(map[string]map[resourcev3.Type]map[string][]ktypes.NamespacedName)
+	}
+	if resources == nil {
+		delete(p.resources, irKey)
+		return
+	}
+	p.resources[irKey] = resources
+}
+
+func (p *patchedResources) policies(irKey string, typeURL resourcev3.Type, name string) []ktypes.NamespacedName {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.resources[irKey][typeURL][name]
+}
+
+// handleNACKs publishes the xds updates rejected by the Envoy proxies of the IR key,
+// mapped to the resources the rejected xds resources are translated from.
+func (r *Runner) handleNACKs(irKey string, nacks []cache.NACK) {
+	if r.ProviderResources == nil {
+		return
+	}
+	if len(nacks) == 0 {
+		r.ProviderResources.XdsNACKStatuses.Delete(irKey)
+		return
+	}
+
+	nackStatus := &message.XdsNACKStatus{}
+	for _, nack := range nacks {
+		r.Logger.Info("envoy rejected an xds update", "irKey", irKey, "node", nack.NodeID,
+			"typeURL", nack.TypeURL, "message", nack.Message)
+		nackStatus.NACKs = append(nackStatus.NACKs, message.XdsNACK{
+			NodeID:        nack.NodeID,
+			TypeURL:       nack.TypeURL,
+			Message:       nack.Message,
+			ResourceNames: sortedNames(nack.Resources),
+			Sources:       r.nackSources(irKey, nack),
+		})
+	}
+	r.ProviderResources.XdsNACKStatuses.Store(irKey, nackStatus)
+}
+
+// nackSources returns the routes and EnvoyPatchPolicies the rejected xds resources are translated from.
+func (r *Runner) nackSources(irKey string, nack cache.NACK) []message.XdsResourceSource {
+	sources := make(map[message.XdsResourceSource]struct{})
+	for name, res := range nack.Resources {
+		for _, policy := range r.patchedResources.policies(irKey, nack.TypeURL, name) {
+			sources[message.XdsResourceSource{
+				Kind:      resource.KindEnvoyPatchPolicy,
+				Namespace: policy.Namespace,
+				Name:      policy.Name,
+			}] = struct{}{}
+		}
+		for _, source := range routeSources(res, nack.Message) {
+			sources[source] = struct{}{}
+		}
+	}
+
+	result := make([]message.XdsResourceSource, 0, len(sources))
+	for source := range sources {
+		result = append(result, source)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return result
+}
+
+// routeSources returns the routes the rejected xds resource is translated from.
+func routeSources(res envoytypes.Resource, msg string) []message.XdsResourceSource {
+	var sources []message.XdsResourceSource
+	switch res := res.(type) {
+	case *routev3.RouteConfiguration:
+		// Only the routes named in the error detail are rejected, since a route
+		// configuration holds all the routes of a listener.
+		for _, vHost := range res.VirtualHosts {
+			for _, route := range vHost.Routes {
+				if route.Name == "" || !strings.Contains(msg, route.Name) {
+					continue
+				}
+				if source, ok := metadataSource(route.Metadata); ok {
+					sources = append(sources, source)
+				} else if source, ok := nameSource(route.Name); ok {
+					sources = append(sources, source)
+				}
+			}
+		}
+	case *listenerv3.Listener:
+		// The filter chains of the TCP and UDP listeners are named after their routes.
+		for _, filterChain := range res.FilterChains {
+			if source, ok := nameSource(filterChain.Name); ok {
+				sources = append(sources, source)
+			}
+		}
+		if source, ok := nameSource(res.Name); ok {
+			sources = append(sources, source)
+		}
+	default:
+		// The clusters and cluster load assignments are named after their routes.
+		if named, ok := res.(interface{ GetName() string }); ok {
+			if source, ok := nameSource(named.GetName()); ok {
+				sources = append(sources, source)
+			}
+		}
+		if named, ok := res.(interface{ GetClusterName() string }); ok {
+			if source, ok := nameSource(named.GetClusterName()); ok {
+				sources = append(sources, source)
+			}
+		}
+	}
+	return sources
+}
+
+// metadataSource returns the route recorded in the xds metadata.
+func metadataSource(metadata *corev3.Metadata) (message.XdsResourceSource, bool) {
+	resources := metadata.GetFilterMetadata()[envoyGatewayXdsMetadataNamespace].GetFields()["resources"].GetListValue().GetValues()
+	for _, value := range resources {
+		fields := value.GetStructValue().GetFields()
+		kind := fields["kind"].GetStringValue()
+		if _, ok := routeKinds[strings.ToLower(kind)]; !ok {
+			continue
+		}
+		return message.XdsResourceSource{
+			Kind:      kind,
+			Namespace: fields["namespace"].GetStringValue(),
+			Name:      fields["name"].GetStringValue(),
+		}, true
+	}
+	return message.XdsResourceSource{}, false
+}
+
+// nameSource returns the route an xds resource named "<kind>/<namespace>/<name>[/...]" is translated from.
+func nameSource(name string) (message.XdsResourceSource, bool) {
+	parts := strings.SplitN(name, "/", 4)
+	if len(parts) < 3 || parts[1] == "" || parts[2] == "" {
+		return message.XdsResourceSource{}, false
+	}
+	kind, ok := routeKinds[parts[0]]
+	if !ok {
+		return message.XdsResourceSource{}, false
+	}
+	return message.XdsResourceSource{Kind: kind, Namespace: parts[1], Name: parts[2]}, true
+}
+
+func sortedNames(resources map[string]envoytypes.Resource) []string {
+	if len(resources) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
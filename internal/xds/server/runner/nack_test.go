@@ -0,0 +1,118 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package runner
+
+import (
+	"os"
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+	ktypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/wukongcloud/gateway/internal/envoygateway/config"
+	"github.com/wukongcloud/gateway/internal/message"
+	"github.com/wukongcloud/gateway/internal/xds/cache"
+)
+
+func routeMetadata(t *testing.T, kind, namespace, name string) *corev3.Metadata {
+	t.Helper()
+	resources, err := structpb.NewList([]any{map[string]any{"kind": kind, "namespace": namespace, "name": name}})
+	require.NoError(t, err)
+	return &corev3.Metadata{FilterMetadata: map[string]*structpb.Struct{
+		envoyGatewayXdsMetadataNamespace: {Fields: map[string]*structpb.Value{"resources": structpb.NewListValue(resources)}},
+	}}
+}
+
+func TestHandleNACKs(t *testing.T) {
+	cfg, err := config.New(os.Stdout)
+	require.NoError(t, err)
+	pResources := new(message.ProviderResources)
+	r := New(&Config{Server: *cfg, ProviderResources: pResources})
+
+	policy := ktypes.NamespacedName{Namespace: "default", Name: "patch"}
+	r.patchedResources.store("default/eg", map[resourcev3.Type]map[string][]ktypes.NamespacedName{
+		resourcev3.ClusterType: {"httproute/default/backend/rule/0": {policy}},
+	})
+
+	routeConfig := &routev3.RouteConfiguration{
+		Name: "default/eg/http",
+		VirtualHosts: []*routev3.VirtualHost{{
+			Name: "default/eg/http/www_example_com",
+			Routes: []*routev3.Route{
+				{Name: "httproute/default/backend/rule/0/match/0/www_example_com", Metadata: routeMetadata(t, "HTTPRoute", "default", "backend")},
+				{Name: "grpcroute/default/grpc/rule/0/match/0/www_example_com"},
+				{Name: "httproute/default/other/rule/0/match/0/www_example_com"},
+			},
+		}},
+	}
+
+	r.handleNACKs("default/eg", []cache.NACK{
+		{
+			NodeID:    "envoy",
+			TypeURL:   resourcev3.ClusterType,
+			Message:   "cluster httproute/default/backend/rule/0 is invalid",
+			Resources: map[string]envoytypes.Resource{"httproute/default/backend/rule/0": &clusterv3.Cluster{Name: "httproute/default/backend/rule/0"}},
+		},
+		{
+			NodeID:  "envoy",
+			TypeURL: resourcev3.RouteType,
+			Message: "routes httproute/default/backend/rule/0/match/0/www_example_com and grpcroute/default/grpc/rule/0/match/0/www_example_com are invalid",
+			Resources: map[string]envoytypes.Resource{
+				"default/eg/http": routeConfig,
+			},
+		},
+	})
+
+	nackStatus, ok := pResources.XdsNACKStatuses.Load("default/eg")
+	require.True(t, ok)
+	require.Len(t, nackStatus.NACKs, 2)
+	require.Equal(t, []string{"httproute/default/backend/rule/0"}, nackStatus.NACKs[0].ResourceNames)
+	require.Equal(t, []message.XdsResourceSource{
+		{Kind: "EnvoyPatchPolicy", Namespace: "default", Name: "patch"},
+		{Kind: "HTTPRoute", Namespace: "default", Name: "backend"},
+	}, nackStatus.NACKs[0].Sources)
+	// Only the routes named in the error detail are rejected.
+	require.Equal(t, []message.XdsResourceSource{
+		{Kind: "GRPCRoute", Namespace: "default", Name: "grpc"},
+		{Kind: "HTTPRoute", Namespace: "default", Name: "backend"},
+	}, nackStatus.NACKs[1].Sources)
+	require.NotNil(t, nackStatus.NACKFor(message.XdsResourceSource{Kind: "GRPCRoute", Namespace: "default", Name: "grpc"}))
+	require.Nil(t, nackStatus.NACKFor(message.XdsResourceSource{Kind: "HTTPRoute", Namespace: "default", Name: "other"}))
+
+	// The status is removed once the updates are accepted.
+	r.handleNACKs("default/eg", nil)
+	_, ok = pResources.XdsNACKStatuses.Load("default/eg")
+	require.False(t, ok)
+}
+
+func TestNameSource(t *testing.T) {
+	tests := map[string]*message.XdsResourceSource{
+		"httproute/default/backend/rule/0":          {Kind: "HTTPRoute", Namespace: "default", Name: "backend"},
+		"tcproute/default/tcp":                      {Kind: "TCPRoute", Namespace: "default", Name: "tcp"},
+		"udproute/default/udp/rule/0/backend/0":     {Kind: "UDPRoute", Namespace: "default", Name: "udp"},
+		"default/eg/http":                           nil,
+		"httproute/default":                         nil,
+		"ratelimit_cluster":                         nil,
+		"securitypolicy/default/oidc/oauth_cluster": nil,
+	}
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			source, ok := nameSource(name)
+			if expected == nil {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, *expected, source)
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
+	"k8s.io/utils/ptr"
 
 	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
 	"github.com/wukongcloud/gateway/internal/crypto"
@@ -59,17 +60,27 @@ const (
 	// defaultKubernetesIssuer is the default issuer URL for Kubernetes.
 	// This is used for validating Service Account JWT tokens.
 	defaultKubernetesIssuer = "https://kubernetes.default.svc.cluster.local"
+
+	// defaultCanarySoakDuration is how long the canary Envoy proxies run a new xDS
+	// configuration before it can be promoted, unless configured.
+	defaultCanarySoakDuration = 60 * time.Second
 )
 
 type Config struct {
 	config.Server
-	Xds   *message.Xds
-	grpc  *grpc.Server
-	cache cache.SnapshotCacheWithCallbacks
+	Xds *message.Xds
+	// IRGenerations holds the generation of the Gateway API resources the
+	// xds resources are translated from, kept along with the snapshots.
+	IRGenerations *message.IRGenerations
+	// ProviderResources receives the xds updates rejected by the Envoy proxies.
+	ProviderResources *message.ProviderResources
+	grpc              *grpc.Server
+	cache             cache.SnapshotCacheWithCallbacks
 }
 
 type Runner struct {
 	Config
+	patchedResources patchedResources
 }
 
 func New(cfg *Config) *Runner {
@@ -86,7 +97,24 @@ func (r *Runner) Close() error { return nil }
 // Start starts the xds-server runner
 func (r *Runner) Start(ctx context.Context) (err error) {
 	r.Logger = r.Logger.WithName(r.Name()).WithValues("runner", r.Name())
-	r.cache = cache.NewSnapshotCache(true, r.Logger)
+	r.cache = cache.NewSnapshotCache(true, r.EnvoyGateway.GetEnvoyGatewayAdmin().GetSnapshotHistorySize(), r.Logger)
+	r.cache.SetNACKHandler(r.handleNACKs)
+	canary, err := canaryRollout(r.EnvoyGateway.XdsCanary)
+	if err != nil {
+		return err
+	}
+	r.cache.SetCanaryRollout(canary)
+	if dir := r.EnvoyGateway.XdsSnapshotPath; dir != "" {
+		restored, err := r.cache.PersistSnapshots(dir)
+		if err != nil {
+			return fmt.Errorf("failed to restore the xds snapshots from %s: %w", dir, err)
+		}
+		r.Logger.Info("restored xds snapshots", "path", dir, "irKeys", restored)
+		if len(restored) > 0 {
+			go r.dropStaleSnapshots(ctx)
+		}
+	}
+	registerSnapshotHandlers(r.cache)
 
 	// Set up the gRPC server and register the xDS handler.
 	// Create SnapshotCache before start subscribeAndTranslate,
@@ -150,6 +178,24 @@ func (r *Runner) Start(ctx context.Context) (err error) {
 	return
 }
 
+// dropStaleSnapshots drops the restored snapshots of the IR keys which the first
+// translation of the Gateway API resources doesn't produce, e.g. of the Gateways
+// deleted while Envoy Gateway was down.
+func (r *Runner) dropStaleSnapshots(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-r.IRGenerations.Translated():
+	}
+
+	dropped := r.cache.DropRestoredSnapshots(func(irKey string) bool {
+		return r.IRGenerations.Load(irKey) != 0
+	})
+	if len(dropped) > 0 {
+		r.Logger.Info("dropped stale xds snapshots", "irKeys", dropped)
+	}
+}
+
 func (r *Runner) serveXdsServer(ctx context.Context) {
 	addr := net.JoinHostPort(XdsServerAddress, strconv.Itoa(bootstrap.DefaultXdsServerPort))
 	l, err := net.Listen("tcp", addr)
@@ -195,14 +241,16 @@ func (r *Runner) subscribeAndTranslate(sub <-chan watchable.Snapshot[string, *xd
 			r.Logger.Info("received an update")
 			var err error
 			if update.Delete {
-				err = r.cache.GenerateNewSnapshot(key, nil)
+				r.patchedResources.store(key, nil)
+				err = r.cache.GenerateNewSnapshot(key, r.IRGenerations.Load(key), nil)
 			} else if val != nil && val.XdsResources != nil {
+				r.patchedResources.store(key, val.PatchedResources)
 				if r.cache == nil {
 					r.Logger.Error(err, "failed to init snapshot cache")
 					errChan <- err
 				} else {
 					// Update snapshot cache
-					err = r.cache.GenerateNewSnapshot(key, val.XdsResources)
+					err = r.cache.GenerateNewSnapshot(key, r.IRGenerations.Load(key), val.XdsResources)
 				}
 			}
 			if err != nil {
@@ -223,7 +271,7 @@ func (r *Runner) loadTLSConfig() (tlsConfig *tls.Config, err error) {
 			return nil, fmt.Errorf("failed to create tls config: %w", err)
 		}
 
-	case r.EnvoyGateway.Provider.IsRunningOnHost():
+	case r.EnvoyGateway.Provider.IsRunningOnHost() || r.EnvoyGateway.Provider.IsRunningOnDocker():
 		tlsConfig, err = crypto.LoadTLSConfig(localTLSCertFilepath, localTLSKeyFilepath, localTLSCaFilepath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create tls config: %w", err)
@@ -234,3 +282,32 @@ func (r *Runner) loadTLSConfig() (tlsConfig *tls.Config, err error) {
 	}
 	return
 }
+
+// canaryRollout returns the canary rollout of the snapshots configured by Envoy Gateway,
+// or nil if it's disabled.
+func canaryRollout(canary *egv1a1.XdsCanaryRollout) (*cache.CanaryRollout, error) {
+	if canary == nil {
+		return nil, nil
+	}
+
+	rollout := &cache.CanaryRollout{
+		Replicas:     1,
+		SoakDuration: defaultCanarySoakDuration,
+		AutoPromote:  ptr.Deref(canary.Promotion, egv1a1.XdsCanaryActionAutomatic) == egv1a1.XdsCanaryActionAutomatic,
+		AutoAbort:    ptr.Deref(canary.Abort, egv1a1.XdsCanaryActionAutomatic) == egv1a1.XdsCanaryActionAutomatic,
+	}
+	if canary.Replicas != nil {
+		rollout.Replicas = int(*canary.Replicas)
+	}
+	if canary.Percentage != nil {
+		rollout.Percentage = int(*canary.Percentage)
+	}
+	if canary.SoakDuration != nil {
+		d, err := time.ParseDuration(string(*canary.SoakDuration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid xds canary soak duration: %w", err)
+		}
+		rollout.SoakDuration = d
+	}
+	return rollout, nil
+}
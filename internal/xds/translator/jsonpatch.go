@@ -17,6 +17,7 @@ import (
 	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
 	"google.golang.org/protobuf/encoding/protojson"
+	ktypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/yaml"
 
 	"github.com/wukongcloud/gateway/internal/gatewayapi/status"
@@ -65,6 +66,10 @@ func processJSONPatches(tCtx *types.ResourceVersionTable, envoyPatchPolicies []*
 				continue
 			}
 
+			// Record the patched resource, so the rejections of the resource by
+			// Envoy can be reported in the status of the policy.
+			tCtx.AddPatchedResource(p.Type, p.Name, ktypes.NamespacedName{Namespace: e.Namespace, Name: e.Name})
+
 			// If Path and JSONPath is "" and op is "add", unmarshal and add the patch as a complete
 			// resource
 			if p.Operation.Op == ir.JSONPatchOpAdd && p.Operation.IsPathNilOrEmpty() && p.Operation.IsJSONPathNilOrEmpty() {
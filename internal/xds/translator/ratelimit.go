@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"fmt"
 	"net/url"
+	"path"
 	"sort"
 	"strconv"
 	"strings"
@@ -35,12 +36,14 @@ import (
 )
 
 const (
+	// rateLimitClientTLSCertDir is the default directory of the ratelimit client certificates.
+	rateLimitClientTLSCertDir = "/certs"
 	// rateLimitClientTLSCertFilename is the ratelimit tls cert file.
-	rateLimitClientTLSCertFilename = "/certs/tls.crt"
+	rateLimitClientTLSCertFilename = "tls.crt"
 	// rateLimitClientTLSKeyFilename is the ratelimit key file.
-	rateLimitClientTLSKeyFilename = "/certs/tls.key"
+	rateLimitClientTLSKeyFilename = "tls.key"
 	// rateLimitClientTLSCACertFilename is the ratelimit ca cert file.
-	rateLimitClientTLSCACertFilename = "/certs/ca.crt"
+	rateLimitClientTLSCACertFilename = "ca.crt"
 )
 
 // patchHCMWithRateLimit builds and appends the Rate Limit Filter to the HTTP connection manager
@@ -708,14 +711,18 @@ func buildRateLimitServiceDescriptors(route *ir.HTTPRoute) []*rlsconfv3.RateLimi
 }
 
 // buildRateLimitTLSocket builds the TLS socket for the rate limit service.
-func buildRateLimitTLSocket() (*corev3.TransportSocket, error) {
+func buildRateLimitTLSocket(certDir string) (*corev3.TransportSocket, error) {
+	if certDir == "" {
+		certDir = rateLimitClientTLSCertDir
+	}
+
 	tlsCtx := &tlsv3.UpstreamTlsContext{
 		CommonTlsContext: &tlsv3.CommonTlsContext{
 			TlsCertificates: []*tlsv3.TlsCertificate{},
 			ValidationContextType: &tlsv3.CommonTlsContext_ValidationContext{
 				ValidationContext: &tlsv3.CertificateValidationContext{
 					TrustedCa: &corev3.DataSource{
-						Specifier: &corev3.DataSource_Filename{Filename: rateLimitClientTLSCACertFilename},
+						Specifier: &corev3.DataSource_Filename{Filename: path.Join(certDir, rateLimitClientTLSCACertFilename)},
 					},
 				},
 			},
@@ -724,10 +731,10 @@ func buildRateLimitTLSocket() (*corev3.TransportSocket, error) {
 
 	tlsCert := &tlsv3.TlsCertificate{
 		CertificateChain: &corev3.DataSource{
-			Specifier: &corev3.DataSource_Filename{Filename: rateLimitClientTLSCertFilename},
+			Specifier: &corev3.DataSource_Filename{Filename: path.Join(certDir, rateLimitClientTLSCertFilename)},
 		},
 		PrivateKey: &corev3.DataSource{
-			Specifier: &corev3.DataSource_Filename{Filename: rateLimitClientTLSKeyFilename},
+			Specifier: &corev3.DataSource_Filename{Filename: path.Join(certDir, rateLimitClientTLSKeyFilename)},
 		},
 	}
 	tlsCtx.CommonTlsContext.TlsCertificates = append(tlsCtx.CommonTlsContext.TlsCertificates, tlsCert)
@@ -760,7 +767,7 @@ func (t *Translator) createRateLimitServiceCluster(tCtx *types.ResourceVersionTa
 		Name:      destinationSettingName(clusterName),
 	}
 
-	tSocket, err := buildRateLimitTLSocket()
+	tSocket, err := buildRateLimitTLSocket(t.GlobalRateLimit.ClientCertDir)
 	if err != nil {
 		return err
 	}
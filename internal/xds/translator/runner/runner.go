@@ -15,6 +15,8 @@ import (
 	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
 	"github.com/wukongcloud/gateway/internal/envoygateway/config"
 	extension "github.com/wukongcloud/gateway/internal/extension/types"
+	"github.com/wukongcloud/gateway/internal/infrastructure/docker"
+	"github.com/wukongcloud/gateway/internal/infrastructure/host"
 	"github.com/wukongcloud/gateway/internal/infrastructure/kubernetes/ratelimit"
 	"github.com/wukongcloud/gateway/internal/ir"
 	"github.com/wukongcloud/gateway/internal/message"
@@ -85,6 +87,16 @@ func (r *Runner) subscribeAndTranslate(sub <-chan watchable.Snapshot[string, *ir
 					if r.EnvoyGateway.RateLimit.Timeout != nil {
 						t.GlobalRateLimit.Timeout = r.EnvoyGateway.RateLimit.Timeout.Duration
 					}
+					// The rate limit service runs alongside Envoy when using the Host infrastructure.
+					if r.EnvoyGateway.Provider != nil && r.EnvoyGateway.Provider.IsRunningOnHost() {
+						t.GlobalRateLimit.ServiceURL = host.GetRateLimitServiceURL()
+						t.GlobalRateLimit.ClientCertDir = host.GetRateLimitClientCertDir()
+					}
+					// The rate limit service runs as a container next to the Envoy containers when using the Docker infrastructure.
+					if r.EnvoyGateway.Provider != nil && r.EnvoyGateway.Provider.IsRunningOnDocker() {
+						t.GlobalRateLimit.ServiceURL = docker.GetRateLimitServiceURL()
+						t.GlobalRateLimit.ClientCertDir = docker.GetRateLimitClientCertDir()
+					}
 				}
 
 				result, err := t.Translate(val)
@@ -75,6 +75,11 @@ type GlobalRateLimitSettings struct {
 	// FailClosed is a switch used to control the flow of traffic
 	// when the response from the ratelimit server cannot be obtained.
 	FailClosed bool
+
+	// ClientCertDir is the directory containing the certificate, private key
+	// and trusted CA used to connect to the rate limit service.
+	// If not set, /certs is used.
+	ClientCertDir string
 }
 
 // Translate translates the XDS IR into xDS resources
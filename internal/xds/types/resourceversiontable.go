@@ -12,6 +12,7 @@ import (
 	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
 	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
 	protobuf "google.golang.org/protobuf/proto"
+	ktypes "k8s.io/apimachinery/pkg/types"
 
 	"github.com/wukongcloud/gateway/internal/ir"
 	"github.com/wukongcloud/gateway/internal/utils/proto"
@@ -26,6 +27,9 @@ type EnvoyPatchPolicyStatuses []*ir.EnvoyPatchPolicyStatus
 type ResourceVersionTable struct {
 	XdsResources
 	EnvoyPatchPolicyStatuses
+	// PatchedResources maps the xds resources targeted by EnvoyPatchPolicies to
+	// the policies, keyed by type URL and resource name.
+	PatchedResources map[resourcev3.Type]map[string][]ktypes.NamespacedName
 }
 
 // DeepCopyInto copies the contents into the output object
@@ -53,6 +57,17 @@ func (t *ResourceVersionTable) DeepCopyInto(out *ResourceVersionTable) {
 			(*out)[key] = outVal
 		}
 	}
+	if t.PatchedResources != nil {
+		in, out := &t.PatchedResources, &out.PatchedResources
+		*out = make(map[resourcev3.Type]map[string][]ktypes.NamespacedName, len(*in))
+		for key, val := range *in {
+			outVal := make(map[string][]ktypes.NamespacedName, len(val))
+			for name, policies := range val {
+				outVal[name] = append([]ktypes.NamespacedName(nil), policies...)
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
 // DeepCopy generates a deep copy of the ResourceVersionTable object.
@@ -93,6 +108,23 @@ func (t *ResourceVersionTable) AddXdsResource(rType resourcev3.Type, xdsResource
 	return nil
 }
 
+// AddPatchedResource records that the xds resource of rType with the given name
+// is targeted by the EnvoyPatchPolicy.
+func (t *ResourceVersionTable) AddPatchedResource(rType resourcev3.Type, name string, policy ktypes.NamespacedName) {
+	if t.PatchedResources == nil {
+		t.PatchedResources = make(map[resourcev3.Type]map[string][]ktypes.NamespacedName)
+	}
+	if t.PatchedResources[rType] == nil {
+		t.PatchedResources[rType] = make(map[string][]ktypes.NamespacedName)
+	}
+	for _, p := range t.PatchedResources[rType][name] {
+		if p == policy {
+			return
+		}
+	}
+	t.PatchedResources[rType][name] = append(t.PatchedResources[rType][name], policy)
+}
+
 // ValidateAll validates all the xds resources in the ResourceVersionTable
 func (t *ResourceVersionTable) ValidateAll() error {
 	var errs error
@@ -0,0 +1,683 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package offline
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/utils/ptr"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/yaml"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+)
+
+const (
+	// OpenAPITimeoutExtension sets the request timeout of an operation, or of the operations
+	// of a path, e.g. "10s".
+	OpenAPITimeoutExtension = "x-envoy-gateway-timeout"
+	// OpenAPIRateLimitExtension sets the local rate limit of an operation, or of each operation
+	// of a path, e.g. {"requests": 10, "unit": "Second"}.
+	OpenAPIRateLimitExtension = "x-envoy-gateway-rate-limit"
+	// OpenAPIJWKSExtension sets the URI of the JWKS verifying the tokens of a bearer, OAuth2 or
+	// OpenID Connect security scheme.
+	OpenAPIJWKSExtension = "x-envoy-gateway-jwks-uri"
+	// OpenAPIIssuerExtension sets the issuer of the tokens of a bearer, OAuth2 or OpenID Connect
+	// security scheme. The issuer of an OpenID Connect scheme defaults to its discovery URL.
+	OpenAPIIssuerExtension = "x-envoy-gateway-issuer"
+	// OpenAPIAudiencesExtension sets the audiences of the tokens of a bearer, OAuth2 or OpenID
+	// Connect security scheme.
+	OpenAPIAudiencesExtension = "x-envoy-gateway-audiences"
+	// OpenAPISecretExtension sets the name of the Secret holding the credentials of an API key or
+	// basic security scheme. It defaults to the name of the scheme.
+	OpenAPISecretExtension = "x-envoy-gateway-secret"
+
+	// OpenAPILabel is set on the generated resources to the name they are generated with, e.g. to
+	// prune the resources of the operations removed from the document.
+	OpenAPILabel = "gateway.envoyproxy.io/openapi"
+
+	// The limits of the HTTPRoute API.
+	openAPIMaxRules   = 16
+	openAPIMaxMatches = 64
+	// The limit of the JWT providers of a SecurityPolicy.
+	openAPIMaxJWTProviders = 4
+)
+
+var (
+	// openAPIPathTemplate is a parameter of an OpenAPI path, e.g. {petId}.
+	openAPIPathTemplate = regexp.MustCompile(`\{[^{}/]+\}`)
+	// openAPIDuration is the validation of the Gateway API durations.
+	openAPIDuration = regexp.MustCompile(`^([0-9]{1,5}(h|m|s|ms)){1,4}$`)
+	// openAPIInvalidNameChars are the characters replaced when deriving the resource names.
+	openAPIInvalidNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// OpenAPIOptions configures the generation of the resources of an OpenAPI document.
+type OpenAPIOptions struct {
+	// Name is the name of the generated resources, and the prefix of the names of the ones
+	// generated for the operations with their own security or rate limit.
+	// If unset, it's derived from the title of the document.
+	Name string
+
+	// Namespace is the namespace of the generated resources. If unset, "default" is used.
+	Namespace string
+
+	// ParentRefs are the Gateways the HTTPRoutes attach to.
+	ParentRefs []gwapiv1.ParentReference
+
+	// BackendRef is the backend serving the API.
+	BackendRef gwapiv1.BackendObjectReference
+
+	// Hostnames are the hostnames of the HTTPRoutes.
+	// If unset, the hosts of the absolute URLs of the servers of the document are used.
+	Hostnames []gwapiv1.Hostname
+
+	// BasePath is prepended to the paths of the document.
+	// If unset, the path of the URLs of the servers of the document is used.
+	BasePath string
+}
+
+// openAPIMethods are the methods of the operations of a path, in the order they are generated.
+var openAPIMethods = []struct {
+	method    gwapiv1.HTTPMethod
+	operation func(*spec3.Path) *spec3.Operation
+}{
+	{gwapiv1.HTTPMethodGet, func(p *spec3.Path) *spec3.Operation { return p.Get }},
+	{gwapiv1.HTTPMethodHead, func(p *spec3.Path) *spec3.Operation { return p.Head }},
+	{gwapiv1.HTTPMethodPost, func(p *spec3.Path) *spec3.Operation { return p.Post }},
+	{gwapiv1.HTTPMethodPut, func(p *spec3.Path) *spec3.Operation { return p.Put }},
+	{gwapiv1.HTTPMethodPatch, func(p *spec3.Path) *spec3.Operation { return p.Patch }},
+	{gwapiv1.HTTPMethodDelete, func(p *spec3.Path) *spec3.Operation { return p.Delete }},
+	{gwapiv1.HTTPMethodOptions, func(p *spec3.Path) *spec3.Operation { return p.Options }},
+	{gwapiv1.HTTPMethodTrace, func(p *spec3.Path) *spec3.Operation { return p.Trace }},
+}
+
+// openAPIOperation is an operation of the document with its effective settings.
+type openAPIOperation struct {
+	name      string
+	method    gwapiv1.HTTPMethod
+	path      string
+	security  []map[string][]string
+	timeout   *gwapiv1.Duration
+	rateLimit *egv1a1.RateLimitValue
+}
+
+// openAPIRouteGroup holds the operations sharing an HTTPRoute, and its policies.
+type openAPIRouteGroup struct {
+	name       string
+	security   []map[string][]string
+	rateLimit  *egv1a1.RateLimitValue
+	operations []*openAPIOperation
+}
+
+// GenerateFromOpenAPI generates the HTTPRoutes matching the operations of an OpenAPI 3 document,
+// in YAML or JSON, along with the SecurityPolicies of their security requirements and the
+// BackendTrafficPolicies of their rate limits. The timeouts and the rate limits of the operations
+// are read from the x-envoy-gateway-timeout and x-envoy-gateway-rate-limit extensions.
+//
+// The operations are matched on their method and their exact path, the path parameters matching
+// a single path segment. The generation is deterministic: the same document and options always
+// produce the same resources, with the same names.
+func GenerateFromOpenAPI(document []byte, opts *OpenAPIOptions) (*Resources, error) {
+	if opts == nil {
+		opts = &OpenAPIOptions{}
+	}
+	if len(opts.ParentRefs) == 0 {
+		return nil, fmt.Errorf("a parent reference is required")
+	}
+	if opts.BackendRef.Name == "" {
+		return nil, fmt.Errorf("a backend reference is required")
+	}
+
+	jsonDocument, err := yaml.YAMLToJSON(document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	doc := &spec3.OpenAPI{}
+	if err := json.Unmarshal(jsonDocument, doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(doc.Version, "3.") {
+		return nil, fmt.Errorf("only the OpenAPI 3 documents are supported")
+	}
+
+	name := opts.Name
+	if name == "" && doc.Info != nil {
+		name = openAPIName(doc.Info.Title)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("the document has no title, a name is required")
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	hostnames, basePath, err := openAPIServers(doc.Servers)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Hostnames) > 0 {
+		hostnames = opts.Hostnames
+	}
+	if opts.BasePath != "" {
+		basePath = opts.BasePath
+	}
+	basePath = strings.TrimSuffix(basePath, "/")
+
+	operations, err := openAPIOperations(doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("the document has no operations")
+	}
+	var schemes spec3.SecuritySchemes
+	if doc.Components != nil {
+		schemes = doc.Components.SecuritySchemes
+	}
+
+	resources := resource.NewResources()
+	for _, group := range openAPIRouteGroups(name, doc.SecurityRequirement, operations) {
+		objectMeta := metav1.ObjectMeta{
+			Name:      group.name,
+			Namespace: namespace,
+			Labels:    map[string]string{OpenAPILabel: name},
+		}
+		routes := openAPIHTTPRoutes(objectMeta, group.operations, opts, hostnames, basePath)
+		resources.HTTPRoutes = append(resources.HTTPRoutes, routes...)
+
+		var targetRefs []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName
+		for _, route := range routes {
+			targetRefs = append(targetRefs, gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{
+				LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{
+					Group: gwapiv1.GroupName,
+					Kind:  resource.KindHTTPRoute,
+					Name:  gwapiv1.ObjectName(route.Name),
+				},
+			})
+		}
+		if len(group.security) > 0 {
+			sp := &egv1a1.SecurityPolicy{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: egv1a1.GroupVersion.String(),
+					Kind:       egv1a1.KindSecurityPolicy,
+				},
+				ObjectMeta: objectMeta,
+			}
+			sp.Spec.TargetRefs = targetRefs
+			if err := openAPISecurity(&sp.Spec, group.security, schemes); err != nil {
+				return nil, fmt.Errorf("security of the operation %s: %w", group.operations[0].name, err)
+			}
+			resources.SecurityPolicies = append(resources.SecurityPolicies, sp)
+		}
+		if group.rateLimit != nil {
+			btp := &egv1a1.BackendTrafficPolicy{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: egv1a1.GroupVersion.String(),
+					Kind:       egv1a1.KindBackendTrafficPolicy,
+				},
+				ObjectMeta: objectMeta,
+			}
+			btp.Spec.TargetRefs = targetRefs
+			btp.Spec.RateLimit = &egv1a1.RateLimitSpec{
+				Type: egv1a1.LocalRateLimitType,
+				Local: &egv1a1.LocalRateLimit{
+					Rules: []egv1a1.RateLimitRule{{Limit: *group.rateLimit}},
+				},
+			}
+			resources.BackendTrafficPolicies = append(resources.BackendTrafficPolicies, btp)
+		}
+	}
+	return resources, nil
+}
+
+// openAPIName derives a resource name from a title, e.g. "Pet Store" becomes "pet-store".
+func openAPIName(title string) string {
+	return strings.Trim(openAPIInvalidNameChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+}
+
+// openAPIServers returns the hosts of the absolute URLs of the servers, and their common path.
+func openAPIServers(servers []*spec3.Server) ([]gwapiv1.Hostname, string, error) {
+	var (
+		hostnames []gwapiv1.Hostname
+		basePath  string
+	)
+	for i, server := range servers {
+		rawURL := server.URL
+		for name, variable := range server.Variables {
+			rawURL = strings.ReplaceAll(rawURL, "{"+name+"}", variable.Default)
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid server URL %q: %w", server.URL, err)
+		}
+		path := strings.TrimSuffix(u.Path, "/")
+		if i > 0 && path != basePath {
+			return nil, "", fmt.Errorf("the servers have different paths %q and %q, a base path is required", basePath, path)
+		}
+		basePath = path
+		if host := gwapiv1.Hostname(u.Hostname()); host != "" && !hostnameListed(hostnames, host) {
+			hostnames = append(hostnames, host)
+		}
+	}
+	return hostnames, basePath, nil
+}
+
+func hostnameListed(hostnames []gwapiv1.Hostname, hostname gwapiv1.Hostname) bool {
+	for _, h := range hostnames {
+		if h == hostname {
+			return true
+		}
+	}
+	return false
+}
+
+// openAPIOperations returns the operations of the document, sorted by path and method.
+func openAPIOperations(doc *spec3.OpenAPI) ([]*openAPIOperation, error) {
+	if doc.Paths == nil {
+		return nil, nil
+	}
+	paths := make([]string, 0, len(doc.Paths.Paths))
+	for path := range doc.Paths.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var operations []*openAPIOperation
+	for _, path := range paths {
+		item := doc.Paths.Paths[path]
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+		for _, m := range openAPIMethods {
+			op := m.operation(item)
+			if op == nil {
+				continue
+			}
+			o := &openAPIOperation{
+				name:     op.OperationId,
+				method:   m.method,
+				path:     path,
+				security: doc.SecurityRequirement,
+			}
+			if o.name == "" {
+				o.name = string(m.method) + " " + path
+			}
+			if op.SecurityRequirement != nil {
+				o.security = op.SecurityRequirement
+			}
+			var err error
+			if o.timeout, err = openAPITimeout(op.Extensions, item.Extensions); err != nil {
+				return nil, fmt.Errorf("operation %s: %w", o.name, err)
+			}
+			if o.rateLimit, err = openAPIRateLimit(op.Extensions, item.Extensions); err != nil {
+				return nil, fmt.Errorf("operation %s: %w", o.name, err)
+			}
+			operations = append(operations, o)
+		}
+	}
+	return operations, nil
+}
+
+// openAPIExtension unmarshals the extension of an operation, or of its path if the operation doesn't set it.
+func openAPIExtension(name string, out any, operation, path map[string]any) (bool, error) {
+	for _, extensions := range []map[string]any{operation, path} {
+		value, ok := extensions[name]
+		if !ok {
+			continue
+		}
+		content, err := json.Marshal(value)
+		if err != nil {
+			return false, err
+		}
+		if err := json.Unmarshal(content, out); err != nil {
+			return false, fmt.Errorf("invalid %s %s: %w", name, content, err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func openAPITimeout(operation, path map[string]any) (*gwapiv1.Duration, error) {
+	var timeout gwapiv1.Duration
+	if found, err := openAPIExtension(OpenAPITimeoutExtension, &timeout, operation, path); !found || err != nil {
+		return nil, err
+	}
+	if !openAPIDuration.MatchString(string(timeout)) {
+		return nil, fmt.Errorf("invalid %s %q, e.g. 10s or 1m30s is expected", OpenAPITimeoutExtension, timeout)
+	}
+	return &timeout, nil
+}
+
+func openAPIRateLimit(operation, path map[string]any) (*egv1a1.RateLimitValue, error) {
+	var limit egv1a1.RateLimitValue
+	if found, err := openAPIExtension(OpenAPIRateLimitExtension, &limit, operation, path); !found || err != nil {
+		return nil, err
+	}
+	switch limit.Unit {
+	case egv1a1.RateLimitUnitSecond, egv1a1.RateLimitUnitMinute, egv1a1.RateLimitUnitHour, egv1a1.RateLimitUnitDay:
+	default:
+		return nil, fmt.Errorf("invalid %s unit %q, one of Second, Minute, Hour or Day is expected", OpenAPIRateLimitExtension, limit.Unit)
+	}
+	if limit.Requests == 0 {
+		return nil, fmt.Errorf("invalid %s, a number of requests is required", OpenAPIRateLimitExtension)
+	}
+	return &limit, nil
+}
+
+// openAPIRouteGroups groups the operations by security requirements. The operations with the security
+// requirements of the document share the HTTPRoute named after the document, and each rate limited
+// operation has its own HTTPRoute, as a local rate limit applies to all the requests of an HTTPRoute.
+func openAPIRouteGroups(name string, security []map[string][]string, operations []*openAPIOperation) []*openAPIRouteGroup {
+	var (
+		groups []*openAPIRouteGroup
+		byKey  = map[string]*openAPIRouteGroup{}
+		names  = map[string]bool{}
+	)
+	documentKey := openAPISecurityKey(security)
+	for _, op := range operations {
+		key := openAPISecurityKey(op.security)
+		if op.rateLimit != nil {
+			key += "\x00" + string(op.method) + " " + op.path
+		}
+		group, ok := byKey[key]
+		if !ok {
+			var suffix string
+			switch {
+			case op.rateLimit != nil:
+				suffix = op.name
+			case key != documentKey && len(op.security) == 0:
+				suffix = "public"
+			case key != documentKey:
+				suffix = openAPISecuritySuffix(op.security)
+			}
+			groupName := name
+			if suffix != "" {
+				groupName = name + "-" + openAPIName(suffix)
+			}
+			// Different requirements can have the same suffix, e.g. when the scopes differ by their case.
+			for i := 2; names[groupName]; i++ {
+				groupName = fmt.Sprintf("%s-%s-%d", name, openAPIName(suffix), i)
+			}
+			names[groupName] = true
+			group = &openAPIRouteGroup{name: groupName, security: op.security, rateLimit: op.rateLimit}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+		group.operations = append(group.operations, op)
+	}
+	return groups
+}
+
+// openAPISecurityKey identifies the security requirements of an operation.
+func openAPISecurityKey(security []map[string][]string) string {
+	if security == nil {
+		return ""
+	}
+	content, _ := json.Marshal(security)
+	return string(content)
+}
+
+// openAPISecuritySuffix names the security requirements of an operation after their schemes and scopes.
+func openAPISecuritySuffix(security []map[string][]string) string {
+	var parts []string
+	for _, requirement := range security {
+		schemes := make([]string, 0, len(requirement))
+		for scheme := range requirement {
+			schemes = append(schemes, scheme)
+		}
+		sort.Strings(schemes)
+		for _, scheme := range schemes {
+			parts = append(parts, scheme)
+			parts = append(parts, requirement[scheme]...)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// openAPIHTTPRoutes generates the HTTPRoutes of the operations of a group, split to fit the limits of the API.
+func openAPIHTTPRoutes(objectMeta metav1.ObjectMeta, operations []*openAPIOperation, opts *OpenAPIOptions,
+	hostnames []gwapiv1.Hostname, basePath string,
+) []*gwapiv1.HTTPRoute {
+	var rules []gwapiv1.HTTPRouteRule
+	ruleIndexes := map[string]int{}
+	for _, op := range operations {
+		var timeout string
+		if op.timeout != nil {
+			timeout = string(*op.timeout)
+		}
+		i, ok := ruleIndexes[timeout]
+		if !ok || len(rules[i].Matches) == openAPIMaxMatches {
+			rule := gwapiv1.HTTPRouteRule{
+				BackendRefs: []gwapiv1.HTTPBackendRef{{
+					BackendRef: gwapiv1.BackendRef{BackendObjectReference: opts.BackendRef},
+				}},
+			}
+			if op.timeout != nil {
+				rule.Timeouts = &gwapiv1.HTTPRouteTimeouts{Request: op.timeout}
+			}
+			rules = append(rules, rule)
+			i = len(rules) - 1
+			ruleIndexes[timeout] = i
+		}
+		rules[i].Matches = append(rules[i].Matches, openAPIMatch(basePath+op.path, op.method))
+	}
+
+	var routes []*gwapiv1.HTTPRoute
+	for i := 0; i < len(rules); i += openAPIMaxRules {
+		route := &gwapiv1.HTTPRoute{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: gwapiv1.GroupVersion.String(),
+				Kind:       resource.KindHTTPRoute,
+			},
+			ObjectMeta: *objectMeta.DeepCopy(),
+		}
+		if i > 0 {
+			route.Name = fmt.Sprintf("%s-%d", objectMeta.Name, i/openAPIMaxRules+1)
+		}
+		route.Spec.ParentRefs = opts.ParentRefs
+		route.Spec.Hostnames = hostnames
+		route.Spec.Rules = rules[i:min(i+openAPIMaxRules, len(rules))]
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// openAPIMatch matches the requests of an operation. The paths with parameters are matched with a regular
+// expression, in which a parameter matches a path segment.
+func openAPIMatch(path string, method gwapiv1.HTTPMethod) gwapiv1.HTTPRouteMatch {
+	match := gwapiv1.HTTPRouteMatch{
+		Path: &gwapiv1.HTTPPathMatch{
+			Type:  ptr.To(gwapiv1.PathMatchExact),
+			Value: ptr.To(path),
+		},
+		Method: ptr.To(method),
+	}
+	if openAPIPathTemplate.MatchString(path) {
+		var expr strings.Builder
+		last := 0
+		for _, loc := range openAPIPathTemplate.FindAllStringIndex(path, -1) {
+			expr.WriteString(regexp.QuoteMeta(path[last:loc[0]]))
+			expr.WriteString("[^/]+")
+			last = loc[1]
+		}
+		expr.WriteString(regexp.QuoteMeta(path[last:]))
+		match.Path.Type = ptr.To(gwapiv1.PathMatchRegularExpression)
+		match.Path.Value = ptr.To(expr.String())
+	}
+	return match
+}
+
+// openAPISecurity configures the authentication of a SecurityPolicy from the security requirements of an operation.
+// The requirements are alternatives, which are supported when they use the same kind of scheme: the tokens of any
+// JWT provider, or the keys of any API key scheme, are accepted.
+func openAPISecurity(spec *egv1a1.SecurityPolicySpec, security []map[string][]string, schemes spec3.SecuritySchemes) error {
+	var (
+		optional     bool
+		requirements []map[string][]string
+	)
+	for _, requirement := range security {
+		if len(requirement) == 0 {
+			optional = true
+			continue
+		}
+		requirements = append(requirements, requirement)
+	}
+	if len(requirements) == 0 {
+		return nil
+	}
+
+	var scoped, unscoped []egv1a1.AuthorizationRule
+	for _, requirement := range requirements {
+		names := make([]string, 0, len(requirement))
+		for name := range requirement {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(requirements) > 1 && len(names) > 1 {
+			return fmt.Errorf("alternatives requiring several security schemes together aren't supported")
+		}
+
+		for _, name := range names {
+			scheme, ok := schemes[name]
+			if !ok {
+				return fmt.Errorf("security scheme %s not found", name)
+			}
+			switch kind := openAPISchemeKind(scheme); kind {
+			case "jwt":
+				if spec.JWT == nil {
+					spec.JWT = &egv1a1.JWT{}
+				} else if len(requirements) == 1 {
+					return fmt.Errorf("requiring several bearer schemes together isn't supported")
+				}
+				provider, err := openAPIJWTProvider(name, scheme)
+				if err != nil {
+					return err
+				}
+				spec.JWT.Providers = append(spec.JWT.Providers, *provider)
+				rule := egv1a1.AuthorizationRule{
+					Name:   ptr.To(name),
+					Action: egv1a1.AuthorizationActionAllow,
+				}
+				if scopes := requirement[name]; len(scopes) > 0 {
+					rule.Principal.JWT = &egv1a1.JWTPrincipal{Provider: name}
+					for _, scope := range scopes {
+						rule.Principal.JWT.Scopes = append(rule.Principal.JWT.Scopes, egv1a1.JWTScope(scope))
+					}
+					scoped = append(scoped, rule)
+				} else {
+					unscoped = append(unscoped, rule)
+				}
+			case "apiKey":
+				if spec.APIKeyAuth == nil {
+					spec.APIKeyAuth = &egv1a1.APIKeyAuth{}
+				} else if len(requirements) == 1 {
+					return fmt.Errorf("requiring several API keys together isn't supported")
+				}
+				extractFrom := &egv1a1.ExtractFrom{}
+				switch scheme.In {
+				case "header":
+					extractFrom.Headers = []string{scheme.Name}
+				case "query":
+					extractFrom.Params = []string{scheme.Name}
+				case "cookie":
+					extractFrom.Cookies = []string{scheme.Name}
+				default:
+					return fmt.Errorf("security scheme %s: invalid location %q of the API key", name, scheme.In)
+				}
+				spec.APIKeyAuth.ExtractFrom = append(spec.APIKeyAuth.ExtractFrom, extractFrom)
+				spec.APIKeyAuth.CredentialRefs = append(spec.APIKeyAuth.CredentialRefs, openAPISecretRef(name, scheme))
+			case "basic":
+				if spec.BasicAuth != nil {
+					return fmt.Errorf("several basic security schemes aren't supported")
+				}
+				spec.BasicAuth = &egv1a1.BasicAuth{Users: openAPISecretRef(name, scheme)}
+			default:
+				return fmt.Errorf("security scheme %s of type %s isn't supported", name, kind)
+			}
+		}
+	}
+
+	kinds := 0
+	for _, configured := range []bool{spec.JWT != nil, spec.APIKeyAuth != nil, spec.BasicAuth != nil} {
+		if configured {
+			kinds++
+		}
+	}
+	if len(requirements) > 1 && kinds > 1 {
+		return fmt.Errorf("alternatives of different kinds of security schemes aren't supported")
+	}
+	if spec.JWT != nil && len(spec.JWT.Providers) > openAPIMaxJWTProviders {
+		return fmt.Errorf("more than %d bearer schemes aren't supported", openAPIMaxJWTProviders)
+	}
+	if optional {
+		if spec.JWT == nil || kinds > 1 || len(scoped) > 0 {
+			return fmt.Errorf("optional security is only supported for bearer schemes without scopes")
+		}
+		spec.JWT.Optional = ptr.To(true)
+	}
+	if len(scoped) > 0 {
+		if len(unscoped) > 0 {
+			return fmt.Errorf("alternatives with and without scopes aren't supported")
+		}
+		spec.Authorization = &egv1a1.Authorization{
+			DefaultAction: ptr.To(egv1a1.AuthorizationActionDeny),
+			Rules:         scoped,
+		}
+	}
+	return nil
+}
+
+// openAPISchemeKind returns the kind of authentication of a security scheme: "jwt", "apiKey" or "basic",
+// or its type if it isn't supported.
+func openAPISchemeKind(scheme *spec3.SecurityScheme) string {
+	switch {
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"),
+		scheme.Type == "oauth2", scheme.Type == "openIdConnect":
+		return "jwt"
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+		return "basic"
+	case scheme.Type == "apiKey":
+		return "apiKey"
+	case scheme.Type == "http":
+		return "http " + scheme.Scheme
+	}
+	return scheme.Type
+}
+
+func openAPIJWTProvider(name string, scheme *spec3.SecurityScheme) (*egv1a1.JWTProvider, error) {
+	jwksURI, _ := scheme.Extensions.GetString(OpenAPIJWKSExtension)
+	if jwksURI == "" {
+		return nil, fmt.Errorf("security scheme %s: the %s extension is required", name, OpenAPIJWKSExtension)
+	}
+	provider := &egv1a1.JWTProvider{
+		Name:       name,
+		RemoteJWKS: &egv1a1.RemoteJWKS{URI: jwksURI},
+	}
+	provider.Issuer, _ = scheme.Extensions.GetString(OpenAPIIssuerExtension)
+	if provider.Issuer == "" && scheme.Type == "openIdConnect" {
+		provider.Issuer = strings.TrimSuffix(scheme.OpenIdConnectUrl, "/.well-known/openid-configuration")
+	}
+	provider.Audiences, _ = scheme.Extensions.GetStringSlice(OpenAPIAudiencesExtension)
+	return provider, nil
+}
+
+func openAPISecretRef(name string, scheme *spec3.SecurityScheme) gwapiv1.SecretObjectReference {
+	secret, _ := scheme.Extensions.GetString(OpenAPISecretExtension)
+	if secret == "" {
+		secret = openAPIName(name)
+	}
+	return gwapiv1.SecretObjectReference{Name: gwapiv1.ObjectName(secret)}
+}
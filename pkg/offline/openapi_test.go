@@ -0,0 +1,193 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package offline
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+)
+
+func testOpenAPIOptions() *OpenAPIOptions {
+	return &OpenAPIOptions{
+		ParentRefs: []gwapiv1.ParentReference{{Name: "eg"}},
+		BackendRef: gwapiv1.BackendObjectReference{Name: "backend", Port: ptr.To(gwapiv1.PortNumber(3000))},
+	}
+}
+
+func generateTestOpenAPIResources(t *testing.T) *Resources {
+	t.Helper()
+	document, err := os.ReadFile("testdata/openapi.yaml")
+	require.NoError(t, err)
+	resources, err := GenerateFromOpenAPI(document, testOpenAPIOptions())
+	require.NoError(t, err)
+	return resources
+}
+
+func TestGenerateFromOpenAPI(t *testing.T) {
+	resources := generateTestOpenAPIResources(t)
+
+	routes := map[string]gwapiv1.HTTPRouteSpec{}
+	for _, route := range resources.HTTPRoutes {
+		require.Equal(t, "default", route.Namespace)
+		require.Equal(t, map[string]string{OpenAPILabel: "pet-store"}, route.Labels)
+		routes[route.Name] = route.Spec
+	}
+	require.Len(t, routes, 5)
+
+	// The operations with the security of the document, grouped by timeout.
+	spec := routes["pet-store"]
+	require.Equal(t, []gwapiv1.Hostname{"pets.example.com"}, spec.Hostnames)
+	require.Equal(t, []gwapiv1.ParentReference{{Name: "eg"}}, spec.ParentRefs)
+	require.Len(t, spec.Rules, 2)
+	require.Nil(t, spec.Rules[0].Timeouts)
+	require.Equal(t, []gwapiv1.HTTPRouteMatch{{
+		Path:   &gwapiv1.HTTPPathMatch{Type: ptr.To(gwapiv1.PathMatchRegularExpression), Value: ptr.To("/v1/pets/[^/]+")},
+		Method: ptr.To(gwapiv1.HTTPMethodGet),
+	}}, spec.Rules[0].Matches)
+	require.Equal(t, ptr.To(gwapiv1.Duration("30s")), spec.Rules[1].Timeouts.Request)
+	require.Equal(t, `/v1/pets/[^/]+/photos/[^/]+\.jpg`, *spec.Rules[1].Matches[0].Path.Value)
+	require.Equal(t, "backend", string(spec.Rules[1].BackendRefs[0].Name))
+
+	// The operations with their own security or rate limit.
+	spec = routes["pet-store-public"]
+	require.Equal(t, []gwapiv1.HTTPRouteMatch{{
+		Path:   &gwapiv1.HTTPPathMatch{Type: ptr.To(gwapiv1.PathMatchExact), Value: ptr.To("/v1/pets")},
+		Method: ptr.To(gwapiv1.HTTPMethodGet),
+	}}, spec.Rules[0].Matches)
+	require.Equal(t, ptr.To(gwapiv1.Duration("5s")), spec.Rules[0].Timeouts.Request)
+	require.Contains(t, routes, "pet-store-createpet")
+	require.Contains(t, routes, "pet-store-bearerauth-pets-write")
+	require.Contains(t, routes, "pet-store-apikey-adminkey")
+
+	policies := map[string]egv1a1.SecurityPolicySpec{}
+	for _, sp := range resources.SecurityPolicies {
+		policies[sp.Name] = sp.Spec
+	}
+	require.Len(t, policies, 4)
+	require.NotContains(t, policies, "pet-store-public")
+
+	spec2 := policies["pet-store"]
+	require.Equal(t, []gwapiv1a2.LocalPolicyTargetReferenceWithSectionName{{
+		LocalPolicyTargetReference: gwapiv1a2.LocalPolicyTargetReference{Group: gwapiv1.GroupName, Kind: "HTTPRoute", Name: "pet-store"},
+	}}, spec2.TargetRefs)
+	require.Equal(t, []egv1a1.JWTProvider{{
+		Name:       "bearerAuth",
+		Issuer:     "https://auth.example.com",
+		Audiences:  []string{"pet-store"},
+		RemoteJWKS: &egv1a1.RemoteJWKS{URI: "https://auth.example.com/.well-known/jwks.json"},
+	}}, spec2.JWT.Providers)
+	require.Nil(t, spec2.Authorization)
+
+	spec2 = policies["pet-store-bearerauth-pets-write"]
+	require.Equal(t, ptr.To(egv1a1.AuthorizationActionDeny), spec2.Authorization.DefaultAction)
+	require.Equal(t, []egv1a1.AuthorizationRule{{
+		Name:      ptr.To("bearerAuth"),
+		Action:    egv1a1.AuthorizationActionAllow,
+		Principal: egv1a1.Principal{JWT: &egv1a1.JWTPrincipal{Provider: "bearerAuth", Scopes: []egv1a1.JWTScope{"pets.write"}}},
+	}}, spec2.Authorization.Rules)
+
+	spec2 = policies["pet-store-apikey-adminkey"]
+	require.Nil(t, spec2.JWT)
+	require.Equal(t, &egv1a1.APIKeyAuth{
+		CredentialRefs: []gwapiv1.SecretObjectReference{{Name: "pet-store-api-keys"}, {Name: "adminkey"}},
+		ExtractFrom:    []*egv1a1.ExtractFrom{{Headers: []string{"X-API-Key"}}, {Params: []string{"admin_key"}}},
+	}, spec2.APIKeyAuth)
+
+	require.Len(t, resources.BackendTrafficPolicies, 1)
+	btp := resources.BackendTrafficPolicies[0]
+	require.Equal(t, "pet-store-createpet", btp.Name)
+	require.Equal(t, egv1a1.RateLimitValue{Requests: 10, Unit: egv1a1.RateLimitUnitMinute}, btp.Spec.RateLimit.Local.Rules[0].Limit)
+
+	// The generation is idempotent.
+	require.Equal(t, resources, generateTestOpenAPIResources(t))
+}
+
+func TestGenerateFromOpenAPITranslation(t *testing.T) {
+	generated := generateTestOpenAPIResources(t)
+	resources := loadTestResources(t)
+	resources.HTTPRoutes = append(resources.HTTPRoutes, generated.HTTPRoutes...)
+	resources.SecurityPolicies = generated.SecurityPolicies
+	resources.BackendTrafficPolicies = generated.BackendTrafficPolicies
+	for _, name := range []string{"pet-store-api-keys", "adminkey"} {
+		resources.Secrets = append(resources.Secrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data:       map[string][]byte{"client": []byte("key")},
+		})
+	}
+
+	result, err := Translate(resources, nil)
+	require.NoError(t, err)
+	for _, route := range result.Resources.HTTPRoutes[2:] {
+		require.Len(t, route.Status.Parents, 1)
+		require.True(t, meta.IsStatusConditionTrue(route.Status.Parents[0].Conditions, string(gwapiv1.RouteConditionAccepted)), route.Name)
+		require.True(t, meta.IsStatusConditionTrue(route.Status.Parents[0].Conditions, string(gwapiv1.RouteConditionResolvedRefs)), route.Name)
+	}
+	for _, sp := range result.Resources.SecurityPolicies {
+		require.Len(t, sp.Status.Ancestors, 1)
+		require.True(t, meta.IsStatusConditionTrue(sp.Status.Ancestors[0].Conditions, string(gwapiv1a2.PolicyConditionAccepted)), sp.Name)
+	}
+	btp := result.Resources.BackendTrafficPolicies[0]
+	require.Len(t, btp.Status.Ancestors, 1)
+	require.True(t, meta.IsStatusConditionTrue(btp.Status.Ancestors[0].Conditions, string(gwapiv1a2.PolicyConditionAccepted)))
+}
+
+func TestGenerateFromOpenAPIErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		document string
+		err      string
+	}{
+		{
+			name:     "swagger",
+			document: "swagger: \"2.0\"\ninfo: {title: API}\npaths: {/a: {get: {}}}",
+			err:      "only the OpenAPI 3 documents are supported",
+		},
+		{
+			name:     "invalid timeout",
+			document: "openapi: 3.0.0\ninfo: {title: API}\npaths: {/a: {get: {x-envoy-gateway-timeout: 10}}}",
+			err:      "operation GET /a: invalid x-envoy-gateway-timeout",
+		},
+		{
+			name:     "invalid rate limit",
+			document: "openapi: 3.0.0\ninfo: {title: API}\npaths: {/a: {get: {x-envoy-gateway-rate-limit: {requests: 1, unit: Week}}}}",
+			err:      "operation GET /a: invalid x-envoy-gateway-rate-limit unit \"Week\"",
+		},
+		{
+			name: "missing JWKS",
+			document: "openapi: 3.0.0\ninfo: {title: API}\nsecurity: [{oauth: []}]\npaths: {/a: {get: {}}}\n" +
+				"components: {securitySchemes: {oauth: {type: oauth2}}}",
+			err: "security scheme oauth: the x-envoy-gateway-jwks-uri extension is required",
+		},
+		{
+			name: "unsupported scheme",
+			document: "openapi: 3.0.0\ninfo: {title: API}\nsecurity: [{mtls: []}]\npaths: {/a: {get: {}}}\n" +
+				"components: {securitySchemes: {mtls: {type: mutualTLS}}}",
+			err: "security scheme mtls of type mutualTLS isn't supported",
+		},
+		{
+			name: "alternatives of different kinds",
+			document: "openapi: 3.0.0\ninfo: {title: API}\nsecurity: [{key: []}, {basic: []}]\npaths: {/a: {get: {}}}\n" +
+				"components: {securitySchemes: {key: {type: apiKey, in: header, name: X-Key}, basic: {type: http, scheme: basic}}}",
+			err: "alternatives of different kinds of security schemes aren't supported",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := GenerateFromOpenAPI([]byte(tc.document), testOpenAPIOptions())
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
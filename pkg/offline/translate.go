@@ -0,0 +1,231 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+// Package offline translates Gateway API and Envoy Gateway resources into the
+// xDS IR, the xDS resources and the statuses of the resources without a
+// running Envoy Gateway or Kubernetes cluster.
+package offline
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/wukongcloud/gateway/api/v1alpha1/validation"
+	extensionTypes "github.com/wukongcloud/gateway/internal/extension/types"
+	"github.com/wukongcloud/gateway/internal/gatewayapi"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/resource"
+	"github.com/wukongcloud/gateway/internal/gatewayapi/status"
+	"github.com/wukongcloud/gateway/internal/infrastructure/kubernetes/ratelimit"
+	"github.com/wukongcloud/gateway/internal/ir"
+	"github.com/wukongcloud/gateway/internal/logging"
+	"github.com/wukongcloud/gateway/internal/utils"
+	"github.com/wukongcloud/gateway/internal/xds/bootstrap"
+	"github.com/wukongcloud/gateway/internal/xds/translator"
+	xdstypes "github.com/wukongcloud/gateway/internal/xds/types"
+)
+
+type (
+	// Resources holds the Gateway API and Envoy Gateway resources of a GatewayClass.
+	Resources = resource.Resources
+	// XdsIR is the xDS IR of a Gateway, or of a GatewayClass when its Gateways are merged.
+	XdsIR = ir.Xds
+	// InfraIR is the infrastructure IR of a Gateway, or of a GatewayClass when its Gateways are merged.
+	InfraIR = ir.Infra
+	// XdsResources holds the xDS resources translated from an xDS IR, and the
+	// statuses of the EnvoyPatchPolicies applied to them.
+	XdsResources = xdstypes.ResourceVersionTable
+
+	// ExtensionManager provides the clients of the xDS hooks of an extension.
+	ExtensionManager = extensionTypes.Manager
+	// XDSHookClient runs the xDS hooks of an extension.
+	XDSHookClient = extensionTypes.XDSHookClient
+)
+
+// Options configures the translation.
+type Options struct {
+	// ControllerName is the controller name of the Envoy Gateway the resources are translated for.
+	// If unset, the controller name of the GatewayClass of the resources is used.
+	ControllerName string
+
+	// Namespace and DNSDomain are the namespace and the cluster DNS domain Envoy Gateway runs in,
+	// used to compute the URL of the global rate limit service.
+	// If unset, "envoy-gateway-system" and "cluster.local" are used.
+	Namespace string
+	DNSDomain string
+
+	// GlobalRateLimitDisabled disables the translation of global rate limits.
+	GlobalRateLimitDisabled bool
+
+	// EnvoyPatchPolicyDisabled disables the translation of EnvoyPatchPolicies.
+	EnvoyPatchPolicyDisabled bool
+
+	// BackendDisabled disables the translation of Backends.
+	BackendDisabled bool
+
+	// ExtensionGroupKinds are the group/kinds of the resources introduced by an extension,
+	// which are referenced by the HTTPRoute filters.
+	ExtensionGroupKinds []schema.GroupKind
+
+	// ExtensionManager runs the xDS hooks of an extension during the xDS translation.
+	ExtensionManager ExtensionManager
+
+	// Logger logs the failures of the xDS hooks. If unset, nothing is logged.
+	Logger *logr.Logger
+}
+
+// Result holds the output of a translation.
+type Result struct {
+	// Resources holds the translated resources with their statuses.
+	Resources *Resources
+	// XdsIR holds the xDS IR keyed by IR key.
+	XdsIR map[string]*XdsIR
+	// InfraIR holds the infrastructure IR keyed by IR key.
+	InfraIR map[string]*InfraIR
+	// Xds holds the xDS resources keyed by IR key.
+	Xds map[string]*XdsResources
+}
+
+// LoadResources loads the resources from Kubernetes YAML manifests. When addMissingResources
+// is set, the Namespaces and Services referenced by the resources but missing from the
+// manifests are added.
+func LoadResources(yamlBytes []byte, addMissingResources bool) (*Resources, error) {
+	return resource.LoadResourcesFromYAMLBytes(yamlBytes, addMissingResources)
+}
+
+// Translate translates the resources of a GatewayClass into the xDS IR and the xDS resources,
+// and computes the statuses of the resources.
+//
+// The statuses are set on the given resources, callers that need to keep them untouched should
+// pass a deep copy. The translation is best-effort: invalid resources are reflected in their
+// statuses, and the failures of the xDS translation are returned along with the partial Result.
+func Translate(resources *Resources, opts *Options) (*Result, error) {
+	if resources == nil || resources.GatewayClass == nil {
+		return nil, fmt.Errorf("the GatewayClass resource is required")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	controllerName := opts.ControllerName
+	if controllerName == "" {
+		controllerName = string(resources.GatewayClass.Spec.ControllerName)
+	}
+
+	gTranslator := &gatewayapi.Translator{
+		GatewayControllerName:   controllerName,
+		GatewayClassName:        gwapiv1.ObjectName(resources.GatewayClass.Name),
+		GlobalRateLimitEnabled:  !opts.GlobalRateLimitDisabled,
+		EndpointRoutingDisabled: true,
+		EnvoyPatchPolicyEnabled: !opts.EnvoyPatchPolicyDisabled,
+		BackendEnabled:          !opts.BackendDisabled,
+		MergeGateways:           gatewayapi.IsMergeGatewaysEnabled(resources),
+		ExtensionGroupKinds:     opts.ExtensionGroupKinds,
+	}
+	// The errors of the Gateway API translation are reflected in the statuses.
+	gRes, _ := gTranslator.Translate(resources)
+
+	setGatewayClassStatus(resources)
+	gRes.GatewayClass = resources.GatewayClass
+	gRes.EnvoyProxyForGatewayClass = resources.EnvoyProxyForGatewayClass
+	gRes.EnvoyPatchPolicies = resources.EnvoyPatchPolicies
+
+	result := &Result{
+		Resources: &gRes.Resources,
+		XdsIR:     gRes.XdsIR,
+		InfraIR:   gRes.InfraIR,
+		Xds:       make(map[string]*XdsResources, len(gRes.XdsIR)),
+	}
+
+	logger := logging.Logger{Logger: logr.Discard()}
+	if opts.Logger != nil {
+		logger.Logger = *opts.Logger
+	}
+	namespace, dnsDomain := opts.Namespace, opts.DNSDomain
+	if namespace == "" {
+		namespace = "envoy-gateway-system"
+	}
+	if dnsDomain == "" {
+		dnsDomain = "cluster.local"
+	}
+
+	// The statuses of the EnvoyPatchPolicies are computed by the xDS translation.
+	for _, policy := range result.Resources.EnvoyPatchPolicies {
+		policy.Status.Ancestors = nil
+	}
+
+	// Translate in a stable order since the EnvoyPatchPolicy statuses are
+	// merged in that order.
+	keys := make([]string, 0, len(gRes.XdsIR))
+	for key := range gRes.XdsIR {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		xTranslator := &translator.Translator{
+			GlobalRateLimit: &translator.GlobalRateLimitSettings{
+				ServiceURL: ratelimit.GetServiceURL(namespace, dnsDomain),
+			},
+			Logger: logger,
+		}
+		if opts.ExtensionManager != nil {
+			xTranslator.ExtensionManager = &opts.ExtensionManager
+		}
+		if resources.EnvoyProxyForGatewayClass != nil {
+			xTranslator.FilterOrder = resources.EnvoyProxyForGatewayClass.Spec.FilterOrder
+		}
+		xRes, err := xTranslator.Translate(gRes.XdsIR[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to translate xds ir %s: %w", key, err))
+		}
+		if xRes == nil {
+			continue
+		}
+		result.Xds[key] = xRes
+		setEnvoyPatchPolicyStatuses(result.Resources, xRes.EnvoyPatchPolicyStatuses)
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// setGatewayClassStatus sets the Accepted condition of the GatewayClass based on
+// the validation of its EnvoyProxy.
+func setGatewayClassStatus(resources *Resources) {
+	if ep := resources.EnvoyProxyForGatewayClass; ep != nil {
+		err := validation.ValidateEnvoyProxy(ep)
+		if err == nil {
+			err = bootstrap.Validate(ep.Spec.Bootstrap)
+		}
+		if err != nil {
+			msg := fmt.Sprintf("%s: %v", status.MsgGatewayClassInvalidParams, err)
+			status.SetGatewayClassAccepted(resources.GatewayClass, false, string(gwapiv1.GatewayClassReasonInvalidParameters), msg)
+			return
+		}
+	}
+	status.SetGatewayClassAccepted(resources.GatewayClass, true, string(gwapiv1.GatewayClassReasonAccepted), status.MsgValidGatewayClass)
+}
+
+// setEnvoyPatchPolicyStatuses merges the ancestor statuses computed by the xDS
+// translation into the EnvoyPatchPolicies.
+func setEnvoyPatchPolicyStatuses(resources *Resources, statuses xdstypes.EnvoyPatchPolicyStatuses) {
+	for _, s := range statuses {
+		if s.Status == nil {
+			continue
+		}
+		key := types.NamespacedName{Namespace: s.Namespace, Name: s.Name}
+		for _, policy := range resources.EnvoyPatchPolicies {
+			if utils.NamespacedName(policy) == key {
+				policy.Status.Ancestors = append(policy.Status.Ancestors, s.Status.Ancestors...)
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright Envoy Gateway Authors
+// SPDX-License-Identifier: Apache-2.0
+// The full text of the Apache license is available in the LICENSE file at
+// the root of the repo.
+
+package offline
+
+import (
+	"os"
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	egv1a1 "github.com/wukongcloud/gateway/api/v1alpha1"
+)
+
+func loadTestResources(t *testing.T) *Resources {
+	t.Helper()
+	content, err := os.ReadFile("testdata/resources.yaml")
+	require.NoError(t, err)
+	resources, err := LoadResources(content, false)
+	require.NoError(t, err)
+	return resources
+}
+
+func TestTranslate(t *testing.T) {
+	result, err := Translate(loadTestResources(t), nil)
+	require.NoError(t, err)
+
+	require.Contains(t, result.XdsIR, "default/eg")
+	require.Contains(t, result.InfraIR, "default/eg")
+	require.Contains(t, result.Xds, "default/eg")
+
+	// The EnvoyPatchPolicy is applied to the listener.
+	require.Equal(t, uint32(1024), findListener(t, result, "default/eg/http").GetPerConnectionBufferLimitBytes().GetValue())
+
+	// The statuses are computed.
+	resources := result.Resources
+	require.True(t, meta.IsStatusConditionTrue(resources.GatewayClass.Status.Conditions, string(gwapiv1.GatewayClassConditionStatusAccepted)))
+	require.Len(t, resources.Gateways, 1)
+	require.Len(t, resources.Gateways[0].Status.Listeners, 1)
+	require.True(t, meta.IsStatusConditionTrue(resources.Gateways[0].Status.Listeners[0].Conditions, string(gwapiv1.ListenerConditionProgrammed)))
+
+	routeConditions := map[string]bool{}
+	for _, route := range resources.HTTPRoutes {
+		require.Len(t, route.Status.Parents, 1)
+		routeConditions[route.Name] = meta.IsStatusConditionTrue(route.Status.Parents[0].Conditions, string(gwapiv1.RouteConditionResolvedRefs))
+	}
+	require.Equal(t, map[string]bool{"backend": true, "missing-backend": false}, routeConditions)
+
+	require.Len(t, resources.EnvoyPatchPolicies, 1)
+	require.Len(t, resources.EnvoyPatchPolicies[0].Status.Ancestors, 1)
+	require.True(t, meta.IsStatusConditionTrue(resources.EnvoyPatchPolicies[0].Status.Ancestors[0].Conditions, string(egv1a1.PolicyConditionProgrammed)))
+}
+
+func TestTranslateWithoutGatewayClass(t *testing.T) {
+	resources := loadTestResources(t)
+	resources.GatewayClass = nil
+	_, err := Translate(resources, nil)
+	require.Error(t, err)
+}
+
+func TestTranslateWithOptions(t *testing.T) {
+	resources := loadTestResources(t)
+	result, err := Translate(resources, &Options{EnvoyPatchPolicyDisabled: true})
+	require.NoError(t, err)
+
+	require.NotEqual(t, uint32(1024), findListener(t, result, "default/eg/http").GetPerConnectionBufferLimitBytes().GetValue())
+	ancestors := result.Resources.EnvoyPatchPolicies[0].Status.Ancestors
+	require.Len(t, ancestors, 1)
+	require.True(t, meta.IsStatusConditionFalse(ancestors[0].Conditions, string(gwapiv1a2.PolicyConditionAccepted)))
+}
+
+func findListener(t *testing.T, result *Result, name string) *listenerv3.Listener {
+	t.Helper()
+	for _, r := range result.Xds["default/eg"].XdsResources[resourcev3.ListenerType] {
+		if listener := r.(*listenerv3.Listener); listener.Name == name {
+			return listener
+		}
+	}
+	require.Failf(t, "listener not found", "listener %s", name)
+	return nil
+}
+
+func TestTranslateWithExtensionManager(t *testing.T) {
+	result, err := Translate(loadTestResources(t), &Options{ExtensionManager: &fakeExtensionManager{}})
+	require.NoError(t, err)
+
+	routes := result.Xds["default/eg"].XdsResources[resourcev3.RouteType]
+	require.Len(t, routes, 1)
+	var names []string
+	for _, vHost := range routes[0].(*routev3.RouteConfiguration).VirtualHosts {
+		for _, route := range vHost.Routes {
+			names = append(names, route.Name)
+		}
+	}
+	require.Contains(t, names, "extension-route")
+}
+
+// fakeExtensionManager adds a route to every virtual host.
+type fakeExtensionManager struct{}
+
+func (*fakeExtensionManager) HasExtension(gwapiv1.Group, gwapiv1.Kind) bool { return false }
+
+func (*fakeExtensionManager) GetPreXDSHookClient(egv1a1.XDSTranslatorHook) (XDSHookClient, error) {
+	return nil, nil
+}
+
+func (*fakeExtensionManager) GetPostXDSHookClient(hook egv1a1.XDSTranslatorHook) (XDSHookClient, error) {
+	if hook != egv1a1.XDSVirtualHost {
+		return nil, nil
+	}
+	return &fakeHookClient{}, nil
+}
+
+func (*fakeExtensionManager) FailOpen() bool { return false }
+
+type fakeHookClient struct{}
+
+func (*fakeHookClient) PostRouteModifyHook(route *routev3.Route, _ []string, _ []*unstructured.Unstructured) (*routev3.Route, error) {
+	return route, nil
+}
+
+func (*fakeHookClient) PostVirtualHostModifyHook(vHost *routev3.VirtualHost) (*routev3.VirtualHost, error) {
+	vHost.Routes = append(vHost.Routes, &routev3.Route{
+		Name:   "extension-route",
+		Match:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/extension"}},
+		Action: &routev3.Route_DirectResponse{DirectResponse: &routev3.DirectResponseAction{Status: 200}},
+	})
+	return vHost, nil
+}
+
+func (*fakeHookClient) PostHTTPListenerModifyHook(listener *listenerv3.Listener, _ []*unstructured.Unstructured) (*listenerv3.Listener, error) {
+	return listener, nil
+}
+
+func (*fakeHookClient) PostTranslateModifyHook(clusters []*clusterv3.Cluster, secrets []*tlsv3.Secret) ([]*clusterv3.Cluster, []*tlsv3.Secret, error) {
+	return clusters, secrets, nil
+}